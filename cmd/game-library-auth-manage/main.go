@@ -1,16 +1,21 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/OutOfStack/game-library-auth/internal/appconf"
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	store "github.com/OutOfStack/game-library-auth/internal/database"
 	"github.com/OutOfStack/game-library-auth/pkg/crypto"
 	"github.com/OutOfStack/game-library-auth/pkg/database"
 	"github.com/jmoiron/sqlx"
 	migrate "github.com/rubenv/sql-migrate"
+	"go.uber.org/zap"
 )
 
 const (
@@ -53,6 +58,20 @@ func main() {
 		if err := rollbackMigration(dsn, migrations); err != nil {
 			log.Fatalf("Rollback migration error: %v", err)
 		}
+	case "clear-lockout":
+		if dsn == "" {
+			log.Fatal("DB_DSN environment or config variable is required")
+		}
+		if flag.Arg(1) == "" {
+			log.Fatal("username or ip is required: clear-lockout <username|ip>")
+		}
+		if err := clearLockout(dsn, flag.Arg(1)); err != nil {
+			log.Fatalf("Clear lockout error: %v", err)
+		}
+	case "render-email":
+		if err := renderEmail(fromFile, flag.Args()[1:]); err != nil {
+			log.Fatalf("Render email error: %v", err)
+		}
 	case "keygen":
 		keygen()
 	case "secretgen":
@@ -61,8 +80,10 @@ func main() {
 		fmt.Println("Unknown command, available commands:")
 		fmt.Println("migrate: applies all migrations to database")
 		fmt.Println("rollback: roll backs one last migration of database")
+		fmt.Println("clear-lockout <username|ip>: clears failed sign in attempts and lockout for username or client ip")
+		fmt.Println("render-email [-locale <locale>] [-out <dir>] [template...]: renders email templates with sample data to HTML and text files, all templates if none provided")
 		fmt.Println("keygen: creates private/public key pair files")
-		fmt.Println("secretgen: generates a cryptographically secure random secret for HMAC")
+		fmt.Println("secretgen: generates a cryptographically secure random secret for HMAC or encryption")
 	}
 }
 
@@ -111,6 +132,89 @@ func rollbackMigration(dsn string, migrations *migrate.FileMigrationSource) erro
 	return nil
 }
 
+func clearLockout(dsn string, subject string) error {
+	db := connectDB(dsn)
+	defer func() {
+		if cErr := db.Close(); cErr != nil {
+			log.Printf("can't close database: %v", cErr)
+		}
+	}()
+
+	repo := store.NewUserRepo(db, zap.NewNop())
+	n, err := repo.DeleteLoginAttemptsBySubject(context.Background(), subject)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		fmt.Printf("There is no lockout for %s\n", subject)
+	} else {
+		fmt.Printf("Lockout cleared for %s. Deleted %d records\n", subject, n)
+	}
+	return nil
+}
+
+// renders email templates with sample data and writes them to <template>.<locale>.html and .txt files in out dir.
+// Links and templates dir are taken from config file or environment variables
+func renderEmail(fromFile bool, args []string) error {
+	flags := flag.NewFlagSet("render-email", flag.ExitOnError)
+	locale := flags.String("locale", mailer.TemplateDefaultLocale, "locale of rendered emails")
+	outDir := flags.String("out", ".", "directory rendered emails are written to")
+	_ = flags.Parse(args)
+
+	rendererCfg := mailer.RendererConfig{
+		ContactEmail:   os.Getenv("EMAIL_SENDER_CONTACT_EMAIL"),
+		BaseURL:        os.Getenv("EMAIL_SENDER_BASE_URL"),
+		UnsubscribeURL: os.Getenv("EMAIL_SENDER_UNSUBSCRIBE_URL"),
+		VerifyEmailURL: os.Getenv("EMAIL_SENDER_VERIFY_EMAIL_URL"),
+		TemplatesDir:   os.Getenv("EMAIL_SENDER_TEMPLATES_DIR"),
+	}
+	if fromFile {
+		cfg, err := appconf.Get()
+		if err != nil {
+			return fmt.Errorf("read config file: %w", err)
+		}
+		rendererCfg = mailer.RendererConfig{
+			ContactEmail:   cfg.EmailSender.ContactEmail,
+			BaseURL:        cfg.EmailSender.BaseURL,
+			UnsubscribeURL: cfg.EmailSender.UnsubscribeURL,
+			VerifyEmailURL: cfg.EmailSender.VerifyEmailURL,
+			TemplatesDir:   cfg.EmailSender.TemplatesDir,
+		}
+	}
+
+	renderer, err := mailer.NewRenderer(rendererCfg)
+	if err != nil {
+		return err
+	}
+
+	names := flags.Args()
+	if len(names) == 0 {
+		names = mailer.TemplateNames()
+	}
+
+	if err = os.MkdirAll(*outDir, 0o755); err != nil {
+		return fmt.Errorf("create out dir: %w", err)
+	}
+
+	for _, name := range names {
+		msg, pErr := renderer.Preview(name, *locale)
+		if pErr != nil {
+			return pErr
+		}
+
+		basePath := filepath.Join(*outDir, name+"."+*locale)
+		if err = os.WriteFile(basePath+".html", []byte(msg.HTML), 0o600); err != nil {
+			return fmt.Errorf("write %s HTML: %w", name, err)
+		}
+		if err = os.WriteFile(basePath+".txt", []byte(msg.Text), 0o600); err != nil {
+			return fmt.Errorf("write %s text: %w", name, err)
+		}
+		fmt.Printf("Rendered %s (%s) to %s.html and %s.txt\n", name, msg.Subject, basePath, basePath)
+	}
+
+	return nil
+}
+
 func keygen() {
 	if err := crypto.KeyGen(); err != nil {
 		log.Fatalf("Error creating private/public keypair: %v", err)
@@ -125,5 +229,5 @@ func secretgen() {
 	}
 	fmt.Println("Generated secret (base64-encoded 32 bytes):")
 	fmt.Println(secret)
-	fmt.Println("\nAdd this to your app.env file as EMAIL_SENDER_UNSUBSCRIBE_SECRET")
+	fmt.Println("\nAdd this to your app.env file as AUTH_TOTP_ENCRYPTION_KEY or as a key of AUTH_SIGNED_TOKEN_KEYS in <key id>:<secret> format")
 }
@@ -6,21 +6,38 @@ import (
 	"fmt"
 	"log"
 	_ "net/http/pprof"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/OutOfStack/game-library-auth/internal/appconf"
 	auth_ "github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/client/fallbackmailer"
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
 	"github.com/OutOfStack/game-library-auth/internal/client/resendapi"
+	"github.com/OutOfStack/game-library-auth/internal/client/smtpclient"
 	store "github.com/OutOfStack/game-library-auth/internal/database"
 	"github.com/OutOfStack/game-library-auth/internal/facade"
 	"github.com/OutOfStack/game-library-auth/internal/handlers"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/internal/ratelimit"
 	"github.com/OutOfStack/game-library-auth/internal/server"
 	"github.com/OutOfStack/game-library-auth/pkg/crypto"
 	"github.com/OutOfStack/game-library-auth/pkg/database"
 	zaplog "github.com/OutOfStack/game-library-auth/pkg/log"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"go.uber.org/zap"
 	"google.golang.org/api/idtoken"
 )
 
+const (
+	rateLimitsCleanupInterval       = 10 * time.Minute
+	webAuthnSessionsCleanupInterval = 10 * time.Minute
+	emailOutboxDispatchInterval     = time.Second
+	emailOutboxBatchSize            = 20
+)
+
 // @title Game library auth API
 // @version 0.4
 // @description API for game library auth service
@@ -72,15 +89,23 @@ func run() error {
 		return fmt.Errorf("create google token validator: %w", err)
 	}
 
-	// create email sender
-	emailSender, err := resendapi.NewClient(resendapi.Config{
-		APIToken:       cfg.EmailSender.APIToken,
-		FromEmail:      cfg.EmailSender.EmailFrom,
+	// create email renderer. Templates from templates dir are reloaded on SIGHUP
+	renderer, err := mailer.NewRenderer(mailer.RendererConfig{
 		ContactEmail:   cfg.EmailSender.ContactEmail,
 		BaseURL:        cfg.EmailSender.BaseURL,
 		UnsubscribeURL: cfg.EmailSender.UnsubscribeURL,
-		Timeout:        cfg.EmailSender.APITimeout,
+		VerifyEmailURL: cfg.EmailSender.VerifyEmailURL,
+		TemplatesDir:   cfg.EmailSender.TemplatesDir,
 	})
+	if err != nil {
+		return fmt.Errorf("create email renderer: %w", err)
+	}
+	if cfg.EmailSender.TemplatesDir != "" {
+		go reloadEmailTemplates(ctx, renderer, logger)
+	}
+
+	// create email sender
+	emailSender, err := newEmailSender(cfg.EmailSender, renderer, logger)
 	if err != nil {
 		return fmt.Errorf("create email sender client: %w", err)
 	}
@@ -95,11 +120,48 @@ func run() error {
 		return fmt.Errorf("create token service instance: %w", err)
 	}
 
+	// create signed tokens service for email links
+	signingKeys, err := auth_.ParseSigningKeys(cfg.Auth.SignedTokenKeys)
+	if err != nil {
+		return fmt.Errorf("parse signed token keys: %w", err)
+	}
+	signedTokens, err := auth_.NewSignedTokens(signingKeys...)
+	if err != nil {
+		return fmt.Errorf("create signed tokens service: %w", err)
+	}
+
 	// create unsubscribe token generator
-	unsubscribeTokenGenerator := auth_.NewUnsubscribeTokenGenerator([]byte(cfg.EmailSender.UnsubscribeSecret))
+	unsubscribeTokenGenerator := auth_.NewUnsubscribeTokenGenerator(signedTokens, []byte(cfg.EmailSender.UnsubscribeSecret))
+
+	// create email verification link token generator
+	verificationTokenGenerator := auth_.NewEmailVerificationTokenGenerator(signedTokens, []byte(cfg.EmailVerification.LinkSecret))
+
+	// create cipher for secrets stored in database
+	secretCipher, err := crypto.NewCipher(cfg.Auth.TOTPEncryptionKey)
+	if err != nil {
+		return fmt.Errorf("create secret cipher: %w", err)
+	}
+
+	// create webauthn relying party for passkeys
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.WebAuthn.RPID,
+		RPDisplayName: model.WebAuthnRPDisplayName,
+		RPOrigins:     cfg.WebAuthn.Origins(),
+	})
+	if err != nil {
+		return fmt.Errorf("create webauthn: %w", err)
+	}
+	go cleanupWebAuthnSessions(ctx, userRepo, logger)
 
 	// create user facade
-	userFacade := facade.New(logger, userRepo, emailSender, auth, unsubscribeTokenGenerator)
+	userFacade := facade.New(logger, userRepo, emailSender, auth, unsubscribeTokenGenerator, verificationTokenGenerator, secretCipher, webAuthn, facade.EmailCfg{
+		VerificationCodeTTL:        cfg.EmailVerification.CodeTTL,
+		VerificationCodeLength:     cfg.EmailVerification.CodeLength,
+		VerificationCodeAlphabet:   cfg.EmailVerification.CodeAlphabet,
+		ResendVerificationCooldown: cfg.EmailVerification.ResendCooldown,
+		UnsubscribeTokenTTL:        cfg.EmailSender.UnsubscribeTokenTTL,
+	})
+	go dispatchEmailOutbox(ctx, userFacade, logger)
 
 	// auth api
 	authAPI, err := handlers.NewAuthAPI(logger, googleTokenValidator, userFacade, handlers.AuthAPICfg{
@@ -115,12 +177,40 @@ func run() error {
 	// unsubscribe api
 	unsubscribeAPI := handlers.NewUnsubscribeAPI(logger, unsubscribeTokenGenerator, userFacade, cfg.EmailSender.ContactEmail)
 
+	// email delivery webhook api. Enabled only if webhook secret is set
+	var emailWebhookAPI *handlers.EmailWebhookAPI
+	if cfg.EmailSender.WebhookSecret != "" {
+		webhookVerifier, wErr := resendapi.NewWebhookVerifier(cfg.EmailSender.WebhookSecret)
+		if wErr != nil {
+			return fmt.Errorf("create email webhook verifier: %w", wErr)
+		}
+		emailWebhookAPI = handlers.NewEmailWebhookAPI(logger, webhookVerifier, userFacade)
+	}
+
 	// health api
 	checkAPI := handlers.NewCheckAPI(db)
 
+	// create rate limit store
+	var rateLimitStore ratelimit.Store
+	if cfg.RateLimit.Enabled {
+		switch cfg.RateLimit.Store {
+		case appconf.RateLimitStorePostgres:
+			rateLimitStore = userRepo
+			go cleanupRateLimits(ctx, userRepo, logger)
+		default:
+			rateLimitStore = ratelimit.NewMemoryStore()
+		}
+	}
+
+	// email history api for support. Enabled only if support token is set
+	var emailHistoryAPI *handlers.EmailHistoryAPI
+	if cfg.Web.SupportToken != "" {
+		emailHistoryAPI = handlers.NewEmailHistoryAPI(logger, userFacade, cfg.Web.SupportToken)
+	}
+
 	// start debug service
 	go func() {
-		debugApp := handlers.DebugService()
+		debugApp := handlers.DebugService(handlers.NewEmailPreviewAPI(logger, renderer), emailHistoryAPI)
 		logger.Info("Debug service started", zap.String("address", cfg.Web.DebugAddress))
 		err = server.Start(debugApp, cfg.Web.DebugAddress)
 		if err != nil {
@@ -129,10 +219,132 @@ func run() error {
 	}()
 
 	// start auth service
-	app, err := handlers.Service(authAPI, checkAPI, unsubscribeAPI, cfg)
+	app, err := handlers.Service(authAPI, checkAPI, unsubscribeAPI, emailWebhookAPI, rateLimitStore, cfg)
 	if err != nil {
 		return fmt.Errorf("creating auth service: %w", err)
 	}
 	logger.Info("Auth service started", zap.String("address", cfg.Web.Address))
 	return server.StartWithGracefulShutdown(app, logger, cfg.Web.Address)
 }
+
+// periodically deletes expired rate limit counters from database
+func cleanupRateLimits(ctx context.Context, userRepo *store.UserRepo, logger *zap.Logger) {
+	ticker := time.NewTicker(rateLimitsCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := userRepo.DeleteExpiredRateLimits(ctx); err != nil {
+				logger.Error("delete expired rate limits", zap.Error(err))
+			}
+		}
+	}
+}
+
+// periodically sends pending emails from outbox. Batches are sent until outbox is drained
+func dispatchEmailOutbox(ctx context.Context, userFacade *facade.Provider, logger *zap.Logger) {
+	ticker := time.NewTicker(emailOutboxDispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				n, err := userFacade.DispatchEmailOutbox(ctx, emailOutboxBatchSize)
+				if err != nil {
+					logger.Error("dispatch email outbox", zap.Error(err))
+					break
+				}
+				if n < emailOutboxBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// periodically deletes expired passkey ceremony sessions from database
+func cleanupWebAuthnSessions(ctx context.Context, userRepo *store.UserRepo, logger *zap.Logger) {
+	ticker := time.NewTicker(webAuthnSessionsCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := userRepo.DeleteExpiredWebAuthnSessions(ctx); err != nil {
+				logger.Error("delete expired webauthn sessions", zap.Error(err))
+			}
+		}
+	}
+}
+
+// reloads email templates on SIGHUP. Current templates are kept if new ones are invalid
+func reloadEmailTemplates(ctx context.Context, renderer *mailer.Renderer, logger *zap.Logger) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			if err := renderer.Reload(); err != nil {
+				logger.Error("reload email templates", zap.Error(err))
+				continue
+			}
+			logger.Info("Email templates reloaded")
+		}
+	}
+}
+
+// creates email sender of configured providers. Several providers are tried in configured order,
+// provider failing repeatedly is skipped in favor of the next one
+func newEmailSender(cfg appconf.EmailSender, renderer *mailer.Renderer, logger *zap.Logger) (facade.EmailSender, error) {
+	var providers []fallbackmailer.Provider
+	for _, name := range cfg.Providers() {
+		sender, err := newProviderEmailSender(name, cfg, renderer)
+		if err != nil {
+			return nil, fmt.Errorf("create %s email sender: %w", name, err)
+		}
+		providers = append(providers, fallbackmailer.Provider{Name: name, Sender: sender})
+	}
+
+	if len(providers) == 1 {
+		return providers[0].Sender, nil
+	}
+
+	return fallbackmailer.NewClient(logger, fallbackmailer.Config{
+		FailureThreshold: cfg.FailureThreshold,
+		Cooldown:         cfg.FailureCooldown,
+	}, providers...), nil
+}
+
+// creates email sender of provider
+func newProviderEmailSender(provider string, cfg appconf.EmailSender, renderer *mailer.Renderer) (fallbackmailer.Sender, error) {
+	switch provider {
+	case appconf.EmailSenderProviderSMTP:
+		return smtpclient.NewClient(smtpclient.Config{
+			Host:      cfg.SMTP.Host,
+			Port:      cfg.SMTP.Port,
+			Username:  cfg.SMTP.Username,
+			Password:  cfg.SMTP.Password,
+			FromEmail: cfg.EmailFrom,
+			Security:  cfg.SMTP.Security,
+			Timeout:   cfg.APITimeout,
+		}, renderer)
+	default:
+		return resendapi.NewClient(resendapi.Config{
+			APIToken:  cfg.APIToken,
+			FromEmail: cfg.EmailFrom,
+			Timeout:   cfg.APITimeout,
+		}, renderer), nil
+	}
+}
@@ -17,6 +17,56 @@ const docTemplate = `{
     "basePath": "{{.BasePath}}",
     "paths": {
         "/account": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns profile of the authenticated user including email address and its verification status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get user profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ProfileResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing authorization token",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            },
             "delete": {
                 "description": "Deletes a user account",
                 "produces": [
@@ -122,29 +172,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/logout": {
+        "/account/2fa/recovery-codes": {
             "post": {
-                "description": "Revokes the refresh token and clears the refresh token cookie",
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Logout user",
-                "responses": {
-                    "204": {
-                        "description": "Successfully logged out"
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/web.ErrResp"
-                        }
+                "security": [
+                    {
+                        "Bearer": []
                     }
-                }
-            }
-        },
-        "/oauth/google": {
-            "post": {
-                "description": "Handles Google OAuth 2.0 authentication",
+                ],
+                "description": "Generates a new batch of single-use recovery codes. Previous codes stop working",
                 "consumes": [
                     "application/json"
                 ],
@@ -154,65 +189,52 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Google OAuth sign in handler",
+                "summary": "Regenerate recovery codes",
                 "parameters": [
                     {
-                        "description": "Google OAuth token",
-                        "name": "token",
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Current password",
+                        "name": "params",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.GoogleOAuthRequest"
+                            "$ref": "#/definitions/handlers.RegenerateRecoveryCodesReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User credentials",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.TokenResp"
+                            "$ref": "#/definitions/handlers.RecoveryCodesResp"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Two-factor authentication is not enabled",
                         "schema": {
                             "$ref": "#/definitions/web.ErrResp"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Invalid password or token",
                         "schema": {
                             "$ref": "#/definitions/web.ErrResp"
                         }
-                    }
-                }
-            }
-        },
-        "/refresh": {
-            "post": {
-                "description": "Use a refresh token from httpOnly cookie to obtain new access and refresh tokens",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Refresh access and refresh tokens",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.TokenResp"
-                        }
                     },
-                    "401": {
-                        "description": "Invalid or expired refresh token",
+                    "404": {
+                        "description": "User not found",
                         "schema": {
                             "$ref": "#/definitions/web.ErrResp"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/web.ErrResp"
                         }
@@ -220,29 +242,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/resend-verification": {
+        "/account/2fa/totp": {
             "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Resends email verification code to the user's email address",
+                "description": "Generates a new TOTP secret and provisioning uri for authenticator app. Enrollment has to be confirmed with a code",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "auth"
                 ],
-                "summary": "Resend email verification code",
+                "summary": "Start TOTP two-factor authentication enrollment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
                 "responses": {
-                    "204": {
-                        "description": "Successfully resent verification email"
-                    },
-                    "400": {
-                        "description": "User email is already verified",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/web.ErrResp"
+                            "$ref": "#/definitions/handlers.TOTPEnrollmentResp"
                         }
                     },
                     "401": {
@@ -251,8 +279,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/web.ErrResp"
                         }
                     },
-                    "429": {
-                        "description": "Too many resend requests",
+                    "403": {
+                        "description": "Two-factor authentication is not available for account",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is already enabled",
                         "schema": {
                             "$ref": "#/definitions/web.ErrResp"
                         }
@@ -264,11 +304,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/signin": {
-            "post": {
-                "description": "Authenticate a user and return an access token",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Disables two-factor authentication. Requires current password and a code from authenticator app or a recovery code",
                 "consumes": [
                     "application/json"
                 ],
@@ -278,39 +321,49 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Sign in",
+                "summary": "Disable TOTP two-factor authentication",
                 "parameters": [
                     {
-                        "description": "User credentials",
-                        "name": "signin",
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Current password and code from authenticator app or recovery code",
+                        "name": "params",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.SignInReq"
+                            "$ref": "#/definitions/handlers.DisableTOTPReq"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.TokenResp"
-                        }
+                    "204": {
+                        "description": "Two-factor authentication disabled"
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid code or two-factor authentication is not enabled",
                         "schema": {
                             "$ref": "#/definitions/web.ErrResp"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Invalid password or token",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
                         "schema": {
                             "$ref": "#/definitions/web.ErrResp"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/web.ErrResp"
                         }
@@ -318,9 +371,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/signup": {
+        "/account/2fa/totp/confirm": {
             "post": {
-                "description": "Create a new user account with the provided information",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Confirms TOTP enrollment with a code from authenticator app and enables two-factor authentication. Returns single-use recovery codes",
                 "consumes": [
                     "application/json"
                 ],
@@ -330,33 +388,46 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Register a new user",
+                "summary": "Confirm TOTP two-factor authentication enrollment",
                 "parameters": [
                     {
-                        "description": "User signup information",
-                        "name": "signup",
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Code from authenticator app",
+                        "name": "confirmation",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.SignUpReq"
+                            "$ref": "#/definitions/handlers.ConfirmTOTPReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User credentials",
+                        "description": "Two-factor authentication enabled",
                         "schema": {
-                            "$ref": "#/definitions/handlers.TokenResp"
+                            "$ref": "#/definitions/handlers.RecoveryCodesResp"
                         }
                     },
                     "400": {
-                        "description": "Invalid input data",
+                        "description": "Invalid code or enrollment not started",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing authorization token",
                         "schema": {
                             "$ref": "#/definitions/web.ErrResp"
                         }
                     },
                     "409": {
-                        "description": "Username or publisher name already exists",
+                        "description": "Two-factor authentication is already enabled",
                         "schema": {
                             "$ref": "#/definitions/web.ErrResp"
                         }
@@ -370,9 +441,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/token/verify": {
+        "/account/email": {
             "post": {
-                "description": "Validates a JWT token and returns if it's valid",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Adds email address to user who doesn't have one and sends verification code to it",
                 "consumes": [
                     "application/json"
                 ],
@@ -382,27 +458,48 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Verify JWT token",
+                "summary": "Add email address",
                 "parameters": [
                     {
-                        "description": "Token to verify",
-                        "name": "token",
+                        "description": "Email address",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.VerifyTokenReq"
+                            "$ref": "#/definitions/handlers.AddEmailReq"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "Email is added and verification code is sent"
+                    },
+                    "400": {
+                        "description": "Invalid request, user already has an email or email is unsubscribed",
                         "schema": {
-                            "$ref": "#/definitions/handlers.VerifyTokenResp"
+                            "$ref": "#/definitions/web.ErrResp"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Invalid or missing authorization token",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Email is already taken",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/web.ErrResp"
                         }
@@ -410,14 +507,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/verify-email": {
+        "/account/email/change": {
             "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Verifies user email address using the code sent via email",
+                "description": "Sends code confirming the change to the new email address. Email is changed only after the code is confirmed.\nRequires current password, and two-factor code if two-factor authentication is enabled. Not available for OAuth users",
                 "consumes": [
                     "application/json"
                 ],
@@ -427,10 +524,1037 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Verify email address using verification code",
+                "summary": "Request email address change",
                 "parameters": [
                     {
-                        "description": "Email verification code",
+                        "description": "New email address",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ChangeEmailReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Code is sent to the new email address"
+                    },
+                    "400": {
+                        "description": "Invalid request, invalid two-factor code or email is the same as current",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing authorization token or invalid password",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "403": {
+                        "description": "Email change is not available for OAuth users",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Email is already taken",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/email/change/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Changes user email to the new address using the code sent to it. New email is verified and security notice is sent to the previous address",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Confirm email address change",
+                "parameters": [
+                    {
+                        "description": "Email change code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ConfirmEmailChangeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TokenResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired code or too many failed attempts",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing authorization token",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Email is already taken",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/email/resubscribe": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Resubscribes verified email address of the user to all emails, including address unsubscribed after bounce or complaint",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Resubscribe email address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Email address resubscribed"
+                    },
+                    "400": {
+                        "description": "User has no verified email",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing authorization token",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/email/status": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns delivery status of user email address reported by email provider and whether it is unsubscribed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get email delivery status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EmailStatusResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing authorization token",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "User has no email",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/passkeys": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns passkeys registered by user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "List passkeys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PasskeysResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing authorization token",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/passkeys/register/begin": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Starts passkey registration ceremony. Returned options are passed to navigator.credentials.create()",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Begin passkey registration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PasskeyRegistrationOptionsResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing authorization token",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/passkeys/register/finish": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Verifies authenticator response to passkey registration ceremony and stores new passkey",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Finish passkey registration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Session id, passkey name and authenticator response",
+                        "name": "params",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FinishPasskeyRegistrationReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PasskeyResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid session or passkey verification failed",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing authorization token",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/passkeys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Deletes passkey registered by user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Delete passkey",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Passkey id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Invalid or missing authorization token",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Passkey not found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "description": "Revokes the refresh token and clears the refresh token cookie",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout user",
+                "responses": {
+                    "204": {
+                        "description": "Successfully logged out"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/google": {
+            "post": {
+                "description": "Handles Google OAuth 2.0 authentication",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Google OAuth sign in handler",
+                "parameters": [
+                    {
+                        "description": "Google OAuth token",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GoogleOAuthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User credentials",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TokenResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/refresh": {
+            "post": {
+                "description": "Use a refresh token from httpOnly cookie to obtain new access and refresh tokens",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh access and refresh tokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TokenResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/resend-verification": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Resends email verification code to the user's email address",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Resend email verification code",
+                "responses": {
+                    "204": {
+                        "description": "Successfully resent verification email"
+                    },
+                    "400": {
+                        "description": "User email is already verified",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing authorization token",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many resend requests",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "503": {
+                        "description": "Email delivery is delayed",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin": {
+            "post": {
+                "description": "Authenticate a user and return an access token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Sign in",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "signin",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SignInReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TokenResp"
+                        }
+                    },
+                    "202": {
+                        "description": "Two-factor authentication is required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TwoFactorChallengeResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed sign in attempts",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/2fa": {
+            "post": {
+                "description": "Exchanges challenge token returned by sign in and a code from authenticator app or a recovery code for an access token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Complete sign in with two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Challenge token and code",
+                        "name": "signin",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SignInTwoFactorReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TokenResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired challenge token or invalid code",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed sign in attempts",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/email": {
+            "post": {
+                "description": "Sends single-use sign in code and link to email address. Only accounts with verified email can sign in by email.\nResponse doesn't reveal whether account with such email exists, new code isn't sent if previous one was sent recently",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Send sign in code by email",
+                "parameters": [
+                    {
+                        "description": "Email address",
+                        "name": "signin",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EmailSignInReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Sign in email is sent if account exists"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/email/verify": {
+            "post": {
+                "description": "Signs in with email and single-use code or with token from sign in link and returns an access token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Sign in with code sent by email",
+                "parameters": [
+                    {
+                        "description": "Email with code or sign in link token",
+                        "name": "signin",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.VerifyEmailSignInReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TokenResp"
+                        }
+                    },
+                    "202": {
+                        "description": "Two-factor authentication is required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TwoFactorChallengeResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired sign in code or too many failed attempts",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/passkey/begin": {
+            "post": {
+                "description": "Starts passkey sign in ceremony. Returned options are passed to navigator.credentials.get()",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Begin sign in with passkey",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PasskeySignInOptionsResp"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/passkey/finish": {
+            "post": {
+                "description": "Verifies authenticator response to passkey sign in ceremony and returns an access token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Finish sign in with passkey",
+                "parameters": [
+                    {
+                        "description": "Session id and authenticator response",
+                        "name": "signin",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FinishPasskeySignInReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TokenResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid session or passkey verification failed",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/signup": {
+            "post": {
+                "description": "Create a new user account with the provided information.\nverificationEmailDelayed is a best-effort hint: email quota state is tracked per service instance,\nso verification email may be delayed even if it is not set",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User signup information",
+                        "name": "signup",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SignUpReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User credentials",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SignUpResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Username or publisher name already exists",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/verify": {
+            "post": {
+                "description": "Validates a JWT token and returns if it's valid",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verify JWT token",
+                "parameters": [
+                    {
+                        "description": "Token to verify",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.VerifyTokenReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.VerifyTokenResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Verifies user email address using the code sent via email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verify email address using verification code",
+                "parameters": [
+                    {
+                        "description": "Email verification code",
                         "name": "verification",
                         "in": "body",
                         "required": true,
@@ -440,26 +1564,87 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.TokenResp"
-                        }
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TokenResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired verification code or too many failed attempts",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing authorization token",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Verification code is not found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/resend": {
+            "post": {
+                "description": "Records delivered, bounced and complained events of sent emails. Hard-bounced and complained addresses are unsubscribed.\nRequest is signed by Svix, other event types are acknowledged and ignored",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Receive Resend delivery webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook message id",
+                        "name": "svix-id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook timestamp",
+                        "name": "svix-timestamp",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook signature",
+                        "name": "svix-signature",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
-                        "description": "Invalid or expired verification code",
+                        "description": "Invalid webhook payload",
                         "schema": {
                             "$ref": "#/definitions/web.ErrResp"
                         }
                     },
                     "401": {
-                        "description": "Invalid or missing authorization token",
-                        "schema": {
-                            "$ref": "#/definitions/web.ErrResp"
-                        }
-                    },
-                    "404": {
-                        "description": "Verification code is not found",
+                        "description": "Invalid webhook signature",
                         "schema": {
                             "$ref": "#/definitions/web.ErrResp"
                         }
@@ -475,6 +1660,145 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "handlers.AddEmailReq": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ChangeEmailReq": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 16,
+                    "minLength": 6
+                },
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 8
+                }
+            }
+        },
+        "handlers.ConfirmEmailChangeReq": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ConfirmTOTPReq": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.DisableTOTPReq": {
+            "type": "object",
+            "required": [
+                "code",
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 16,
+                    "minLength": 6
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 8
+                }
+            }
+        },
+        "handlers.EmailSignInReq": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.EmailStatusResp": {
+            "type": "object",
+            "properties": {
+                "deliveryStatus": {
+                    "type": "string"
+                },
+                "deliveryStatusAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "unsubscribeReason": {
+                    "type": "string"
+                },
+                "unsubscribed": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.FinishPasskeyRegistrationReq": {
+            "type": "object",
+            "required": [
+                "credential",
+                "sessionId"
+            ],
+            "properties": {
+                "credential": {
+                    "type": "object"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "sessionId": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.FinishPasskeySignInReq": {
+            "type": "object",
+            "required": [
+                "credential",
+                "sessionId"
+            ],
+            "properties": {
+                "credential": {
+                    "type": "object"
+                },
+                "sessionId": {
+                    "type": "string"
+                }
+            }
+        },
         "handlers.GoogleOAuthRequest": {
             "type": "object",
             "required": [
@@ -486,6 +1810,106 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.PasskeyRegistrationOptionsResp": {
+            "type": "object",
+            "properties": {
+                "options": {
+                    "type": "object"
+                },
+                "sessionId": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.PasskeyResp": {
+            "type": "object",
+            "properties": {
+                "dateCreated": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.PasskeySignInOptionsResp": {
+            "type": "object",
+            "properties": {
+                "options": {
+                    "type": "object"
+                },
+                "sessionId": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.PasskeysResp": {
+            "type": "object",
+            "properties": {
+                "passkeys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.PasskeyResp"
+                    }
+                }
+            }
+        },
+        "handlers.ProfileResp": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "emailVerified": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RecoveryCodesResp": {
+            "type": "object",
+            "properties": {
+                "recoveryCodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.RegenerateRecoveryCodesReq": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 8
+                }
+            }
+        },
         "handlers.SignInReq": {
             "type": "object",
             "required": [
@@ -505,6 +1929,23 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.SignInTwoFactorReq": {
+            "type": "object",
+            "required": [
+                "challengeToken",
+                "code"
+            ],
+            "properties": {
+                "challengeToken": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string",
+                    "maxLength": 16,
+                    "minLength": 6
+                }
+            }
+        },
         "handlers.SignUpReq": {
             "type": "object",
             "required": [
@@ -536,6 +1977,29 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.SignUpResp": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "verificationEmailDelayed": {
+                    "description": "Best-effort hint, set only if the instance handling sign up has seen exhausted email quota.\nVerification email may be delayed even if it is not set",
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.TOTPEnrollmentResp": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "type": "string"
+                },
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
         "handlers.TokenResp": {
             "type": "object",
             "properties": {
@@ -544,6 +2008,17 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.TwoFactorChallengeResp": {
+            "type": "object",
+            "properties": {
+                "challengeToken": {
+                    "type": "string"
+                },
+                "twoFactorRequired": {
+                    "type": "boolean"
+                }
+            }
+        },
         "handlers.UpdateProfileReq": {
             "type": "object",
             "properties": {
@@ -552,6 +2027,10 @@ const docTemplate = `{
                     "maxLength": 64,
                     "minLength": 8
                 },
+                "locale": {
+                    "type": "string",
+                    "maxLength": 35
+                },
                 "name": {
                     "type": "string"
                 },
@@ -574,7 +2053,24 @@ const docTemplate = `{
             ],
             "properties": {
                 "code": {
+                    "type": "string",
+                    "maxLength": 12,
+                    "minLength": 4
+                }
+            }
+        },
+        "handlers.VerifyEmailSignInReq": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "email": {
                     "type": "string"
+                },
+                "token": {
+                    "type": "string",
+                    "maxLength": 64
                 }
             }
         },
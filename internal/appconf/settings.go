@@ -2,8 +2,14 @@ package appconf
 
 import (
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/internal/ratelimit"
 )
 
 // ServiceName - service name
@@ -11,13 +17,16 @@ const ServiceName = "game-library-auth"
 
 // Cfg - app configuration
 type Cfg struct {
-	DB          DB          `mapstructure:",squash"`
-	Web         Web         `mapstructure:",squash"`
-	Auth        Auth        `mapstructure:",squash"`
-	Zipkin      Zipkin      `mapstructure:",squash"`
-	Graylog     Graylog     `mapstructure:",squash"`
-	Log         Log         `mapstructure:",squash"`
-	EmailSender EmailSender `mapstructure:",squash"`
+	DB                DB                `mapstructure:",squash"`
+	Web               Web               `mapstructure:",squash"`
+	Auth              Auth              `mapstructure:",squash"`
+	Zipkin            Zipkin            `mapstructure:",squash"`
+	Graylog           Graylog           `mapstructure:",squash"`
+	Log               Log               `mapstructure:",squash"`
+	EmailSender       EmailSender       `mapstructure:",squash"`
+	EmailVerification EmailVerification `mapstructure:",squash"`
+	RateLimit         RateLimit         `mapstructure:",squash"`
+	WebAuthn          WebAuthn          `mapstructure:",squash"`
 }
 
 // DB represents settings related to database
@@ -34,6 +43,10 @@ type Web struct {
 	AllowedCORSOrigin     string        `mapstructure:"APP_ALLOWEDCORSORIGIN"`
 	RefreshCookieSameSite string        `mapstructure:"APP_REFRESH_TOKEN_COOKIE_SAMESITE"`
 	RefreshCookieSecure   bool          `mapstructure:"APP_REFRESH_TOKEN_COOKIE_SECURE"`
+	// ProxyHeader - header to read client ip from when running behind a reverse proxy. Remote address is used if empty
+	ProxyHeader string `mapstructure:"APP_PROXYHEADER"`
+	// SupportToken - bearer token of support endpoints of debug server. Support endpoints are disabled if empty
+	SupportToken string `mapstructure:"DEBUG_SUPPORT_TOKEN"`
 }
 
 // Auth represents settings related to authentication and authorization
@@ -44,6 +57,11 @@ type Auth struct {
 	GoogleClientID   string        `mapstructure:"AUTH_GOOGLECLIENTID"`
 	AccessTokenTTL   time.Duration `mapstructure:"AUTH_ACCESSTOKENTTL"`
 	RefreshTokenTTL  time.Duration `mapstructure:"AUTH_REFRESHTOKENTTL"`
+	// TOTPEncryptionKey - base64-encoded 32 bytes key used to encrypt TOTP secrets at rest
+	TOTPEncryptionKey string `mapstructure:"AUTH_TOTP_ENCRYPTION_KEY"`
+	// SignedTokenKeys - comma separated list of <key id>:<secret> pairs signing tokens of email links.
+	// New tokens are signed with the first key, tokens signed with any of the keys are accepted
+	SignedTokenKeys string `mapstructure:"AUTH_SIGNED_TOKEN_KEYS"`
 }
 
 // Zipkin represents settings related to zipkin trace storage
@@ -61,15 +79,135 @@ type Log struct {
 	Level string `mapstructure:"LOG_LEVEL"`
 }
 
+// Email sender providers
+const (
+	EmailSenderProviderResend = "resend"
+	EmailSenderProviderSMTP   = "smtp"
+)
+
+// SMTP connection security modes
+const (
+	SMTPSecurityNone     = "none"
+	SMTPSecurityStartTLS = "starttls"
+	SMTPSecurityTLS      = "tls"
+)
+
 // EmailSender represents settings for email sending service
 type EmailSender struct {
-	APIToken          string        `mapstructure:"EMAIL_SENDER_API_TOKEN"`
-	APITimeout        time.Duration `mapstructure:"EMAIL_SENDER_API_TIMEOUT"`
-	EmailFrom         string        `mapstructure:"EMAIL_SENDER_EMAIL_FROM"`
-	ContactEmail      string        `mapstructure:"EMAIL_SENDER_CONTACT_EMAIL"`
-	BaseURL           string        `mapstructure:"EMAIL_SENDER_BASE_URL"`
-	UnsubscribeURL    string        `mapstructure:"EMAIL_SENDER_UNSUBSCRIBE_URL"`
-	UnsubscribeSecret string        `mapstructure:"EMAIL_SENDER_UNSUBSCRIBE_SECRET"`
+	// Provider - comma separated list of email sending backends in order they are tried in, each one of resend, smtp
+	Provider       string        `mapstructure:"EMAIL_SENDER_PROVIDER"`
+	APIToken       string        `mapstructure:"EMAIL_SENDER_API_TOKEN"`
+	APITimeout     time.Duration `mapstructure:"EMAIL_SENDER_API_TIMEOUT"`
+	EmailFrom      string        `mapstructure:"EMAIL_SENDER_EMAIL_FROM"`
+	ContactEmail   string        `mapstructure:"EMAIL_SENDER_CONTACT_EMAIL"`
+	BaseURL        string        `mapstructure:"EMAIL_SENDER_BASE_URL"`
+	UnsubscribeURL string        `mapstructure:"EMAIL_SENDER_UNSUBSCRIBE_URL"`
+	// UnsubscribeSecret - legacy secret of unsubscribe links issued before AUTH_SIGNED_TOKEN_KEYS, accepted until they expire
+	UnsubscribeSecret string `mapstructure:"EMAIL_SENDER_UNSUBSCRIBE_SECRET"`
+	// VerifyEmailURL - url of email verification confirmation page opened from verification link
+	VerifyEmailURL string `mapstructure:"EMAIL_SENDER_VERIFY_EMAIL_URL"`
+	// UnsubscribeTokenTTL - time an unsubscribe link in sent emails is valid for
+	UnsubscribeTokenTTL time.Duration `mapstructure:"EMAIL_SENDER_UNSUBSCRIBE_TOKEN_TTL"`
+	// WebhookSecret - signing secret of Resend delivery webhooks. Webhook endpoint is disabled if empty
+	WebhookSecret string `mapstructure:"EMAIL_SENDER_WEBHOOK_SECRET"`
+	// TemplatesDir - directory with email templates overriding embedded ones. Templates are reloaded on SIGHUP
+	TemplatesDir string `mapstructure:"EMAIL_SENDER_TEMPLATES_DIR"`
+	// FailureThreshold - number of consecutive failed sends after which provider is skipped in favor of the next one.
+	// Required if more than one provider is set
+	FailureThreshold int `mapstructure:"EMAIL_SENDER_FAILURE_THRESHOLD"`
+	// FailureCooldown - time provider that reached failure threshold is skipped for
+	FailureCooldown time.Duration `mapstructure:"EMAIL_SENDER_FAILURE_COOLDOWN"`
+	SMTP            SMTP          `mapstructure:",squash"`
+}
+
+// Providers returns list of email sending backends in order they are tried in
+func (e EmailSender) Providers() []string {
+	var providers []string
+	for _, provider := range strings.Split(e.Provider, ",") {
+		if provider = strings.TrimSpace(provider); provider != "" {
+			providers = append(providers, strings.ToLower(provider))
+		}
+	}
+	return providers
+}
+
+// SMTP represents settings for SMTP email sending backend
+type SMTP struct {
+	Host     string `mapstructure:"EMAIL_SENDER_SMTP_HOST"`
+	Port     int    `mapstructure:"EMAIL_SENDER_SMTP_PORT"`
+	Username string `mapstructure:"EMAIL_SENDER_SMTP_USERNAME"`
+	Password string `mapstructure:"EMAIL_SENDER_SMTP_PASSWORD"`
+	// Security - connection security, one of none, starttls, tls
+	Security string `mapstructure:"EMAIL_SENDER_SMTP_SECURITY"`
+}
+
+// Verification code length bounds
+const (
+	MinVerificationCodeLength = 4
+	MaxVerificationCodeLength = 12
+)
+
+// EmailVerification represents settings for email verification codes
+type EmailVerification struct {
+	CodeTTL    time.Duration `mapstructure:"EMAIL_VERIFICATION_CODE_TTL"`
+	CodeLength int           `mapstructure:"EMAIL_VERIFICATION_CODE_LENGTH"`
+	// CodeAlphabet - characters verification code consists of, one of numeric, alphanumeric
+	CodeAlphabet string `mapstructure:"EMAIL_VERIFICATION_CODE_ALPHABET"`
+	// ResendCooldown - minimal period between verification code resends
+	ResendCooldown time.Duration `mapstructure:"EMAIL_VERIFICATION_RESEND_COOLDOWN"`
+	// LinkSecret - legacy secret of email verification links issued before AUTH_SIGNED_TOKEN_KEYS, accepted until they expire
+	LinkSecret string `mapstructure:"EMAIL_VERIFICATION_LINK_SECRET"`
+}
+
+// Rate limit store types
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+)
+
+// RateLimit represents settings for rate limiting of public endpoints.
+// Route limits are set in format "<requests>/<window>", e.g. "10/1m". Empty limit disables limiting for the route
+type RateLimit struct {
+	Enabled            bool   `mapstructure:"RATE_LIMIT_ENABLED"`
+	Store              string `mapstructure:"RATE_LIMIT_STORE"`
+	ByUser             bool   `mapstructure:"RATE_LIMIT_BY_USER"`
+	SignUp             string `mapstructure:"RATE_LIMIT_SIGNUP"`
+	SignIn             string `mapstructure:"RATE_LIMIT_SIGNIN"`
+	GoogleOAuth        string `mapstructure:"RATE_LIMIT_OAUTH_GOOGLE"`
+	ResendVerification string `mapstructure:"RATE_LIMIT_RESEND_VERIFICATION"`
+	VerifyEmail        string `mapstructure:"RATE_LIMIT_VERIFY_EMAIL"`
+	SignInEmail        string `mapstructure:"RATE_LIMIT_SIGNIN_EMAIL"`
+}
+
+// RouteLimits returns route limits by config variable names
+func (rl RateLimit) RouteLimits() map[string]string {
+	return map[string]string{
+		"RATE_LIMIT_SIGNUP":              rl.SignUp,
+		"RATE_LIMIT_SIGNIN":              rl.SignIn,
+		"RATE_LIMIT_OAUTH_GOOGLE":        rl.GoogleOAuth,
+		"RATE_LIMIT_RESEND_VERIFICATION": rl.ResendVerification,
+		"RATE_LIMIT_VERIFY_EMAIL":        rl.VerifyEmail,
+		"RATE_LIMIT_SIGNIN_EMAIL":        rl.SignInEmail,
+	}
+}
+
+// WebAuthn represents settings for passkey authentication
+type WebAuthn struct {
+	// RPID - relying party id, usually domain of the web application without scheme and port
+	RPID string `mapstructure:"WEBAUTHN_RP_ID"`
+	// RPOrigins - comma separated list of origins passkey ceremonies are allowed from
+	RPOrigins string `mapstructure:"WEBAUTHN_RP_ORIGINS"`
+}
+
+// Origins returns list of allowed relying party origins
+func (w WebAuthn) Origins() []string {
+	var origins []string
+	for _, origin := range strings.Split(w.RPOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
 }
 
 // Validate validates configuration
@@ -125,6 +263,16 @@ func (cfg *Cfg) Validate() error {
 	if cfg.Auth.RefreshTokenTTL <= 0 {
 		return errors.New("AUTH_REFRESHTOKENTTL must be greater than 0")
 	}
+	if cfg.Auth.TOTPEncryptionKey == "" {
+		return errors.New("AUTH_TOTP_ENCRYPTION_KEY is required")
+	}
+	signingKeys, err := auth.ParseSigningKeys(cfg.Auth.SignedTokenKeys)
+	if err != nil {
+		return fmt.Errorf("AUTH_SIGNED_TOKEN_KEYS: %w", err)
+	}
+	if _, err = auth.NewSignedTokens(signingKeys...); err != nil {
+		return fmt.Errorf("AUTH_SIGNED_TOKEN_KEYS: %w", err)
+	}
 
 	// Zipkin validation
 	if cfg.Zipkin.ReporterURL == "" {
@@ -142,8 +290,45 @@ func (cfg *Cfg) Validate() error {
 	}
 
 	// EmailSender validation
-	if cfg.EmailSender.APIToken == "" {
-		return errors.New("EMAIL_SENDER_API_TOKEN is required")
+	providers := cfg.EmailSender.Providers()
+	if len(providers) == 0 {
+		return errors.New("EMAIL_SENDER_PROVIDER is required")
+	}
+	for i, provider := range providers {
+		if slices.Contains(providers[:i], provider) {
+			return fmt.Errorf("EMAIL_SENDER_PROVIDER has duplicate provider %s", provider)
+		}
+		switch provider {
+		case EmailSenderProviderResend:
+			if cfg.EmailSender.APIToken == "" {
+				return errors.New("EMAIL_SENDER_API_TOKEN is required")
+			}
+		case EmailSenderProviderSMTP:
+			if cfg.EmailSender.SMTP.Host == "" {
+				return errors.New("EMAIL_SENDER_SMTP_HOST is required")
+			}
+			if cfg.EmailSender.SMTP.Port <= 0 || cfg.EmailSender.SMTP.Port > 65535 {
+				return errors.New("EMAIL_SENDER_SMTP_PORT must be between 1 and 65535")
+			}
+			switch cfg.EmailSender.SMTP.Security {
+			case SMTPSecurityNone, SMTPSecurityStartTLS, SMTPSecurityTLS:
+			default:
+				return errors.New("EMAIL_SENDER_SMTP_SECURITY must be one of none, starttls, tls")
+			}
+			if cfg.EmailSender.SMTP.Username == "" && cfg.EmailSender.SMTP.Password != "" {
+				return errors.New("EMAIL_SENDER_SMTP_USERNAME is required when EMAIL_SENDER_SMTP_PASSWORD is set")
+			}
+		default:
+			return errors.New("EMAIL_SENDER_PROVIDER must be a comma separated list of resend, smtp")
+		}
+	}
+	if len(providers) > 1 {
+		if cfg.EmailSender.FailureThreshold <= 0 {
+			return errors.New("EMAIL_SENDER_FAILURE_THRESHOLD must be greater than 0 when several providers are set")
+		}
+		if cfg.EmailSender.FailureCooldown <= 0 {
+			return errors.New("EMAIL_SENDER_FAILURE_COOLDOWN must be greater than 0 when several providers are set")
+		}
 	}
 	if cfg.EmailSender.APITimeout <= 0 {
 		return errors.New("EMAIL_SENDER_API_TIMEOUT must be greater than 0")
@@ -160,8 +345,55 @@ func (cfg *Cfg) Validate() error {
 	if cfg.EmailSender.UnsubscribeURL == "" {
 		return errors.New("EMAIL_SENDER_UNSUBSCRIBE_URL is required")
 	}
-	if cfg.EmailSender.UnsubscribeSecret == "" {
-		return errors.New("EMAIL_SENDER_UNSUBSCRIBE_SECRET is required")
+	if cfg.EmailSender.VerifyEmailURL == "" {
+		return errors.New("EMAIL_SENDER_VERIFY_EMAIL_URL is required")
+	}
+	if cfg.EmailSender.UnsubscribeTokenTTL <= 0 {
+		return errors.New("EMAIL_SENDER_UNSUBSCRIBE_TOKEN_TTL must be greater than 0")
+	}
+
+	// EmailVerification validation
+	if cfg.EmailVerification.CodeTTL <= 0 {
+		return errors.New("EMAIL_VERIFICATION_CODE_TTL must be greater than 0")
+	}
+	if cfg.EmailVerification.CodeLength < MinVerificationCodeLength || cfg.EmailVerification.CodeLength > MaxVerificationCodeLength {
+		return fmt.Errorf("EMAIL_VERIFICATION_CODE_LENGTH must be between %d and %d", MinVerificationCodeLength, MaxVerificationCodeLength)
+	}
+	switch cfg.EmailVerification.CodeAlphabet {
+	case model.VerificationCodeAlphabetNumeric, model.VerificationCodeAlphabetAlphanumeric:
+	default:
+		return errors.New("EMAIL_VERIFICATION_CODE_ALPHABET must be one of numeric, alphanumeric")
+	}
+	if cfg.EmailVerification.ResendCooldown < 0 {
+		return errors.New("EMAIL_VERIFICATION_RESEND_COOLDOWN must not be negative")
+	}
+	if cfg.EmailVerification.ResendCooldown >= cfg.EmailVerification.CodeTTL {
+		return errors.New("EMAIL_VERIFICATION_RESEND_COOLDOWN must be less than EMAIL_VERIFICATION_CODE_TTL")
+	}
+
+	// RateLimit validation
+	if cfg.RateLimit.Enabled {
+		switch cfg.RateLimit.Store {
+		case RateLimitStoreMemory, RateLimitStorePostgres:
+		default:
+			return errors.New("RATE_LIMIT_STORE must be one of memory, postgres")
+		}
+		for name, limit := range cfg.RateLimit.RouteLimits() {
+			if limit == "" {
+				continue
+			}
+			if _, err := ratelimit.ParseLimit(limit); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+
+	// WebAuthn validation
+	if cfg.WebAuthn.RPID == "" {
+		return errors.New("WEBAUTHN_RP_ID is required")
+	}
+	if len(cfg.WebAuthn.Origins()) == 0 {
+		return errors.New("WEBAUTHN_RP_ORIGINS is required")
 	}
 
 	return nil
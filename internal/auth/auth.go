@@ -76,6 +76,11 @@ func (a *Auth) ValidateToken(tokenStr string) (Claims, error) {
 		return Claims{}, errors.New("token expired")
 	}
 
+	// tokens of other audiences, e.g. challenge tokens, are signed with the same key but must not grant access
+	if !claims.VerifyAudience(AccessTokenAudience, true) {
+		return Claims{}, errors.New("invalid token audience")
+	}
+
 	return claims, nil
 }
 
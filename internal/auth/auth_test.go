@@ -26,6 +26,7 @@ func TestGenerateValidate(t *testing.T) {
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    "test_runner",
 			Subject:   "12345qwerty",
+			Audience:  jwt.ClaimStrings{auth.AccessTokenAudience},
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(720 * time.Hour)),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
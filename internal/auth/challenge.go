@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// twoFactorChallengeAudience - audience of two-factor authentication challenge tokens.
+// Such tokens can't be used as access tokens
+const twoFactorChallengeAudience = "2fa_challenge"
+
+// ChallengeClaims represent claims of two-factor authentication challenge token. User id is in subject claim,
+// challenge token has no user_id claim of access tokens so it isn't accepted by services reading it
+type ChallengeClaims struct {
+	jwt.RegisteredClaims
+}
+
+// GenerateChallengeToken generates short-lived two-factor authentication challenge token for user
+func (a *Auth) GenerateChallengeToken(userID string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := ChallengeClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    a.claimsIssuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{twoFactorChallengeAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	return a.GenerateToken(claims)
+}
+
+// ValidateChallengeToken validates two-factor authentication challenge token and returns user id from it
+func (a *Auth) ValidateChallengeToken(tokenStr string) (string, error) {
+	var claims ChallengeClaims
+	token, err := a.parser.ParseWithClaims(tokenStr, &claims, a.keyFunc)
+	if err != nil {
+		return "", fmt.Errorf("parsing token: %w", err)
+	}
+
+	if !token.Valid {
+		return "", errors.New("invalid token")
+	}
+
+	if !claims.VerifyAudience(twoFactorChallengeAudience, true) {
+		return "", errors.New("invalid token audience")
+	}
+
+	if claims.Subject == "" {
+		return "", errors.New("empty user id")
+	}
+
+	return claims.Subject, nil
+}
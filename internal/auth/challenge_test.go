@@ -0,0 +1,131 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func newTestAuth(t *testing.T) *auth.Auth {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Generating private key: %v", err)
+	}
+
+	a, err := auth.New("RS256", privateKey, "test_runner", 15*time.Minute, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Initializing auth service instance: %v", err)
+	}
+
+	return a
+}
+
+func TestGenerateValidateChallengeToken(t *testing.T) {
+	a := newTestAuth(t)
+
+	tokenStr, err := a.GenerateChallengeToken("user-id", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Generating challenge token: %v", err)
+	}
+
+	userID, err := a.ValidateChallengeToken(tokenStr)
+	if err != nil {
+		t.Fatalf("Validating challenge token: %v", err)
+	}
+	if userID != "user-id" {
+		t.Fatalf("Expected user id to be %v, got %v", "user-id", userID)
+	}
+}
+
+func TestValidateChallengeToken_Expired(t *testing.T) {
+	a := newTestAuth(t)
+
+	tokenStr, err := a.GenerateChallengeToken("user-id", -time.Minute)
+	if err != nil {
+		t.Fatalf("Generating challenge token: %v", err)
+	}
+
+	if _, err = a.ValidateChallengeToken(tokenStr); err == nil {
+		t.Fatal("Expected error for expired challenge token")
+	}
+}
+
+func TestValidateChallengeToken_AccessToken(t *testing.T) {
+	a := newTestAuth(t)
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-id",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		UserID: "user-id",
+	}
+	tokenStr, err := a.GenerateToken(claims)
+	if err != nil {
+		t.Fatalf("Generating token: %v", err)
+	}
+
+	if _, err = a.ValidateChallengeToken(tokenStr); err == nil {
+		t.Fatal("Expected access token to be rejected as challenge token")
+	}
+}
+
+func TestValidateToken_ChallengeToken(t *testing.T) {
+	a := newTestAuth(t)
+
+	tokenStr, err := a.GenerateChallengeToken("user-id", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Generating challenge token: %v", err)
+	}
+
+	if _, err = a.ValidateToken(tokenStr); err == nil {
+		t.Fatal("Expected challenge token to be rejected as access token")
+	}
+}
+
+func TestGenerateChallengeToken_NoUserIDClaim(t *testing.T) {
+	a := newTestAuth(t)
+
+	tokenStr, err := a.GenerateChallengeToken("user-id", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Generating challenge token: %v", err)
+	}
+
+	claims := jwt.MapClaims{}
+	if _, _, err = jwt.NewParser().ParseUnverified(tokenStr, claims); err != nil {
+		t.Fatalf("Parsing challenge token: %v", err)
+	}
+	if _, ok := claims["user_id"]; ok {
+		t.Fatal("Expected challenge token to have no user_id claim")
+	}
+	if claims["sub"] != "user-id" {
+		t.Fatalf("Expected subject to be %v, got %v", "user-id", claims["sub"])
+	}
+}
+
+func TestValidateToken_NoAccessAudience(t *testing.T) {
+	a := newTestAuth(t)
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-id",
+			Audience:  jwt.ClaimStrings{"other_svc"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		UserID: "user-id",
+	}
+	tokenStr, err := a.GenerateToken(claims)
+	if err != nil {
+		t.Fatalf("Generating token: %v", err)
+	}
+
+	if _, err = a.ValidateToken(tokenStr); err == nil {
+		t.Fatal("Expected token without access audience to be rejected")
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// AccessTokenAudience - audience of access tokens. Tokens of other audiences signed with the same key don't grant access
+const AccessTokenAudience = "game_lib_svc"
+
 // Claims represent jwt claims
 type Claims struct {
 	jwt.RegisteredClaims
@@ -16,6 +19,8 @@ type Claims struct {
 	Name     string `json:"name,omitempty"`
 	// VerificationRequired - represents requirement to verify email (true = not verified, false = verified or does not require verification)
 	VerificationRequired bool `json:"vrf_required"`
+	// EmailVerified - user has a verified email address
+	EmailVerified bool `json:"email_verified"`
 }
 
 // CreateUserClaims creates claims for user
@@ -25,7 +30,7 @@ func (a *Auth) CreateUserClaims(user model.User) jwt.Claims {
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    a.claimsIssuer,
 			Subject:   user.ID,
-			Audience:  jwt.ClaimStrings{"game_lib_svc"},
+			Audience:  jwt.ClaimStrings{AccessTokenAudience},
 			ExpiresAt: jwt.NewNumericDate(now.Add(a.accessTokenTTL)),
 			NotBefore: jwt.NewNumericDate(now),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -35,6 +40,7 @@ func (a *Auth) CreateUserClaims(user model.User) jwt.Claims {
 		Username:             user.Username,
 		Name:                 user.DisplayName,
 		VerificationRequired: user.IsPublisher() && !user.EmailVerified,
+		EmailVerified:        user.EmailVerified,
 	}
 
 	return claims
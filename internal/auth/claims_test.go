@@ -58,6 +58,10 @@ func TestCreateUserClaims_RegularUser(t *testing.T) {
 		t.Error("expected VerificationRequired to be false for regular user")
 	}
 
+	if !authClaims.EmailVerified {
+		t.Error("expected EmailVerified to be true for regular user with verified email")
+	}
+
 	if authClaims.Issuer != "test-issuer" {
 		t.Errorf("expected Issuer test-issuer, got %s", authClaims.Issuer)
 	}
@@ -138,6 +142,10 @@ func TestCreateUserClaims_Publisher_EmailNotVerified(t *testing.T) {
 		t.Error("expected VerificationRequired to be true for unverified publisher")
 	}
 
+	if authClaims.EmailVerified {
+		t.Error("expected EmailVerified to be false for unverified publisher")
+	}
+
 	if authClaims.UserRole != "publisher" {
 		t.Errorf("expected UserRole publisher, got %s", authClaims.UserRole)
 	}
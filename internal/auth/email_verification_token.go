@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+// EmailVerificationTokenGenerator generates and validates tokens of email verification links
+type EmailVerificationTokenGenerator struct {
+	tokens       *SignedTokens
+	legacySecret []byte
+}
+
+// NewEmailVerificationTokenGenerator creates a new email verification token generator signing tokens with the given signed tokens service.
+// Unversioned tokens signed with legacy secret are accepted if it is not empty
+func NewEmailVerificationTokenGenerator(tokens *SignedTokens, legacySecret []byte) *EmailVerificationTokenGenerator {
+	return &EmailVerificationTokenGenerator{
+		tokens:       tokens,
+		legacySecret: legacySecret,
+	}
+}
+
+// GenerateToken creates an email verification link token for the given verification id and expiry
+func (g *EmailVerificationTokenGenerator) GenerateToken(verificationID string, expiresAt time.Time) string {
+	return g.tokens.GenerateToken(TokenPurposeVerifyLink, verificationID, expiresAt)
+}
+
+// ValidateToken validates an email verification link token and returns the verification id if valid
+func (g *EmailVerificationTokenGenerator) ValidateToken(token string) (string, error) {
+	verificationID, err := g.tokens.ValidateToken(TokenPurposeVerifyLink, token)
+	if errors.Is(err, ErrTokenFormat) && len(g.legacySecret) > 0 {
+		return validateLegacyToken(g.legacySecret, token)
+	}
+	return verificationID, err
+}
@@ -0,0 +1,67 @@
+package auth_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+)
+
+func newEmailVerificationTokenGenerator(t *testing.T, signedTokens *auth.SignedTokens) *auth.EmailVerificationTokenGenerator {
+	t.Helper()
+
+	if signedTokens == nil {
+		var err error
+		signedTokens, err = auth.NewSignedTokens(auth.SigningKey{ID: "k1", Secret: []byte("test-verification-secret")})
+		if err != nil {
+			t.Fatalf("create signed tokens: %v", err)
+		}
+	}
+
+	return auth.NewEmailVerificationTokenGenerator(signedTokens, nil)
+}
+
+func TestEmailVerificationToken_Success(t *testing.T) {
+	generator := newEmailVerificationTokenGenerator(t, nil)
+
+	verificationID := "8d7c6f1e-3c1b-4b8e-9f3a-2d5e6a7b8c9d"
+	token := generator.GenerateToken(verificationID, time.Now().Add(24*time.Hour))
+
+	validatedID, err := generator.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if validatedID != verificationID {
+		t.Errorf("expected verification id %s, got %s", verificationID, validatedID)
+	}
+}
+
+func TestEmailVerificationToken_Expired(t *testing.T) {
+	generator := newEmailVerificationTokenGenerator(t, nil)
+
+	token := generator.GenerateToken("verification-123", time.Now().Add(-time.Minute))
+
+	_, err := generator.ValidateToken(token)
+	if err == nil || err.Error() != "token expired" {
+		t.Errorf("expected 'token expired' error, got %v", err)
+	}
+}
+
+func TestEmailVerificationToken_UnsubscribeTokenRejected(t *testing.T) {
+	signedTokens, err := auth.NewSignedTokens(auth.SigningKey{ID: "k1", Secret: []byte("test-secret")})
+	if err != nil {
+		t.Fatalf("create signed tokens: %v", err)
+	}
+	verificationGenerator := newEmailVerificationTokenGenerator(t, signedTokens)
+	unsubscribeGenerator := auth.NewUnsubscribeTokenGenerator(signedTokens, nil)
+
+	// tokens signed with the same key are still bound to their purpose
+	token := unsubscribeGenerator.GenerateToken("test@example.com", time.Now().Add(time.Hour))
+
+	_, err = verificationGenerator.ValidateToken(token)
+	if !errors.Is(err, auth.ErrTokenPurposeMismatch) {
+		t.Errorf("expected purpose mismatch error, got %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signed token purposes. Token signed for one purpose is rejected when validated for another
+const (
+	// TokenPurposeUnsubscribe - token of unsubscribe and email preferences links
+	TokenPurposeUnsubscribe = "unsubscribe"
+	// TokenPurposeResubscribe - token of resubscribe links shown after unsubscribing
+	TokenPurposeResubscribe = "resubscribe"
+	// TokenPurposeVerifyLink - token of email verification links
+	TokenPurposeVerifyLink = "verify-link"
+	// TokenPurposeReset - token of password reset links
+	TokenPurposeReset = "reset"
+)
+
+// signedTokenPrefix is prepended to tokens of the current format version
+const signedTokenPrefix = "v1."
+
+// errors
+var (
+	ErrTokenFormat          = errors.New("invalid token format")
+	ErrTokenSignature       = errors.New("invalid token signature")
+	ErrTokenExpired         = errors.New("token expired")
+	ErrTokenUnknownKey      = errors.New("unknown token signing key")
+	ErrTokenPurposeMismatch = errors.New("token purpose mismatch")
+)
+
+// SigningKey represents secret signing tokens, identified by key id
+type SigningKey struct {
+	ID     string
+	Secret []byte
+}
+
+// ParseSigningKeys parses comma separated list of <key id>:<secret> pairs
+func ParseSigningKeys(s string) ([]SigningKey, error) {
+	var keys []SigningKey
+	for pair := range strings.SplitSeq(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("signing key %q must be in <key id>:<secret> format", pair)
+		}
+		keys = append(keys, SigningKey{ID: id, Secret: []byte(secret)})
+	}
+
+	return keys, nil
+}
+
+// SignedTokens generates and validates HMAC signed tokens bound to a purpose.
+// New tokens are signed with the first key, tokens signed with any of the keys are accepted,
+// so the signing key can be rotated without invalidating already issued tokens
+type SignedTokens struct {
+	signingKey SigningKey
+	keys       map[string][]byte
+}
+
+// NewSignedTokens creates signed tokens service with the given keys. First key is used for signing
+func NewSignedTokens(keys ...SigningKey) (*SignedTokens, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one signing key is required")
+	}
+
+	byID := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if key.ID == "" || len(key.ID) > 255 {
+			return nil, fmt.Errorf("signing key id %q must be 1 to 255 bytes long", key.ID)
+		}
+		if len(key.Secret) == 0 {
+			return nil, fmt.Errorf("signing key %s has empty secret", key.ID)
+		}
+		if _, ok := byID[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate signing key id %s", key.ID)
+		}
+		byID[key.ID] = key.Secret
+	}
+
+	return &SignedTokens{
+		signingKey: keys[0],
+		keys:       byID,
+	}, nil
+}
+
+// GenerateToken creates token for subject with the given purpose and expiry
+func (s *SignedTokens) GenerateToken(purpose, subject string, expiresAt time.Time) string {
+	// body: key id length | key id | purpose length | purpose | expiry timestamp | subject
+	body := make([]byte, 0, 2+len(s.signingKey.ID)+len(purpose)+8+len(subject)+sha256.Size)
+	body = append(body, byte(len(s.signingKey.ID)))
+	body = append(body, s.signingKey.ID...)
+	body = append(body, byte(len(purpose)))
+	body = append(body, purpose...)
+	body = binary.BigEndian.AppendUint64(body, uint64(expiresAt.Unix())) //nolint:gosec // expiry is after epoch
+	body = append(body, subject...)
+
+	body = append(body, signTokenBody(s.signingKey.Secret, body)...)
+	return signedTokenPrefix + base64.RawURLEncoding.EncodeToString(body)
+}
+
+// ValidateToken validates token of the given purpose and returns its subject if token is valid and not expired
+func (s *SignedTokens) ValidateToken(purpose, token string) (string, error) {
+	encoded, ok := strings.CutPrefix(token, signedTokenPrefix)
+	if !ok {
+		return "", ErrTokenFormat
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrTokenFormat
+	}
+	if len(data) < sha256.Size {
+		return "", ErrTokenFormat
+	}
+	body, signature := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+
+	// parse length prefixed fields, subject is the rest of the body, so it may contain any characters
+	keyID, rest, ok := cutLengthPrefixed(body)
+	if !ok {
+		return "", ErrTokenFormat
+	}
+	tokenPurpose, rest, ok := cutLengthPrefixed(rest)
+	if !ok || len(rest) < 8 {
+		return "", ErrTokenFormat
+	}
+	expiry := int64(binary.BigEndian.Uint64(rest[:8])) //nolint:gosec // expiry is signed before use
+	subject := string(rest[8:])
+
+	secret, ok := s.keys[string(keyID)]
+	if !ok {
+		return "", ErrTokenUnknownKey
+	}
+	if !hmac.Equal(signature, signTokenBody(secret, body)) {
+		return "", ErrTokenSignature
+	}
+	if string(tokenPurpose) != purpose {
+		return "", ErrTokenPurposeMismatch
+	}
+	if time.Now().Unix() > expiry {
+		return "", ErrTokenExpired
+	}
+
+	return subject, nil
+}
+
+// signTokenBody returns HMAC signature of token format version and body
+func signTokenBody(secret, body []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(signedTokenPrefix))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// cutLengthPrefixed splits one byte length prefixed field from the start of data
+func cutLengthPrefixed(data []byte) (field, rest []byte, ok bool) {
+	if len(data) == 0 || len(data) < 1+int(data[0]) {
+		return nil, nil, false
+	}
+	n := 1 + int(data[0])
+	return data[1:n], data[n:], true
+}
+
+// validateLegacyToken validates unversioned token in <signature><value>:<expiry> format issued before signed tokens
+// had purpose and key id, and returns its value if token is valid and not expired
+func validateLegacyToken(secretKey []byte, token string) (string, error) {
+	tokenData, err := base64.URLEncoding.DecodeString(token)
+	if err != nil || len(tokenData) < sha256.Size {
+		return "", ErrTokenFormat
+	}
+
+	signature := tokenData[:sha256.Size]
+	payload := string(tokenData[sha256.Size:])
+
+	h := hmac.New(sha256.New, secretKey)
+	h.Write([]byte(payload))
+	if !hmac.Equal(signature, h.Sum(nil)) {
+		return "", ErrTokenSignature
+	}
+
+	// expiry is after the last colon, value may contain colons
+	i := strings.LastIndex(payload, ":")
+	if i < 0 {
+		return "", ErrTokenFormat
+	}
+	expiry, err := strconv.ParseInt(payload[i+1:], 10, 64)
+	if err != nil {
+		return "", ErrTokenFormat
+	}
+	if time.Now().Unix() > expiry {
+		return "", ErrTokenExpired
+	}
+
+	return payload[:i], nil
+}
@@ -0,0 +1,128 @@
+package auth_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+)
+
+func TestSignedTokens_KeyRotation(t *testing.T) {
+	oldKey := auth.SigningKey{ID: "k1", Secret: []byte("old-secret")}
+	newKey := auth.SigningKey{ID: "k2", Secret: []byte("new-secret")}
+
+	oldTokens, err := auth.NewSignedTokens(oldKey)
+	if err != nil {
+		t.Fatalf("create signed tokens: %v", err)
+	}
+	rotatedTokens, err := auth.NewSignedTokens(newKey, oldKey)
+	if err != nil {
+		t.Fatalf("create signed tokens: %v", err)
+	}
+	newOnlyTokens, err := auth.NewSignedTokens(newKey)
+	if err != nil {
+		t.Fatalf("create signed tokens: %v", err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	oldToken := oldTokens.GenerateToken(auth.TokenPurposeReset, "user-1", expiresAt)
+
+	// token signed with previous key is accepted after rotation
+	subject, err := rotatedTokens.ValidateToken(auth.TokenPurposeReset, oldToken)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if subject != "user-1" {
+		t.Errorf("expected subject user-1, got %s", subject)
+	}
+
+	// new tokens are signed with the first key
+	newToken := rotatedTokens.GenerateToken(auth.TokenPurposeReset, "user-1", expiresAt)
+	if _, err = newOnlyTokens.ValidateToken(auth.TokenPurposeReset, newToken); err != nil {
+		t.Errorf("expected token signed with new key, got %v", err)
+	}
+
+	// token signed with removed key is rejected
+	_, err = newOnlyTokens.ValidateToken(auth.TokenPurposeReset, oldToken)
+	if !errors.Is(err, auth.ErrTokenUnknownKey) {
+		t.Errorf("expected unknown key error, got %v", err)
+	}
+}
+
+func TestSignedTokens_PurposeMismatch(t *testing.T) {
+	signedTokens, err := auth.NewSignedTokens(auth.SigningKey{ID: "k1", Secret: []byte("secret")})
+	if err != nil {
+		t.Fatalf("create signed tokens: %v", err)
+	}
+
+	token := signedTokens.GenerateToken(auth.TokenPurposeVerifyLink, "verification-123", time.Now().Add(time.Hour))
+
+	_, err = signedTokens.ValidateToken(auth.TokenPurposeReset, token)
+	if !errors.Is(err, auth.ErrTokenPurposeMismatch) {
+		t.Errorf("expected purpose mismatch error, got %v", err)
+	}
+}
+
+func TestSignedTokens_Tampered(t *testing.T) {
+	signedTokens, err := auth.NewSignedTokens(auth.SigningKey{ID: "k1", Secret: []byte("secret")})
+	if err != nil {
+		t.Fatalf("create signed tokens: %v", err)
+	}
+
+	token := signedTokens.GenerateToken(auth.TokenPurposeUnsubscribe, "user1@example.com", time.Now().Add(time.Hour))
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, "v1."))
+	if err != nil {
+		t.Fatalf("decode token: %v", err)
+	}
+
+	// replace subject with another email of the same length
+	tampered := strings.Replace(string(data), "user1@example.com", "user2@example.com", 1)
+	_, err = signedTokens.ValidateToken(auth.TokenPurposeUnsubscribe, "v1."+base64.RawURLEncoding.EncodeToString([]byte(tampered)))
+	if !errors.Is(err, auth.ErrTokenSignature) {
+		t.Errorf("expected invalid signature error, got %v", err)
+	}
+}
+
+func TestNewSignedTokens_InvalidKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []auth.SigningKey
+	}{
+		{name: "no keys"},
+		{name: "empty key id", keys: []auth.SigningKey{{Secret: []byte("secret")}}},
+		{name: "empty secret", keys: []auth.SigningKey{{ID: "k1"}}},
+		{name: "duplicate key id", keys: []auth.SigningKey{{ID: "k1", Secret: []byte("secret1")}, {ID: "k1", Secret: []byte("secret2")}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := auth.NewSignedTokens(tt.keys...); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestParseSigningKeys(t *testing.T) {
+	keys, err := auth.ParseSigningKeys("k2:new-secret, k1:old:secret")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(keys))
+	}
+	if keys[0].ID != "k2" || string(keys[0].Secret) != "new-secret" {
+		t.Errorf("unexpected first key %s:%s", keys[0].ID, keys[0].Secret)
+	}
+	if keys[1].ID != "k1" || string(keys[1].Secret) != "old:secret" {
+		t.Errorf("unexpected second key %s:%s", keys[1].ID, keys[1].Secret)
+	}
+
+	if _, err = auth.ParseSigningKeys("secret-without-key-id"); err == nil {
+		t.Error("expected error for key without id")
+	}
+}
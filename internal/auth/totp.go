@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	totpPeriod = 30
+	// number of periods before and after current one to accept codes from, to tolerate clock drift
+	totpSkew = 1
+)
+
+var totpOpts = totp.ValidateOpts{
+	Period:    totpPeriod,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// GenerateTOTPKey generates a new TOTP secret for account and returns it with otpauth provisioning uri
+func GenerateTOTPKey(issuer, accountName string) (secret string, uri string, err error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: accountName,
+		Period:      totpPeriod,
+		Digits:      totpOpts.Digits,
+		Algorithm:   totpOpts.Algorithm,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("generate totp key: %w", err)
+	}
+
+	return key.Secret(), key.URL(), nil
+}
+
+// ValidateTOTPCode validates TOTP code against secret at provided time and returns matched time step.
+// Returned step can be used to prevent reuse of the same code
+func ValidateTOTPCode(secret, code string, t time.Time) (int64, bool) {
+	currentStep := t.Unix() / totpPeriod
+	for step := currentStep - totpSkew; step <= currentStep+totpSkew; step++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), totpOpts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,78 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/pquerna/otp/totp"
+)
+
+func TestGenerateTOTPKey(t *testing.T) {
+	secret, uri, err := auth.GenerateTOTPKey("Game Library", "username")
+	if err != nil {
+		t.Fatalf("Generating totp key: %v", err)
+	}
+
+	if secret == "" {
+		t.Fatal("Expected non-empty secret")
+	}
+	if !strings.HasPrefix(uri, "otpauth://totp/") {
+		t.Fatalf("Expected otpauth uri, got %v", uri)
+	}
+	if !strings.Contains(uri, "secret="+secret) {
+		t.Fatalf("Expected uri to contain secret, got %v", uri)
+	}
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	secret, _, err := auth.GenerateTOTPKey("Game Library", "username")
+	if err != nil {
+		t.Fatalf("Generating totp key: %v", err)
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name      string
+		codeTime  time.Time
+		wantValid bool
+		wantStep  int64
+	}{
+		{name: "current period", codeTime: now, wantValid: true, wantStep: now.Unix() / 30},
+		{name: "previous period", codeTime: now.Add(-30 * time.Second), wantValid: true, wantStep: now.Unix()/30 - 1},
+		{name: "next period", codeTime: now.Add(30 * time.Second), wantValid: true, wantStep: now.Unix()/30 + 1},
+		{name: "outdated code", codeTime: now.Add(-2 * time.Minute), wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, gErr := totp.GenerateCode(secret, tt.codeTime)
+			if gErr != nil {
+				t.Fatalf("Generating code: %v", gErr)
+			}
+
+			step, valid := auth.ValidateTOTPCode(secret, code, now)
+			if valid != tt.wantValid {
+				t.Fatalf("Expected valid to be %v, got %v", tt.wantValid, valid)
+			}
+			if valid && step != tt.wantStep {
+				t.Fatalf("Expected step to be %v, got %v", tt.wantStep, step)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPCode_InvalidCode(t *testing.T) {
+	secret, _, err := auth.GenerateTOTPKey("Game Library", "username")
+	if err != nil {
+		t.Fatalf("Generating totp key: %v", err)
+	}
+
+	if _, valid := auth.ValidateTOTPCode(secret, "12345", time.Now()); valid {
+		t.Fatal("Expected short code to be invalid")
+	}
+	if _, valid := auth.ValidateTOTPCode(secret, "abcdef", time.Now()); valid {
+		t.Fatal("Expected non-numeric code to be invalid")
+	}
+}
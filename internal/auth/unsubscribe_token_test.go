@@ -1,41 +1,57 @@
 package auth_test
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/OutOfStack/game-library-auth/internal/auth"
 )
 
-func TestGenerateToken(t *testing.T) {
-	secretKey := []byte("test-secret-key-32-bytes-long!")
-	generator := auth.NewUnsubscribeTokenGenerator(secretKey)
+func newUnsubscribeTokenGenerator(t *testing.T, secretKey []byte, legacySecret []byte) *auth.UnsubscribeTokenGenerator {
+	t.Helper()
 
-	email := "test@example.com"
-	expiresAt := time.Now().Add(24 * time.Hour)
+	signedTokens, err := auth.NewSignedTokens(auth.SigningKey{ID: "k1", Secret: secretKey})
+	if err != nil {
+		t.Fatalf("create signed tokens: %v", err)
+	}
 
-	token := generator.GenerateToken(email, expiresAt)
+	return auth.NewUnsubscribeTokenGenerator(signedTokens, legacySecret)
+}
+
+// creates token in format issued before signed tokens had purpose and key id
+func legacyToken(secretKey []byte, value string, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%s:%d", value, expiresAt.Unix())
+	h := hmac.New(sha256.New, secretKey)
+	h.Write([]byte(payload))
+	return base64.URLEncoding.EncodeToString(append(h.Sum(nil), payload...))
+}
 
-	if token == "" {
-		t.Error("expected non-empty token")
+func TestGenerateToken(t *testing.T) {
+	generator := newUnsubscribeTokenGenerator(t, []byte("test-secret-key-32-bytes-long!"), nil)
+
+	token := generator.GenerateToken("test@example.com", time.Now().Add(24*time.Hour))
+
+	if !strings.HasPrefix(token, "v1.") {
+		t.Errorf("expected versioned token, got %s", token)
 	}
 
-	// verify token can be base64 decoded
-	_, err := base64.URLEncoding.DecodeString(token)
-	if err != nil {
+	// verify token is safe to use in urls
+	if _, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, "v1.")); err != nil {
 		t.Errorf("token should be valid base64: %v", err)
 	}
 }
 
 func TestValidateToken_Success(t *testing.T) {
-	secretKey := []byte("test-secret-key-32-bytes-long!")
-	generator := auth.NewUnsubscribeTokenGenerator(secretKey)
+	generator := newUnsubscribeTokenGenerator(t, []byte("test-secret-key-32-bytes-long!"), nil)
 
 	email := "test@example.com"
-	expiresAt := time.Now().Add(24 * time.Hour)
-
-	token := generator.GenerateToken(email, expiresAt)
+	token := generator.GenerateToken(email, time.Now().Add(24*time.Hour))
 
 	validatedEmail, err := generator.ValidateToken(token)
 	if err != nil {
@@ -48,17 +64,13 @@ func TestValidateToken_Success(t *testing.T) {
 }
 
 func TestValidateToken_ExpiredToken(t *testing.T) {
-	secretKey := []byte("test-secret-key-32-bytes-long!")
-	generator := auth.NewUnsubscribeTokenGenerator(secretKey)
-
-	email := "test@example.com"
-	expiresAt := time.Now().Add(-1 * time.Hour)
+	generator := newUnsubscribeTokenGenerator(t, []byte("test-secret-key-32-bytes-long!"), nil)
 
-	token := generator.GenerateToken(email, expiresAt)
+	token := generator.GenerateToken("test@example.com", time.Now().Add(-1*time.Hour))
 
 	_, err := generator.ValidateToken(token)
 	if err == nil {
-		t.Error("expected error for expired token")
+		t.Fatal("expected error for expired token")
 	}
 
 	if err.Error() != "token expired" {
@@ -67,92 +79,29 @@ func TestValidateToken_ExpiredToken(t *testing.T) {
 }
 
 func TestValidateToken_InvalidFormat(t *testing.T) {
-	secretKey := []byte("test-secret-key-32-bytes-long!")
-	generator := auth.NewUnsubscribeTokenGenerator(secretKey)
+	generator := newUnsubscribeTokenGenerator(t, []byte("test-secret-key-32-bytes-long!"), nil)
 
-	invalidToken := "not-a-valid-base64-token!!!"
+	for _, invalidToken := range []string{"not-a-valid-base64-token!!!", "v1.not-a-valid-base64-token!!!", "v1." + base64.RawURLEncoding.EncodeToString([]byte("short"))} {
+		_, err := generator.ValidateToken(invalidToken)
+		if err == nil {
+			t.Fatalf("expected error for invalid token format %s", invalidToken)
+		}
 
-	_, err := generator.ValidateToken(invalidToken)
-	if err == nil {
-		t.Error("expected error for invalid token format")
-	}
-
-	if err.Error() != "invalid token format" {
-		t.Errorf("expected 'invalid token format' error, got %v", err)
-	}
-}
-
-func TestValidateToken_TokenTooShort(t *testing.T) {
-	secretKey := []byte("test-secret-key-32-bytes-long!")
-	generator := auth.NewUnsubscribeTokenGenerator(secretKey)
-
-	shortToken := base64.URLEncoding.EncodeToString([]byte("short"))
-
-	_, err := generator.ValidateToken(shortToken)
-	if err == nil {
-		t.Error("expected error for short token")
-	}
-
-	if err.Error() != "token too short" {
-		t.Errorf("expected 'token too short' error, got %v", err)
+		if err.Error() != "invalid token format" {
+			t.Errorf("expected 'invalid token format' error, got %v", err)
+		}
 	}
 }
 
 func TestValidateToken_InvalidSignature(t *testing.T) {
-	secretKey1 := []byte("test-secret-key-32-bytes-long!")
-	secretKey2 := []byte("different-secret-key-32-bytes!")
-
-	generator1 := auth.NewUnsubscribeTokenGenerator(secretKey1)
-	generator2 := auth.NewUnsubscribeTokenGenerator(secretKey2)
-
-	email := "test@example.com"
-	expiresAt := time.Now().Add(24 * time.Hour)
+	generator1 := newUnsubscribeTokenGenerator(t, []byte("test-secret-key-32-bytes-long!"), nil)
+	generator2 := newUnsubscribeTokenGenerator(t, []byte("different-secret-key-32-bytes!"), nil)
 
-	token := generator1.GenerateToken(email, expiresAt)
+	token := generator1.GenerateToken("test@example.com", time.Now().Add(24*time.Hour))
 
 	_, err := generator2.ValidateToken(token)
 	if err == nil {
-		t.Error("expected error for invalid signature")
-	}
-
-	if err.Error() != "invalid token signature" {
-		t.Errorf("expected 'invalid token signature' error, got %v", err)
-	}
-}
-
-func TestValidateToken_InvalidPayloadFormat(t *testing.T) {
-	secretKey := []byte("test-secret-key-32-bytes-long!")
-	generator := auth.NewUnsubscribeTokenGenerator(secretKey)
-
-	// manually create a token with invalid payload (no colon separator)
-	invalidPayload := "invalid-payload-format"
-	tokenData := make([]byte, 32+len(invalidPayload))
-	copy(tokenData[32:], []byte(invalidPayload))
-	invalidToken := base64.URLEncoding.EncodeToString(tokenData)
-
-	_, err := generator.ValidateToken(invalidToken)
-	if err == nil {
-		t.Error("expected error for invalid payload format")
-	}
-
-	if err.Error() != "invalid token signature" {
-		t.Errorf("expected 'invalid token signature' error, got %v", err)
-	}
-}
-
-func TestValidateToken_InvalidExpiryTime(t *testing.T) {
-	secretKey := []byte("test-secret-key-32-bytes-long!")
-	generator := auth.NewUnsubscribeTokenGenerator(secretKey)
-
-	// manually create a token with invalid expiry time
-	invalidPayload := "test@example.com:not-a-number"
-	tokenData := make([]byte, 32+len(invalidPayload))
-	copy(tokenData[32:], []byte(invalidPayload))
-	invalidToken := base64.URLEncoding.EncodeToString(tokenData)
-
-	_, err := generator.ValidateToken(invalidToken)
-	if err == nil {
-		t.Error("expected error for invalid expiry time")
+		t.Fatal("expected error for invalid signature")
 	}
 
 	if err.Error() != "invalid token signature" {
@@ -161,8 +110,7 @@ func TestValidateToken_InvalidExpiryTime(t *testing.T) {
 }
 
 func TestGenerateToken_Deterministic(t *testing.T) {
-	secretKey := []byte("test-secret-key-32-bytes-long!")
-	generator := auth.NewUnsubscribeTokenGenerator(secretKey)
+	generator := newUnsubscribeTokenGenerator(t, []byte("test-secret-key-32-bytes-long!"), nil)
 
 	email := "test@example.com"
 	expiresAt := time.Unix(1704067200, 0)
@@ -176,8 +124,7 @@ func TestGenerateToken_Deterministic(t *testing.T) {
 }
 
 func TestGenerateToken_DifferentEmails(t *testing.T) {
-	secretKey := []byte("test-secret-key-32-bytes-long!")
-	generator := auth.NewUnsubscribeTokenGenerator(secretKey)
+	generator := newUnsubscribeTokenGenerator(t, []byte("test-secret-key-32-bytes-long!"), nil)
 
 	expiresAt := time.Now().Add(24 * time.Hour)
 
@@ -206,22 +153,86 @@ func TestGenerateToken_DifferentEmails(t *testing.T) {
 }
 
 func TestValidateToken_EmailWithColon(t *testing.T) {
-	secretKey := []byte("test-secret-key-32-bytes-long!")
-	generator := auth.NewUnsubscribeTokenGenerator(secretKey)
-
-	// emails with colons will cause parsing issues because colon is used as separator
-	// payload format: "email:timestamp", but "test:user@example.com:timestamp" splits into 3 parts
-	email := "test:user@example.com"
-	expiresAt := time.Now().Add(24 * time.Hour)
+	generator := newUnsubscribeTokenGenerator(t, []byte("test-secret-key-32-bytes-long!"), nil)
 
-	token := generator.GenerateToken(email, expiresAt)
+	// quoted local part of email address may contain colons
+	email := `"test:user:1"@example.com`
+	token := generator.GenerateToken(email, time.Now().Add(24*time.Hour))
 
-	_, err := generator.ValidateToken(token)
-	if err == nil {
-		t.Error("expected error for email with colon")
+	validatedEmail, err := generator.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
 	}
 
-	if err.Error() != "invalid token payload" {
-		t.Errorf("expected 'invalid token payload' error, got %v", err)
+	if validatedEmail != email {
+		t.Errorf("expected email %s, got %s", email, validatedEmail)
 	}
 }
+
+func TestValidateToken_LegacyToken(t *testing.T) {
+	legacySecret := []byte("legacy-secret")
+	generator := newUnsubscribeTokenGenerator(t, []byte("test-secret-key-32-bytes-long!"), legacySecret)
+
+	t.Run("valid", func(t *testing.T) {
+		email := "test:user@example.com"
+		validatedEmail, err := generator.ValidateToken(legacyToken(legacySecret, email, time.Now().Add(time.Hour)))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if validatedEmail != email {
+			t.Errorf("expected email %s, got %s", email, validatedEmail)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		_, err := generator.ValidateToken(legacyToken(legacySecret, "test@example.com", time.Now().Add(-time.Hour)))
+		if err == nil || err.Error() != "token expired" {
+			t.Errorf("expected 'token expired' error, got %v", err)
+		}
+	})
+
+	t.Run("other secret", func(t *testing.T) {
+		_, err := generator.ValidateToken(legacyToken([]byte("other-secret"), "test@example.com", time.Now().Add(time.Hour)))
+		if err == nil || err.Error() != "invalid token signature" {
+			t.Errorf("expected 'invalid token signature' error, got %v", err)
+		}
+	})
+
+	t.Run("legacy secret not set", func(t *testing.T) {
+		noLegacyGenerator := newUnsubscribeTokenGenerator(t, []byte("test-secret-key-32-bytes-long!"), nil)
+
+		_, err := noLegacyGenerator.ValidateToken(legacyToken(legacySecret, "test@example.com", time.Now().Add(time.Hour)))
+		if err == nil || err.Error() != "invalid token format" {
+			t.Errorf("expected 'invalid token format' error, got %v", err)
+		}
+	})
+}
+
+func TestValidateResubscribeToken(t *testing.T) {
+	generator := newUnsubscribeTokenGenerator(t, []byte("test-secret-key-32-bytes-long!"), nil)
+	email := "test@example.com"
+
+	t.Run("valid", func(t *testing.T) {
+		validatedEmail, err := generator.ValidateResubscribeToken(generator.GenerateResubscribeToken(email, time.Now().Add(time.Hour)))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if validatedEmail != email {
+			t.Errorf("expected email %s, got %s", email, validatedEmail)
+		}
+	})
+
+	t.Run("unsubscribe token", func(t *testing.T) {
+		_, err := generator.ValidateResubscribeToken(generator.GenerateToken(email, time.Now().Add(time.Hour)))
+		if !errors.Is(err, auth.ErrTokenPurposeMismatch) {
+			t.Errorf("expected ErrTokenPurposeMismatch, got %v", err)
+		}
+	})
+
+	t.Run("used as unsubscribe token", func(t *testing.T) {
+		_, err := generator.ValidateToken(generator.GenerateResubscribeToken(email, time.Now().Add(time.Hour)))
+		if !errors.Is(err, auth.ErrTokenPurposeMismatch) {
+			t.Errorf("expected ErrTokenPurposeMismatch, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,249 @@
+package fallbackmailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+var tracer = otel.Tracer("fallbackmailer")
+
+// ErrNoProviderAvailable is returned when email can't be sent with any of email providers
+var ErrNoProviderAvailable = errors.New("no email provider available")
+
+// Sender sends emails with single email provider
+type Sender interface {
+	SendEmailVerification(ctx context.Context, req mailer.SendEmailVerificationRequest) (mailer.SendResult, error)
+	SendEmailSignIn(ctx context.Context, req mailer.SendEmailSignInRequest) (mailer.SendResult, error)
+	SendRecoveryCodeUsed(ctx context.Context, req mailer.SendRecoveryCodeUsedRequest) (mailer.SendResult, error)
+	SendEmailChange(ctx context.Context, req mailer.SendEmailChangeRequest) (mailer.SendResult, error)
+	SendEmailChanged(ctx context.Context, req mailer.SendEmailChangedRequest) (mailer.SendResult, error)
+	SendSecurityNotice(ctx context.Context, req mailer.SendSecurityNoticeRequest) (mailer.SendResult, error)
+}
+
+// Provider represents email provider of fallback chain
+type Provider struct {
+	Name   string
+	Sender Sender
+}
+
+// Config represents fallback client configuration
+type Config struct {
+	// FailureThreshold - number of consecutive failed sends after which provider is skipped
+	FailureThreshold int
+	// Cooldown - duration provider is skipped for once failure threshold is reached
+	Cooldown time.Duration
+}
+
+// Client sends emails with the first available email provider in configured order.
+// Provider failing FailureThreshold times in a row is skipped for Cooldown, after that it is tried again
+// and is skipped for another Cooldown on failure. Provider with exhausted quota is skipped until quota is reset
+type Client struct {
+	log              *zap.Logger
+	providers        []*provider
+	failureThreshold int
+	cooldown         time.Duration
+}
+
+// provider holds health state of email provider
+type provider struct {
+	name   string
+	sender Sender
+
+	mu sync.Mutex
+	// failures - number of consecutive failed sends
+	failures int
+	// skipUntil - time provider is skipped until
+	skipUntil time.Time
+	// quotaExceeded - whether provider is skipped because of exhausted quota
+	quotaExceeded bool
+}
+
+// NewClient creates a new fallback client. Providers are tried in provided order
+func NewClient(log *zap.Logger, cfg Config, providers ...Provider) *Client {
+	c := &Client{
+		log:              log,
+		failureThreshold: cfg.FailureThreshold,
+		cooldown:         cfg.Cooldown,
+	}
+	for _, p := range providers {
+		c.providers = append(c.providers, &provider{name: p.Name, sender: p.Sender})
+	}
+
+	return c
+}
+
+// SendEmailVerification sends email verification email with verification code and link
+func (c *Client) SendEmailVerification(ctx context.Context, req mailer.SendEmailVerificationRequest) (mailer.SendResult, error) {
+	return c.send(ctx, "sendEmailVerification", func(ctx context.Context, s Sender) (mailer.SendResult, error) {
+		return s.SendEmailVerification(ctx, req)
+	})
+}
+
+// SendEmailSignIn sends passwordless sign in email with sign in code and link
+func (c *Client) SendEmailSignIn(ctx context.Context, req mailer.SendEmailSignInRequest) (mailer.SendResult, error) {
+	return c.send(ctx, "sendEmailSignIn", func(ctx context.Context, s Sender) (mailer.SendResult, error) {
+		return s.SendEmailSignIn(ctx, req)
+	})
+}
+
+// SendRecoveryCodeUsed sends security notice about two-factor authentication recovery code being used
+func (c *Client) SendRecoveryCodeUsed(ctx context.Context, req mailer.SendRecoveryCodeUsedRequest) (mailer.SendResult, error) {
+	return c.send(ctx, "sendRecoveryCodeUsed", func(ctx context.Context, s Sender) (mailer.SendResult, error) {
+		return s.SendRecoveryCodeUsed(ctx, req)
+	})
+}
+
+// SendEmailChange sends code confirming new email address
+func (c *Client) SendEmailChange(ctx context.Context, req mailer.SendEmailChangeRequest) (mailer.SendResult, error) {
+	return c.send(ctx, "sendEmailChange", func(ctx context.Context, s Sender) (mailer.SendResult, error) {
+		return s.SendEmailChange(ctx, req)
+	})
+}
+
+// SendEmailChanged sends security notice about email address being changed to the previous address
+func (c *Client) SendEmailChanged(ctx context.Context, req mailer.SendEmailChangedRequest) (mailer.SendResult, error) {
+	return c.send(ctx, "sendEmailChanged", func(ctx context.Context, s Sender) (mailer.SendResult, error) {
+		return s.SendEmailChanged(ctx, req)
+	})
+}
+
+// SendSecurityNotice sends security notice about account event
+func (c *Client) SendSecurityNotice(ctx context.Context, req mailer.SendSecurityNoticeRequest) (mailer.SendResult, error) {
+	return c.send(ctx, "sendSecurityNotice", func(ctx context.Context, s Sender) (mailer.SendResult, error) {
+		return s.SendSecurityNotice(ctx, req)
+	})
+}
+
+// send sends email with the first provider which doesn't fail. Result has name of provider that sent the email.
+// Returns *mailer.QuotaExceededError only if quota of every provider is exhausted
+// and *mailer.ProvidersSkippedError if no provider was tried because every provider is skipped
+func (c *Client) send(ctx context.Context, spanName string, sendFn func(context.Context, Sender) (mailer.SendResult, error)) (mailer.SendResult, error) {
+	ctx, span := tracer.Start(ctx, spanName)
+	defer span.End()
+
+	var failures []string
+	for _, p := range c.providers {
+		if !p.isAvailable(time.Now()) {
+			continue
+		}
+
+		result, err := sendFn(ctx, p.sender)
+		if err == nil {
+			c.registerSuccess(p)
+			span.SetAttributes(attribute.String("provider", p.name))
+			return result, nil
+		}
+		// email which can't be rendered or send which is cancelled fails with any provider
+		if errors.Is(err, mailer.ErrRender) || errors.Is(err, mailer.ErrUnknownTemplate) || ctx.Err() != nil {
+			return mailer.SendResult{}, err
+		}
+
+		c.registerFailure(p, err)
+		failures = append(failures, fmt.Sprintf("%s: %v", p.name, err))
+	}
+
+	if resetAt, exceeded := c.quotaResetAt(time.Now()); exceeded {
+		return mailer.SendResult{}, &mailer.QuotaExceededError{ResetAt: resetAt}
+	}
+	if len(failures) == 0 {
+		return mailer.SendResult{}, &mailer.ProvidersSkippedError{RetryAt: c.retryAt()}
+	}
+	return mailer.SendResult{}, fmt.Errorf("%w: %s", ErrNoProviderAvailable, strings.Join(failures, "; "))
+}
+
+// registerSuccess resets health state of provider after successful send
+func (c *Client) registerSuccess(p *provider) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failures >= c.failureThreshold || p.quotaExceeded {
+		c.log.Info("email provider recovered", zap.String("provider", p.name))
+	}
+	p.failures = 0
+	p.skipUntil = time.Time{}
+	p.quotaExceeded = false
+}
+
+// registerFailure updates health state of provider after failed send. Provider is skipped once it reaches failure threshold
+// or its quota is exhausted
+func (c *Client) registerFailure(p *provider, sendErr error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if quotaErr := mailer.AsQuotaExceededError(sendErr); quotaErr != nil {
+		p.skipUntil = quotaResetAt(quotaErr.ResetAt, now)
+		p.quotaExceeded = true
+		c.log.Warn("email provider quota exceeded, provider is skipped", zap.String("provider", p.name), zap.Time("until", p.skipUntil))
+		return
+	}
+
+	p.failures++
+	p.quotaExceeded = false
+	if p.failures >= c.failureThreshold {
+		p.skipUntil = now.Add(c.cooldown)
+		c.log.Warn("email provider failed, provider is skipped", zap.String("provider", p.name),
+			zap.Int("failures", p.failures), zap.Time("until", p.skipUntil), zap.Error(sendErr))
+	}
+}
+
+// quotaResetAt returns the earliest reset time of exhausted quota if quota of every provider is exhausted
+func (c *Client) quotaResetAt(now time.Time) (time.Time, bool) {
+	var resetAt time.Time
+	for _, p := range c.providers {
+		p.mu.Lock()
+		exceeded, until := p.quotaExceeded && now.Before(p.skipUntil), p.skipUntil
+		p.mu.Unlock()
+
+		if !exceeded {
+			return time.Time{}, false
+		}
+		if resetAt.IsZero() || until.Before(resetAt) {
+			resetAt = until
+		}
+	}
+
+	return resetAt, !resetAt.IsZero()
+}
+
+// retryAt returns the earliest time skipped provider is tried again at
+func (c *Client) retryAt() time.Time {
+	var retryAt time.Time
+	for _, p := range c.providers {
+		p.mu.Lock()
+		until := p.skipUntil
+		p.mu.Unlock()
+
+		if retryAt.IsZero() || until.Before(retryAt) {
+			retryAt = until
+		}
+	}
+
+	return retryAt
+}
+
+// isAvailable reports whether provider is not skipped at provided time
+func (p *provider) isAvailable(now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return !now.Before(p.skipUntil)
+}
+
+// quotaResetAt returns reset time of exhausted quota. Quota which reset time is unknown is considered daily quota
+// and is reset at the start of the next UTC day
+func quotaResetAt(resetAt, now time.Time) time.Time {
+	if resetAt.After(now) {
+		return resetAt
+	}
+	return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
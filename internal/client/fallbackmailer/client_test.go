@@ -0,0 +1,163 @@
+package fallbackmailer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/fallbackmailer"
+	mocks "github.com/OutOfStack/game-library-auth/internal/client/fallbackmailer/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+var (
+	resendResult = mailer.SendResult{MessageID: "resend-id", Provider: "resend"}
+	smtpResult   = mailer.SendResult{MessageID: "smtp-id", Provider: "smtp"}
+	errProvider  = errors.New("provider unavailable")
+)
+
+// newTestClient returns fallback client trying resend and then smtp sender mocks
+func newTestClient(t *testing.T, cooldown time.Duration) (*fallbackmailer.Client, *mocks.MockSender, *mocks.MockSender) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	resendSender := mocks.NewMockSender(ctrl)
+	smtpSender := mocks.NewMockSender(ctrl)
+
+	client := fallbackmailer.NewClient(zap.NewNop(), fallbackmailer.Config{FailureThreshold: 2, Cooldown: cooldown},
+		fallbackmailer.Provider{Name: "resend", Sender: resendSender},
+		fallbackmailer.Provider{Name: "smtp", Sender: smtpSender},
+	)
+	return client, resendSender, smtpSender
+}
+
+func TestClient_SendEmailVerification(t *testing.T) {
+	ctx := context.Background()
+	req := mailer.SendEmailVerificationRequest{Email: "user@example.com", VerificationCode: "123456"}
+
+	t.Run("sent by first provider", func(t *testing.T) {
+		client, resendSender, _ := newTestClient(t, time.Minute)
+
+		resendSender.EXPECT().SendEmailVerification(gomock.Any(), req).Return(resendResult, nil)
+
+		result, err := client.SendEmailVerification(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, resendResult, result)
+	})
+
+	t.Run("falls back to next provider", func(t *testing.T) {
+		client, resendSender, smtpSender := newTestClient(t, time.Minute)
+
+		resendSender.EXPECT().SendEmailVerification(gomock.Any(), req).Return(mailer.SendResult{}, errProvider)
+		smtpSender.EXPECT().SendEmailVerification(gomock.Any(), req).Return(smtpResult, nil)
+
+		result, err := client.SendEmailVerification(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, smtpResult, result)
+	})
+
+	t.Run("failing provider is skipped until cooldown passes", func(t *testing.T) {
+		cooldown := 50 * time.Millisecond
+		client, resendSender, smtpSender := newTestClient(t, cooldown)
+
+		resendSender.EXPECT().SendEmailVerification(gomock.Any(), req).Return(mailer.SendResult{}, errProvider).Times(2)
+		smtpSender.EXPECT().SendEmailVerification(gomock.Any(), req).Return(smtpResult, nil).Times(3)
+
+		for range 3 {
+			result, err := client.SendEmailVerification(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, smtpResult, result)
+		}
+
+		// provider is tried again after cooldown
+		time.Sleep(cooldown)
+		resendSender.EXPECT().SendEmailVerification(gomock.Any(), req).Return(resendResult, nil)
+
+		result, err := client.SendEmailVerification(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, resendResult, result)
+	})
+
+	t.Run("provider with exhausted quota is skipped", func(t *testing.T) {
+		client, resendSender, smtpSender := newTestClient(t, time.Minute)
+
+		resendSender.EXPECT().SendEmailVerification(gomock.Any(), req).
+			Return(mailer.SendResult{}, &mailer.QuotaExceededError{ResetAt: time.Now().Add(time.Hour)})
+		smtpSender.EXPECT().SendEmailVerification(gomock.Any(), req).Return(smtpResult, nil).Times(2)
+
+		for range 2 {
+			result, err := client.SendEmailVerification(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, smtpResult, result)
+		}
+	})
+
+	t.Run("quota of every provider exhausted", func(t *testing.T) {
+		client, resendSender, smtpSender := newTestClient(t, time.Minute)
+
+		resetAt := time.Now().Add(time.Hour)
+		resendSender.EXPECT().SendEmailVerification(gomock.Any(), req).
+			Return(mailer.SendResult{}, &mailer.QuotaExceededError{ResetAt: resetAt.Add(time.Hour)})
+		smtpSender.EXPECT().SendEmailVerification(gomock.Any(), req).
+			Return(mailer.SendResult{}, &mailer.QuotaExceededError{ResetAt: resetAt})
+
+		_, err := client.SendEmailVerification(ctx, req)
+		quotaErr := mailer.AsQuotaExceededError(err)
+		require.NotNil(t, quotaErr)
+		assert.True(t, quotaErr.ResetAt.Equal(resetAt))
+
+		// providers are skipped until quota is reset
+		_, err = client.SendEmailVerification(ctx, req)
+		require.ErrorIs(t, err, mailer.ErrQuotaExceeded)
+	})
+
+	t.Run("every provider failed", func(t *testing.T) {
+		client, resendSender, smtpSender := newTestClient(t, time.Minute)
+
+		resendSender.EXPECT().SendEmailVerification(gomock.Any(), req).
+			Return(mailer.SendResult{}, &mailer.QuotaExceededError{ResetAt: time.Now().Add(time.Hour)})
+		smtpSender.EXPECT().SendEmailVerification(gomock.Any(), req).Return(mailer.SendResult{}, errProvider)
+
+		_, err := client.SendEmailVerification(ctx, req)
+		require.ErrorIs(t, err, fallbackmailer.ErrNoProviderAvailable)
+		assert.NotErrorIs(t, err, mailer.ErrQuotaExceeded)
+	})
+
+	t.Run("every provider skipped", func(t *testing.T) {
+		client, resendSender, smtpSender := newTestClient(t, time.Minute)
+
+		resendSender.EXPECT().SendEmailVerification(gomock.Any(), req).Return(mailer.SendResult{}, errProvider).Times(2)
+		smtpSender.EXPECT().SendEmailVerification(gomock.Any(), req).Return(mailer.SendResult{}, errProvider).Times(2)
+
+		for range 2 {
+			_, err := client.SendEmailVerification(ctx, req)
+			require.ErrorIs(t, err, fallbackmailer.ErrNoProviderAvailable)
+		}
+
+		// no provider is tried until cooldown passes
+		_, err := client.SendEmailVerification(ctx, req)
+		skippedErr := mailer.AsProvidersSkippedError(err)
+		require.NotNil(t, skippedErr)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), skippedErr.RetryAt, time.Second)
+		assert.NotErrorIs(t, err, fallbackmailer.ErrNoProviderAvailable)
+	})
+
+	t.Run("render error is not retried with next provider", func(t *testing.T) {
+		client, resendSender, _ := newTestClient(t, time.Minute)
+
+		renderErr := fmt.Errorf("%w: fill HTML template: bad data", mailer.ErrRender)
+		resendSender.EXPECT().SendEmailVerification(gomock.Any(), req).Return(mailer.SendResult{}, renderErr).Times(3)
+
+		// render errors don't make provider skipped
+		for range 3 {
+			_, err := client.SendEmailVerification(ctx, req)
+			require.ErrorIs(t, err, mailer.ErrRender)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package mailer
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrQuotaExceeded is returned by email senders when sending quota of email provider is exhausted.
+// Errors of exhausted quota are of type QuotaExceededError
+var ErrQuotaExceeded = errors.New("email quota exceeded")
+
+// QuotaExceededError - error of exhausted sending quota of email provider
+type QuotaExceededError struct {
+	// ResetAt - time quota is reset at, zero if provider didn't report it
+	ResetAt time.Time
+}
+
+// Error implements error interface
+func (e *QuotaExceededError) Error() string {
+	if e.ResetAt.IsZero() {
+		return ErrQuotaExceeded.Error()
+	}
+	return ErrQuotaExceeded.Error() + ", reset at " + e.ResetAt.UTC().Format(time.RFC3339)
+}
+
+// Is reports whether target is ErrQuotaExceeded
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// AsQuotaExceededError - returns *QuotaExceededError if err is of type QuotaExceededError
+func AsQuotaExceededError(err error) *QuotaExceededError {
+	var quotaErr *QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		return quotaErr
+	}
+	return nil
+}
+
+// ErrProvidersSkipped is returned when email wasn't sent with any email provider because every provider is skipped after failures.
+// Errors of skipped providers are of type ProvidersSkippedError
+var ErrProvidersSkipped = errors.New("all email providers are skipped")
+
+// ProvidersSkippedError - error of email not sent because every email provider is skipped after failures
+type ProvidersSkippedError struct {
+	// RetryAt - time the first provider is tried again at
+	RetryAt time.Time
+}
+
+// Error implements error interface
+func (e *ProvidersSkippedError) Error() string {
+	return ErrProvidersSkipped.Error() + " until " + e.RetryAt.UTC().Format(time.RFC3339)
+}
+
+// Is reports whether target is ErrProvidersSkipped
+func (e *ProvidersSkippedError) Is(target error) bool {
+	return target == ErrProvidersSkipped
+}
+
+// AsProvidersSkippedError - returns *ProvidersSkippedError if err is of type ProvidersSkippedError
+func AsProvidersSkippedError(err error) *ProvidersSkippedError {
+	var skippedErr *ProvidersSkippedError
+	if errors.As(err, &skippedErr) {
+		return skippedErr
+	}
+	return nil
+}
+
+// SendResult represents result of sent email
+type SendResult struct {
+	// MessageID - id of message assigned by email provider
+	MessageID string
+	// Provider - name of email provider that delivered the message
+	Provider string
+}
+
+// SendEmailVerificationRequest represents email verification request.
+// Locale of all email requests is user locale the email is rendered in
+type SendEmailVerificationRequest struct {
+	Email            string
+	Username         string
+	Locale           string
+	VerificationCode string
+	// VerificationToken - signed token of verification link
+	VerificationToken string
+	CodeTTL           time.Duration
+	UnsubscribeToken  string
+}
+
+// SendEmailSignInRequest represents passwordless sign in email request
+type SendEmailSignInRequest struct {
+	Email            string
+	Username         string
+	Locale           string
+	SignInCode       string
+	SignInToken      string
+	UnsubscribeToken string
+}
+
+// SendRecoveryCodeUsedRequest represents security notice request about recovery code being used
+type SendRecoveryCodeUsedRequest struct {
+	Email             string
+	Username          string
+	Locale            string
+	RecoveryCodesLeft int
+	ClientIP          string
+	UsedAt            time.Time
+}
+
+// SendEmailChangeRequest represents request to send code confirming new email address.
+// Email is the new address
+type SendEmailChangeRequest struct {
+	Email            string
+	Username         string
+	Locale           string
+	VerificationCode string
+	CodeTTL          time.Duration
+}
+
+// SendEmailChangedRequest represents security notice request about email address being changed.
+// Email is the previous address
+type SendEmailChangedRequest struct {
+	Email     string
+	Username  string
+	Locale    string
+	NewEmail  string
+	ChangedAt time.Time
+}
+
+// SendSecurityNoticeRequest represents security notice request about account event.
+// Event is type of security event, each of them has notice template with the same name
+type SendSecurityNoticeRequest struct {
+	Email      string
+	Username   string
+	Locale     string
+	Event      string
+	ClientIP   string
+	UserAgent  string
+	OccurredAt time.Time
+}
+
+// Message represents rendered email ready to be sent
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+	// Headers - additional email headers, e.g. List-Unsubscribe
+	Headers map[string]string
+}
+
+// templateData represents data passed to email templates
+type templateData struct {
+	Email             string
+	Username          string
+	VerificationCode  string
+	VerificationToken string
+	VerifyEmailURL    string
+	CodeExpiresIn     string
+	UnsubscribeToken  string
+	BaseURL           string
+	UnsubscribeURL    string
+	ContactEmail      string
+	PrivacyPolicyURL  string
+	TermsOfServiceURL string
+	CurrentYear       int
+
+	// passwordless sign in
+	SignInCode  string
+	SignInToken string
+
+	// security notices
+	RecoveryCodesLeft int
+	ClientIP          string
+	UserAgent         string
+	EventTime         string
+	NewEmail          string
+}
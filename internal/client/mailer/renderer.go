@@ -0,0 +1,578 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"maps"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+// List-Unsubscribe headers (RFC 2369, RFC 8058)
+const (
+	HeaderListUnsubscribe     = "List-Unsubscribe"
+	HeaderListUnsubscribePost = "List-Unsubscribe-Post"
+	// ListUnsubscribeOneClick - value of List-Unsubscribe-Post header and body of one-click unsubscribe request
+	ListUnsubscribeOneClick = "List-Unsubscribe=One-Click"
+)
+
+// TemplateDefaultLocale - locale of emails used when there are no templates in user locale
+const TemplateDefaultLocale = "en"
+
+// email template names
+const (
+	emailVerificationTemplate = "email_verification"
+	emailSignInTemplate       = "email_sign_in"
+	recoveryCodeUsedTemplate  = "recovery_code_used"
+	emailChangeTemplate       = "email_change"
+	emailChangedTemplate      = "email_changed"
+)
+
+// security notice template names, one per security event. Template name is the name of its event
+const (
+	passwordChangedTemplate          = "password_changed"
+	newSignInTemplate                = "new_sign_in"
+	accountDeletionRequestedTemplate = "account_deletion_requested"
+	twoFactorEnabledTemplate         = "two_factor_enabled"
+	twoFactorDisabledTemplate        = "two_factor_disabled"
+	recoveryCodesRegeneratedTemplate = "recovery_codes_regenerated"
+)
+
+var securityNoticeTemplates = []string{
+	passwordChangedTemplate,
+	newSignInTemplate,
+	accountDeletionRequestedTemplate,
+	twoFactorEnabledTemplate,
+	twoFactorDisabledTemplate,
+	recoveryCodesRegeneratedTemplate,
+}
+
+// ErrUnknownTemplate is returned on preview of template which doesn't exist
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+// ErrRender is returned when email template can't be filled with data. Such email fails with any email provider
+var ErrRender = errors.New("render email")
+
+var templateNames = []string{
+	emailVerificationTemplate,
+	emailSignInTemplate,
+	recoveryCodeUsedTemplate,
+	emailChangeTemplate,
+	emailChangedTemplate,
+	passwordChangedTemplate,
+	newSignInTemplate,
+	accountDeletionRequestedTemplate,
+	twoFactorEnabledTemplate,
+	twoFactorDisabledTemplate,
+	recoveryCodesRegeneratedTemplate,
+}
+
+// Renderer renders emails from templates. Renderer is shared by all email sender implementations.
+// Emails are rendered in user locale if there are templates in it, otherwise in TemplateDefaultLocale
+type Renderer struct {
+	baseURL        string
+	contactEmail   string
+	unsubscribeURL string
+	verifyEmailURL string
+	templatesDir   string
+	locales        atomic.Pointer[map[string]*localeTemplates]
+}
+
+// RendererConfig represents settings of links and contacts in rendered emails
+type RendererConfig struct {
+	ContactEmail   string
+	BaseURL        string
+	UnsubscribeURL string
+	VerifyEmailURL string
+	// TemplatesDir - directory with templates overriding embedded ones. It has the same layout as embedded templates:
+	// one directory per locale with templates and locale.json. Embedded templates are used if empty
+	TemplatesDir string
+}
+
+// localeConfig represents subjects and formats of emails in one locale, loaded from locale.json
+type localeConfig struct {
+	Subjects        map[string]string `json:"subjects"`
+	EventTimeLayout string            `json:"eventTimeLayout"`
+	// Units - plural forms of duration units, "one" for 1 and "other" for other numbers
+	Units map[string]map[string]string `json:"units"`
+}
+
+// emailTemplates represents HTML and text templates of one email
+type emailTemplates struct {
+	html *template.Template
+	text *template.Template
+}
+
+// localeTemplates represents templates, subjects and formats of emails in one locale
+type localeTemplates struct {
+	localeConfig
+	templates map[string]emailTemplates
+}
+
+// NewRenderer creates a new email renderer. Templates are validated on creation
+func NewRenderer(cfg RendererConfig) (*Renderer, error) {
+	r := &Renderer{
+		baseURL:        cfg.BaseURL,
+		contactEmail:   cfg.ContactEmail,
+		unsubscribeURL: cfg.UnsubscribeURL,
+		verifyEmailURL: cfg.VerifyEmailURL,
+		templatesDir:   cfg.TemplatesDir,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload loads and validates templates and replaces current ones with them.
+// Current templates are kept if any template is invalid
+func (r *Renderer) Reload() error {
+	fsys, err := fs.Sub(templateFS, "templates")
+	if err != nil {
+		return fmt.Errorf("open embedded templates: %w", err)
+	}
+	if r.templatesDir != "" {
+		info, sErr := os.Stat(r.templatesDir)
+		if sErr != nil {
+			return fmt.Errorf("open templates dir: %w", sErr)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("templates dir %s is not a directory", r.templatesDir)
+		}
+		fsys = overlayFS{dir: os.DirFS(r.templatesDir), base: fsys}
+	}
+
+	locales, err := loadLocales(fsys)
+	if err != nil {
+		return err
+	}
+	r.locales.Store(&locales)
+
+	return nil
+}
+
+// EmailVerification renders email verification email with verification code and link
+func (r *Renderer) EmailVerification(req SendEmailVerificationRequest) (Message, error) {
+	lt := r.localeTemplates(req.Locale)
+	data := r.newTemplateData(req.Email, req.Username)
+	data.VerificationCode = req.VerificationCode
+	data.VerificationToken = req.VerificationToken
+	data.VerifyEmailURL = r.verifyEmailURL
+	data.CodeExpiresIn = lt.formatDuration(req.CodeTTL)
+	data.UnsubscribeToken = req.UnsubscribeToken
+
+	msg, err := lt.render(emailVerificationTemplate, req.Email, data)
+	if err != nil {
+		return Message{}, err
+	}
+	msg.Headers = r.listUnsubscribeHeaders(req.UnsubscribeToken)
+
+	return msg, nil
+}
+
+// EmailSignIn renders passwordless sign in email with sign in code and link
+func (r *Renderer) EmailSignIn(req SendEmailSignInRequest) (Message, error) {
+	lt := r.localeTemplates(req.Locale)
+	data := r.newTemplateData(req.Email, req.Username)
+	data.SignInCode = req.SignInCode
+	data.SignInToken = req.SignInToken
+	data.UnsubscribeToken = req.UnsubscribeToken
+
+	msg, err := lt.render(emailSignInTemplate, req.Email, data)
+	if err != nil {
+		return Message{}, err
+	}
+	msg.Headers = r.listUnsubscribeHeaders(req.UnsubscribeToken)
+
+	return msg, nil
+}
+
+// RecoveryCodeUsed renders security notice about two-factor authentication recovery code being used.
+// Security notices don't have unsubscribe link
+func (r *Renderer) RecoveryCodeUsed(req SendRecoveryCodeUsedRequest) (Message, error) {
+	lt := r.localeTemplates(req.Locale)
+	data := r.newTemplateData(req.Email, req.Username)
+	data.RecoveryCodesLeft = req.RecoveryCodesLeft
+	data.ClientIP = req.ClientIP
+	data.EventTime = req.UsedAt.UTC().Format(lt.EventTimeLayout)
+
+	return lt.render(recoveryCodeUsedTemplate, req.Email, data)
+}
+
+// EmailChange renders email with code confirming new email address
+func (r *Renderer) EmailChange(req SendEmailChangeRequest) (Message, error) {
+	lt := r.localeTemplates(req.Locale)
+	data := r.newTemplateData(req.Email, req.Username)
+	data.VerificationCode = req.VerificationCode
+	data.CodeExpiresIn = lt.formatDuration(req.CodeTTL)
+
+	return lt.render(emailChangeTemplate, req.Email, data)
+}
+
+// EmailChanged renders security notice about email address being changed. Notice is sent to the previous address
+func (r *Renderer) EmailChanged(req SendEmailChangedRequest) (Message, error) {
+	lt := r.localeTemplates(req.Locale)
+	data := r.newTemplateData(req.Email, req.Username)
+	data.NewEmail = req.NewEmail
+	data.EventTime = req.ChangedAt.UTC().Format(lt.EventTimeLayout)
+
+	return lt.render(emailChangedTemplate, req.Email, data)
+}
+
+// SecurityNotice renders security notice about account event with template of the event
+func (r *Renderer) SecurityNotice(req SendSecurityNoticeRequest) (Message, error) {
+	if !slices.Contains(securityNoticeTemplates, req.Event) {
+		return Message{}, fmt.Errorf("%w of security event %q", ErrUnknownTemplate, req.Event)
+	}
+
+	lt := r.localeTemplates(req.Locale)
+	data := r.newTemplateData(req.Email, req.Username)
+	data.ClientIP = req.ClientIP
+	data.UserAgent = req.UserAgent
+	data.EventTime = req.OccurredAt.UTC().Format(lt.EventTimeLayout)
+
+	return lt.render(req.Event, req.Email, data)
+}
+
+// Preview renders email template with sample data in provided locale. Returns ErrUnknownTemplate if there is no template with name
+func (r *Renderer) Preview(name, locale string) (Message, error) {
+	if !slices.Contains(templateNames, name) {
+		return Message{}, fmt.Errorf("%w %q, available templates: %s", ErrUnknownTemplate, name, strings.Join(templateNames, ", "))
+	}
+
+	lt := r.localeTemplates(locale)
+	data := sampleTemplateData
+	data.BaseURL = r.baseURL
+	data.UnsubscribeURL = r.unsubscribeURL
+	data.VerifyEmailURL = r.verifyEmailURL
+	data.ContactEmail = r.contactEmail
+	data.PrivacyPolicyURL = r.baseURL + "/privacy-policy.html"
+	data.TermsOfServiceURL = r.baseURL + "/terms-of-service.html"
+	data.CurrentYear = time.Now().Year()
+	data.CodeExpiresIn = lt.formatDuration(24 * time.Hour)
+	data.EventTime = time.Now().UTC().Format(lt.EventTimeLayout)
+
+	return lt.render(name, data.Email, data)
+}
+
+// TemplateNames returns names of email templates
+func TemplateNames() []string {
+	return slices.Clone(templateNames)
+}
+
+// localeTemplates returns templates of provided locale. Locale without templates falls back to its language,
+// e.g. "es-MX" to "es", and then to TemplateDefaultLocale
+func (r *Renderer) localeTemplates(locale string) *localeTemplates {
+	locales := *r.locales.Load()
+
+	locale = strings.ToLower(locale)
+	if lt, ok := locales[locale]; ok {
+		return lt
+	}
+	if language, _, found := strings.Cut(locale, "-"); found {
+		if lt, ok := locales[language]; ok {
+			return lt
+		}
+	}
+
+	return locales[TemplateDefaultLocale]
+}
+
+// newTemplateData returns template data with fields common for all emails
+func (r *Renderer) newTemplateData(email, username string) templateData {
+	return templateData{
+		Email:             email,
+		Username:          username,
+		BaseURL:           r.baseURL,
+		UnsubscribeURL:    r.unsubscribeURL,
+		ContactEmail:      r.contactEmail,
+		PrivacyPolicyURL:  r.baseURL + "/privacy-policy.html",
+		TermsOfServiceURL: r.baseURL + "/terms-of-service.html",
+		CurrentYear:       time.Now().Year(),
+	}
+}
+
+// listUnsubscribeHeaders returns List-Unsubscribe headers allowing mail clients to unsubscribe with one click (RFC 8058).
+// Mail client sends POST request with "List-Unsubscribe=One-Click" body to unsubscribe link.
+// Headers are added only to emails with unsubscribe link. Security notices have no unsubscribe token and get no headers
+func (r *Renderer) listUnsubscribeHeaders(token string) map[string]string {
+	if token == "" || r.unsubscribeURL == "" {
+		return nil
+	}
+
+	unsubscribeURL, err := url.Parse(r.unsubscribeURL)
+	if err != nil {
+		return nil
+	}
+	query := unsubscribeURL.Query()
+	query.Set("token", token)
+	unsubscribeURL.RawQuery = query.Encode()
+
+	return map[string]string{
+		HeaderListUnsubscribe:     "<" + unsubscribeURL.String() + ">",
+		HeaderListUnsubscribePost: ListUnsubscribeOneClick,
+	}
+}
+
+// render fills HTML and text templates of email with data
+func (lt *localeTemplates) render(name, to string, data templateData) (Message, error) {
+	tmpl := lt.templates[name]
+	htmlContent, err := fillTemplate(tmpl.html, data)
+	if err != nil {
+		return Message{}, fmt.Errorf("%w: fill HTML template: %w", ErrRender, err)
+	}
+	textContent, err := fillTemplate(tmpl.text, data)
+	if err != nil {
+		return Message{}, fmt.Errorf("%w: fill text template: %w", ErrRender, err)
+	}
+
+	return Message{
+		To:      to,
+		Subject: lt.Subjects[name],
+		HTML:    htmlContent,
+		Text:    textContent,
+	}, nil
+}
+
+// fillTemplate fills template placeholders
+func fillTemplate(tmpl *template.Template, data templateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// formatDuration formats duration for display in emails, e.g. "24 hours" or "15 minutes"
+func (lt *localeTemplates) formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return lt.pluralize(int(d/time.Hour), "hour")
+	case d >= time.Minute && d%time.Minute == 0:
+		return lt.pluralize(int(d/time.Minute), "minute")
+	default:
+		return d.String()
+	}
+}
+
+func (lt *localeTemplates) pluralize(n int, unit string) string {
+	forms := lt.Units[unit]
+	if n == 1 {
+		return "1 " + forms["one"]
+	}
+	return fmt.Sprintf("%d %s", n, forms["other"])
+}
+
+// loadLocales loads templates of all locales. Every locale is a directory with templates and locale.json.
+// Templates, subjects and formats missing in locale are taken from TemplateDefaultLocale, which must be complete
+func loadLocales(fsys fs.FS) (map[string]*localeTemplates, error) {
+	defaultTemplates, err := loadLocale(fsys, TemplateDefaultLocale, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read templates dir: %w", err)
+	}
+
+	locales := map[string]*localeTemplates{TemplateDefaultLocale: defaultTemplates}
+	for _, entry := range entries {
+		locale := strings.ToLower(entry.Name())
+		if locale == TemplateDefaultLocale || strings.HasPrefix(locale, ".") {
+			continue
+		}
+		// entry is checked with stat as locale directories may be symlinks, e.g. in mounted config maps
+		info, sErr := fs.Stat(fsys, entry.Name())
+		if sErr != nil {
+			return nil, fmt.Errorf("stat %s locale dir: %w", entry.Name(), sErr)
+		}
+		if !info.IsDir() {
+			continue
+		}
+		locales[locale], err = loadLocale(fsys, entry.Name(), defaultTemplates)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return locales, nil
+}
+
+// loadLocale loads templates and locale.json of locale. Missing templates and settings are taken from fallback.
+// If fallback is nil, all of them are required
+func loadLocale(fsys fs.FS, locale string, fallback *localeTemplates) (*localeTemplates, error) {
+	var cfg localeConfig
+	cfgContent, err := fs.ReadFile(fsys, path.Join(locale, "locale.json"))
+	if err != nil && (fallback == nil || !errors.Is(err, fs.ErrNotExist)) {
+		return nil, fmt.Errorf("load %s locale config: %w", locale, err)
+	}
+	if err == nil {
+		if err = json.Unmarshal(cfgContent, &cfg); err != nil {
+			return nil, fmt.Errorf("parse %s locale config: %w", locale, err)
+		}
+	}
+
+	lt := &localeTemplates{
+		localeConfig: cfg,
+		templates:    make(map[string]emailTemplates, len(templateNames)),
+	}
+	if lt.Subjects == nil {
+		lt.Subjects = make(map[string]string, len(templateNames))
+	}
+	if lt.Units == nil {
+		lt.Units = make(map[string]map[string]string)
+	}
+
+	for _, name := range templateNames {
+		htmlTmpl, textTmpl, lErr := loadTemplates(fsys, locale, name)
+		switch {
+		case lErr == nil:
+			lt.templates[name] = emailTemplates{html: htmlTmpl, text: textTmpl}
+		case fallback != nil && errors.Is(lErr, fs.ErrNotExist):
+			lt.templates[name] = fallback.templates[name]
+		default:
+			return nil, fmt.Errorf("locale %s: %w", locale, lErr)
+		}
+
+		if lt.Subjects[name] == "" {
+			if fallback == nil {
+				return nil, fmt.Errorf("locale %s: no subject of %s email", locale, name)
+			}
+			lt.Subjects[name] = fallback.Subjects[name]
+		}
+	}
+
+	if lt.EventTimeLayout == "" {
+		if fallback == nil {
+			return nil, fmt.Errorf("locale %s: no event time layout", locale)
+		}
+		lt.EventTimeLayout = fallback.EventTimeLayout
+	}
+
+	for _, unit := range []string{"hour", "minute"} {
+		if lt.Units[unit]["one"] != "" && lt.Units[unit]["other"] != "" {
+			continue
+		}
+		if fallback == nil {
+			return nil, fmt.Errorf("locale %s: no plural forms of %s", locale, unit)
+		}
+		lt.Units[unit] = fallback.Units[unit]
+	}
+
+	return lt, nil
+}
+
+// loadTemplates loads and parses HTML and text templates with provided name from dir
+func loadTemplates(fsys fs.FS, dir, name string) (htmlTmpl *template.Template, textTmpl *template.Template, err error) {
+	htmlTemplateContent, err := fs.ReadFile(fsys, path.Join(dir, name+".html"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("load %s HTML template: %w", name, err)
+	}
+
+	textTemplateContent, err := fs.ReadFile(fsys, path.Join(dir, name+".txt"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("load %s text template: %w", name, err)
+	}
+
+	htmlTmpl, err = template.New("email").Parse(string(htmlTemplateContent))
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse %s HTML template: %w", name, err)
+	}
+	if err = htmlTmpl.Execute(io.Discard, sampleTemplateData); err != nil {
+		return nil, nil, fmt.Errorf("validate %s HTML template: %w", name, err)
+	}
+
+	textTmpl, err = template.New("email").Parse(string(textTemplateContent))
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse %s text template: %w", name, err)
+	}
+	if err = textTmpl.Execute(io.Discard, sampleTemplateData); err != nil {
+		return nil, nil, fmt.Errorf("validate %s text template: %w", name, err)
+	}
+
+	return htmlTmpl, textTmpl, nil
+}
+
+// sampleTemplateData is used to validate templates on load, e.g. that they reference only existing fields
+var sampleTemplateData = templateData{
+	Email:             "player@example.com",
+	Username:          "player",
+	VerificationCode:  "123456",
+	VerificationToken: "token",
+	VerifyEmailURL:    "https://example.com/verify-email",
+	CodeExpiresIn:     "24 hours",
+	UnsubscribeToken:  "token",
+	BaseURL:           "https://example.com",
+	UnsubscribeURL:    "https://example.com/unsubscribe",
+	ContactEmail:      "support@example.com",
+	PrivacyPolicyURL:  "https://example.com/privacy-policy.html",
+	TermsOfServiceURL: "https://example.com/terms-of-service.html",
+	CurrentYear:       2026,
+	SignInCode:        "123456",
+	SignInToken:       "token",
+	RecoveryCodesLeft: 1,
+	ClientIP:          "127.0.0.1",
+	UserAgent:         "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Firefox/140.0",
+	EventTime:         "Jan 2, 2026 15:04 UTC",
+	NewEmail:          "new@example.com",
+}
+
+// overlayFS serves files from dir and falls back to base for files missing in dir
+type overlayFS struct {
+	dir  fs.FS
+	base fs.FS
+}
+
+// Open opens file from dir or from base if dir doesn't have it
+func (o overlayFS) Open(name string) (fs.File, error) {
+	f, err := o.dir.Open(name)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return f, err
+	}
+
+	return o.base.Open(name)
+}
+
+// ReadDir returns merged entries of directory in dir and base sorted by name. Entries of dir take precedence
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	dirEntries, dirErr := fs.ReadDir(o.dir, name)
+	if dirErr != nil && !errors.Is(dirErr, fs.ErrNotExist) {
+		return nil, dirErr
+	}
+	baseEntries, baseErr := fs.ReadDir(o.base, name)
+	if baseErr != nil && (dirErr != nil || !errors.Is(baseErr, fs.ErrNotExist)) {
+		return nil, baseErr
+	}
+
+	entries := make(map[string]fs.DirEntry, len(dirEntries)+len(baseEntries))
+	for _, entry := range baseEntries {
+		entries[entry.Name()] = entry
+	}
+	for _, entry := range dirEntries {
+		entries[entry.Name()] = entry
+	}
+
+	merged := slices.Collect(maps.Values(entries))
+	slices.SortFunc(merged, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return merged, nil
+}
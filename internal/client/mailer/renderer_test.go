@@ -0,0 +1,271 @@
+package mailer_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer_EmailVerification_Locale(t *testing.T) {
+	renderer, err := mailer.NewRenderer(mailer.RendererConfig{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		locale          string
+		expectedSubject string
+		expectedText    string
+	}{
+		{
+			name:            "default locale",
+			locale:          "",
+			expectedSubject: "Verify Your Email Address - Game Library",
+			expectedText:    "will expire in 24 hours",
+		},
+		{
+			name:            "locale with templates",
+			locale:          "es",
+			expectedSubject: "Verifica tu dirección de correo electrónico - Game Library",
+			expectedText:    "caducarán en 24 horas",
+		},
+		{
+			name:            "regional locale falls back to language",
+			locale:          "es-MX",
+			expectedSubject: "Verifica tu dirección de correo electrónico - Game Library",
+			expectedText:    "caducarán en 24 horas",
+		},
+		{
+			name:            "locale without templates falls back to default",
+			locale:          "ja",
+			expectedSubject: "Verify Your Email Address - Game Library",
+			expectedText:    "will expire in 24 hours",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := renderer.EmailVerification(mailer.SendEmailVerificationRequest{
+				Email:            "test@example.com",
+				Username:         "testuser",
+				Locale:           tt.locale,
+				VerificationCode: "123456",
+				CodeTTL:          24 * time.Hour,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, "test@example.com", msg.To)
+			assert.Equal(t, tt.expectedSubject, msg.Subject)
+			assert.Contains(t, msg.Text, tt.expectedText)
+			assert.Contains(t, msg.HTML, "123456")
+		})
+	}
+}
+
+func TestRenderer_RecoveryCodeUsed_LocaleEventTime(t *testing.T) {
+	renderer, err := mailer.NewRenderer(mailer.RendererConfig{})
+	require.NoError(t, err)
+
+	usedAt := time.Date(2026, 3, 4, 5, 6, 0, 0, time.UTC)
+
+	msg, err := renderer.RecoveryCodeUsed(mailer.SendRecoveryCodeUsedRequest{
+		Email:             "test@example.com",
+		Username:          "testuser",
+		RecoveryCodesLeft: 5,
+		UsedAt:            usedAt,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, msg.Text, "Time: Mar 4, 2026 05:06 UTC")
+
+	msg, err = renderer.RecoveryCodeUsed(mailer.SendRecoveryCodeUsedRequest{
+		Email:             "test@example.com",
+		Username:          "testuser",
+		Locale:            "es",
+		RecoveryCodesLeft: 5,
+		UsedAt:            usedAt,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Código de recuperación usado - Game Library", msg.Subject)
+	assert.Contains(t, msg.Text, "Fecha: 04/03/2026 05:06 UTC")
+}
+
+func TestRenderer_SecurityNotice(t *testing.T) {
+	renderer, err := mailer.NewRenderer(mailer.RendererConfig{})
+	require.NoError(t, err)
+
+	occurredAt := time.Date(2026, 3, 4, 5, 6, 0, 0, time.UTC)
+
+	msg, err := renderer.SecurityNotice(mailer.SendSecurityNoticeRequest{
+		Email:      "test@example.com",
+		Username:   "testuser",
+		Event:      "new_sign_in",
+		ClientIP:   "10.0.0.1",
+		UserAgent:  "Mozilla/5.0 Firefox/140.0",
+		OccurredAt: occurredAt,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "test@example.com", msg.To)
+	assert.Equal(t, "New Sign In to Your Account - Game Library", msg.Subject)
+	assert.Contains(t, msg.Text, "Time: Mar 4, 2026 05:06 UTC")
+	assert.Contains(t, msg.Text, "IP address: 10.0.0.1")
+	assert.Contains(t, msg.HTML, "Mozilla/5.0 Firefox/140.0")
+
+	msg, err = renderer.SecurityNotice(mailer.SendSecurityNoticeRequest{
+		Email:      "test@example.com",
+		Username:   "testuser",
+		Locale:     "es",
+		Event:      "password_changed",
+		OccurredAt: occurredAt,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Contraseña cambiada - Game Library", msg.Subject)
+	assert.NotContains(t, msg.Text, "IP")
+
+	_, err = renderer.SecurityNotice(mailer.SendSecurityNoticeRequest{Email: "test@example.com", Event: "unknown"})
+	require.ErrorIs(t, err, mailer.ErrUnknownTemplate)
+}
+
+func writeTemplate(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	p := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+	require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+}
+
+func TestRenderer_TemplatesDir(t *testing.T) {
+	t.Run("overrides embedded templates", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTemplate(t, dir, "en/email_verification.txt", "Custom code {{.VerificationCode}}")
+		writeTemplate(t, dir, "de/locale.json", `{"subjects": {"email_verification": "Bestätige deine E-Mail-Adresse"}}`)
+
+		renderer, err := mailer.NewRenderer(mailer.RendererConfig{TemplatesDir: dir})
+		require.NoError(t, err)
+
+		msg, err := renderer.EmailVerification(mailer.SendEmailVerificationRequest{Email: "test@example.com", VerificationCode: "123456"})
+		require.NoError(t, err)
+		assert.Equal(t, "Custom code 123456", msg.Text)
+		// embedded HTML template is used as it isn't overridden
+		assert.Contains(t, msg.HTML, "Verify Your Email Address")
+		assert.Equal(t, "Verify Your Email Address - Game Library", msg.Subject)
+
+		// locale added in templates dir uses default templates with its own subjects
+		msg, err = renderer.EmailVerification(mailer.SendEmailVerificationRequest{Email: "test@example.com", Locale: "de", VerificationCode: "123456"})
+		require.NoError(t, err)
+		assert.Equal(t, "Bestätige deine E-Mail-Adresse", msg.Subject)
+		assert.Equal(t, "Custom code 123456", msg.Text)
+	})
+
+	t.Run("unknown field fails", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTemplate(t, dir, "en/email_sign_in.html", "<p>{{.SignInLink}}</p>")
+
+		_, err := mailer.NewRenderer(mailer.RendererConfig{TemplatesDir: dir})
+		require.ErrorContains(t, err, "validate email_sign_in HTML template")
+	})
+
+	t.Run("parse error fails", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTemplate(t, dir, "es/email_change.txt", "{{if .VerificationCode}}")
+
+		_, err := mailer.NewRenderer(mailer.RendererConfig{TemplatesDir: dir})
+		require.ErrorContains(t, err, "parse email_change text template")
+	})
+
+	t.Run("default locale without subjects fails", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTemplate(t, dir, "en/locale.json", `{"subjects": {}}`)
+
+		_, err := mailer.NewRenderer(mailer.RendererConfig{TemplatesDir: dir})
+		require.ErrorContains(t, err, "no subject of email_verification email")
+	})
+
+	t.Run("missing dir fails", func(t *testing.T) {
+		_, err := mailer.NewRenderer(mailer.RendererConfig{TemplatesDir: filepath.Join(t.TempDir(), "missing")})
+		require.Error(t, err)
+	})
+}
+
+func TestRenderer_Reload(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "en/email_verification.txt", "Version 1")
+
+	renderer, err := mailer.NewRenderer(mailer.RendererConfig{TemplatesDir: dir})
+	require.NoError(t, err)
+
+	render := func() string {
+		msg, rErr := renderer.EmailVerification(mailer.SendEmailVerificationRequest{Email: "test@example.com"})
+		require.NoError(t, rErr)
+		return msg.Text
+	}
+	assert.Equal(t, "Version 1", render())
+
+	writeTemplate(t, dir, "en/email_verification.txt", "Version 2")
+	require.NoError(t, renderer.Reload())
+	assert.Equal(t, "Version 2", render())
+
+	// invalid templates are not applied
+	writeTemplate(t, dir, "en/email_verification.txt", "Version 3 {{.Unknown}}")
+	require.Error(t, renderer.Reload())
+	assert.Equal(t, "Version 2", render())
+}
+
+func TestRenderer_Preview(t *testing.T) {
+	renderer, err := mailer.NewRenderer(mailer.RendererConfig{BaseURL: "https://example.com"})
+	require.NoError(t, err)
+
+	for _, name := range mailer.TemplateNames() {
+		t.Run(name, func(t *testing.T) {
+			msg, pErr := renderer.Preview(name, "es")
+			require.NoError(t, pErr)
+			assert.Contains(t, msg.Subject, "Game Library")
+			assert.NotEmpty(t, msg.HTML)
+			assert.NotEmpty(t, msg.Text)
+			assert.Contains(t, msg.HTML, "https://example.com")
+		})
+	}
+
+	t.Run("unknown template", func(t *testing.T) {
+		_, pErr := renderer.Preview("unknown", "")
+		require.ErrorIs(t, pErr, mailer.ErrUnknownTemplate)
+	})
+}
+
+func TestRenderer_ListUnsubscribeHeaders(t *testing.T) {
+	renderer, err := mailer.NewRenderer(mailer.RendererConfig{UnsubscribeURL: "https://example.com/unsubscribe?source=email"})
+	require.NoError(t, err)
+
+	t.Run("email verification with unsubscribe token", func(t *testing.T) {
+		msg, rErr := renderer.EmailVerification(mailer.SendEmailVerificationRequest{Email: "test@example.com", UnsubscribeToken: "dG9rZW4="})
+		require.NoError(t, rErr)
+		assert.Equal(t, map[string]string{
+			mailer.HeaderListUnsubscribe:     "<https://example.com/unsubscribe?source=email&token=dG9rZW4%3D>",
+			mailer.HeaderListUnsubscribePost: mailer.ListUnsubscribeOneClick,
+		}, msg.Headers)
+	})
+
+	t.Run("email sign in with unsubscribe token", func(t *testing.T) {
+		msg, rErr := renderer.EmailSignIn(mailer.SendEmailSignInRequest{Email: "test@example.com", UnsubscribeToken: "dG9rZW4="})
+		require.NoError(t, rErr)
+		assert.Equal(t, map[string]string{
+			mailer.HeaderListUnsubscribe:     "<https://example.com/unsubscribe?source=email&token=dG9rZW4%3D>",
+			mailer.HeaderListUnsubscribePost: mailer.ListUnsubscribeOneClick,
+		}, msg.Headers)
+	})
+
+	t.Run("email without unsubscribe token", func(t *testing.T) {
+		msg, rErr := renderer.EmailVerification(mailer.SendEmailVerificationRequest{Email: "test@example.com"})
+		require.NoError(t, rErr)
+		assert.Empty(t, msg.Headers)
+	})
+
+	t.Run("security notice", func(t *testing.T) {
+		msg, rErr := renderer.RecoveryCodeUsed(mailer.SendRecoveryCodeUsedRequest{Email: "test@example.com", UsedAt: time.Now()})
+		require.NoError(t, rErr)
+		assert.Empty(t, msg.Headers)
+	})
+}
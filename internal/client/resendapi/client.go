@@ -1,147 +1,165 @@
 package resendapi
 
 import (
-	"bytes"
 	"context"
-	"embed"
 	"errors"
 	"fmt"
-	"html/template"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
 	"github.com/resend/resend-go/v2"
 	"go.opentelemetry.io/otel"
 )
 
-//go:embed templates/*
-var templateFS embed.FS
+// ProviderName - name of Resend email provider
+const ProviderName = "resend"
 
-var (
-	// ErrDailyQuotaExceeded is returned when the daily email quota is exceeded
-	ErrDailyQuotaExceeded = errors.New("daily quota exceeded")
-
-	tracer = otel.Tracer("resendapi")
-)
+var tracer = otel.Tracer("resendapi")
 
 // Client represents Resend client
 type Client struct {
-	client               *resend.Client
-	baseURL              string
-	fromEmail            string
-	fromName             string
-	contactEmail         string
-	unsubscribeURL       string
-	verificationHTMLTmpl *template.Template
-	verificationTextTmpl *template.Template
+	client    *resend.Client
+	renderer  *mailer.Renderer
+	fromEmail string
+	fromName  string
 }
 
 // Config represents Resend client configuration
 type Config struct {
-	APIToken       string
-	FromEmail      string
-	ContactEmail   string
-	BaseURL        string
-	UnsubscribeURL string
-	Timeout        time.Duration
+	APIToken  string
+	FromEmail string
+	Timeout   time.Duration
 }
 
 // NewClient creates a new Resend client
-func NewClient(cfg Config) (*Client, error) {
+func NewClient(cfg Config, renderer *mailer.Renderer) *Client {
 	httpClient := &http.Client{
 		Timeout: cfg.Timeout,
 	}
 	client := resend.NewCustomClient(httpClient, cfg.APIToken)
 
-	htmlTemplateContent, err := templateFS.ReadFile("templates/email_verification.html")
+	return &Client{
+		client:    client,
+		renderer:  renderer,
+		fromEmail: cfg.FromEmail,
+		fromName:  "Game Library",
+	}
+}
+
+// SendEmailVerification sends email verification email with verification code and link and returns message id
+func (c *Client) SendEmailVerification(ctx context.Context, req mailer.SendEmailVerificationRequest) (mailer.SendResult, error) {
+	ctx, span := tracer.Start(ctx, "sendEmailVerification")
+	defer span.End()
+
+	msg, err := c.renderer.EmailVerification(req)
 	if err != nil {
-		return nil, fmt.Errorf("load HTML template: %w", err)
+		return mailer.SendResult{}, err
 	}
 
-	textTemplateContent, err := templateFS.ReadFile("templates/email_verification.txt")
+	return c.send(ctx, msg)
+}
+
+// SendEmailSignIn sends passwordless sign in email with sign in code and link and returns message id
+func (c *Client) SendEmailSignIn(ctx context.Context, req mailer.SendEmailSignInRequest) (mailer.SendResult, error) {
+	ctx, span := tracer.Start(ctx, "sendEmailSignIn")
+	defer span.End()
+
+	msg, err := c.renderer.EmailSignIn(req)
 	if err != nil {
-		return nil, fmt.Errorf("load text template: %w", err)
+		return mailer.SendResult{}, err
 	}
 
-	htmlTmpl, err := template.New("email").Parse(string(htmlTemplateContent))
+	return c.send(ctx, msg)
+}
+
+// SendRecoveryCodeUsed sends security notice about two-factor authentication recovery code being used and returns message id
+func (c *Client) SendRecoveryCodeUsed(ctx context.Context, req mailer.SendRecoveryCodeUsedRequest) (mailer.SendResult, error) {
+	ctx, span := tracer.Start(ctx, "sendRecoveryCodeUsed")
+	defer span.End()
+
+	msg, err := c.renderer.RecoveryCodeUsed(req)
 	if err != nil {
-		return nil, fmt.Errorf("parse HTML template: %w", err)
+		return mailer.SendResult{}, err
 	}
 
-	textTmpl, err := template.New("email").Parse(string(textTemplateContent))
+	return c.send(ctx, msg)
+}
+
+// SendEmailChange sends code confirming new email address and returns message id
+func (c *Client) SendEmailChange(ctx context.Context, req mailer.SendEmailChangeRequest) (mailer.SendResult, error) {
+	ctx, span := tracer.Start(ctx, "sendEmailChange")
+	defer span.End()
+
+	msg, err := c.renderer.EmailChange(req)
 	if err != nil {
-		return nil, fmt.Errorf("parse text template: %w", err)
+		return mailer.SendResult{}, err
 	}
 
-	return &Client{
-		client:               client,
-		baseURL:              cfg.BaseURL,
-		fromEmail:            cfg.FromEmail,
-		fromName:             "Game Library",
-		contactEmail:         cfg.ContactEmail,
-		unsubscribeURL:       cfg.UnsubscribeURL,
-		verificationHTMLTmpl: htmlTmpl,
-		verificationTextTmpl: textTmpl,
-	}, nil
+	return c.send(ctx, msg)
 }
 
-// SendEmailVerification sends email verification email with verification code and returns message id
-func (c *Client) SendEmailVerification(ctx context.Context, req SendEmailVerificationRequest) (string, error) {
-	ctx, span := tracer.Start(ctx, "sendEmailVerification")
+// SendEmailChanged sends security notice about email address being changed to the previous address and returns message id
+func (c *Client) SendEmailChanged(ctx context.Context, req mailer.SendEmailChangedRequest) (mailer.SendResult, error) {
+	ctx, span := tracer.Start(ctx, "sendEmailChanged")
 	defer span.End()
 
-	htmlContent, err := c.fillTemplate(c.verificationHTMLTmpl, req)
+	msg, err := c.renderer.EmailChanged(req)
 	if err != nil {
-		return "", fmt.Errorf("fill HTML template: %w", err)
+		return mailer.SendResult{}, err
 	}
-	textContent, err := c.fillTemplate(c.verificationTextTmpl, req)
+
+	return c.send(ctx, msg)
+}
+
+// SendSecurityNotice sends security notice about account event and returns message id
+func (c *Client) SendSecurityNotice(ctx context.Context, req mailer.SendSecurityNoticeRequest) (mailer.SendResult, error) {
+	ctx, span := tracer.Start(ctx, "sendSecurityNotice")
+	defer span.End()
+
+	msg, err := c.renderer.SecurityNotice(req)
 	if err != nil {
-		return "", fmt.Errorf("fill text template: %w", err)
+		return mailer.SendResult{}, err
 	}
 
+	return c.send(ctx, msg)
+}
+
+// send sends rendered email. Returns message id
+func (c *Client) send(ctx context.Context, msg mailer.Message) (mailer.SendResult, error) {
 	params := &resend.SendEmailRequest{
 		From:    fmt.Sprintf("%s <%s>", c.fromName, c.fromEmail),
-		To:      []string{req.Email},
-		Subject: "Verify Your Email Address - Game Library",
-		Html:    htmlContent,
-		Text:    textContent,
+		To:      []string{msg.To},
+		Subject: msg.Subject,
+		Html:    msg.HTML,
+		Text:    msg.Text,
+		Headers: msg.Headers,
 	}
 
 	sent, err := c.client.Emails.SendWithContext(ctx, params)
 	if err != nil {
-		if isQuotaExceededError(err) {
-			return "", ErrDailyQuotaExceeded
+		if quotaErr := asQuotaExceededError(err, time.Now()); quotaErr != nil {
+			return mailer.SendResult{}, quotaErr
 		}
-		return "", fmt.Errorf("send email: %w", err)
+		return mailer.SendResult{}, fmt.Errorf("send email: %w", err)
 	}
 
-	return sent.Id, nil
+	return mailer.SendResult{MessageID: sent.Id, Provider: ProviderName}, nil
 }
 
-// fillTemplate fills template placeholders
-func (c *Client) fillTemplate(tmpl *template.Template, req SendEmailVerificationRequest) (string, error) {
-	data := templateData{
-		Email:             req.Email,
-		Username:          req.Username,
-		VerificationCode:  req.VerificationCode,
-		UnsubscribeToken:  req.UnsubscribeToken,
-		BaseURL:           c.baseURL,
-		UnsubscribeURL:    c.unsubscribeURL,
-		ContactEmail:      c.contactEmail,
-		PrivacyPolicyURL:  c.baseURL + "/privacy-policy.html",
-		TermsOfServiceURL: c.baseURL + "/terms-of-service.html",
-		CurrentYear:       time.Now().Year(),
+// asQuotaExceededError returns *mailer.QuotaExceededError if err is rate limit error of exhausted daily or monthly quota.
+// Other rate limit errors are short-term and are retried as any other send error
+func asQuotaExceededError(err error, now time.Time) *mailer.QuotaExceededError {
+	var rateLimitErr *resend.RateLimitError
+	if !errors.As(err, &rateLimitErr) || !strings.Contains(strings.ToLower(rateLimitErr.Message), "quota") {
+		return nil
 	}
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("execute template: %w", err)
-	}
-
-	return buf.String(), nil
-}
 
-func isQuotaExceededError(err error) bool {
-	errStr := err.Error()
-	return strings.Contains(errStr, "429") || strings.Contains(errStr, "Too Many Requests")
+	quotaErr := &mailer.QuotaExceededError{}
+	if retryAfter, pErr := strconv.Atoi(rateLimitErr.RetryAfter); pErr == nil && retryAfter > 0 {
+		quotaErr.ResetAt = now.Add(time.Duration(retryAfter) * time.Second)
+	}
+	return quotaErr
 }
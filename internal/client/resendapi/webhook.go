@@ -0,0 +1,125 @@
+package resendapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Resend webhook event types
+const (
+	EventTypeDelivered  = "email.delivered"
+	EventTypeBounced    = "email.bounced"
+	EventTypeComplained = "email.complained"
+)
+
+// BounceTypePermanent - bounce type of hard bounce, email address permanently rejects emails
+const BounceTypePermanent = "Permanent"
+
+// webhookTolerance - maximal difference between webhook timestamp and current time
+const webhookTolerance = 5 * time.Minute
+
+// webhookSecretPrefix - prefix of webhook signing secret
+const webhookSecretPrefix = "whsec_"
+
+// webhook verification errors
+var (
+	ErrWebhookInvalidSignature = errors.New("invalid webhook signature")
+	ErrWebhookInvalidTimestamp = errors.New("invalid webhook timestamp")
+)
+
+// WebhookVerifier verifies signatures of Resend webhooks. Resend signs webhooks with Svix
+type WebhookVerifier struct {
+	key []byte
+}
+
+// NewWebhookVerifier creates a new webhook verifier with signing secret in format whsec_<base64 key>
+func NewWebhookVerifier(secret string) (*WebhookVerifier, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, webhookSecretPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("decode webhook secret: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, errors.New("webhook secret is empty")
+	}
+
+	return &WebhookVerifier{key: key}, nil
+}
+
+// Verify verifies webhook signature using values of svix-id, svix-timestamp and svix-signature headers and raw body
+func (v *WebhookVerifier) Verify(msgID, timestamp, signature string, body []byte) error {
+	if msgID == "" || timestamp == "" || signature == "" {
+		return ErrWebhookInvalidSignature
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrWebhookInvalidTimestamp
+	}
+	if diff := time.Since(time.Unix(ts, 0)); diff > webhookTolerance || diff < -webhookTolerance {
+		return ErrWebhookInvalidTimestamp
+	}
+
+	mac := hmac.New(sha256.New, v.key)
+	mac.Write([]byte(msgID + "." + timestamp + "."))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	// header contains space separated list of versioned signatures, e.g. "v1,<base64> v1,<base64>"
+	for _, versioned := range strings.Fields(signature) {
+		version, sig, ok := strings.Cut(versioned, ",")
+		if !ok || version != "v1" {
+			continue
+		}
+		decoded, dErr := base64.StdEncoding.DecodeString(sig)
+		if dErr != nil {
+			continue
+		}
+		if hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+
+	return ErrWebhookInvalidSignature
+}
+
+// WebhookEvent represents Resend webhook event
+type WebhookEvent struct {
+	Type      string           `json:"type"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      WebhookEventData `json:"data"`
+}
+
+// WebhookEventData represents data of Resend webhook event
+type WebhookEventData struct {
+	EmailID   string         `json:"email_id"`
+	To        []string       `json:"to"`
+	Bounce    *WebhookBounce `json:"bounce,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// WebhookBounce represents bounce details of email.bounced event
+type WebhookBounce struct {
+	Type    string `json:"type"`
+	SubType string `json:"subType"`
+	Message string `json:"message"`
+}
+
+// ParseWebhookEvent parses webhook body
+func ParseWebhookEvent(body []byte) (WebhookEvent, error) {
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return WebhookEvent{}, fmt.Errorf("unmarshal webhook event: %w", err)
+	}
+	if event.Type == "" || event.Data.EmailID == "" {
+		return WebhookEvent{}, errors.New("webhook event type or email id is empty")
+	}
+
+	return event, nil
+}
@@ -0,0 +1,338 @@
+package smtpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"go.opentelemetry.io/otel"
+)
+
+// ProviderName - name of SMTP email provider
+const ProviderName = "smtp"
+
+// Connection security modes
+const (
+	// SecurityNone - plain connection without encryption
+	SecurityNone = "none"
+	// SecurityStartTLS - plain connection upgraded to TLS with STARTTLS command
+	SecurityStartTLS = "starttls"
+	// SecurityTLS - implicit TLS connection
+	SecurityTLS = "tls"
+)
+
+var tracer = otel.Tracer("smtpclient")
+
+// Client represents SMTP client
+type Client struct {
+	renderer *mailer.Renderer
+	host     string
+	addr     string
+	username string
+	password string
+	security string
+	from     *mail.Address
+	timeout  time.Duration
+}
+
+// Config represents SMTP client configuration
+type Config struct {
+	Host      string
+	Port      int
+	Username  string
+	Password  string
+	FromEmail string
+	// Security - connection security, one of none, starttls, tls
+	Security string
+	Timeout  time.Duration
+}
+
+// NewClient creates a new SMTP client
+func NewClient(cfg Config, renderer *mailer.Renderer) (*Client, error) {
+	switch cfg.Security {
+	case SecurityNone, SecurityStartTLS, SecurityTLS:
+	default:
+		return nil, fmt.Errorf("unsupported connection security %q", cfg.Security)
+	}
+
+	from, err := mail.ParseAddress(cfg.FromEmail)
+	if err != nil {
+		return nil, fmt.Errorf("parse from email: %w", err)
+	}
+	if from.Name == "" {
+		from.Name = "Game Library"
+	}
+
+	return &Client{
+		renderer: renderer,
+		host:     cfg.Host,
+		addr:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		username: cfg.Username,
+		password: cfg.Password,
+		security: cfg.Security,
+		from:     from,
+		timeout:  cfg.Timeout,
+	}, nil
+}
+
+// SendEmailVerification sends email verification email with verification code and link and returns message id
+func (c *Client) SendEmailVerification(ctx context.Context, req mailer.SendEmailVerificationRequest) (mailer.SendResult, error) {
+	ctx, span := tracer.Start(ctx, "sendEmailVerification")
+	defer span.End()
+
+	msg, err := c.renderer.EmailVerification(req)
+	if err != nil {
+		return mailer.SendResult{}, err
+	}
+
+	return c.send(ctx, msg)
+}
+
+// SendEmailSignIn sends passwordless sign in email with sign in code and link and returns message id
+func (c *Client) SendEmailSignIn(ctx context.Context, req mailer.SendEmailSignInRequest) (mailer.SendResult, error) {
+	ctx, span := tracer.Start(ctx, "sendEmailSignIn")
+	defer span.End()
+
+	msg, err := c.renderer.EmailSignIn(req)
+	if err != nil {
+		return mailer.SendResult{}, err
+	}
+
+	return c.send(ctx, msg)
+}
+
+// SendRecoveryCodeUsed sends security notice about two-factor authentication recovery code being used and returns message id
+func (c *Client) SendRecoveryCodeUsed(ctx context.Context, req mailer.SendRecoveryCodeUsedRequest) (mailer.SendResult, error) {
+	ctx, span := tracer.Start(ctx, "sendRecoveryCodeUsed")
+	defer span.End()
+
+	msg, err := c.renderer.RecoveryCodeUsed(req)
+	if err != nil {
+		return mailer.SendResult{}, err
+	}
+
+	return c.send(ctx, msg)
+}
+
+// SendEmailChange sends code confirming new email address and returns message id
+func (c *Client) SendEmailChange(ctx context.Context, req mailer.SendEmailChangeRequest) (mailer.SendResult, error) {
+	ctx, span := tracer.Start(ctx, "sendEmailChange")
+	defer span.End()
+
+	msg, err := c.renderer.EmailChange(req)
+	if err != nil {
+		return mailer.SendResult{}, err
+	}
+
+	return c.send(ctx, msg)
+}
+
+// SendEmailChanged sends security notice about email address being changed to the previous address and returns message id
+func (c *Client) SendEmailChanged(ctx context.Context, req mailer.SendEmailChangedRequest) (mailer.SendResult, error) {
+	ctx, span := tracer.Start(ctx, "sendEmailChanged")
+	defer span.End()
+
+	msg, err := c.renderer.EmailChanged(req)
+	if err != nil {
+		return mailer.SendResult{}, err
+	}
+
+	return c.send(ctx, msg)
+}
+
+// SendSecurityNotice sends security notice about account event and returns message id
+func (c *Client) SendSecurityNotice(ctx context.Context, req mailer.SendSecurityNoticeRequest) (mailer.SendResult, error) {
+	ctx, span := tracer.Start(ctx, "sendSecurityNotice")
+	defer span.End()
+
+	msg, err := c.renderer.SecurityNotice(req)
+	if err != nil {
+		return mailer.SendResult{}, err
+	}
+
+	return c.send(ctx, msg)
+}
+
+// send delivers rendered email to SMTP server. Returns message id
+func (c *Client) send(ctx context.Context, msg mailer.Message) (mailer.SendResult, error) {
+	messageID, err := c.newMessageID()
+	if err != nil {
+		return mailer.SendResult{}, fmt.Errorf("generate message id: %w", err)
+	}
+
+	body, err := buildMessage(c.from, msg, messageID, time.Now())
+	if err != nil {
+		return mailer.SendResult{}, fmt.Errorf("build message: %w", err)
+	}
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return mailer.SendResult{}, fmt.Errorf("connect to smtp server: %w", err)
+	}
+
+	sc, err := smtp.NewClient(conn, c.host)
+	if err != nil {
+		_ = conn.Close()
+		return mailer.SendResult{}, fmt.Errorf("create smtp client: %w", err)
+	}
+	defer sc.Close()
+
+	if c.security == SecurityStartTLS {
+		if ok, _ := sc.Extension("STARTTLS"); !ok {
+			return mailer.SendResult{}, fmt.Errorf("smtp server %s does not support STARTTLS", c.addr)
+		}
+		if err = sc.StartTLS(&tls.Config{ServerName: c.host, MinVersion: tls.VersionTLS12}); err != nil {
+			return mailer.SendResult{}, fmt.Errorf("starttls: %w", err)
+		}
+	}
+
+	if c.username != "" {
+		if err = sc.Auth(smtp.PlainAuth("", c.username, c.password, c.host)); err != nil {
+			return mailer.SendResult{}, fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err = sc.Mail(c.from.Address); err != nil {
+		return mailer.SendResult{}, fmt.Errorf("smtp mail from: %w", err)
+	}
+	if err = sc.Rcpt(msg.To); err != nil {
+		return mailer.SendResult{}, fmt.Errorf("smtp rcpt to: %w", err)
+	}
+
+	w, err := sc.Data()
+	if err != nil {
+		return mailer.SendResult{}, fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err = w.Write(body); err != nil {
+		return mailer.SendResult{}, fmt.Errorf("write message: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return mailer.SendResult{}, fmt.Errorf("send message: %w", err)
+	}
+
+	if err = sc.Quit(); err != nil {
+		return mailer.SendResult{}, fmt.Errorf("smtp quit: %w", err)
+	}
+
+	return mailer.SendResult{MessageID: messageID, Provider: ProviderName}, nil
+}
+
+// dial opens connection to SMTP server, using implicit TLS if configured.
+// Connection deadline is set to client timeout or context deadline, whichever comes first
+func (c *Client) dial(ctx context.Context) (net.Conn, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var conn net.Conn
+	var err error
+	if c.security == SecurityTLS {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: c.host, MinVersion: tls.VersionTLS12}}
+		conn, err = dialer.DialContext(ctx, "tcp", c.addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", c.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// newMessageID generates unique message id in domain of sender address
+func (c *Client) newMessageID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	domain := c.host
+	if i := strings.LastIndex(c.from.Address, "@"); i >= 0 {
+		domain = c.from.Address[i+1:]
+	}
+
+	return hex.EncodeToString(b) + "@" + domain, nil
+}
+
+// buildMessage builds MIME message with text and HTML alternatives
+func buildMessage(from *mail.Address, msg mailer.Message, messageID string, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	type header struct{ key, value string }
+	headers := []header{
+		{"From", from.String()},
+		{"To", (&mail.Address{Address: msg.To}).String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"Message-ID", "<" + messageID + ">"},
+	}
+	for _, key := range slices.Sorted(maps.Keys(msg.Headers)) {
+		value := msg.Headers[key]
+		if strings.ContainsAny(key+value, "\r\n") {
+			return nil, fmt.Errorf("invalid header %s", key)
+		}
+		headers = append(headers, header{key, value})
+	}
+	headers = append(headers,
+		header{"MIME-Version", "1.0"},
+		header{"Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()})},
+	)
+	for _, h := range headers {
+		buf.WriteString(h.key + ": " + h.value + "\r\n")
+	}
+	buf.WriteString("\r\n")
+
+	parts := []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", msg.Text},
+		{"text/html; charset=UTF-8", msg.HTML},
+	}
+	for _, p := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err = qw.Write([]byte(p.content)); err != nil {
+			return nil, err
+		}
+		if err = qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,188 @@
+package smtpclient_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/OutOfStack/game-library-auth/internal/client/smtpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer accepts single SMTP session and returns envelope and message data
+func fakeSMTPServer(t *testing.T) (port int, received <-chan receivedMail) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	ch := make(chan receivedMail, 1)
+	go func() {
+		conn, aErr := ln.Accept()
+		if aErr != nil {
+			return
+		}
+		defer conn.Close()
+
+		var rm receivedMail
+		r := bufio.NewReader(conn)
+		write := func(s string) { _, _ = io.WriteString(conn, s+"\r\n") }
+
+		write("220 localhost ESMTP")
+		for {
+			line, rErr := r.ReadString('\n')
+			if rErr != nil {
+				return
+			}
+			cmd := strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				write("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				rm.from = strings.Trim(strings.TrimPrefix(cmd, "MAIL FROM:"), "<>")
+				write("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				rm.to = strings.Trim(strings.TrimPrefix(cmd, "RCPT TO:"), "<>")
+				write("250 OK")
+			case cmd == "DATA":
+				write("354 Start mail input")
+				var data strings.Builder
+				for {
+					dl, dErr := r.ReadString('\n')
+					if dErr != nil {
+						return
+					}
+					if dl == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(dl, "."))
+				}
+				rm.data = data.String()
+				write("250 OK")
+			case cmd == "QUIT":
+				write("221 Bye")
+				ch <- rm
+				return
+			default:
+				write("502 Command not implemented")
+			}
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port, ch
+}
+
+type receivedMail struct {
+	from string
+	to   string
+	data string
+}
+
+func TestSendEmailVerification(t *testing.T) {
+	port, received := fakeSMTPServer(t)
+
+	renderer, err := mailer.NewRenderer(mailer.RendererConfig{
+		ContactEmail:   "contact@example.com",
+		BaseURL:        "https://example.com",
+		UnsubscribeURL: "https://example.com/unsubscribe",
+		VerifyEmailURL: "https://example.com/verify-email/confirm",
+	})
+	require.NoError(t, err)
+
+	client, err := smtpclient.NewClient(smtpclient.Config{
+		Host:      "127.0.0.1",
+		Port:      port,
+		FromEmail: "Game Library <info@example.com>",
+		Security:  smtpclient.SecurityNone,
+		Timeout:   5 * time.Second,
+	}, renderer)
+	require.NoError(t, err)
+
+	result, err := client.SendEmailVerification(context.Background(), mailer.SendEmailVerificationRequest{
+		Email:             "user@example.com",
+		Username:          "testuser",
+		VerificationCode:  "123456",
+		VerificationToken: "verification-token",
+		CodeTTL:           24 * time.Hour,
+		UnsubscribeToken:  "unsubscribe-token",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, smtpclient.ProviderName, result.Provider)
+	assert.True(t, strings.HasSuffix(result.MessageID, "@example.com"))
+
+	var rm receivedMail
+	select {
+	case rm = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was not received")
+	}
+	assert.Equal(t, "info@example.com", rm.from)
+	assert.Equal(t, "user@example.com", rm.to)
+
+	msg, err := mail.ReadMessage(strings.NewReader(rm.data))
+	require.NoError(t, err)
+	assert.Equal(t, `"Game Library" <info@example.com>`, msg.Header.Get("From"))
+	assert.Equal(t, "<user@example.com>", msg.Header.Get("To"))
+	assert.Equal(t, "Verify Your Email Address - Game Library", msg.Header.Get("Subject"))
+	assert.Equal(t, "<"+result.MessageID+">", msg.Header.Get("Message-ID"))
+	assert.Equal(t, "<https://example.com/unsubscribe?token=unsubscribe-token>", msg.Header.Get("List-Unsubscribe"))
+	assert.Equal(t, "List-Unsubscribe=One-Click", msg.Header.Get("List-Unsubscribe-Post"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	var contentTypes []string
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, pErr := mr.NextPart()
+		if pErr == io.EOF {
+			break
+		}
+		require.NoError(t, pErr)
+		contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+
+		// multipart reader decodes quoted-printable content
+		content, rErr := io.ReadAll(part)
+		require.NoError(t, rErr)
+		assert.Contains(t, string(content), "123456")
+		assert.Contains(t, string(content), "https://example.com/verify-email/confirm?token=verification-token")
+	}
+	assert.Equal(t, []string{"text/plain; charset=UTF-8", "text/html; charset=UTF-8"}, contentTypes)
+}
+
+func TestNewClient_InvalidConfig(t *testing.T) {
+	renderer, err := mailer.NewRenderer(mailer.RendererConfig{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		cfg  smtpclient.Config
+	}{
+		{
+			name: "unsupported security",
+			cfg:  smtpclient.Config{Host: "localhost", Port: 25, FromEmail: "info@example.com", Security: "ssl"},
+		},
+		{
+			name: "invalid from email",
+			cfg:  smtpclient.Config{Host: "localhost", Port: 25, FromEmail: "invalid", Security: smtpclient.SecurityNone},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err = smtpclient.NewClient(tt.cfg, renderer)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CreateEmailChange creates a new email change record
+func (r *UserRepo) CreateEmailChange(ctx context.Context, change EmailChange) error {
+	ctx, span := tracer.Start(ctx, "createEmailChange")
+	defer span.End()
+
+	const q = `INSERT INTO email_changes
+        (id, user_id, new_email, code_hash, date_created)
+        VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := r.query().Exec(ctx, q, change.ID, change.UserID, change.NewEmail, change.CodeHash, change.DateCreated)
+	if err != nil {
+		return fmt.Errorf("insert email change: %w", err)
+	}
+
+	return nil
+}
+
+// GetEmailChangeByUserID gets email change record by user ID (most recent unused)
+func (r *UserRepo) GetEmailChangeByUserID(ctx context.Context, userID string) (EmailChange, error) {
+	ctx, span := tracer.Start(ctx, "getEmailChangeByUserID")
+	defer span.End()
+
+	const q = `SELECT id, user_id, new_email, code_hash, message_id, failed_attempts, date_created
+        FROM email_changes
+        WHERE user_id = $1 AND used_at IS NULL AND code_hash IS NOT NULL
+        ORDER BY date_created DESC
+        LIMIT 1
+		FOR NO KEY UPDATE`
+
+	var change EmailChange
+	if err := r.query().Get(ctx, &change, q, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return EmailChange{}, ErrNotFound
+		}
+		return EmailChange{}, fmt.Errorf("select email change: %w", err)
+	}
+	return change, nil
+}
+
+// SetEmailChangeMessageID sets the message_id for an email change record
+func (r *UserRepo) SetEmailChangeMessageID(ctx context.Context, id string, messageID string) error {
+	ctx, span := tracer.Start(ctx, "setEmailChangeMessageID")
+	defer span.End()
+
+	const q = `UPDATE email_changes SET message_id = $1 WHERE id = $2`
+
+	_, err := r.query().Exec(ctx, q, messageID, id)
+	if err != nil {
+		return fmt.Errorf("set email change message_id: %w", err)
+	}
+
+	return nil
+}
+
+// SetEmailChangeUsed sets email change record as used by clearing code hash and optionally setting used_at
+func (r *UserRepo) SetEmailChangeUsed(ctx context.Context, id string, used bool) error {
+	ctx, span := tracer.Start(ctx, "setEmailChangeUsed")
+	defer span.End()
+
+	usedAt := sql.NullTime{
+		Time:  time.Now(),
+		Valid: used,
+	}
+
+	const q = `UPDATE email_changes
+		SET code_hash = NULL,
+		    used_at = $2
+		WHERE id = $1`
+
+	_, err := r.query().Exec(ctx, q, id, usedAt)
+	if err != nil {
+		return fmt.Errorf("set email change as used: %w", err)
+	}
+
+	return nil
+}
+
+// IncrementEmailChangeFailedAttempts increments failed attempts counter of email change and returns updated value
+func (r *UserRepo) IncrementEmailChangeFailedAttempts(ctx context.Context, id string) (int, error) {
+	ctx, span := tracer.Start(ctx, "incrementEmailChangeFailedAttempts")
+	defer span.End()
+
+	const q = `UPDATE email_changes
+		SET failed_attempts = failed_attempts + 1
+		WHERE id = $1
+		RETURNING failed_attempts`
+
+	var failedAttempts int
+	if err := r.query().Get(ctx, &failedAttempts, q, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("increment email change failed attempts: %w", err)
+	}
+	return failedAttempts, nil
+}
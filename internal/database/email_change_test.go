@@ -0,0 +1,93 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateEmailChange_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	change := database.NewEmailChange(user.ID, "new@example.com", "hashedcode123", time.Now())
+	err = s.CreateEmailChange(ctx, change)
+	require.NoError(t, err)
+
+	createdChange, err := s.GetEmailChangeByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, change.ID, createdChange.ID)
+	require.Equal(t, change.UserID, createdChange.UserID)
+	require.Equal(t, change.NewEmail, createdChange.NewEmail)
+	require.Equal(t, change.CodeHash, createdChange.CodeHash)
+	require.Equal(t, 0, createdChange.FailedAttempts)
+}
+
+func TestGetEmailChangeByUserID_NotFound(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	_, err := s.GetEmailChangeByUserID(ctx, uuid.New().String())
+	require.Error(t, err)
+	require.Equal(t, database.ErrNotFound, err)
+}
+
+func TestSetEmailChangeUsed_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	change := database.NewEmailChange(user.ID, "new@example.com", "hashedcode123", time.Now())
+	err = s.CreateEmailChange(ctx, change)
+	require.NoError(t, err)
+
+	err = s.SetEmailChangeMessageID(ctx, change.ID, "message-id")
+	require.NoError(t, err)
+
+	err = s.SetEmailChangeUsed(ctx, change.ID, true)
+	require.NoError(t, err)
+
+	_, err = s.GetEmailChangeByUserID(ctx, user.ID)
+	require.Equal(t, database.ErrNotFound, err)
+}
+
+func TestIncrementEmailChangeFailedAttempts_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	change := database.NewEmailChange(user.ID, "new@example.com", "hashedcode123", time.Now())
+	err = s.CreateEmailChange(ctx, change)
+	require.NoError(t, err)
+
+	failedAttempts, err := s.IncrementEmailChangeFailedAttempts(ctx, change.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, failedAttempts)
+
+	failedAttempts, err = s.IncrementEmailChangeFailedAttempts(ctx, change.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, failedAttempts)
+}
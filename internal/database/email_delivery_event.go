@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// CreateEmailDeliveryEvent inserts email delivery event. Returns false if event with the same event id was already recorded
+func (r *UserRepo) CreateEmailDeliveryEvent(ctx context.Context, event EmailDeliveryEvent) (bool, error) {
+	ctx, span := tracer.Start(ctx, "createEmailDeliveryEvent")
+	defer span.End()
+
+	const q = `INSERT INTO email_delivery_events (id, event_id, message_id, email, status, detail, occurred_at, date_created)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+        ON CONFLICT (event_id) DO NOTHING`
+
+	res, err := r.query().Exec(ctx, q, event.ID, event.EventID, event.MessageID, event.Email, event.Status, event.Detail, event.OccurredAt)
+	if err != nil {
+		return false, fmt.Errorf("insert email delivery event: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("get affected rows: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+// GetLatestEmailDeliveryEvent gets the most recent delivery event of an email address
+func (r *UserRepo) GetLatestEmailDeliveryEvent(ctx context.Context, email string) (EmailDeliveryEvent, error) {
+	ctx, span := tracer.Start(ctx, "getLatestEmailDeliveryEvent")
+	defer span.End()
+
+	const q = `SELECT id, event_id, message_id, email, status, detail, occurred_at, date_created
+        FROM email_delivery_events
+        WHERE email = $1
+        ORDER BY occurred_at DESC
+        LIMIT 1`
+
+	var event EmailDeliveryEvent
+	if err := r.query().Get(ctx, &event, q, email); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return EmailDeliveryEvent{}, ErrNotFound
+		}
+		return EmailDeliveryEvent{}, fmt.Errorf("select latest email delivery event: %w", err)
+	}
+	return event, nil
+}
@@ -0,0 +1,57 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailDeliveryEvent_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	email := "test@example.com"
+	now := time.Now()
+
+	_, err := s.GetLatestEmailDeliveryEvent(ctx, email)
+	require.ErrorIs(t, err, database.ErrNotFound)
+
+	delivered := database.NewEmailDeliveryEvent("msg_1", "message-id", email, model.EmailDeliveryStatusDelivered, "", now.Add(-time.Minute))
+	created, err := s.CreateEmailDeliveryEvent(ctx, delivered)
+	require.NoError(t, err)
+	require.True(t, created)
+
+	complained := database.NewEmailDeliveryEvent("msg_2", "message-id", email, model.EmailDeliveryStatusComplained, "", now)
+	created, err = s.CreateEmailDeliveryEvent(ctx, complained)
+	require.NoError(t, err)
+	require.True(t, created)
+
+	latest, err := s.GetLatestEmailDeliveryEvent(ctx, email)
+	require.NoError(t, err)
+	require.Equal(t, complained.ID, latest.ID)
+	require.Equal(t, model.EmailDeliveryStatusComplained, latest.Status)
+}
+
+func TestCreateEmailDeliveryEvent_Duplicate(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	event := database.NewEmailDeliveryEvent("msg_1", "message-id", "test@example.com", model.EmailDeliveryStatusBounced, "mailbox does not exist", time.Now())
+	created, err := s.CreateEmailDeliveryEvent(ctx, event)
+	require.NoError(t, err)
+	require.True(t, created)
+
+	// redelivered event has the same event id
+	redelivered := database.NewEmailDeliveryEvent("msg_1", "message-id", "test@example.com", model.EmailDeliveryStatusBounced, "mailbox does not exist", time.Now())
+	created, err = s.CreateEmailDeliveryEvent(ctx, redelivered)
+	require.NoError(t, err)
+	require.False(t, created)
+}
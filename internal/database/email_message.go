@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CreateEmailMessage inserts record of outgoing email send attempt. Enclosing transaction stays usable on error
+func (r *UserRepo) CreateEmailMessage(ctx context.Context, msg EmailMessage) error {
+	ctx, span := tracer.Start(ctx, "createEmailMessage")
+	defer span.End()
+
+	const q = `INSERT INTO email_messages
+        (id, outbox_id, user_id, template, recipient, provider, provider_message_id, status, error, date_created, date_updated)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	err := r.withSavepoint(ctx, "create_email_message", func() error {
+		_, err := r.query().Exec(ctx, q, msg.ID, msg.OutboxID, msg.UserID, msg.Template, msg.Recipient, msg.Provider, msg.ProviderMessageID,
+			msg.Status, msg.Error, msg.DateCreated, msg.DateUpdated)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("insert email message: %w", err)
+	}
+
+	return nil
+}
+
+// SetEmailMessageStatus sets status of email message with provider message id.
+// Status is not changed if it was updated later than the provided time, so out of order updates are ignored
+func (r *UserRepo) SetEmailMessageStatus(ctx context.Context, providerMessageID, status string, updatedAt time.Time) error {
+	ctx, span := tracer.Start(ctx, "setEmailMessageStatus")
+	defer span.End()
+
+	const q = `UPDATE email_messages
+		SET status = $2,
+		    date_updated = $3
+		WHERE provider_message_id = $1 AND date_updated <= $3`
+
+	_, err := r.query().Exec(ctx, q, providerMessageID, status, updatedAt)
+	if err != nil {
+		return fmt.Errorf("set email message status: %w", err)
+	}
+
+	return nil
+}
+
+// GetEmailMessagesByUserID gets up to limit latest email messages sent to user, newest first
+func (r *UserRepo) GetEmailMessagesByUserID(ctx context.Context, userID string, limit int) ([]EmailMessage, error) {
+	ctx, span := tracer.Start(ctx, "getEmailMessagesByUserID")
+	defer span.End()
+
+	const q = `SELECT id, outbox_id, user_id, template, recipient, provider, provider_message_id, status, error, date_created, date_updated
+		FROM email_messages
+		WHERE user_id = $1
+		ORDER BY date_created DESC
+		LIMIT $2`
+
+	var messages []EmailMessage
+	if err := r.query().Select(ctx, &messages, q, userID, limit); err != nil {
+		return nil, fmt.Errorf("select email messages: %w", err)
+	}
+
+	return messages, nil
+}
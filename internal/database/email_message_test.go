@@ -0,0 +1,67 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailMessages_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	userID := uuid.New().String()
+	outbox := database.NewEmailOutboxMessage(model.EmailKindVerification, userID, "test@example.com", "", []byte("payload"))
+
+	failed := database.NewEmailMessage(outbox, "email_verification", "", "", "provider error")
+	err := s.CreateEmailMessage(ctx, failed)
+	require.NoError(t, err)
+
+	sent := database.NewEmailMessage(outbox, "email_verification", "resend", "message-id", "")
+	sent.DateCreated = failed.DateCreated.Add(time.Second)
+	sent.DateUpdated = sent.DateCreated
+	err = s.CreateEmailMessage(ctx, sent)
+	require.NoError(t, err)
+
+	// message of another user
+	err = s.CreateEmailMessage(ctx, database.NewEmailMessage(database.NewEmailOutboxMessage(model.EmailKindSignIn, uuid.New().String(),
+		"other@example.com", "", nil), "email_sign_in", "smtp", "other-message-id", ""))
+	require.NoError(t, err)
+
+	err = s.SetEmailMessageStatus(ctx, "message-id", model.EmailDeliveryStatusDelivered, sent.DateUpdated.Add(time.Minute))
+	require.NoError(t, err)
+
+	// earlier event doesn't overwrite status
+	err = s.SetEmailMessageStatus(ctx, "message-id", model.EmailDeliveryStatusBounced, sent.DateUpdated)
+	require.NoError(t, err)
+
+	messages, err := s.GetEmailMessagesByUserID(ctx, userID, 10)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+
+	require.Equal(t, sent.ID, messages[0].ID)
+	require.Equal(t, outbox.ID, messages[0].OutboxID)
+	require.Equal(t, "test@example.com", messages[0].Recipient)
+	require.Equal(t, "resend", messages[0].Provider.String)
+	require.Equal(t, "message-id", messages[0].ProviderMessageID.String)
+	require.Equal(t, model.EmailDeliveryStatusDelivered, messages[0].Status)
+	require.False(t, messages[0].Error.Valid)
+
+	require.Equal(t, failed.ID, messages[1].ID)
+	require.Equal(t, model.EmailMessageStatusFailed, messages[1].Status)
+	require.Equal(t, "provider error", messages[1].Error.String)
+	require.False(t, messages[1].Provider.Valid)
+	require.False(t, messages[1].ProviderMessageID.Valid)
+
+	messages, err = s.GetEmailMessagesByUserID(ctx, userID, 1)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	require.Equal(t, sent.ID, messages[0].ID)
+}
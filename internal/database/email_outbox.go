@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/model"
+)
+
+// CreateEmailOutboxMessage inserts a new outgoing email into outbox
+func (r *UserRepo) CreateEmailOutboxMessage(ctx context.Context, msg EmailOutboxMessage) error {
+	ctx, span := tracer.Start(ctx, "createEmailOutboxMessage")
+	defer span.End()
+
+	const q = `INSERT INTO email_outbox
+        (id, kind, user_id, recipient, reference_id, payload, status, next_attempt_at, date_created)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err := r.query().Exec(ctx, q, msg.ID, msg.Kind, msg.UserID, msg.Recipient, msg.ReferenceID, msg.Payload, msg.Status, msg.NextAttemptAt, msg.DateCreated)
+	if err != nil {
+		return fmt.Errorf("insert email outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// GetNextPendingEmailOutboxMessage gets the oldest pending outgoing email due for sending and locks it.
+// Messages locked by other dispatchers are skipped. Returns ErrNotFound if there are no messages to send
+func (r *UserRepo) GetNextPendingEmailOutboxMessage(ctx context.Context) (EmailOutboxMessage, error) {
+	ctx, span := tracer.Start(ctx, "getNextPendingEmailOutboxMessage")
+	defer span.End()
+
+	const q = `SELECT id, kind, user_id, recipient, reference_id, payload, status, attempts, next_attempt_at, last_error, message_id, sent_at, date_created
+        FROM email_outbox
+        WHERE status = $1 AND next_attempt_at <= NOW()
+        ORDER BY next_attempt_at
+        LIMIT 1
+		FOR UPDATE SKIP LOCKED`
+
+	var msg EmailOutboxMessage
+	if err := r.query().Get(ctx, &msg, q, model.EmailOutboxStatusPending); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return EmailOutboxMessage{}, ErrNotFound
+		}
+		return EmailOutboxMessage{}, fmt.Errorf("select pending email outbox message: %w", err)
+	}
+	return msg, nil
+}
+
+// SetEmailOutboxMessageSent marks outgoing email as sent, sets message id returned by email provider and clears payload
+func (r *UserRepo) SetEmailOutboxMessageSent(ctx context.Context, id, messageID string) error {
+	ctx, span := tracer.Start(ctx, "setEmailOutboxMessageSent")
+	defer span.End()
+
+	const q = `UPDATE email_outbox
+		SET status = $2,
+		    message_id = $3,
+		    attempts = attempts + 1,
+		    payload = NULL,
+		    last_error = NULL,
+		    sent_at = NOW()
+		WHERE id = $1`
+
+	_, err := r.query().Exec(ctx, q, id, model.EmailOutboxStatusSent, messageID)
+	if err != nil {
+		return fmt.Errorf("set email outbox message sent: %w", err)
+	}
+
+	return nil
+}
+
+// RescheduleEmailOutboxMessage records failed send attempt of outgoing email and schedules the next attempt
+func (r *UserRepo) RescheduleEmailOutboxMessage(ctx context.Context, id, lastError string, nextAttemptAt time.Time) error {
+	ctx, span := tracer.Start(ctx, "rescheduleEmailOutboxMessage")
+	defer span.End()
+
+	const q = `UPDATE email_outbox
+		SET attempts = attempts + 1,
+		    last_error = $2,
+		    next_attempt_at = $3
+		WHERE id = $1`
+
+	_, err := r.query().Exec(ctx, q, id, lastError, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("reschedule email outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// DeferEmailOutboxMessage records send error of outgoing email and postpones the next attempt without counting the failed one
+func (r *UserRepo) DeferEmailOutboxMessage(ctx context.Context, id, lastError string, nextAttemptAt time.Time) error {
+	ctx, span := tracer.Start(ctx, "deferEmailOutboxMessage")
+	defer span.End()
+
+	const q = `UPDATE email_outbox
+		SET last_error = $2,
+		    next_attempt_at = $3
+		WHERE id = $1`
+
+	_, err := r.query().Exec(ctx, q, id, lastError, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("defer email outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// SetEmailOutboxMessageFailed records last failed send attempt of outgoing email, marks it as failed and clears payload
+func (r *UserRepo) SetEmailOutboxMessageFailed(ctx context.Context, id, lastError string) error {
+	ctx, span := tracer.Start(ctx, "setEmailOutboxMessageFailed")
+	defer span.End()
+
+	const q = `UPDATE email_outbox
+		SET status = $2,
+		    attempts = attempts + 1,
+		    last_error = $3,
+		    payload = NULL
+		WHERE id = $1`
+
+	_, err := r.query().Exec(ctx, q, id, model.EmailOutboxStatusFailed, lastError)
+	if err != nil {
+		return fmt.Errorf("set email outbox message failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailOutbox_Sent(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	userID, referenceID := uuid.New().String(), uuid.New().String()
+	msg := database.NewEmailOutboxMessage(model.EmailKindVerification, userID, "test@example.com", referenceID, []byte("payload"))
+	err := s.CreateEmailOutboxMessage(ctx, msg)
+	require.NoError(t, err)
+
+	pending, err := s.GetNextPendingEmailOutboxMessage(ctx)
+	require.NoError(t, err)
+	require.Equal(t, msg.ID, pending.ID)
+	require.Equal(t, model.EmailKindVerification, pending.Kind)
+	require.Equal(t, userID, pending.UserID.String)
+	require.Equal(t, "test@example.com", pending.Recipient)
+	require.Equal(t, referenceID, pending.ReferenceID.String)
+	require.Equal(t, []byte("payload"), pending.Payload)
+	require.Equal(t, 0, pending.Attempts)
+
+	err = s.SetEmailOutboxMessageSent(ctx, msg.ID, "message-id")
+	require.NoError(t, err)
+
+	_, err = s.GetNextPendingEmailOutboxMessage(ctx)
+	require.True(t, errors.Is(err, database.ErrNotFound))
+}
+
+func TestEmailOutbox_Reschedule(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	msg := database.NewEmailOutboxMessage(model.EmailKindRecoveryCodeUsed, "", "test@example.com", "", []byte("payload"))
+	err := s.CreateEmailOutboxMessage(ctx, msg)
+	require.NoError(t, err)
+
+	// message scheduled in future is not due for sending
+	err = s.RescheduleEmailOutboxMessage(ctx, msg.ID, "provider error", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	_, err = s.GetNextPendingEmailOutboxMessage(ctx)
+	require.True(t, errors.Is(err, database.ErrNotFound))
+
+	err = s.RescheduleEmailOutboxMessage(ctx, msg.ID, "provider error", time.Now().Add(-time.Second))
+	require.NoError(t, err)
+
+	pending, err := s.GetNextPendingEmailOutboxMessage(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, pending.Attempts)
+	require.Equal(t, "provider error", pending.LastError.String)
+	require.False(t, pending.UserID.Valid)
+	require.False(t, pending.ReferenceID.Valid)
+
+	err = s.SetEmailOutboxMessageFailed(ctx, msg.ID, "provider error")
+	require.NoError(t, err)
+
+	_, err = s.GetNextPendingEmailOutboxMessage(ctx)
+	require.True(t, errors.Is(err, database.ErrNotFound))
+}
+
+func TestEmailOutbox_Defer(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	msg := database.NewEmailOutboxMessage(model.EmailKindSecurityNotice, "", "test@example.com", "", []byte("payload"))
+	err := s.CreateEmailOutboxMessage(ctx, msg)
+	require.NoError(t, err)
+
+	err = s.DeferEmailOutboxMessage(ctx, msg.ID, "email quota exceeded", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	_, err = s.GetNextPendingEmailOutboxMessage(ctx)
+	require.True(t, errors.Is(err, database.ErrNotFound))
+
+	// deferred send is not counted as attempt
+	err = s.DeferEmailOutboxMessage(ctx, msg.ID, "email quota exceeded", time.Now().Add(-time.Second))
+	require.NoError(t, err)
+
+	pending, err := s.GetNextPendingEmailOutboxMessage(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, pending.Attempts)
+	require.Equal(t, "email quota exceeded", pending.LastError.String)
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// UpsertEmailPreference creates or updates subscription of an email address to emails of category
+func (r *UserRepo) UpsertEmailPreference(ctx context.Context, pref EmailPreference) error {
+	ctx, span := tracer.Start(ctx, "upsertEmailPreference")
+	defer span.End()
+
+	const q = `INSERT INTO email_preferences (email, category, subscribed, date_updated)
+        VALUES ($1, $2, $3, NOW())
+        ON CONFLICT (email, category) DO UPDATE
+        SET subscribed = EXCLUDED.subscribed, date_updated = EXCLUDED.date_updated
+        WHERE email_preferences.subscribed <> EXCLUDED.subscribed`
+
+	_, err := r.query().Exec(ctx, q, pref.Email, pref.Category, pref.Subscribed)
+	if err != nil {
+		return fmt.Errorf("upsert email preference: %w", err)
+	}
+
+	return nil
+}
+
+// GetEmailPreferences gets stored subscriptions of an email address to email categories
+func (r *UserRepo) GetEmailPreferences(ctx context.Context, email string) ([]EmailPreference, error) {
+	ctx, span := tracer.Start(ctx, "getEmailPreferences")
+	defer span.End()
+
+	const q = `SELECT email, category, subscribed, date_updated
+        FROM email_preferences
+        WHERE email = $1
+        ORDER BY category`
+
+	var prefs []EmailPreference
+	if err := r.query().Select(ctx, &prefs, q, email); err != nil {
+		return nil, fmt.Errorf("select email preferences: %w", err)
+	}
+
+	return prefs, nil
+}
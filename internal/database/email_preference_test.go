@@ -0,0 +1,45 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertEmailPreference_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	email := "test@example.com"
+	err := s.UpsertEmailPreference(ctx, database.NewEmailPreference(email, model.EmailCategoryProductNews, false))
+	require.NoError(t, err)
+	err = s.UpsertEmailPreference(ctx, database.NewEmailPreference(email, model.EmailCategoryPublisherReports, false))
+	require.NoError(t, err)
+
+	// update existing preference
+	err = s.UpsertEmailPreference(ctx, database.NewEmailPreference(email, model.EmailCategoryPublisherReports, true))
+	require.NoError(t, err)
+
+	prefs, err := s.GetEmailPreferences(ctx, email)
+	require.NoError(t, err)
+	require.Len(t, prefs, 2)
+	require.Equal(t, model.EmailCategoryProductNews, prefs[0].Category)
+	require.False(t, prefs[0].Subscribed)
+	require.Equal(t, model.EmailCategoryPublisherReports, prefs[1].Category)
+	require.True(t, prefs[1].Subscribed)
+	require.False(t, prefs[1].DateUpdated.IsZero())
+}
+
+func TestGetEmailPreferences_None(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	prefs, err := s.GetEmailPreferences(context.Background(), "subscribed@example.com")
+	require.NoError(t, err)
+	require.Empty(t, prefs)
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CreateEmailSignIn creates a new passwordless sign in record
+func (r *UserRepo) CreateEmailSignIn(ctx context.Context, signIn EmailSignIn) error {
+	ctx, span := tracer.Start(ctx, "createEmailSignIn")
+	defer span.End()
+
+	const q = `INSERT INTO email_sign_ins
+        (id, user_id, code_hash, token_hash, date_created)
+        VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := r.query().Exec(ctx, q, signIn.ID, signIn.UserID, signIn.CodeHash, signIn.TokenHash, signIn.DateCreated)
+	if err != nil {
+		return fmt.Errorf("insert email sign in: %w", err)
+	}
+
+	return nil
+}
+
+// GetEmailSignInByUserID gets passwordless sign in record by user ID (most recent unused)
+func (r *UserRepo) GetEmailSignInByUserID(ctx context.Context, userID string) (EmailSignIn, error) {
+	ctx, span := tracer.Start(ctx, "getEmailSignInByUserID")
+	defer span.End()
+
+	const q = `SELECT id, user_id, code_hash, token_hash, message_id, failed_attempts, date_created
+        FROM email_sign_ins
+        WHERE user_id = $1 AND used_at IS NULL AND code_hash IS NOT NULL
+        ORDER BY date_created DESC
+        LIMIT 1
+		FOR NO KEY UPDATE`
+
+	var signIn EmailSignIn
+	if err := r.query().Get(ctx, &signIn, q, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return EmailSignIn{}, ErrNotFound
+		}
+		return EmailSignIn{}, fmt.Errorf("select email sign in: %w", err)
+	}
+	return signIn, nil
+}
+
+// GetEmailSignInByTokenHash gets unused passwordless sign in record by hash of sign in link token
+func (r *UserRepo) GetEmailSignInByTokenHash(ctx context.Context, tokenHash string) (EmailSignIn, error) {
+	ctx, span := tracer.Start(ctx, "getEmailSignInByTokenHash")
+	defer span.End()
+
+	const q = `SELECT id, user_id, code_hash, token_hash, message_id, failed_attempts, date_created
+        FROM email_sign_ins
+        WHERE token_hash = $1 AND used_at IS NULL
+		FOR NO KEY UPDATE`
+
+	var signIn EmailSignIn
+	if err := r.query().Get(ctx, &signIn, q, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return EmailSignIn{}, ErrNotFound
+		}
+		return EmailSignIn{}, fmt.Errorf("select email sign in by token: %w", err)
+	}
+	return signIn, nil
+}
+
+// SetEmailSignInMessageID sets the message_id for a passwordless sign in record
+func (r *UserRepo) SetEmailSignInMessageID(ctx context.Context, id string, messageID string) error {
+	ctx, span := tracer.Start(ctx, "setEmailSignInMessageID")
+	defer span.End()
+
+	const q = `UPDATE email_sign_ins SET message_id = $1 WHERE id = $2`
+
+	_, err := r.query().Exec(ctx, q, messageID, id)
+	if err != nil {
+		return fmt.Errorf("set email sign in message_id: %w", err)
+	}
+
+	return nil
+}
+
+// SetEmailSignInUsed sets passwordless sign in record as used by clearing code and token hashes and optionally setting used_at
+func (r *UserRepo) SetEmailSignInUsed(ctx context.Context, id string, used bool) error {
+	ctx, span := tracer.Start(ctx, "setEmailSignInUsed")
+	defer span.End()
+
+	usedAt := sql.NullTime{
+		Time:  time.Now(),
+		Valid: used,
+	}
+
+	const q = `UPDATE email_sign_ins
+		SET code_hash = NULL,
+		    token_hash = NULL,
+		    used_at = $2
+		WHERE id = $1`
+
+	_, err := r.query().Exec(ctx, q, id, usedAt)
+	if err != nil {
+		return fmt.Errorf("set email sign in as used: %w", err)
+	}
+
+	return nil
+}
+
+// InvalidateEmailSignIns invalidates all pending passwordless sign ins of user by clearing code and token hashes
+func (r *UserRepo) InvalidateEmailSignIns(ctx context.Context, userID string) error {
+	ctx, span := tracer.Start(ctx, "invalidateEmailSignIns")
+	defer span.End()
+
+	const q = `UPDATE email_sign_ins
+		SET code_hash = NULL,
+		    token_hash = NULL
+		WHERE user_id = $1 AND used_at IS NULL`
+
+	_, err := r.query().Exec(ctx, q, userID)
+	if err != nil {
+		return fmt.Errorf("invalidate email sign ins: %w", err)
+	}
+
+	return nil
+}
+
+// IncrementEmailSignInFailedAttempts increments failed attempts counter of passwordless sign in and returns updated value
+func (r *UserRepo) IncrementEmailSignInFailedAttempts(ctx context.Context, id string) (int, error) {
+	ctx, span := tracer.Start(ctx, "incrementEmailSignInFailedAttempts")
+	defer span.End()
+
+	const q = `UPDATE email_sign_ins
+		SET failed_attempts = failed_attempts + 1
+		WHERE id = $1
+		RETURNING failed_attempts`
+
+	var failedAttempts int
+	if err := r.query().Get(ctx, &failedAttempts, q, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("increment email sign in failed attempts: %w", err)
+	}
+	return failedAttempts, nil
+}
@@ -0,0 +1,185 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateEmailSignIn_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	signIn := database.NewEmailSignIn(user.ID, "hashedcode123", "hashedtoken123", time.Now())
+	err = s.CreateEmailSignIn(ctx, signIn)
+	require.NoError(t, err)
+
+	createdSignIn, err := s.GetEmailSignInByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, signIn.ID, createdSignIn.ID)
+	require.Equal(t, signIn.UserID, createdSignIn.UserID)
+	require.Equal(t, signIn.CodeHash, createdSignIn.CodeHash)
+	require.Equal(t, signIn.TokenHash, createdSignIn.TokenHash)
+}
+
+func TestGetEmailSignInByUserID_NotFound(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	_, err := s.GetEmailSignInByUserID(ctx, uuid.New().String())
+	require.Error(t, err)
+	require.Equal(t, database.ErrNotFound, err)
+}
+
+func TestGetEmailSignInByUserID_MostRecent(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	signIn1 := database.NewEmailSignIn(user.ID, "hashedcode123", "hashedtoken123", time.Now())
+	err = s.CreateEmailSignIn(ctx, signIn1)
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+	signIn2 := database.NewEmailSignIn(user.ID, "hashedcode456", "hashedtoken456", time.Now())
+	err = s.CreateEmailSignIn(ctx, signIn2)
+	require.NoError(t, err)
+
+	foundSignIn, err := s.GetEmailSignInByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, signIn2.ID, foundSignIn.ID)
+}
+
+func TestGetEmailSignInByTokenHash_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	signIn := database.NewEmailSignIn(user.ID, "hashedcode123", "hashedtoken123", time.Now())
+	err = s.CreateEmailSignIn(ctx, signIn)
+	require.NoError(t, err)
+
+	foundSignIn, err := s.GetEmailSignInByTokenHash(ctx, "hashedtoken123")
+	require.NoError(t, err)
+	require.Equal(t, signIn.ID, foundSignIn.ID)
+	require.Equal(t, user.ID, foundSignIn.UserID)
+
+	_, err = s.GetEmailSignInByTokenHash(ctx, "unknowntoken")
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestSetEmailSignInMessageID_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	signIn := database.NewEmailSignIn(user.ID, "hashedcode123", "hashedtoken123", time.Now())
+	err = s.CreateEmailSignIn(ctx, signIn)
+	require.NoError(t, err)
+
+	err = s.SetEmailSignInMessageID(ctx, signIn.ID, "msg_12345")
+	require.NoError(t, err)
+
+	updatedSignIn, err := s.GetEmailSignInByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, "msg_12345", updatedSignIn.MessageID.String)
+}
+
+func TestSetEmailSignInUsed_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	signIn := database.NewEmailSignIn(user.ID, "hashedcode123", "hashedtoken123", time.Now())
+	err = s.CreateEmailSignIn(ctx, signIn)
+	require.NoError(t, err)
+
+	err = s.SetEmailSignInUsed(ctx, signIn.ID, true)
+	require.NoError(t, err)
+
+	// used sign in can't be found by user id or token
+	_, err = s.GetEmailSignInByUserID(ctx, user.ID)
+	require.ErrorIs(t, err, database.ErrNotFound)
+	_, err = s.GetEmailSignInByTokenHash(ctx, "hashedtoken123")
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestInvalidateEmailSignIns_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	signIn := database.NewEmailSignIn(user.ID, "hashedcode123", "hashedtoken123", time.Now())
+	err = s.CreateEmailSignIn(ctx, signIn)
+	require.NoError(t, err)
+
+	err = s.InvalidateEmailSignIns(ctx, user.ID)
+	require.NoError(t, err)
+
+	// invalidated sign in can't be found by user id or token
+	_, err = s.GetEmailSignInByUserID(ctx, user.ID)
+	require.ErrorIs(t, err, database.ErrNotFound)
+	_, err = s.GetEmailSignInByTokenHash(ctx, "hashedtoken123")
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestIncrementEmailSignInFailedAttempts_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	signIn := database.NewEmailSignIn(user.ID, "hashedcode123", "hashedtoken123", time.Now())
+	err = s.CreateEmailSignIn(ctx, signIn)
+	require.NoError(t, err)
+
+	failedAttempts, err := s.IncrementEmailSignInFailedAttempts(ctx, signIn.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, failedAttempts)
+
+	updatedSignIn, err := s.GetEmailSignInByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, updatedSignIn.FailedAttempts)
+}
@@ -2,6 +2,8 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 )
 
@@ -10,11 +12,11 @@ func (r *UserRepo) CreateEmailUnsubscribe(ctx context.Context, unsubscribe Email
 	ctx, span := tracer.Start(ctx, "createEmailUnsubscribe")
 	defer span.End()
 
-	const q = `INSERT INTO email_unsubscribes (id, email, date_created)
-        VALUES ($1, $2, NOW())
+	const q = `INSERT INTO email_unsubscribes (id, email, reason, date_created)
+        VALUES ($1, $2, $3, NOW())
         ON CONFLICT (email) DO NOTHING`
 
-	_, err := r.query().Exec(ctx, q, unsubscribe.ID, unsubscribe.Email)
+	_, err := r.query().Exec(ctx, q, unsubscribe.ID, unsubscribe.Email, unsubscribe.Reason)
 	if err != nil {
 		return fmt.Errorf("insert email unsubscribe: %w", err)
 	}
@@ -37,6 +39,63 @@ func (r *UserRepo) IsEmailUnsubscribed(ctx context.Context, email string) (bool,
 	return exists, nil
 }
 
+// GetEmailUnsubscribe gets unsubscribe record of an email address
+func (r *UserRepo) GetEmailUnsubscribe(ctx context.Context, email string) (EmailUnsubscribe, error) {
+	ctx, span := tracer.Start(ctx, "getEmailUnsubscribe")
+	defer span.End()
+
+	const q = `SELECT id, email, reason, date_created FROM email_unsubscribes WHERE email = $1`
+
+	var unsubscribe EmailUnsubscribe
+	if err := r.query().Get(ctx, &unsubscribe, q, email); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return EmailUnsubscribe{}, ErrNotFound
+		}
+		return EmailUnsubscribe{}, fmt.Errorf("select email unsubscribe: %w", err)
+	}
+	return unsubscribe, nil
+}
+
+// DeleteEmailUnsubscribe deletes unsubscribe record of an email address. Returns ErrNotFound if there is none
+func (r *UserRepo) DeleteEmailUnsubscribe(ctx context.Context, email string) error {
+	ctx, span := tracer.Start(ctx, "deleteEmailUnsubscribe")
+	defer span.End()
+
+	const q = `DELETE FROM email_unsubscribes WHERE email = $1`
+
+	res, err := r.query().Exec(ctx, q, email)
+	if err != nil {
+		return fmt.Errorf("delete email unsubscribe: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get affected rows: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// CreateEmailResubscribe creates a new email resubscribe record
+func (r *UserRepo) CreateEmailResubscribe(ctx context.Context, resubscribe EmailResubscribe) error {
+	ctx, span := tracer.Start(ctx, "createEmailResubscribe")
+	defer span.End()
+
+	const q = `INSERT INTO email_resubscribes (id, email, source, user_id, unsubscribe_reason, date_unsubscribed, date_created)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW())`
+
+	_, err := r.query().Exec(ctx, q, resubscribe.ID, resubscribe.Email, resubscribe.Source, resubscribe.UserID,
+		resubscribe.UnsubscribeReason, resubscribe.DateUnsubscribed)
+	if err != nil {
+		return fmt.Errorf("insert email resubscribe: %w", err)
+	}
+
+	return nil
+}
+
 // SetUnsubscribeToken sets the unsubscribe token for an email verification record
 func (r *UserRepo) SetUnsubscribeToken(ctx context.Context, id string, token string) error {
 	ctx, span := tracer.Start(ctx, "setUnsubscribeToken")
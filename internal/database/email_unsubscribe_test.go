@@ -17,7 +17,7 @@ func TestCreateEmailUnsubscribe_Ok(t *testing.T) {
 	ctx := context.Background()
 
 	email := "test@example.com"
-	unsubscribe := database.NewEmailUnsubscribe(email)
+	unsubscribe := database.NewEmailUnsubscribe(email, model.UnsubscribeReasonComplaint)
 
 	err := s.CreateEmailUnsubscribe(ctx, unsubscribe)
 	require.NoError(t, err)
@@ -34,8 +34,8 @@ func TestCreateEmailUnsubscribe_Duplicate(t *testing.T) {
 	ctx := context.Background()
 
 	email := "duplicate@example.com"
-	unsubscribe1 := database.NewEmailUnsubscribe(email)
-	unsubscribe2 := database.NewEmailUnsubscribe(email)
+	unsubscribe1 := database.NewEmailUnsubscribe(email, model.UnsubscribeReasonComplaint)
+	unsubscribe2 := database.NewEmailUnsubscribe(email, model.UnsubscribeReasonComplaint)
 
 	err := s.CreateEmailUnsubscribe(ctx, unsubscribe1)
 	require.NoError(t, err)
@@ -66,7 +66,7 @@ func TestIsEmailUnsubscribed_Unsubscribed(t *testing.T) {
 	ctx := context.Background()
 
 	email := "unsubscribed@example.com"
-	unsubscribe := database.NewEmailUnsubscribe(email)
+	unsubscribe := database.NewEmailUnsubscribe(email, model.UnsubscribeReasonComplaint)
 
 	err := s.CreateEmailUnsubscribe(ctx, unsubscribe)
 	require.NoError(t, err)
@@ -95,3 +95,72 @@ func TestSetUnsubscribeToken_Ok(t *testing.T) {
 	err = s.SetUnsubscribeToken(ctx, verification.ID, newToken)
 	require.NoError(t, err)
 }
+
+func TestGetEmailUnsubscribe_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	email := "bounced@example.com"
+	err := s.CreateEmailUnsubscribe(ctx, database.NewEmailUnsubscribe(email, model.UnsubscribeReasonHardBounce))
+	require.NoError(t, err)
+
+	unsubscribe, err := s.GetEmailUnsubscribe(ctx, email)
+	require.NoError(t, err)
+	require.Equal(t, email, unsubscribe.Email)
+	require.Equal(t, model.UnsubscribeReasonHardBounce, unsubscribe.Reason)
+
+	_, err = s.GetEmailUnsubscribe(ctx, "subscribed@example.com")
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestDeleteEmailUnsubscribe(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	email := "bounced@example.com"
+	err := s.CreateEmailUnsubscribe(ctx, database.NewEmailUnsubscribe(email, model.UnsubscribeReasonHardBounce))
+	require.NoError(t, err)
+
+	err = s.DeleteEmailUnsubscribe(ctx, email)
+	require.NoError(t, err)
+
+	isUnsubscribed, err := s.IsEmailUnsubscribed(ctx, email)
+	require.NoError(t, err)
+	require.False(t, isUnsubscribed)
+
+	err = s.DeleteEmailUnsubscribe(ctx, email)
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestCreateEmailResubscribe(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	email := "complained@example.com"
+	err := s.CreateEmailUnsubscribe(ctx, database.NewEmailUnsubscribe(email, model.UnsubscribeReasonComplaint))
+	require.NoError(t, err)
+	unsubscribe, err := s.GetEmailUnsubscribe(ctx, email)
+	require.NoError(t, err)
+
+	resubscribe := database.NewEmailResubscribe(email, model.ResubscribeSourceLink, "")
+	resubscribe.SetLiftedUnsubscribe(unsubscribe)
+	err = s.CreateEmailResubscribe(ctx, resubscribe)
+	require.NoError(t, err)
+
+	var stored database.EmailResubscribe
+	err = db.GetContext(ctx, &stored, `SELECT id, email, source, user_id, unsubscribe_reason, date_unsubscribed, date_created
+		FROM email_resubscribes WHERE id = $1`, resubscribe.ID)
+	require.NoError(t, err)
+	require.Equal(t, email, stored.Email)
+	require.Equal(t, model.ResubscribeSourceLink, stored.Source)
+	require.False(t, stored.UserID.Valid)
+	require.Equal(t, model.UnsubscribeReasonComplaint, stored.UnsubscribeReason.String)
+	require.True(t, stored.DateUnsubscribed.Valid)
+	require.False(t, stored.DateCreated.IsZero())
+}
@@ -30,7 +30,7 @@ func (r *UserRepo) GetEmailVerificationByUserID(ctx context.Context, userID stri
 	ctx, span := tracer.Start(ctx, "getEmailVerificationByUserID")
 	defer span.End()
 
-	const q = `SELECT id, user_id, verification_code, message_id, date_created
+	const q = `SELECT id, user_id, verification_code, message_id, provider, failed_attempts, date_created
         FROM email_verifications
         WHERE user_id = $1 AND verified_at IS NULL AND verification_code IS NOT NULL
         ORDER BY date_created DESC
@@ -47,14 +47,34 @@ func (r *UserRepo) GetEmailVerificationByUserID(ctx context.Context, userID stri
 	return verification, nil
 }
 
-// SetEmailVerificationMessageID sets the message_id for an email verification record
-func (r *UserRepo) SetEmailVerificationMessageID(ctx context.Context, id string, messageID string) error {
+// GetEmailVerificationByID gets unused email verification by ID
+func (r *UserRepo) GetEmailVerificationByID(ctx context.Context, id string) (EmailVerification, error) {
+	ctx, span := tracer.Start(ctx, "getEmailVerificationByID")
+	defer span.End()
+
+	const q = `SELECT id, user_id, verification_code, message_id, provider, failed_attempts, date_created
+        FROM email_verifications
+        WHERE id = $1 AND verified_at IS NULL AND verification_code IS NOT NULL
+		FOR NO KEY UPDATE`
+
+	var verification EmailVerification
+	if err := r.query().Get(ctx, &verification, q, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return EmailVerification{}, ErrNotFound
+		}
+		return EmailVerification{}, fmt.Errorf("select email verification by id: %w", err)
+	}
+	return verification, nil
+}
+
+// SetEmailVerificationMessageID sets the message_id and email provider that sent the message for an email verification record
+func (r *UserRepo) SetEmailVerificationMessageID(ctx context.Context, id string, messageID, provider string) error {
 	ctx, span := tracer.Start(ctx, "setEmailVerificationMessageID")
 	defer span.End()
 
-	const q = `UPDATE email_verifications SET message_id = $1 WHERE id = $2`
+	const q = `UPDATE email_verifications SET message_id = $1, provider = $2 WHERE id = $3`
 
-	_, err := r.query().Exec(ctx, q, messageID, id)
+	_, err := r.query().Exec(ctx, q, messageID, provider, id)
 	if err != nil {
 		return fmt.Errorf("set email verification message_id: %w", err)
 	}
@@ -85,3 +105,41 @@ func (r *UserRepo) SetEmailVerificationUsed(ctx context.Context, id string, veri
 
 	return nil
 }
+
+// InvalidateEmailVerifications invalidates all pending email verifications of user by clearing codes
+func (r *UserRepo) InvalidateEmailVerifications(ctx context.Context, userID string) error {
+	ctx, span := tracer.Start(ctx, "invalidateEmailVerifications")
+	defer span.End()
+
+	const q = `UPDATE email_verifications
+		SET verification_code = NULL,
+		    unsubscribe_token = NULL
+		WHERE user_id = $1 AND verified_at IS NULL`
+
+	_, err := r.query().Exec(ctx, q, userID)
+	if err != nil {
+		return fmt.Errorf("invalidate email verifications: %w", err)
+	}
+
+	return nil
+}
+
+// IncrementEmailVerificationFailedAttempts increments failed attempts counter of email verification and returns updated value
+func (r *UserRepo) IncrementEmailVerificationFailedAttempts(ctx context.Context, id string) (int, error) {
+	ctx, span := tracer.Start(ctx, "incrementEmailVerificationFailedAttempts")
+	defer span.End()
+
+	const q = `UPDATE email_verifications
+		SET failed_attempts = failed_attempts + 1
+		WHERE id = $1
+		RETURNING failed_attempts`
+
+	var failedAttempts int
+	if err := r.query().Get(ctx, &failedAttempts, q, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("increment email verification failed attempts: %w", err)
+	}
+	return failedAttempts, nil
+}
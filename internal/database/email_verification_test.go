@@ -94,6 +94,48 @@ func TestGetEmailVerificationByUserID_MostRecent(t *testing.T) {
 	require.Equal(t, verification2.CodeHash, foundVerification.CodeHash)
 }
 
+func TestGetEmailVerificationByID_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	verification := database.NewEmailVerification(user.ID, "hashedcode123", "unsubscribe_token", time.Now())
+	err = s.CreateEmailVerification(ctx, verification)
+	require.NoError(t, err)
+
+	found, err := s.GetEmailVerificationByID(ctx, verification.ID)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, found.UserID)
+	require.Equal(t, "hashedcode123", found.CodeHash.String)
+}
+
+func TestGetEmailVerificationByID_Used(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	verification := database.NewEmailVerification(user.ID, "hashedcode123", "unsubscribe_token", time.Now())
+	err = s.CreateEmailVerification(ctx, verification)
+	require.NoError(t, err)
+
+	err = s.SetEmailVerificationUsed(ctx, verification.ID, true)
+	require.NoError(t, err)
+
+	// used verification can't be used again by link
+	_, err = s.GetEmailVerificationByID(ctx, verification.ID)
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
 func TestSetEmailVerificationMessageID_Ok(t *testing.T) {
 	s := setup(t)
 	defer teardown(t)
@@ -109,13 +151,14 @@ func TestSetEmailVerificationMessageID_Ok(t *testing.T) {
 	require.NoError(t, err)
 
 	messageID := "msg_12345"
-	err = s.SetEmailVerificationMessageID(ctx, verification.ID, messageID)
+	err = s.SetEmailVerificationMessageID(ctx, verification.ID, messageID, "resend")
 	require.NoError(t, err)
 
-	// Verify message ID was set
+	// Verify message ID and provider were set
 	updatedVerification, err := s.GetEmailVerificationByUserID(ctx, user.ID)
 	require.NoError(t, err)
 	require.Equal(t, messageID, updatedVerification.MessageID.String)
+	require.Equal(t, "resend", updatedVerification.Provider.String)
 }
 
 func TestSetEmailVerificationUsed_Ok(t *testing.T) {
@@ -141,16 +184,75 @@ func TestSetEmailVerificationUsed_Ok(t *testing.T) {
 	require.Equal(t, database.ErrNotFound, err)
 }
 
+func TestInvalidateEmailVerifications_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	verification := database.NewEmailVerification(user.ID, "hashedcode123", "unsubscribe_token", time.Now())
+	err = s.CreateEmailVerification(ctx, verification)
+	require.NoError(t, err)
+
+	err = s.InvalidateEmailVerifications(ctx, user.ID)
+	require.NoError(t, err)
+
+	// invalidated verification can't be found by user id or id
+	_, err = s.GetEmailVerificationByUserID(ctx, user.ID)
+	require.ErrorIs(t, err, database.ErrNotFound)
+	_, err = s.GetEmailVerificationByID(ctx, verification.ID)
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestIncrementEmailVerificationFailedAttempts_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	verification := database.NewEmailVerification(user.ID, "hashedcode123", "unsubscribe_token", time.Now())
+	err = s.CreateEmailVerification(ctx, verification)
+	require.NoError(t, err)
+
+	failedAttempts, err := s.IncrementEmailVerificationFailedAttempts(ctx, verification.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, failedAttempts)
+
+	failedAttempts, err = s.IncrementEmailVerificationFailedAttempts(ctx, verification.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, failedAttempts)
+
+	updatedVerification, err := s.GetEmailVerificationByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, updatedVerification.FailedAttempts)
+}
+
+func TestIncrementEmailVerificationFailedAttempts_NotFound(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	_, err := s.IncrementEmailVerificationFailedAttempts(context.Background(), uuid.New().String())
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
 func TestEmailVerification_IsExpired_True(t *testing.T) {
 	// Create verification with old date (expired)
 	verification := database.NewEmailVerification(uuid.New().String(), "hashedcode123", "unsubscribe_token", time.Now().Add(-25*time.Hour))
 
-	require.True(t, verification.IsExpired())
+	require.True(t, verification.IsExpired(24*time.Hour))
 }
 
 func TestEmailVerification_IsExpired_False(t *testing.T) {
 	// Create verification with current time (not expired)
 	verification := database.NewEmailVerification(uuid.New().String(), "hashedcode123", "unsubscribe_token", time.Now())
 
-	require.False(t, verification.IsExpired())
+	require.False(t, verification.IsExpired(24*time.Hour))
 }
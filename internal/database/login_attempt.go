@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GetLoginAttempt returns failed sign in attempts record by kind and subject
+func (r *UserRepo) GetLoginAttempt(ctx context.Context, kind, subject string) (LoginAttempt, error) {
+	ctx, span := tracer.Start(ctx, "getLoginAttempt")
+	defer span.End()
+
+	const q = `SELECT kind, subject, failed_count, locked_until, last_failed_at
+		FROM login_attempts
+		WHERE kind = $1 AND subject = $2`
+
+	var attempt LoginAttempt
+	if err := r.query().Get(ctx, &attempt, q, kind, subject); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return LoginAttempt{}, ErrNotFound
+		}
+		return LoginAttempt{}, fmt.Errorf("select login attempt: %w", err)
+	}
+
+	return attempt, nil
+}
+
+// AddFailedLoginAttempt increments failed sign in attempts counter and returns updated record.
+// Counter and lock are reset if the last failure happened before resetBefore
+func (r *UserRepo) AddFailedLoginAttempt(ctx context.Context, kind, subject string, resetBefore time.Time) (LoginAttempt, error) {
+	ctx, span := tracer.Start(ctx, "addFailedLoginAttempt")
+	defer span.End()
+
+	const q = `INSERT INTO login_attempts (kind, subject, failed_count, last_failed_at)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (kind, subject) DO UPDATE
+		SET failed_count = CASE WHEN login_attempts.last_failed_at < $3 THEN 1 ELSE login_attempts.failed_count + 1 END,
+		    locked_until = CASE WHEN login_attempts.last_failed_at < $3 THEN NULL ELSE login_attempts.locked_until END,
+		    last_failed_at = NOW()
+		RETURNING kind, subject, failed_count, locked_until, last_failed_at`
+
+	var attempt LoginAttempt
+	if err := r.query().Get(ctx, &attempt, q, kind, subject, resetBefore); err != nil {
+		return LoginAttempt{}, fmt.Errorf("upsert login attempt: %w", err)
+	}
+
+	return attempt, nil
+}
+
+// SetLoginAttemptLockedUntil locks sign in for kind and subject until provided time
+func (r *UserRepo) SetLoginAttemptLockedUntil(ctx context.Context, kind, subject string, lockedUntil time.Time) error {
+	ctx, span := tracer.Start(ctx, "setLoginAttemptLockedUntil")
+	defer span.End()
+
+	const q = `UPDATE login_attempts SET locked_until = $3 WHERE kind = $1 AND subject = $2`
+
+	_, err := r.query().Exec(ctx, q, kind, subject, lockedUntil)
+	if err != nil {
+		return fmt.Errorf("set login attempt locked until: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteLoginAttempt deletes failed sign in attempts record by kind and subject
+func (r *UserRepo) DeleteLoginAttempt(ctx context.Context, kind, subject string) error {
+	ctx, span := tracer.Start(ctx, "deleteLoginAttempt")
+	defer span.End()
+
+	const q = `DELETE FROM login_attempts WHERE kind = $1 AND subject = $2`
+
+	_, err := r.query().Exec(ctx, q, kind, subject)
+	if err != nil {
+		return fmt.Errorf("delete login attempt: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteLoginAttemptsBySubject deletes failed sign in attempts records of any kind by subject and returns number of deleted records
+func (r *UserRepo) DeleteLoginAttemptsBySubject(ctx context.Context, subject string) (int64, error) {
+	ctx, span := tracer.Start(ctx, "deleteLoginAttemptsBySubject")
+	defer span.End()
+
+	const q = `DELETE FROM login_attempts WHERE subject = $1`
+
+	res, err := r.query().Exec(ctx, q, subject)
+	if err != nil {
+		return 0, fmt.Errorf("delete login attempts by subject: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("get affected rows: %w", err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,114 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddFailedLoginAttempt_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+	resetBefore := time.Now().Add(-time.Hour)
+
+	attempt, err := s.AddFailedLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser", resetBefore)
+	require.NoError(t, err)
+	require.Equal(t, 1, attempt.FailedCount)
+	require.False(t, attempt.IsLocked())
+
+	attempt, err = s.AddFailedLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser", resetBefore)
+	require.NoError(t, err)
+	require.Equal(t, 2, attempt.FailedCount)
+
+	// same subject of different kind is tracked separately
+	attempt, err = s.AddFailedLoginAttempt(ctx, model.LoginAttemptKindIP, "testuser", resetBefore)
+	require.NoError(t, err)
+	require.Equal(t, 1, attempt.FailedCount)
+}
+
+func TestAddFailedLoginAttempt_ResetsStaleCounter(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	_, err := s.AddFailedLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	err = s.SetLoginAttemptLockedUntil(ctx, model.LoginAttemptKindUsername, "testuser", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	// all previous failures happened before reset time
+	attempt, err := s.AddFailedLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 1, attempt.FailedCount)
+	require.False(t, attempt.LockedUntil.Valid)
+}
+
+func TestSetLoginAttemptLockedUntil_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	_, err := s.AddFailedLoginAttempt(ctx, model.LoginAttemptKindIP, "127.0.0.1", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	err = s.SetLoginAttemptLockedUntil(ctx, model.LoginAttemptKindIP, "127.0.0.1", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	attempt, err := s.GetLoginAttempt(ctx, model.LoginAttemptKindIP, "127.0.0.1")
+	require.NoError(t, err)
+	require.True(t, attempt.IsLocked())
+}
+
+func TestGetLoginAttempt_NotFound(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	_, err := s.GetLoginAttempt(context.Background(), model.LoginAttemptKindUsername, "nonexistent")
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestDeleteLoginAttempt_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	_, err := s.AddFailedLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	err = s.DeleteLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser")
+	require.NoError(t, err)
+
+	_, err = s.GetLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser")
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestDeleteLoginAttemptsBySubject_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+	resetBefore := time.Now().Add(-time.Hour)
+
+	_, err := s.AddFailedLoginAttempt(ctx, model.LoginAttemptKindUsername, "10.0.0.1", resetBefore)
+	require.NoError(t, err)
+	_, err = s.AddFailedLoginAttempt(ctx, model.LoginAttemptKindIP, "10.0.0.1", resetBefore)
+	require.NoError(t, err)
+	_, err = s.AddFailedLoginAttempt(ctx, model.LoginAttemptKindUsername, "otheruser", resetBefore)
+	require.NoError(t, err)
+
+	n, err := s.DeleteLoginAttemptsBySubject(ctx, "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	_, err = s.GetLoginAttempt(ctx, model.LoginAttemptKindUsername, "otheruser")
+	require.NoError(t, err)
+}
@@ -27,6 +27,7 @@ type User struct {
 	DisplayName   string         `db:"name"`
 	Email         sql.NullString `db:"email"`
 	EmailVerified bool           `db:"email_verified"`
+	Locale        string         `db:"locale"`
 	PasswordHash  []byte         `db:"password_hash"`
 	Role          model.Role     `db:"role"`
 	OAuthProvider sql.NullString `db:"oauth_provider"`
@@ -43,6 +44,7 @@ func NewUser(username, name string, passwordHash []byte, role model.Role) User {
 		DisplayName:  name,
 		PasswordHash: passwordHash,
 		Role:         role,
+		Locale:       model.DefaultLocale,
 	}
 }
 
@@ -64,7 +66,9 @@ type EmailVerification struct {
 	UserID           string         `db:"user_id"`
 	CodeHash         sql.NullString `db:"verification_code"`
 	MessageID        sql.NullString `db:"message_id"`
+	Provider         sql.NullString `db:"provider"`
 	UnsubscribeToken sql.NullString `db:"unsubscribe_token"`
+	FailedAttempts   int            `db:"failed_attempts"`
 	DateCreated      time.Time      `db:"date_created"`
 }
 
@@ -79,22 +83,90 @@ func NewEmailVerification(userID, codeHash, unsubscribeToken string, createdAt t
 	}
 }
 
-// IsExpired checks if the verification code has expired
-func (ev *EmailVerification) IsExpired() bool {
-	return time.Now().After(ev.DateCreated.Add(model.VerificationCodeTTL))
+// IsExpired checks if the verification code valid for provided ttl has expired
+func (ev *EmailVerification) IsExpired(ttl time.Duration) bool {
+	return time.Now().After(ev.DateCreated.Add(ttl))
 }
 
 // EmailUnsubscribe represents an email unsubscribe record
 type EmailUnsubscribe struct {
-	ID    string `db:"id"`
-	Email string `db:"email"`
+	ID          string    `db:"id"`
+	Email       string    `db:"email"`
+	Reason      string    `db:"reason"`
+	DateCreated time.Time `db:"date_created"`
 }
 
 // NewEmailUnsubscribe creates a new email unsubscribe record
-func NewEmailUnsubscribe(email string) EmailUnsubscribe {
+func NewEmailUnsubscribe(email, reason string) EmailUnsubscribe {
 	return EmailUnsubscribe{
-		ID:    uuid.New().String(),
-		Email: email,
+		ID:     uuid.New().String(),
+		Email:  email,
+		Reason: reason,
+	}
+}
+
+// EmailPreference represents subscription of an email address to emails of category
+type EmailPreference struct {
+	Email       string    `db:"email"`
+	Category    string    `db:"category"`
+	Subscribed  bool      `db:"subscribed"`
+	DateUpdated time.Time `db:"date_updated"`
+}
+
+// NewEmailPreference creates a new email preference record
+func NewEmailPreference(email, category string, subscribed bool) EmailPreference {
+	return EmailPreference{
+		Email:      email,
+		Category:   category,
+		Subscribed: subscribed,
+	}
+}
+
+// EmailResubscribe represents a record of an email address resubscribed to emails.
+// Unsubscribe reason and date are of unsubscribe record lifted by resubscribe, if there was one
+type EmailResubscribe struct {
+	ID                string         `db:"id"`
+	Email             string         `db:"email"`
+	Source            string         `db:"source"`
+	UserID            sql.NullString `db:"user_id"`
+	UnsubscribeReason sql.NullString `db:"unsubscribe_reason"`
+	DateUnsubscribed  sql.NullTime   `db:"date_unsubscribed"`
+	DateCreated       time.Time      `db:"date_created"`
+}
+
+// NewEmailResubscribe creates a new email resubscribe record. User id is empty if resubscribe was made without signing in
+func NewEmailResubscribe(email, source, userID string) EmailResubscribe {
+	return EmailResubscribe{
+		ID:     uuid.New().String(),
+		Email:  email,
+		Source: source,
+		UserID: sql.NullString{String: userID, Valid: userID != ""},
+	}
+}
+
+// SetLiftedUnsubscribe sets unsubscribe record lifted by resubscribe
+func (r *EmailResubscribe) SetLiftedUnsubscribe(unsubscribe EmailUnsubscribe) {
+	r.UnsubscribeReason = sql.NullString{String: unsubscribe.Reason, Valid: true}
+	r.DateUnsubscribed = sql.NullTime{Time: unsubscribe.DateCreated, Valid: true}
+}
+
+// UserDevice represents a device user signed in from. Device is identified by hash of its user agent
+type UserDevice struct {
+	UserID       string         `db:"user_id"`
+	DeviceHash   string         `db:"device_hash"`
+	UserAgent    string         `db:"user_agent"`
+	ClientIP     sql.NullString `db:"client_ip"`
+	DateCreated  time.Time      `db:"date_created"`
+	DateLastSeen time.Time      `db:"date_last_seen"`
+}
+
+// NewUserDevice creates a new user device record
+func NewUserDevice(userID, deviceHash, userAgent, clientIP string) UserDevice {
+	return UserDevice{
+		UserID:     userID,
+		DeviceHash: deviceHash,
+		UserAgent:  userAgent,
+		ClientIP:   sql.NullString{String: clientIP, Valid: clientIP != ""},
 	}
 }
 
@@ -121,3 +193,258 @@ func NewRefreshToken(userID, tokenHash string, expiresAt time.Time) RefreshToken
 func (rt *RefreshToken) IsExpired() bool {
 	return !time.Now().Before(rt.ExpiresAt)
 }
+
+// LoginAttempt represents failed sign in attempts tracked for a username or a client ip
+type LoginAttempt struct {
+	Kind         string       `db:"kind"`
+	Subject      string       `db:"subject"`
+	FailedCount  int          `db:"failed_count"`
+	LockedUntil  sql.NullTime `db:"locked_until"`
+	LastFailedAt time.Time    `db:"last_failed_at"`
+}
+
+// IsLocked checks if sign in is locked at the moment
+func (la *LoginAttempt) IsLocked() bool {
+	return la.LockedUntil.Valid && time.Now().Before(la.LockedUntil.Time)
+}
+
+// UserTOTP represents user TOTP two-factor authentication settings.
+// Secret is stored encrypted
+type UserTOTP struct {
+	UserID       string       `db:"user_id"`
+	Secret       []byte       `db:"secret"`
+	ConfirmedAt  sql.NullTime `db:"confirmed_at"`
+	LastUsedStep int64        `db:"last_used_step"`
+	DateCreated  time.Time    `db:"date_created"`
+}
+
+// NewUserTOTP creates a new unconfirmed user TOTP record
+func NewUserTOTP(userID string, encryptedSecret []byte) UserTOTP {
+	return UserTOTP{
+		UserID: userID,
+		Secret: encryptedSecret,
+	}
+}
+
+// IsConfirmed checks if TOTP enrollment is confirmed, i.e. two-factor authentication is enabled
+func (ut *UserTOTP) IsConfirmed() bool {
+	return ut.ConfirmedAt.Valid
+}
+
+// RecoveryCode represents single-use two-factor authentication recovery code
+type RecoveryCode struct {
+	ID          string       `db:"id"`
+	UserID      string       `db:"user_id"`
+	CodeHash    string       `db:"code_hash"`
+	UsedAt      sql.NullTime `db:"used_at"`
+	DateCreated time.Time    `db:"date_created"`
+}
+
+// NewRecoveryCode creates a new recovery code
+func NewRecoveryCode(userID, codeHash string) RecoveryCode {
+	return RecoveryCode{
+		ID:       uuid.New().String(),
+		UserID:   userID,
+		CodeHash: codeHash,
+	}
+}
+
+// WebAuthnCredential represents passkey credential of a user.
+// Data contains serialized credential record including public key and signature counter
+type WebAuthnCredential struct {
+	ID           string       `db:"id"`
+	UserID       string       `db:"user_id"`
+	CredentialID []byte       `db:"credential_id"`
+	Name         string       `db:"name"`
+	Data         []byte       `db:"data"`
+	LastUsedAt   sql.NullTime `db:"last_used_at"`
+	DateCreated  time.Time    `db:"date_created"`
+}
+
+// NewWebAuthnCredential creates a new passkey credential
+func NewWebAuthnCredential(userID string, credentialID []byte, name string, data []byte) WebAuthnCredential {
+	return WebAuthnCredential{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		CredentialID: credentialID,
+		Name:         name,
+		Data:         data,
+	}
+}
+
+// WebAuthnSession represents state of a started passkey registration or sign in ceremony
+type WebAuthnSession struct {
+	ID          string         `db:"id"`
+	UserID      sql.NullString `db:"user_id"`
+	Kind        string         `db:"kind"`
+	Data        []byte         `db:"data"`
+	ExpiresAt   time.Time      `db:"expires_at"`
+	DateCreated time.Time      `db:"date_created"`
+}
+
+// NewWebAuthnSession creates a new passkey ceremony session. User id is empty for sign in ceremony
+func NewWebAuthnSession(userID, kind string, data []byte, expiresAt time.Time) WebAuthnSession {
+	return WebAuthnSession{
+		ID:        uuid.New().String(),
+		UserID:    sql.NullString{String: userID, Valid: userID != ""},
+		Kind:      kind,
+		Data:      data,
+		ExpiresAt: expiresAt,
+	}
+}
+
+// IsExpired checks if passkey ceremony session is expired
+func (ws *WebAuthnSession) IsExpired() bool {
+	return time.Now().After(ws.ExpiresAt)
+}
+
+// EmailSignIn represents single-use passwordless sign in code and link sent by email
+type EmailSignIn struct {
+	ID             string         `db:"id"`
+	UserID         string         `db:"user_id"`
+	CodeHash       sql.NullString `db:"code_hash"`
+	TokenHash      sql.NullString `db:"token_hash"`
+	MessageID      sql.NullString `db:"message_id"`
+	FailedAttempts int            `db:"failed_attempts"`
+	DateCreated    time.Time      `db:"date_created"`
+}
+
+// NewEmailSignIn creates a new passwordless sign in record
+func NewEmailSignIn(userID, codeHash, tokenHash string, createdAt time.Time) EmailSignIn {
+	return EmailSignIn{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		CodeHash:    sql.NullString{String: codeHash, Valid: codeHash != ""},
+		TokenHash:   sql.NullString{String: tokenHash, Valid: tokenHash != ""},
+		DateCreated: createdAt,
+	}
+}
+
+// IsExpired checks if the sign in code has expired
+func (es *EmailSignIn) IsExpired() bool {
+	return time.Now().After(es.DateCreated.Add(model.EmailSignInCodeTTL))
+}
+
+// EmailChange represents pending change of user email address confirmed by single-use code sent to the new address
+type EmailChange struct {
+	ID             string         `db:"id"`
+	UserID         string         `db:"user_id"`
+	NewEmail       string         `db:"new_email"`
+	CodeHash       sql.NullString `db:"code_hash"`
+	MessageID      sql.NullString `db:"message_id"`
+	FailedAttempts int            `db:"failed_attempts"`
+	DateCreated    time.Time      `db:"date_created"`
+}
+
+// NewEmailChange creates a new email change record
+func NewEmailChange(userID, newEmail, codeHash string, createdAt time.Time) EmailChange {
+	return EmailChange{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		NewEmail:    newEmail,
+		CodeHash:    sql.NullString{String: codeHash, Valid: codeHash != ""},
+		DateCreated: createdAt,
+	}
+}
+
+// IsExpired checks if the email change code has expired
+func (ec *EmailChange) IsExpired() bool {
+	return time.Now().After(ec.DateCreated.Add(model.EmailChangeCodeTTL))
+}
+
+// EmailOutboxMessage represents outgoing email written in the same transaction as the data it relates to
+// and sent afterward by background dispatcher. Payload is encrypted and cleared once email is sent or failed
+type EmailOutboxMessage struct {
+	ID            string         `db:"id"`
+	Kind          string         `db:"kind"`
+	UserID        sql.NullString `db:"user_id"`
+	Recipient     string         `db:"recipient"`
+	ReferenceID   sql.NullString `db:"reference_id"`
+	Payload       []byte         `db:"payload"`
+	Status        string         `db:"status"`
+	Attempts      int            `db:"attempts"`
+	NextAttemptAt time.Time      `db:"next_attempt_at"`
+	LastError     sql.NullString `db:"last_error"`
+	MessageID     sql.NullString `db:"message_id"`
+	SentAt        sql.NullTime   `db:"sent_at"`
+	DateCreated   time.Time      `db:"date_created"`
+}
+
+// NewEmailOutboxMessage creates a new pending outgoing email to user. Reference id is id of related record, e.g. email verification
+func NewEmailOutboxMessage(kind, userID, recipient, referenceID string, payload []byte) EmailOutboxMessage {
+	now := time.Now()
+	return EmailOutboxMessage{
+		ID:            uuid.New().String(),
+		Kind:          kind,
+		UserID:        sql.NullString{String: userID, Valid: userID != ""},
+		Recipient:     recipient,
+		ReferenceID:   sql.NullString{String: referenceID, Valid: referenceID != ""},
+		Payload:       payload,
+		Status:        model.EmailOutboxStatusPending,
+		NextAttemptAt: now,
+		DateCreated:   now,
+	}
+}
+
+// EmailMessage represents send attempt of outgoing email. Status is updated by delivery events of the sent email
+type EmailMessage struct {
+	ID                string         `db:"id"`
+	OutboxID          string         `db:"outbox_id"`
+	UserID            sql.NullString `db:"user_id"`
+	Template          string         `db:"template"`
+	Recipient         string         `db:"recipient"`
+	Provider          sql.NullString `db:"provider"`
+	ProviderMessageID sql.NullString `db:"provider_message_id"`
+	Status            string         `db:"status"`
+	Error             sql.NullString `db:"error"`
+	DateCreated       time.Time      `db:"date_created"`
+	DateUpdated       time.Time      `db:"date_updated"`
+}
+
+// NewEmailMessage creates a new record of outgoing email send attempt. Provider is email provider that sent the message.
+// Message is sent if send error is empty
+func NewEmailMessage(outbox EmailOutboxMessage, template, provider, providerMessageID, sendErr string) EmailMessage {
+	status := model.EmailMessageStatusSent
+	if sendErr != "" {
+		status = model.EmailMessageStatusFailed
+	}
+	now := time.Now()
+	return EmailMessage{
+		ID:                uuid.New().String(),
+		OutboxID:          outbox.ID,
+		UserID:            outbox.UserID,
+		Template:          template,
+		Recipient:         outbox.Recipient,
+		Provider:          sql.NullString{String: provider, Valid: provider != ""},
+		ProviderMessageID: sql.NullString{String: providerMessageID, Valid: providerMessageID != ""},
+		Status:            status,
+		Error:             sql.NullString{String: sendErr, Valid: sendErr != ""},
+		DateCreated:       now,
+		DateUpdated:       now,
+	}
+}
+
+// EmailDeliveryEvent represents delivery event of sent email reported by email provider
+type EmailDeliveryEvent struct {
+	ID          string         `db:"id"`
+	EventID     string         `db:"event_id"`
+	MessageID   string         `db:"message_id"`
+	Email       string         `db:"email"`
+	Status      string         `db:"status"`
+	Detail      sql.NullString `db:"detail"`
+	OccurredAt  time.Time      `db:"occurred_at"`
+	DateCreated time.Time      `db:"date_created"`
+}
+
+// NewEmailDeliveryEvent creates a new email delivery event record
+func NewEmailDeliveryEvent(eventID, messageID, email, status, detail string, occurredAt time.Time) EmailDeliveryEvent {
+	return EmailDeliveryEvent{
+		ID:         uuid.New().String(),
+		EventID:    eventID,
+		MessageID:  messageID,
+		Email:      email,
+		Status:     status,
+		Detail:     sql.NullString{String: detail, Valid: detail != ""},
+		OccurredAt: occurredAt,
+	}
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// IncrementRateLimit increments rate limit counter for key and returns its value and time when current window ends.
+// Counter starts over when window ends
+func (r *UserRepo) IncrementRateLimit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	ctx, span := tracer.Start(ctx, "incrementRateLimit")
+	defer span.End()
+
+	const q = `INSERT INTO rate_limits (key, count, expires_at)
+		VALUES ($1, 1, NOW() + $2 * INTERVAL '1 millisecond')
+		ON CONFLICT (key) DO UPDATE
+		SET count = CASE WHEN rate_limits.expires_at <= NOW() THEN 1 ELSE rate_limits.count + 1 END,
+		    expires_at = CASE WHEN rate_limits.expires_at <= NOW() THEN EXCLUDED.expires_at ELSE rate_limits.expires_at END
+		RETURNING count, expires_at`
+
+	var res struct {
+		Count     int       `db:"count"`
+		ExpiresAt time.Time `db:"expires_at"`
+	}
+	if err := r.query().Get(ctx, &res, q, key, window.Milliseconds()); err != nil {
+		return 0, time.Time{}, fmt.Errorf("upsert rate limit: %w", err)
+	}
+
+	return res.Count, res.ExpiresAt, nil
+}
+
+// DeleteExpiredRateLimits deletes all expired rate limit counters
+func (r *UserRepo) DeleteExpiredRateLimits(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "deleteExpiredRateLimits")
+	defer span.End()
+
+	const q = `DELETE FROM rate_limits WHERE expires_at <= NOW()`
+
+	_, err := r.query().Exec(ctx, q)
+	if err != nil {
+		return fmt.Errorf("delete expired rate limits: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncrementRateLimit_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	count, resetAt, err := s.IncrementRateLimit(ctx, "signin:ip:127.0.0.1", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.WithinDuration(t, time.Now().Add(time.Minute), resetAt, 5*time.Second)
+
+	count, resetAt2, err := s.IncrementRateLimit(ctx, "signin:ip:127.0.0.1", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.True(t, resetAt.Equal(resetAt2))
+
+	count, _, err = s.IncrementRateLimit(ctx, "signup:ip:127.0.0.1", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestIncrementRateLimit_WindowReset(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	_, _, err := s.IncrementRateLimit(ctx, "signin:ip:127.0.0.1", 100*time.Millisecond)
+	require.NoError(t, err)
+
+	time.Sleep(150 * time.Millisecond)
+
+	count, _, err := s.IncrementRateLimit(ctx, "signin:ip:127.0.0.1", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	err = s.DeleteExpiredRateLimits(ctx)
+	require.NoError(t, err)
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// CreateRecoveryCode inserts a new recovery code into the database
+func (r *UserRepo) CreateRecoveryCode(ctx context.Context, recoveryCode RecoveryCode) error {
+	ctx, span := tracer.Start(ctx, "createRecoveryCode")
+	defer span.End()
+
+	const q = `INSERT INTO recovery_codes (id, user_id, code_hash, date_created)
+		VALUES ($1, $2, $3, NOW())`
+
+	_, err := r.query().Exec(ctx, q, recoveryCode.ID, recoveryCode.UserID, recoveryCode.CodeHash)
+	if err != nil {
+		return fmt.Errorf("insert recovery code: %w", err)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks unused recovery code of user as used. Returns ErrNotFound if there is no such unused code
+func (r *UserRepo) UseRecoveryCode(ctx context.Context, userID, codeHash string) error {
+	ctx, span := tracer.Start(ctx, "useRecoveryCode")
+	defer span.End()
+
+	const q = `UPDATE recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+
+	res, err := r.query().Exec(ctx, q, userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("use recovery code: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get affected rows: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// CountUnusedRecoveryCodes returns number of recovery codes of user that were not used yet
+func (r *UserRepo) CountUnusedRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	ctx, span := tracer.Start(ctx, "countUnusedRecoveryCodes")
+	defer span.End()
+
+	const q = `SELECT COUNT(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+
+	var count int
+	if err := r.query().Get(ctx, &count, q, userID); err != nil {
+		return 0, fmt.Errorf("count unused recovery codes: %w", err)
+	}
+
+	return count, nil
+}
+
+// DeleteRecoveryCodesByUserID deletes all recovery codes of user
+func (r *UserRepo) DeleteRecoveryCodesByUserID(ctx context.Context, userID string) error {
+	ctx, span := tracer.Start(ctx, "deleteRecoveryCodesByUserID")
+	defer span.End()
+
+	const q = `DELETE FROM recovery_codes WHERE user_id = $1`
+
+	_, err := r.query().Exec(ctx, q, userID)
+	if err != nil {
+		return fmt.Errorf("delete recovery codes by user id: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseRecoveryCode_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	err = s.CreateRecoveryCode(ctx, database.NewRecoveryCode(user.ID, "hash1"))
+	require.NoError(t, err)
+	err = s.CreateRecoveryCode(ctx, database.NewRecoveryCode(user.ID, "hash2"))
+	require.NoError(t, err)
+
+	count, err := s.CountUnusedRecoveryCodes(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	err = s.UseRecoveryCode(ctx, user.ID, "hash1")
+	require.NoError(t, err)
+
+	count, err = s.CountUnusedRecoveryCodes(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	// code can be used only once
+	err = s.UseRecoveryCode(ctx, user.ID, "hash1")
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestUseRecoveryCode_OtherUser(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+	otherUser := database.NewUser("otheruser", "Other User", []byte("hashedpassword"), model.UserRoleName)
+	err = s.CreateUser(ctx, otherUser)
+	require.NoError(t, err)
+
+	err = s.CreateRecoveryCode(ctx, database.NewRecoveryCode(user.ID, "hash1"))
+	require.NoError(t, err)
+
+	err = s.UseRecoveryCode(ctx, otherUser.ID, "hash1")
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestDeleteRecoveryCodesByUserID_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	err = s.CreateRecoveryCode(ctx, database.NewRecoveryCode(user.ID, "hash1"))
+	require.NoError(t, err)
+
+	err = s.DeleteRecoveryCodesByUserID(ctx, user.ID)
+	require.NoError(t, err)
+
+	count, err := s.CountUnusedRecoveryCodes(ctx, user.ID)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	err = s.UseRecoveryCode(ctx, user.ID, "hash1")
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
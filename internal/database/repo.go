@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/OutOfStack/game-library-auth/pkg/database"
 	"github.com/jmoiron/sqlx"
@@ -59,3 +60,28 @@ func (r *UserRepo) RunWithTx(ctx context.Context, f func(context.Context) error)
 func (r *UserRepo) query() database.Querier {
 	return database.NewQuerier(r.db)
 }
+
+// withSavepoint runs a function under a savepoint when called in a transaction.
+// On error changes made by the function are rolled back and the transaction stays usable, e.g. after a unique violation
+func (r *UserRepo) withSavepoint(ctx context.Context, name string, f func() error) error {
+	if _, ok := database.TxFromContext(ctx); !ok {
+		return f()
+	}
+
+	if _, err := r.query().Exec(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("create savepoint %s: %w", name, err)
+	}
+
+	if err := f(); err != nil {
+		if _, spErr := r.query().Exec(ctx, "ROLLBACK TO SAVEPOINT "+name); spErr != nil {
+			r.log.Error("rolling back to savepoint", zap.String("savepoint", name), zap.Error(spErr))
+		}
+		return err
+	}
+
+	if _, err := r.query().Exec(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("release savepoint %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -16,10 +16,11 @@ func (r *UserRepo) CreateUser(ctx context.Context, user User) error {
 	defer span.End()
 
 	const q = `INSERT INTO users
-        (id, username, name, email, email_verified, password_hash, role, oauth_provider, oauth_id, date_created)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())`
+        (id, username, name, email, email_verified, locale, password_hash, role, oauth_provider, oauth_id, date_created)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())`
 
-	_, err := r.query().Exec(ctx, q, user.ID, user.Username, user.DisplayName, user.Email, user.EmailVerified, user.PasswordHash, user.Role, user.OAuthProvider, user.OAuthID)
+	_, err := r.query().Exec(ctx, q, user.ID, user.Username, user.DisplayName, user.Email, user.EmailVerified, user.Locale, user.PasswordHash, user.Role,
+		user.OAuthProvider, user.OAuthID)
 	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolationCode {
@@ -39,10 +40,11 @@ func (r *UserRepo) UpdateUser(ctx context.Context, user User) error {
 	const q = `UPDATE users
 		SET name = COALESCE(NULLIF($2, ''), name),
 		password_hash = COALESCE(NULLIF($3, ''), password_hash),
+		locale = COALESCE(NULLIF($4, ''), locale),
 		date_updated = NOW()
 		WHERE id = $1`
 
-	_, err := r.query().Exec(ctx, q, user.ID, user.DisplayName, user.PasswordHash)
+	_, err := r.query().Exec(ctx, q, user.ID, user.DisplayName, user.PasswordHash, user.Locale)
 	if err != nil {
 		return fmt.Errorf("update user: %w", err)
 	}
@@ -55,7 +57,7 @@ func (r *UserRepo) GetUserByID(ctx context.Context, userID string) (user User, e
 	ctx, span := tracer.Start(ctx, "getUserByID")
 	defer span.End()
 
-	const q = `SELECT id, username, name, email, email_verified, password_hash, role, oauth_provider, oauth_id, date_created, date_updated
+	const q = `SELECT id, username, name, email, email_verified, locale, password_hash, role, oauth_provider, oauth_id, date_created, date_updated
 		FROM users
 		WHERE id = $1
 		FOR NO KEY UPDATE`
@@ -75,7 +77,7 @@ func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (user
 	ctx, span := tracer.Start(ctx, "getUserByUsername")
 	defer span.End()
 
-	const q = `SELECT id, username, name, email, email_verified, password_hash, role, oauth_provider, oauth_id, date_created, date_updated
+	const q = `SELECT id, username, name, email, email_verified, locale, password_hash, role, oauth_provider, oauth_id, date_created, date_updated
 		FROM users
 		WHERE username = $1
 		FOR NO KEY UPDATE`
@@ -113,7 +115,7 @@ func (r *UserRepo) GetUserByOAuth(ctx context.Context, provider string, oauthID
 	ctx, span := tracer.Start(ctx, "getUserByOAuth")
 	defer span.End()
 
-	const q = `SELECT id, username, name, email, email_verified, role, oauth_provider, oauth_id, date_created, date_updated
+	const q = `SELECT id, username, name, email, email_verified, locale, role, oauth_provider, oauth_id, date_created, date_updated
         FROM users
         WHERE oauth_provider = $1 AND oauth_id = $2`
 
@@ -147,7 +149,7 @@ func (r *UserRepo) GetUserByEmail(ctx context.Context, email string) (User, erro
 	ctx, span := tracer.Start(ctx, "getUserByEmail")
 	defer span.End()
 
-	const q = `SELECT id, username, name, email, email_verified, password_hash, role, oauth_provider, oauth_id, date_created, date_updated
+	const q = `SELECT id, username, name, email, email_verified, locale, password_hash, role, oauth_provider, oauth_id, date_created, date_updated
 		FROM users
 		WHERE email = $1`
 
@@ -161,6 +163,31 @@ func (r *UserRepo) GetUserByEmail(ctx context.Context, email string) (User, erro
 	return user, nil
 }
 
+// UpdateUserEmail sets new email address of user and its verification status. Returns ErrUserExists if email belongs to another user.
+// Enclosing transaction stays usable after ErrUserExists
+func (r *UserRepo) UpdateUserEmail(ctx context.Context, userID, email string, verified bool) error {
+	ctx, span := tracer.Start(ctx, "updateUserEmail")
+	defer span.End()
+
+	const q = `UPDATE users
+        SET email = $2, email_verified = $3, date_updated = NOW()
+        WHERE id = $1`
+
+	err := r.withSavepoint(ctx, "update_user_email", func() error {
+		_, err := r.query().Exec(ctx, q, userID, email, verified)
+		return err
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolationCode {
+			return ErrUserExists
+		}
+		return fmt.Errorf("update user email: %w", err)
+	}
+
+	return nil
+}
+
 // SetUserEmailVerified sets user email as verified
 func (r *UserRepo) SetUserEmailVerified(ctx context.Context, userID string) error {
 	ctx, span := tracer.Start(ctx, "setUserVerified")
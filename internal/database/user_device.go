@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// UpsertUserDevice creates user device record or updates last seen time and ip of existing one.
+// Returns true if device is new for user
+func (r *UserRepo) UpsertUserDevice(ctx context.Context, device UserDevice) (bool, error) {
+	ctx, span := tracer.Start(ctx, "upsertUserDevice")
+	defer span.End()
+
+	const q = `INSERT INTO user_devices (user_id, device_hash, user_agent, client_ip, date_created, date_last_seen)
+        VALUES ($1, $2, $3, $4, NOW(), NOW())
+        ON CONFLICT (user_id, device_hash) DO UPDATE
+        SET client_ip = EXCLUDED.client_ip, date_last_seen = EXCLUDED.date_last_seen
+        RETURNING (xmax = 0) AS created`
+
+	var created bool
+	if err := r.query().Get(ctx, &created, q, device.UserID, device.DeviceHash, device.UserAgent, device.ClientIP); err != nil {
+		return false, fmt.Errorf("upsert user device: %w", err)
+	}
+
+	return created, nil
+}
+
+// HasUserDevices checks if user has signed in from any device
+func (r *UserRepo) HasUserDevices(ctx context.Context, userID string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "hasUserDevices")
+	defer span.End()
+
+	const q = `SELECT EXISTS (SELECT 1 FROM user_devices WHERE user_id = $1)`
+
+	var exists bool
+	if err := r.query().Get(ctx, &exists, q, userID); err != nil {
+		return false, fmt.Errorf("check user devices: %w", err)
+	}
+
+	return exists, nil
+}
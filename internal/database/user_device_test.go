@@ -0,0 +1,68 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertUserDevice_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	hasDevices, err := s.HasUserDevices(ctx, user.ID)
+	require.NoError(t, err)
+	require.False(t, hasDevices)
+
+	created, err := s.UpsertUserDevice(ctx, database.NewUserDevice(user.ID, "hash1", "Firefox", "127.0.0.1"))
+	require.NoError(t, err)
+	require.True(t, created)
+
+	// same device from another ip is not new
+	created, err = s.UpsertUserDevice(ctx, database.NewUserDevice(user.ID, "hash1", "Firefox", "10.0.0.1"))
+	require.NoError(t, err)
+	require.False(t, created)
+
+	var clientIP string
+	err = db.GetContext(ctx, &clientIP, `SELECT client_ip FROM user_devices WHERE user_id = $1 AND device_hash = $2`, user.ID, "hash1")
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.1", clientIP)
+
+	created, err = s.UpsertUserDevice(ctx, database.NewUserDevice(user.ID, "hash2", "Chrome", ""))
+	require.NoError(t, err)
+	require.True(t, created)
+
+	hasDevices, err = s.HasUserDevices(ctx, user.ID)
+	require.NoError(t, err)
+	require.True(t, hasDevices)
+}
+
+func TestUpsertUserDevice_DeletedWithUser(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	_, err = s.UpsertUserDevice(ctx, database.NewUserDevice(user.ID, "hash1", "Firefox", "127.0.0.1"))
+	require.NoError(t, err)
+
+	err = s.DeleteUser(ctx, user.ID)
+	require.NoError(t, err)
+
+	hasDevices, err := s.HasUserDevices(ctx, user.ID)
+	require.NoError(t, err)
+	require.False(t, hasDevices)
+}
@@ -3,6 +3,7 @@ package database_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/OutOfStack/game-library-auth/internal/database"
 	"github.com/OutOfStack/game-library-auth/internal/model"
@@ -28,6 +29,7 @@ func TestCreateUser_Ok(t *testing.T) {
 	require.Equal(t, user.Username, createdUser.Username)
 	require.Equal(t, user.DisplayName, createdUser.DisplayName)
 	require.Equal(t, user.Email, createdUser.Email)
+	require.Equal(t, model.DefaultLocale, createdUser.Locale)
 	require.Equal(t, user.Role, createdUser.Role)
 }
 
@@ -176,6 +178,7 @@ func TestUpdateUser_Ok(t *testing.T) {
 
 	user.DisplayName = "Updated Name"
 	user.PasswordHash = []byte("newhashedpassword")
+	user.Locale = "es-MX"
 	err = s.UpdateUser(ctx, user)
 	require.NoError(t, err)
 
@@ -183,6 +186,7 @@ func TestUpdateUser_Ok(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "Updated Name", updatedUser.DisplayName)
 	require.Equal(t, []byte("newhashedpassword"), updatedUser.PasswordHash)
+	require.Equal(t, "es-MX", updatedUser.Locale)
 	require.NotNil(t, updatedUser.DateUpdated)
 }
 
@@ -251,3 +255,79 @@ func TestUpdateUserEmail_Ok(t *testing.T) {
 	require.True(t, updatedUser.EmailVerified)
 	require.NotNil(t, updatedUser.DateUpdated)
 }
+
+func TestUpdateUserEmail_ChangesEmail(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	user.SetEmail("old@example.com", false)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	err = s.UpdateUserEmail(ctx, user.ID, "new@example.com", true)
+	require.NoError(t, err)
+
+	updatedUser, err := s.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, "new@example.com", updatedUser.Email.String)
+	require.True(t, updatedUser.EmailVerified)
+}
+
+func TestUpdateUserEmail_EmailTaken(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user1 := database.NewUser("testuser1", "Test User 1", []byte("hashedpassword"), model.UserRoleName)
+	user1.SetEmail("taken@example.com", true)
+	err := s.CreateUser(ctx, user1)
+	require.NoError(t, err)
+
+	user2 := database.NewUser("testuser2", "Test User 2", []byte("hashedpassword"), model.UserRoleName)
+	err = s.CreateUser(ctx, user2)
+	require.NoError(t, err)
+
+	err = s.UpdateUserEmail(ctx, user2.ID, "taken@example.com", false)
+	require.ErrorIs(t, err, database.ErrUserExists)
+}
+
+func TestUpdateUserEmail_EmailTakenInTx(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user1 := database.NewUser("testuser1", "Test User 1", []byte("hashedpassword"), model.UserRoleName)
+	user1.SetEmail("taken@example.com", true)
+	err := s.CreateUser(ctx, user1)
+	require.NoError(t, err)
+
+	user2 := database.NewUser("testuser2", "Test User 2", []byte("hashedpassword"), model.UserRoleName)
+	user2.SetEmail("old@example.com", true)
+	err = s.CreateUser(ctx, user2)
+	require.NoError(t, err)
+
+	change := database.NewEmailChange(user2.ID, "taken@example.com", "hashedcode123", time.Now())
+	err = s.CreateEmailChange(ctx, change)
+	require.NoError(t, err)
+
+	// transaction stays usable after conflict
+	err = s.RunWithTx(ctx, func(ctx context.Context) error {
+		uErr := s.UpdateUserEmail(ctx, user2.ID, "taken@example.com", true)
+		require.ErrorIs(t, uErr, database.ErrUserExists)
+
+		return s.SetEmailChangeUsed(ctx, change.ID, false)
+	})
+	require.NoError(t, err)
+
+	_, err = s.GetEmailChangeByUserID(ctx, user2.ID)
+	require.ErrorIs(t, err, database.ErrNotFound)
+
+	user, err := s.GetUserByID(ctx, user2.ID)
+	require.NoError(t, err)
+	require.Equal(t, "old@example.com", user.Email.String)
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// UpsertUserTOTP inserts user TOTP record or replaces existing one with a new unconfirmed secret
+func (r *UserRepo) UpsertUserTOTP(ctx context.Context, userTOTP UserTOTP) error {
+	ctx, span := tracer.Start(ctx, "upsertUserTOTP")
+	defer span.End()
+
+	const q = `INSERT INTO user_totp (user_id, secret, confirmed_at, last_used_step, date_created)
+		VALUES ($1, $2, NULL, 0, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret,
+		    confirmed_at = NULL,
+		    last_used_step = 0,
+		    date_created = NOW()`
+
+	_, err := r.query().Exec(ctx, q, userTOTP.UserID, userTOTP.Secret)
+	if err != nil {
+		return fmt.Errorf("upsert user totp: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserTOTP returns user TOTP record by user id with row-level lock
+func (r *UserRepo) GetUserTOTP(ctx context.Context, userID string) (UserTOTP, error) {
+	ctx, span := tracer.Start(ctx, "getUserTOTP")
+	defer span.End()
+
+	const q = `SELECT user_id, secret, confirmed_at, last_used_step, date_created
+		FROM user_totp
+		WHERE user_id = $1
+		FOR UPDATE`
+
+	var userTOTP UserTOTP
+	if err := r.query().Get(ctx, &userTOTP, q, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return UserTOTP{}, ErrNotFound
+		}
+		return UserTOTP{}, fmt.Errorf("select user totp: %w", err)
+	}
+
+	return userTOTP, nil
+}
+
+// ConfirmUserTOTP marks user TOTP as confirmed and stores time step of the code used for confirmation
+func (r *UserRepo) ConfirmUserTOTP(ctx context.Context, userID string, usedStep int64) error {
+	ctx, span := tracer.Start(ctx, "confirmUserTOTP")
+	defer span.End()
+
+	const q = `UPDATE user_totp
+		SET confirmed_at = NOW(), last_used_step = $2
+		WHERE user_id = $1`
+
+	_, err := r.query().Exec(ctx, q, userID, usedStep)
+	if err != nil {
+		return fmt.Errorf("confirm user totp: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserTOTPLastUsedStep sets time step of the last accepted code, so it can't be used again
+func (r *UserRepo) SetUserTOTPLastUsedStep(ctx context.Context, userID string, usedStep int64) error {
+	ctx, span := tracer.Start(ctx, "setUserTOTPLastUsedStep")
+	defer span.End()
+
+	const q = `UPDATE user_totp SET last_used_step = $2 WHERE user_id = $1`
+
+	_, err := r.query().Exec(ctx, q, userID, usedStep)
+	if err != nil {
+		return fmt.Errorf("set user totp last used step: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteUserTOTP deletes user TOTP record
+func (r *UserRepo) DeleteUserTOTP(ctx context.Context, userID string) error {
+	ctx, span := tracer.Start(ctx, "deleteUserTOTP")
+	defer span.End()
+
+	const q = `DELETE FROM user_totp WHERE user_id = $1`
+
+	_, err := r.query().Exec(ctx, q, userID)
+	if err != nil {
+		return fmt.Errorf("delete user totp: %w", err)
+	}
+
+	return nil
+}
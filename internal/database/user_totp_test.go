@@ -0,0 +1,116 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertUserTOTP_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	err = s.UpsertUserTOTP(ctx, database.NewUserTOTP(user.ID, []byte("secret1")))
+	require.NoError(t, err)
+
+	userTOTP, err := s.GetUserTOTP(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret1"), userTOTP.Secret)
+	require.False(t, userTOTP.IsConfirmed())
+
+	err = s.ConfirmUserTOTP(ctx, user.ID, 100)
+	require.NoError(t, err)
+
+	// re-enrollment replaces secret and resets confirmation
+	err = s.UpsertUserTOTP(ctx, database.NewUserTOTP(user.ID, []byte("secret2")))
+	require.NoError(t, err)
+
+	userTOTP, err = s.GetUserTOTP(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret2"), userTOTP.Secret)
+	require.False(t, userTOTP.IsConfirmed())
+	require.Zero(t, userTOTP.LastUsedStep)
+}
+
+func TestConfirmUserTOTP_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+	err = s.UpsertUserTOTP(ctx, database.NewUserTOTP(user.ID, []byte("secret")))
+	require.NoError(t, err)
+
+	err = s.ConfirmUserTOTP(ctx, user.ID, 100)
+	require.NoError(t, err)
+
+	userTOTP, err := s.GetUserTOTP(ctx, user.ID)
+	require.NoError(t, err)
+	require.True(t, userTOTP.IsConfirmed())
+	require.Equal(t, int64(100), userTOTP.LastUsedStep)
+
+	err = s.SetUserTOTPLastUsedStep(ctx, user.ID, 101)
+	require.NoError(t, err)
+
+	userTOTP, err = s.GetUserTOTP(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(101), userTOTP.LastUsedStep)
+}
+
+func TestGetUserTOTP_NotFound(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	_, err := s.GetUserTOTP(context.Background(), "00000000-0000-0000-0000-000000000000")
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestDeleteUserTOTP_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+	err = s.UpsertUserTOTP(ctx, database.NewUserTOTP(user.ID, []byte("secret")))
+	require.NoError(t, err)
+
+	err = s.DeleteUserTOTP(ctx, user.ID)
+	require.NoError(t, err)
+
+	_, err = s.GetUserTOTP(ctx, user.ID)
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestDeleteUser_DeletesUserTOTP(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+	err = s.UpsertUserTOTP(ctx, database.NewUserTOTP(user.ID, []byte("secret")))
+	require.NoError(t, err)
+
+	err = s.DeleteUser(ctx, user.ID)
+	require.NoError(t, err)
+
+	_, err = s.GetUserTOTP(ctx, user.ID)
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
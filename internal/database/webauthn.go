@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CreateWebAuthnCredential inserts a new passkey credential into the database
+func (r *UserRepo) CreateWebAuthnCredential(ctx context.Context, credential WebAuthnCredential) error {
+	ctx, span := tracer.Start(ctx, "createWebAuthnCredential")
+	defer span.End()
+
+	const q = `INSERT INTO webauthn_credentials (id, user_id, credential_id, name, data, date_created)
+		VALUES ($1, $2, $3, $4, $5, NOW())`
+
+	_, err := r.query().Exec(ctx, q, credential.ID, credential.UserID, credential.CredentialID, credential.Name, string(credential.Data))
+	if err != nil {
+		return fmt.Errorf("insert webauthn credential: %w", err)
+	}
+
+	return nil
+}
+
+// GetWebAuthnCredentialsByUserID returns passkey credentials of user ordered by creation date
+func (r *UserRepo) GetWebAuthnCredentialsByUserID(ctx context.Context, userID string) ([]WebAuthnCredential, error) {
+	ctx, span := tracer.Start(ctx, "getWebAuthnCredentialsByUserID")
+	defer span.End()
+
+	const q = `SELECT id, user_id, credential_id, name, data, last_used_at, date_created
+		FROM webauthn_credentials
+		WHERE user_id = $1
+		ORDER BY date_created`
+
+	var credentials []WebAuthnCredential
+	if err := r.query().Select(ctx, &credentials, q, userID); err != nil {
+		return nil, fmt.Errorf("select webauthn credentials: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// UpdateWebAuthnCredentialUsage updates stored credential record after successful sign in and sets last usage time
+func (r *UserRepo) UpdateWebAuthnCredentialUsage(ctx context.Context, id string, data []byte, usedAt time.Time) error {
+	ctx, span := tracer.Start(ctx, "updateWebAuthnCredentialUsage")
+	defer span.End()
+
+	const q = `UPDATE webauthn_credentials
+		SET data = $2, last_used_at = $3
+		WHERE id = $1`
+
+	res, err := r.query().Exec(ctx, q, id, string(data), usedAt)
+	if err != nil {
+		return fmt.Errorf("update webauthn credential usage: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get affected rows: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteWebAuthnCredential deletes passkey credential of user. Returns ErrNotFound if user has no such credential
+func (r *UserRepo) DeleteWebAuthnCredential(ctx context.Context, userID, id string) error {
+	ctx, span := tracer.Start(ctx, "deleteWebAuthnCredential")
+	defer span.End()
+
+	const q = `DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2`
+
+	res, err := r.query().Exec(ctx, q, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete webauthn credential: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get affected rows: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// CreateWebAuthnSession inserts a new passkey ceremony session into the database
+func (r *UserRepo) CreateWebAuthnSession(ctx context.Context, session WebAuthnSession) error {
+	ctx, span := tracer.Start(ctx, "createWebAuthnSession")
+	defer span.End()
+
+	const q = `INSERT INTO webauthn_sessions (id, user_id, kind, data, expires_at, date_created)
+		VALUES ($1, $2, $3, $4, $5, NOW())`
+
+	_, err := r.query().Exec(ctx, q, session.ID, session.UserID, session.Kind, string(session.Data), session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("insert webauthn session: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeWebAuthnSession deletes passkey ceremony session of specified kind and returns it, so it can be used only once
+func (r *UserRepo) ConsumeWebAuthnSession(ctx context.Context, id, kind string) (WebAuthnSession, error) {
+	ctx, span := tracer.Start(ctx, "consumeWebAuthnSession")
+	defer span.End()
+
+	const q = `DELETE FROM webauthn_sessions
+		WHERE id = $1 AND kind = $2
+		RETURNING id, user_id, kind, data, expires_at, date_created`
+
+	var session WebAuthnSession
+	if err := r.query().Get(ctx, &session, q, id, kind); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return WebAuthnSession{}, ErrNotFound
+		}
+		return WebAuthnSession{}, fmt.Errorf("delete webauthn session: %w", err)
+	}
+
+	return session, nil
+}
+
+// DeleteExpiredWebAuthnSessions deletes all expired passkey ceremony sessions
+func (r *UserRepo) DeleteExpiredWebAuthnSessions(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "deleteExpiredWebAuthnSessions")
+	defer span.End()
+
+	const q = `DELETE FROM webauthn_sessions WHERE expires_at <= NOW()`
+
+	_, err := r.query().Exec(ctx, q)
+	if err != nil {
+		return fmt.Errorf("delete expired webauthn sessions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebAuthnCredential_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	credential := database.NewWebAuthnCredential(user.ID, []byte("credential-id"), "My laptop", []byte(`{"signCount":0}`))
+	err = s.CreateWebAuthnCredential(ctx, credential)
+	require.NoError(t, err)
+
+	credentials, err := s.GetWebAuthnCredentialsByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, credentials, 1)
+	require.Equal(t, credential.ID, credentials[0].ID)
+	require.Equal(t, []byte("credential-id"), credentials[0].CredentialID)
+	require.Equal(t, "My laptop", credentials[0].Name)
+	require.False(t, credentials[0].LastUsedAt.Valid)
+
+	err = s.UpdateWebAuthnCredentialUsage(ctx, credential.ID, []byte(`{"signCount":1}`), time.Now())
+	require.NoError(t, err)
+
+	credentials, err = s.GetWebAuthnCredentialsByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, credentials, 1)
+	require.JSONEq(t, `{"signCount":1}`, string(credentials[0].Data))
+	require.True(t, credentials[0].LastUsedAt.Valid)
+}
+
+func TestCreateWebAuthnCredential_DuplicateCredentialID(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	err = s.CreateWebAuthnCredential(ctx, database.NewWebAuthnCredential(user.ID, []byte("credential-id"), "Key 1", []byte(`{}`)))
+	require.NoError(t, err)
+
+	err = s.CreateWebAuthnCredential(ctx, database.NewWebAuthnCredential(user.ID, []byte("credential-id"), "Key 2", []byte(`{}`)))
+	require.Error(t, err)
+}
+
+func TestDeleteWebAuthnCredential_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	user := database.NewUser("testuser", "Test User", []byte("hashedpassword"), model.UserRoleName)
+	err := s.CreateUser(ctx, user)
+	require.NoError(t, err)
+	otherUser := database.NewUser("otheruser", "Other User", []byte("hashedpassword"), model.UserRoleName)
+	err = s.CreateUser(ctx, otherUser)
+	require.NoError(t, err)
+
+	credential := database.NewWebAuthnCredential(user.ID, []byte("credential-id"), "My laptop", []byte(`{}`))
+	err = s.CreateWebAuthnCredential(ctx, credential)
+	require.NoError(t, err)
+
+	// credential of other user can't be deleted
+	err = s.DeleteWebAuthnCredential(ctx, otherUser.ID, credential.ID)
+	require.ErrorIs(t, err, database.ErrNotFound)
+
+	err = s.DeleteWebAuthnCredential(ctx, user.ID, credential.ID)
+	require.NoError(t, err)
+
+	credentials, err := s.GetWebAuthnCredentialsByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Empty(t, credentials)
+}
+
+func TestConsumeWebAuthnSession_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	session := database.NewWebAuthnSession("", model.WebAuthnSessionKindSignIn, []byte(`{"challenge":"abc"}`), time.Now().Add(time.Minute))
+	err := s.CreateWebAuthnSession(ctx, session)
+	require.NoError(t, err)
+
+	// session of other kind is not returned
+	_, err = s.ConsumeWebAuthnSession(ctx, session.ID, model.WebAuthnSessionKindRegistration)
+	require.ErrorIs(t, err, database.ErrNotFound)
+
+	consumed, err := s.ConsumeWebAuthnSession(ctx, session.ID, model.WebAuthnSessionKindSignIn)
+	require.NoError(t, err)
+	require.False(t, consumed.UserID.Valid)
+	require.JSONEq(t, `{"challenge":"abc"}`, string(consumed.Data))
+
+	// session can be used only once
+	_, err = s.ConsumeWebAuthnSession(ctx, session.ID, model.WebAuthnSessionKindSignIn)
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestDeleteExpiredWebAuthnSessions_Ok(t *testing.T) {
+	s := setup(t)
+	defer teardown(t)
+
+	ctx := context.Background()
+
+	expired := database.NewWebAuthnSession("", model.WebAuthnSessionKindSignIn, []byte(`{}`), time.Now().Add(-time.Minute))
+	err := s.CreateWebAuthnSession(ctx, expired)
+	require.NoError(t, err)
+	active := database.NewWebAuthnSession("", model.WebAuthnSessionKindSignIn, []byte(`{}`), time.Now().Add(time.Minute))
+	err = s.CreateWebAuthnSession(ctx, active)
+	require.NoError(t, err)
+
+	err = s.DeleteExpiredWebAuthnSessions(ctx)
+	require.NoError(t, err)
+
+	_, err = s.ConsumeWebAuthnSession(ctx, expired.ID, model.WebAuthnSessionKindSignIn)
+	require.ErrorIs(t, err, database.ErrNotFound)
+	_, err = s.ConsumeWebAuthnSession(ctx, active.ID, model.WebAuthnSessionKindSignIn)
+	require.NoError(t, err)
+}
@@ -0,0 +1,258 @@
+package facade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// errors
+var (
+	ErrChangeEmailSameAddress      = errors.New("change email: new email is the same as current")
+	ErrChangeEmailTaken            = errors.New("change email: email is already in use")
+	ErrChangeEmailInvalidOrExpired = errors.New("change email: invalid or expired code")
+	ErrChangeEmailTooManyAttempts  = errors.New("change email: too many failed attempts")
+	ErrChangeEmailNotAllowed       = errors.New("change email: not available for oauth users")
+	ErrChangeEmailInvalidPassword  = errors.New("change email: invalid password")
+	ErrChangeEmailCodeRequired     = errors.New("change email: two-factor code is required")
+)
+
+// RequestEmailChange sends code confirming new email address to the new address.
+// Email is changed only after the code is confirmed with ConfirmEmailChange.
+// User has to confirm current password, and two-factor code if two-factor authentication is enabled. Code may be a recovery code.
+// Returns ErrChangeEmailTaken if email belongs to another user and TooManyRequestsError if code was sent recently
+func (p *Provider) RequestEmailChange(ctx context.Context, userID, newEmail, password, code string) error {
+	user, err := p.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		p.log.Error("get user by id", zap.String("userID", userID), zap.Error(err))
+		return err
+	}
+
+	// access token alone is not enough to take over account by its email
+	usedRecoveryCode, err := p.reauthenticateEmailChange(ctx, user, password, code)
+	if err != nil {
+		return err
+	}
+	if usedRecoveryCode {
+		p.notifyRecoveryCodeUsed(ctx, user, "")
+	}
+
+	// unique index on email is case-sensitive, new address is stored in lower case
+	newEmail = strings.ToLower(strings.TrimSpace(newEmail))
+
+	if user.Email.Valid && strings.EqualFold(user.Email.String, newEmail) {
+		return ErrChangeEmailSameAddress
+	}
+
+	// check email uniqueness. Uniqueness is checked again when email is changed
+	_, err = p.userRepo.GetUserByEmail(ctx, newEmail)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		p.log.Error("get user by email", zap.String("userID", userID), zap.Error(err))
+		return err
+	} else if err == nil {
+		return ErrChangeEmailTaken
+	}
+
+	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		// check if code was already sent
+		change, err := p.userRepo.GetEmailChangeByUserID(ctx, user.ID)
+		if err != nil && !errors.Is(err, database.ErrNotFound) {
+			return fmt.Errorf("get email change record: %w", err)
+		}
+		if err == nil {
+			if err = checkResendCooldown(change.DateCreated, model.ResendEmailChangeCodeCooldown); err != nil {
+				return err
+			}
+
+			// previous request is replaced by a new one
+			if err = p.userRepo.SetEmailChangeUsed(ctx, change.ID, false); err != nil {
+				return fmt.Errorf("clear email change: %w", err)
+			}
+		}
+
+		code, codeHash, err := generateHashedCode(emailChangeCodeLen, numericCodeChars)
+		if err != nil {
+			return err
+		}
+
+		change = database.NewEmailChange(user.ID, newEmail, codeHash, time.Now())
+		if err = p.userRepo.CreateEmailChange(ctx, change); err != nil {
+			return fmt.Errorf("create email change record: %w", err)
+		}
+
+		// queue email change code. Email is sent by outbox dispatcher after transaction is committed
+		err = p.enqueueEmail(ctx, model.EmailKindEmailChange, user.ID, newEmail, change.ID, mailer.SendEmailChangeRequest{
+			Email:            newEmail,
+			Username:         user.Username,
+			Locale:           user.Locale,
+			VerificationCode: code,
+			CodeTTL:          model.EmailChangeCodeTTL,
+		})
+		if err != nil {
+			return fmt.Errorf("queue email change code: %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		if AsTooManyRequestsError(txErr) == nil {
+			p.log.Error("request email change", zap.String("userID", user.ID), zap.Error(txErr))
+		}
+		return txErr
+	}
+
+	return nil
+}
+
+// checks current password and two-factor code of user requesting email change. Returns true if recovery code was used
+func (p *Provider) reauthenticateEmailChange(ctx context.Context, user database.User, password, code string) (bool, error) {
+	if user.OAuthProvider.Valid {
+		return false, ErrChangeEmailNotAllowed
+	}
+	if err := bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(password)); err != nil {
+		return false, ErrChangeEmailInvalidPassword
+	}
+
+	userTOTP, err := p.userRepo.GetUserTOTP(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return false, nil
+		}
+		p.log.Error("get user totp", zap.String("userID", user.ID), zap.Error(err))
+		return false, err
+	}
+	if !userTOTP.IsConfirmed() {
+		return false, nil
+	}
+	if code == "" {
+		return false, ErrChangeEmailCodeRequired
+	}
+
+	return p.verifySecondFactor(ctx, userTOTP, code)
+}
+
+// ConfirmEmailChange changes user email to the address code was sent to by RequestEmailChange. New email is verified.
+// Security notice is sent to the previous verified address.
+// Code is invalidated after too many failed attempts and ErrChangeEmailTooManyAttempts is returned
+func (p *Provider) ConfirmEmailChange(ctx context.Context, userID, code string) (model.User, error) {
+	var user database.User
+	// failed confirmation result. Transaction is committed to persist failed attempts and invalidated codes
+	var changeErr error
+
+	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		user, err = p.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			p.log.Error("get user by id", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+
+		change, err := p.userRepo.GetEmailChangeByUserID(ctx, user.ID)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				changeErr = ErrChangeEmailInvalidOrExpired
+				return nil
+			}
+			p.log.Error("get email change", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+
+		if change.IsExpired() {
+			if err = p.userRepo.SetEmailChangeUsed(ctx, change.ID, false); err != nil {
+				p.log.Error("clear expired email change", zap.String("changeID", change.ID), zap.Error(err))
+				return err
+			}
+			changeErr = ErrChangeEmailInvalidOrExpired
+			return nil
+		}
+
+		// compare codes
+		if err = bcrypt.CompareHashAndPassword([]byte(change.CodeHash.String), []byte(code)); err != nil {
+			invalidated, aErr := p.registerFailedEmailChangeAttempt(ctx, change.ID)
+			if aErr != nil {
+				return aErr
+			}
+			changeErr = ErrChangeEmailInvalidOrExpired
+			if invalidated {
+				changeErr = ErrChangeEmailTooManyAttempts
+			}
+			return nil
+		}
+
+		// change email. Unique index on email rejects address taken after the code was sent.
+		// Repo rolls back to savepoint on conflict so pending change can still be cleared in this transaction
+		if err = p.userRepo.UpdateUserEmail(ctx, user.ID, change.NewEmail, true); err != nil {
+			if !errors.Is(err, database.ErrUserExists) {
+				p.log.Error("update user email", zap.String("userID", userID), zap.Error(err))
+				return err
+			}
+			if err = p.userRepo.SetEmailChangeUsed(ctx, change.ID, false); err != nil {
+				p.log.Error("clear email change", zap.String("changeID", change.ID), zap.Error(err))
+				return err
+			}
+			changeErr = ErrChangeEmailTaken
+			return nil
+		}
+
+		if err = p.userRepo.SetEmailChangeUsed(ctx, change.ID, true); err != nil {
+			p.log.Error("mark email change used", zap.String("changeID", change.ID), zap.Error(err))
+			return err
+		}
+
+		// codes and links sent to the previous address must not work after the change
+		if err = p.userRepo.InvalidateEmailSignIns(ctx, user.ID); err != nil {
+			p.log.Error("invalidate email sign ins", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+		if err = p.userRepo.InvalidateEmailVerifications(ctx, user.ID); err != nil {
+			p.log.Error("invalidate email verifications", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+
+		// notify previous address
+		err = p.onSecurityEvent(ctx, user, model.SecurityEvent{Type: model.SecurityEventEmailChanged, NewEmail: change.NewEmail})
+		if err != nil {
+			return err
+		}
+
+		user.SetEmail(change.NewEmail, true)
+
+		return nil
+	})
+	if txErr != nil {
+		return model.User{}, txErr
+	}
+	if changeErr != nil {
+		return model.User{}, changeErr
+	}
+
+	p.log.Info("user email changed", zap.String("userID", userID))
+	return mapDBUserToUser(user), nil
+}
+
+// counts failed attempt to enter email change code. Returns true if code was invalidated after too many failed attempts
+func (p *Provider) registerFailedEmailChangeAttempt(ctx context.Context, changeID string) (bool, error) {
+	failedAttempts, err := p.userRepo.IncrementEmailChangeFailedAttempts(ctx, changeID)
+	if err != nil {
+		p.log.Error("increment email change failed attempts", zap.String("changeID", changeID), zap.Error(err))
+		return false, err
+	}
+	if failedAttempts < model.MaxEmailChangeCodeAttempts {
+		return false, nil
+	}
+
+	if err = p.userRepo.SetEmailChangeUsed(ctx, changeID, false); err != nil {
+		p.log.Error("invalidate email change", zap.String("changeID", changeID), zap.Error(err))
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,417 @@
+package facade_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestProvider_RequestEmailChange(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+
+	user := database.User{
+		ID:            "user-123",
+		Username:      "testuser",
+		Email:         sql.NullString{String: "old@example.com", Valid: true},
+		EmailVerified: true,
+		PasswordHash:  passwordHash,
+		Role:          model.UserRoleName,
+	}
+
+	t.Run("successful request", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetUserTOTP(ctx, "user-123").Return(database.UserTOTP{}, database.ErrNotFound)
+		mockUserRepo.EXPECT().GetUserByEmail(ctx, "new@example.com").Return(database.User{}, database.ErrNotFound)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetEmailChangeByUserID(ctx, "user-123").Return(database.EmailChange{}, database.ErrNotFound)
+
+		var stored database.EmailChange
+		mockUserRepo.EXPECT().
+			CreateEmailChange(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, change database.EmailChange) error {
+				stored = change
+				return nil
+			})
+
+		var sent mailer.SendEmailChangeRequest
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), gomock.Any()).Return(false, nil)
+		mockUserRepo.EXPECT().
+			CreateEmailOutboxMessage(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg database.EmailOutboxMessage) error {
+				if msg.Kind != model.EmailKindEmailChange || msg.Recipient != "new@example.com" || msg.ReferenceID.String != stored.ID {
+					t.Errorf("unexpected queued email %q to %q (%q)", msg.Kind, msg.Recipient, msg.ReferenceID.String)
+				}
+				decodeOutboxPayload(t, msg, &sent)
+				return nil
+			})
+
+		err := provider.RequestEmailChange(ctx, "user-123", "new@example.com", "password123", "")
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if sent.Email != "new@example.com" || sent.Username != "testuser" {
+			t.Errorf("unexpected email recipient %q (%q)", sent.Email, sent.Username)
+		}
+		if stored.UserID != "user-123" || stored.NewEmail != "new@example.com" {
+			t.Errorf("unexpected email change record of %q to %q", stored.UserID, stored.NewEmail)
+		}
+		if err = bcrypt.CompareHashAndPassword([]byte(stored.CodeHash.String), []byte(sent.VerificationCode)); err != nil {
+			t.Errorf("expected stored code hash to match sent code: %v", err)
+		}
+	})
+
+	t.Run("same email", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetUserTOTP(ctx, "user-123").Return(database.UserTOTP{}, database.ErrNotFound)
+
+		err := provider.RequestEmailChange(ctx, "user-123", "OLD@example.com", "password123", "")
+
+		if !errors.Is(err, facade.ErrChangeEmailSameAddress) {
+			t.Fatalf("expected ErrChangeEmailSameAddress, got %v", err)
+		}
+	})
+
+	t.Run("email taken", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetUserTOTP(ctx, "user-123").Return(database.UserTOTP{}, database.ErrNotFound)
+		mockUserRepo.EXPECT().GetUserByEmail(ctx, "new@example.com").Return(database.User{ID: "user-456"}, nil)
+
+		err := provider.RequestEmailChange(ctx, "user-123", " New@Example.com", "password123", "")
+
+		if !errors.Is(err, facade.ErrChangeEmailTaken) {
+			t.Fatalf("expected ErrChangeEmailTaken, got %v", err)
+		}
+	})
+
+	t.Run("code sent recently", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetUserTOTP(ctx, "user-123").Return(database.UserTOTP{}, database.ErrNotFound)
+		mockUserRepo.EXPECT().GetUserByEmail(ctx, "new@example.com").Return(database.User{}, database.ErrNotFound)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetEmailChangeByUserID(ctx, "user-123").Return(database.EmailChange{
+			ID:          "change-old",
+			DateCreated: time.Now().Add(-10 * time.Second),
+		}, nil)
+
+		err := provider.RequestEmailChange(ctx, "user-123", "new@example.com", "password123", "")
+
+		if facade.AsTooManyRequestsError(err) == nil {
+			t.Fatalf("expected TooManyRequestsError, got %v", err)
+		}
+	})
+
+	t.Run("previous request replaced after cooldown", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetUserTOTP(ctx, "user-123").Return(database.UserTOTP{}, database.ErrNotFound)
+		mockUserRepo.EXPECT().GetUserByEmail(ctx, "new@example.com").Return(database.User{}, database.ErrNotFound)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetEmailChangeByUserID(ctx, "user-123").Return(database.EmailChange{
+			ID:          "change-old",
+			DateCreated: time.Now().Add(-model.ResendEmailChangeCodeCooldown - time.Second),
+		}, nil)
+		mockUserRepo.EXPECT().SetEmailChangeUsed(ctx, "change-old", false).Return(nil)
+		mockUserRepo.EXPECT().CreateEmailChange(ctx, gomock.Any()).Return(nil)
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), gomock.Any()).Return(false, nil)
+		mockUserRepo.EXPECT().CreateEmailOutboxMessage(ctx, gomock.Any()).Return(nil)
+
+		err := provider.RequestEmailChange(ctx, "user-123", "new@example.com", "password123", "")
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("invalid password", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+
+		err := provider.RequestEmailChange(ctx, "user-123", "new@example.com", "wrong-password", "")
+
+		if !errors.Is(err, facade.ErrChangeEmailInvalidPassword) {
+			t.Fatalf("expected ErrChangeEmailInvalidPassword, got %v", err)
+		}
+	})
+
+	t.Run("oauth user", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		oauthUser := user
+		oauthUser.PasswordHash = nil
+		oauthUser.SetOAuthID(model.GoogleAuthTokenProvider, "oauth-123")
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(oauthUser, nil)
+
+		err := provider.RequestEmailChange(ctx, "user-123", "new@example.com", "password123", "")
+
+		if !errors.Is(err, facade.ErrChangeEmailNotAllowed) {
+			t.Fatalf("expected ErrChangeEmailNotAllowed, got %v", err)
+		}
+	})
+
+	t.Run("two-factor code required", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, _ := newTestUserTOTP(t, "user-123", true)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetUserTOTP(ctx, "user-123").Return(userTOTP, nil)
+
+		err := provider.RequestEmailChange(ctx, "user-123", "new@example.com", "password123", "")
+
+		if !errors.Is(err, facade.ErrChangeEmailCodeRequired) {
+			t.Fatalf("expected ErrChangeEmailCodeRequired, got %v", err)
+		}
+	})
+
+	t.Run("invalid two-factor code", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, _ := newTestUserTOTP(t, "user-123", true)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetUserTOTP(ctx, "user-123").Return(userTOTP, nil)
+
+		err := provider.RequestEmailChange(ctx, "user-123", "new@example.com", "password123", "000000")
+
+		if !errors.Is(err, facade.ErrTwoFactorInvalidCode) {
+			t.Fatalf("expected ErrTwoFactorInvalidCode, got %v", err)
+		}
+	})
+
+	t.Run("valid two-factor code", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, secret := newTestUserTOTP(t, "user-123", true)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetUserTOTP(ctx, "user-123").Return(userTOTP, nil)
+		mockUserRepo.EXPECT().SetUserTOTPLastUsedStep(ctx, "user-123", gomock.Any()).Return(nil)
+		mockUserRepo.EXPECT().GetUserByEmail(ctx, "new@example.com").Return(database.User{ID: "user-456"}, nil)
+
+		err := provider.RequestEmailChange(ctx, "user-123", "new@example.com", "password123", generateTestTOTPCode(t, secret))
+
+		// request passes re-authentication
+		if !errors.Is(err, facade.ErrChangeEmailTaken) {
+			t.Fatalf("expected ErrChangeEmailTaken, got %v", err)
+		}
+	})
+}
+
+func TestProvider_ConfirmEmailChange(t *testing.T) {
+	ctx := context.Background()
+
+	user := database.User{
+		ID:            "user-123",
+		Username:      "testuser",
+		Email:         sql.NullString{String: "old@example.com", Valid: true},
+		EmailVerified: true,
+		Role:          model.UserRoleName,
+	}
+
+	newChange := func(t *testing.T, code string) database.EmailChange {
+		t.Helper()
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.MinCost)
+		if err != nil {
+			t.Fatalf("hash code: %v", err)
+		}
+		return database.EmailChange{
+			ID:          "change-123",
+			UserID:      "user-123",
+			NewEmail:    "new@example.com",
+			CodeHash:    sql.NullString{String: string(hash), Valid: true},
+			DateCreated: time.Now(),
+		}
+	}
+
+	t.Run("successful change", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetEmailChangeByUserID(ctx, "user-123").Return(newChange(t, "123456"), nil)
+		mockUserRepo.EXPECT().UpdateUserEmail(ctx, "user-123", "new@example.com", true).Return(nil)
+		mockUserRepo.EXPECT().SetEmailChangeUsed(ctx, "change-123", true).Return(nil)
+		mockUserRepo.EXPECT().InvalidateEmailSignIns(ctx, "user-123").Return(nil)
+		mockUserRepo.EXPECT().InvalidateEmailVerifications(ctx, "user-123").Return(nil)
+
+		var notice mailer.SendEmailChangedRequest
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), gomock.Any()).Return(false, nil)
+		mockUserRepo.EXPECT().
+			CreateEmailOutboxMessage(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg database.EmailOutboxMessage) error {
+				if msg.Kind != model.EmailKindEmailChanged || msg.Recipient != "old@example.com" {
+					t.Errorf("unexpected queued email %q to %q", msg.Kind, msg.Recipient)
+				}
+				decodeOutboxPayload(t, msg, &notice)
+				return nil
+			})
+
+		changed, err := provider.ConfirmEmailChange(ctx, "user-123", "123456")
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if changed.Email != "new@example.com" || !changed.EmailVerified {
+			t.Errorf("expected verified new email, got %q (verified %t)", changed.Email, changed.EmailVerified)
+		}
+		if notice.Email != "old@example.com" || notice.NewEmail != "new@example.com" {
+			t.Errorf("unexpected notice to %q about %q", notice.Email, notice.NewEmail)
+		}
+	})
+
+	t.Run("unverified previous email is not notified", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		unverifiedUser := user
+		unverifiedUser.EmailVerified = false
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(unverifiedUser, nil)
+		mockUserRepo.EXPECT().GetEmailChangeByUserID(ctx, "user-123").Return(newChange(t, "123456"), nil)
+		mockUserRepo.EXPECT().UpdateUserEmail(ctx, "user-123", "new@example.com", true).Return(nil)
+		mockUserRepo.EXPECT().SetEmailChangeUsed(ctx, "change-123", true).Return(nil)
+		mockUserRepo.EXPECT().InvalidateEmailSignIns(ctx, "user-123").Return(nil)
+		mockUserRepo.EXPECT().InvalidateEmailVerifications(ctx, "user-123").Return(nil)
+
+		_, err := provider.ConfirmEmailChange(ctx, "user-123", "123456")
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("invalidate pending codes error", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		dbErr := errors.New("db error")
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetEmailChangeByUserID(ctx, "user-123").Return(newChange(t, "123456"), nil)
+		mockUserRepo.EXPECT().UpdateUserEmail(ctx, "user-123", "new@example.com", true).Return(nil)
+		mockUserRepo.EXPECT().SetEmailChangeUsed(ctx, "change-123", true).Return(nil)
+		mockUserRepo.EXPECT().InvalidateEmailSignIns(ctx, "user-123").Return(nil)
+		mockUserRepo.EXPECT().InvalidateEmailVerifications(ctx, "user-123").Return(dbErr)
+
+		_, err := provider.ConfirmEmailChange(ctx, "user-123", "123456")
+
+		// change is rolled back with the rest of the transaction
+		if !errors.Is(err, dbErr) {
+			t.Fatalf("expected db error, got %v", err)
+		}
+	})
+
+	t.Run("no pending change", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetEmailChangeByUserID(ctx, "user-123").Return(database.EmailChange{}, database.ErrNotFound)
+
+		_, err := provider.ConfirmEmailChange(ctx, "user-123", "123456")
+
+		if !errors.Is(err, facade.ErrChangeEmailInvalidOrExpired) {
+			t.Fatalf("expected ErrChangeEmailInvalidOrExpired, got %v", err)
+		}
+	})
+
+	t.Run("expired code", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		change := newChange(t, "123456")
+		change.DateCreated = time.Now().Add(-model.EmailChangeCodeTTL - time.Minute)
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetEmailChangeByUserID(ctx, "user-123").Return(change, nil)
+		mockUserRepo.EXPECT().SetEmailChangeUsed(ctx, "change-123", false).Return(nil)
+
+		_, err := provider.ConfirmEmailChange(ctx, "user-123", "123456")
+
+		if !errors.Is(err, facade.ErrChangeEmailInvalidOrExpired) {
+			t.Fatalf("expected ErrChangeEmailInvalidOrExpired, got %v", err)
+		}
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetEmailChangeByUserID(ctx, "user-123").Return(newChange(t, "123456"), nil)
+		mockUserRepo.EXPECT().IncrementEmailChangeFailedAttempts(ctx, "change-123").Return(1, nil)
+
+		_, err := provider.ConfirmEmailChange(ctx, "user-123", "654321")
+
+		if !errors.Is(err, facade.ErrChangeEmailInvalidOrExpired) {
+			t.Fatalf("expected ErrChangeEmailInvalidOrExpired, got %v", err)
+		}
+	})
+
+	t.Run("too many failed attempts", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetEmailChangeByUserID(ctx, "user-123").Return(newChange(t, "123456"), nil)
+		mockUserRepo.EXPECT().IncrementEmailChangeFailedAttempts(ctx, "change-123").Return(model.MaxEmailChangeCodeAttempts, nil)
+		mockUserRepo.EXPECT().SetEmailChangeUsed(ctx, "change-123", false).Return(nil)
+
+		_, err := provider.ConfirmEmailChange(ctx, "user-123", "654321")
+
+		if !errors.Is(err, facade.ErrChangeEmailTooManyAttempts) {
+			t.Fatalf("expected ErrChangeEmailTooManyAttempts, got %v", err)
+		}
+	})
+
+	t.Run("email taken after code was sent", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetEmailChangeByUserID(ctx, "user-123").Return(newChange(t, "123456"), nil)
+		mockUserRepo.EXPECT().UpdateUserEmail(ctx, "user-123", "new@example.com", true).Return(database.ErrUserExists)
+		mockUserRepo.EXPECT().SetEmailChangeUsed(ctx, "change-123", false).Return(nil)
+
+		_, err := provider.ConfirmEmailChange(ctx, "user-123", "123456")
+
+		if !errors.Is(err, facade.ErrChangeEmailTaken) {
+			t.Fatalf("expected ErrChangeEmailTaken, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package facade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/zap"
+)
+
+// errors
+var (
+	ErrEmailDeliveryStatusNoEmail = errors.New("email delivery status: user has no email")
+)
+
+// RecordEmailDeliveryEvent records delivery event reported by email provider and updates status of the sent email message.
+// Hard-bounced and complained addresses are unsubscribed, so no more emails are sent to them.
+// Repeated deliveries of the same event are ignored
+func (p *Provider) RecordEmailDeliveryEvent(ctx context.Context, event model.EmailDeliveryEvent) error {
+	return p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		created, err := p.userRepo.CreateEmailDeliveryEvent(ctx, database.NewEmailDeliveryEvent(event.EventID, event.MessageID, event.Email,
+			event.Status, event.Detail, event.OccurredAt))
+		if err != nil {
+			p.log.Error("create email delivery event", zap.String("messageID", event.MessageID), zap.Error(err))
+			return err
+		}
+		if !created {
+			return nil
+		}
+
+		if err = p.userRepo.SetEmailMessageStatus(ctx, event.MessageID, event.Status, event.OccurredAt); err != nil {
+			p.log.Error("set email message status", zap.String("messageID", event.MessageID), zap.Error(err))
+			return fmt.Errorf("set email message status: %w", err)
+		}
+
+		var reason string
+		switch {
+		case event.Status == model.EmailDeliveryStatusComplained:
+			reason = model.UnsubscribeReasonComplaint
+		case event.Status == model.EmailDeliveryStatusBounced && event.HardBounce:
+			reason = model.UnsubscribeReasonHardBounce
+		default:
+			return nil
+		}
+
+		if err = p.userRepo.CreateEmailUnsubscribe(ctx, database.NewEmailUnsubscribe(event.Email, reason)); err != nil {
+			p.log.Error("create email unsubscribe", zap.String("messageID", event.MessageID), zap.Error(err))
+			return fmt.Errorf("create email unsubscribe: %w", err)
+		}
+
+		p.log.Info("email unsubscribed", zap.String("email", event.Email), zap.String("reason", reason))
+		return nil
+	})
+}
+
+// GetEmailDeliveryStatus returns delivery status of user email address.
+// Returns ErrEmailDeliveryStatusNoEmail if user has no email
+func (p *Provider) GetEmailDeliveryStatus(ctx context.Context, userID string) (model.EmailDeliveryStatus, error) {
+	user, err := p.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		p.log.Error("get user by id", zap.String("userID", userID), zap.Error(err))
+		return model.EmailDeliveryStatus{}, err
+	}
+
+	if !user.Email.Valid {
+		return model.EmailDeliveryStatus{}, ErrEmailDeliveryStatusNoEmail
+	}
+
+	status := model.EmailDeliveryStatus{
+		Email: user.Email.String,
+	}
+
+	event, err := p.userRepo.GetLatestEmailDeliveryEvent(ctx, user.Email.String)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		p.log.Error("get latest email delivery event", zap.String("userID", userID), zap.Error(err))
+		return model.EmailDeliveryStatus{}, err
+	}
+	if err == nil {
+		status.Status = event.Status
+		status.StatusAt = &event.OccurredAt
+	}
+
+	unsubscribe, err := p.userRepo.GetEmailUnsubscribe(ctx, user.Email.String)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		p.log.Error("get email unsubscribe", zap.String("userID", userID), zap.Error(err))
+		return model.EmailDeliveryStatus{}, err
+	}
+	if err == nil {
+		status.Unsubscribed = true
+		status.UnsubscribeReason = unsubscribe.Reason
+	}
+
+	return status, nil
+}
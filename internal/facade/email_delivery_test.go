@@ -0,0 +1,151 @@
+package facade_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProvider_RecordEmailDeliveryEvent(t *testing.T) {
+	ctx := context.Background()
+	event := model.EmailDeliveryEvent{
+		EventID:    "msg_123",
+		MessageID:  "message-id-123",
+		Email:      "test@example.com",
+		OccurredAt: time.Now(),
+	}
+
+	tests := []struct {
+		name           string
+		status         string
+		hardBounce     bool
+		created        bool
+		expectedReason string
+	}{
+		{name: "delivered", status: model.EmailDeliveryStatusDelivered, created: true},
+		{name: "soft bounce", status: model.EmailDeliveryStatusBounced, created: true},
+		{name: "hard bounce", status: model.EmailDeliveryStatusBounced, hardBounce: true, created: true, expectedReason: model.UnsubscribeReasonHardBounce},
+		{name: "complaint", status: model.EmailDeliveryStatusComplained, created: true, expectedReason: model.UnsubscribeReasonComplaint},
+		{name: "repeated complaint", status: model.EmailDeliveryStatusComplained},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, mockUserRepo, _, _, ctrl := setupTest(t)
+			defer ctrl.Finish()
+
+			ev := event
+			ev.Status = tt.status
+			ev.HardBounce = tt.hardBounce
+
+			expectTx(mockUserRepo)
+			mockUserRepo.EXPECT().
+				CreateEmailDeliveryEvent(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, e database.EmailDeliveryEvent) (bool, error) {
+					if e.EventID != "msg_123" || e.MessageID != "message-id-123" || e.Status != tt.status {
+						t.Errorf("unexpected delivery event: %+v", e)
+					}
+					return tt.created, nil
+				})
+			if tt.created {
+				mockUserRepo.EXPECT().SetEmailMessageStatus(gomock.Any(), "message-id-123", tt.status, event.OccurredAt).Return(nil)
+			}
+			if tt.expectedReason != "" {
+				mockUserRepo.EXPECT().
+					CreateEmailUnsubscribe(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, u database.EmailUnsubscribe) error {
+						if u.Email != "test@example.com" || u.Reason != tt.expectedReason {
+							t.Errorf("unexpected unsubscribe %q (%q)", u.Email, u.Reason)
+						}
+						return nil
+					})
+			}
+
+			if err := provider.RecordEmailDeliveryEvent(ctx, ev); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		})
+	}
+
+	t.Run("database error", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		dbErr := errors.New("database error")
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().CreateEmailDeliveryEvent(gomock.Any(), gomock.Any()).Return(false, dbErr)
+
+		if err := provider.RecordEmailDeliveryEvent(ctx, event); !errors.Is(err, dbErr) {
+			t.Errorf("expected database error, got %v", err)
+		}
+	})
+}
+
+func TestProvider_GetEmailDeliveryStatus(t *testing.T) {
+	ctx := context.Background()
+	user := database.User{
+		ID:       "user-123",
+		Username: "testuser",
+		Email:    sql.NullString{String: "test@example.com", Valid: true},
+	}
+
+	t.Run("bounced and unsubscribed", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		occurredAt := time.Now()
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().
+			GetLatestEmailDeliveryEvent(ctx, "test@example.com").
+			Return(database.EmailDeliveryEvent{Status: model.EmailDeliveryStatusBounced, OccurredAt: occurredAt}, nil)
+		mockUserRepo.EXPECT().
+			GetEmailUnsubscribe(ctx, "test@example.com").
+			Return(database.EmailUnsubscribe{Email: "test@example.com", Reason: model.UnsubscribeReasonHardBounce}, nil)
+
+		status, err := provider.GetEmailDeliveryStatus(ctx, "user-123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if status.Status != model.EmailDeliveryStatusBounced || status.StatusAt == nil || !status.StatusAt.Equal(occurredAt) {
+			t.Errorf("unexpected delivery status %q at %v", status.Status, status.StatusAt)
+		}
+		if !status.Unsubscribed || status.UnsubscribeReason != model.UnsubscribeReasonHardBounce {
+			t.Errorf("expected unsubscribed with hard bounce reason, got %v (%q)", status.Unsubscribed, status.UnsubscribeReason)
+		}
+	})
+
+	t.Run("no events", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetLatestEmailDeliveryEvent(ctx, "test@example.com").Return(database.EmailDeliveryEvent{}, database.ErrNotFound)
+		mockUserRepo.EXPECT().GetEmailUnsubscribe(ctx, "test@example.com").Return(database.EmailUnsubscribe{}, database.ErrNotFound)
+
+		status, err := provider.GetEmailDeliveryStatus(ctx, "user-123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if status.Email != "test@example.com" || status.Status != "" || status.StatusAt != nil || status.Unsubscribed {
+			t.Errorf("unexpected delivery status: %+v", status)
+		}
+	})
+
+	t.Run("user without email", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(database.User{ID: "user-123"}, nil)
+
+		if _, err := provider.GetEmailDeliveryStatus(ctx, "user-123"); !errors.Is(err, facade.ErrEmailDeliveryStatusNoEmail) {
+			t.Errorf("expected ErrEmailDeliveryStatusNoEmail, got %v", err)
+		}
+	})
+}
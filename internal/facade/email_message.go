@@ -0,0 +1,35 @@
+package facade
+
+import (
+	"context"
+
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/zap"
+)
+
+// GetUserEmailMessages returns history of emails sent to user, newest first.
+// History is limited to model.EmailMessageHistoryLimit latest send attempts
+func (p *Provider) GetUserEmailMessages(ctx context.Context, userID string) ([]model.EmailMessage, error) {
+	messages, err := p.userRepo.GetEmailMessagesByUserID(ctx, userID, model.EmailMessageHistoryLimit)
+	if err != nil {
+		p.log.Error("get email messages by user id", zap.String("userID", userID), zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]model.EmailMessage, 0, len(messages))
+	for _, msg := range messages {
+		result = append(result, model.EmailMessage{
+			ID:                msg.ID,
+			Template:          msg.Template,
+			Recipient:         msg.Recipient,
+			Provider:          msg.Provider.String,
+			ProviderMessageID: msg.ProviderMessageID.String,
+			Status:            msg.Status,
+			Error:             msg.Error.String,
+			DateCreated:       msg.DateCreated,
+			DateUpdated:       msg.DateUpdated,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package facade_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+)
+
+func TestProvider_GetUserEmailMessages(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		sentAt := time.Now()
+		mockUserRepo.EXPECT().
+			GetEmailMessagesByUserID(ctx, "user-123", model.EmailMessageHistoryLimit).
+			Return([]database.EmailMessage{
+				{
+					ID:                "message-2",
+					Template:          "email_verification",
+					Recipient:         "test@example.com",
+					Provider:          sql.NullString{String: "resend", Valid: true},
+					ProviderMessageID: sql.NullString{String: "provider-id", Valid: true},
+					Status:            model.EmailDeliveryStatusDelivered,
+					DateCreated:       sentAt,
+					DateUpdated:       sentAt.Add(time.Minute),
+				},
+				{
+					ID:          "message-1",
+					Template:    "email_verification",
+					Recipient:   "test@example.com",
+					Status:      model.EmailMessageStatusFailed,
+					Error:       sql.NullString{String: "provider unavailable", Valid: true},
+					DateCreated: sentAt.Add(-time.Minute),
+					DateUpdated: sentAt.Add(-time.Minute),
+				},
+			}, nil)
+
+		messages, err := provider.GetUserEmailMessages(ctx, "user-123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(messages) != 2 {
+			t.Fatalf("expected 2 messages, got %d", len(messages))
+		}
+		if messages[0].Provider != "resend" || messages[0].ProviderMessageID != "provider-id" || messages[0].Status != model.EmailDeliveryStatusDelivered ||
+			!messages[0].DateUpdated.Equal(sentAt.Add(time.Minute)) {
+			t.Errorf("unexpected message: %+v", messages[0])
+		}
+		if messages[1].ProviderMessageID != "" || messages[1].Error != "provider unavailable" {
+			t.Errorf("unexpected message: %+v", messages[1])
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		dbErr := errors.New("database error")
+		mockUserRepo.EXPECT().GetEmailMessagesByUserID(ctx, "user-123", model.EmailMessageHistoryLimit).Return(nil, dbErr)
+
+		if _, err := provider.GetUserEmailMessages(ctx, "user-123"); !errors.Is(err, dbErr) {
+			t.Errorf("expected database error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package facade
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/zap"
+)
+
+// errInvalidEmailPayload is returned when outgoing email payload can't be decoded. Such emails are not retried
+var errInvalidEmailPayload = errors.New("invalid email payload")
+
+// queues outgoing email. Email is sent by outbox dispatcher once the transaction writing it is committed.
+// Payload is encrypted as it contains codes and tokens. User id is id of user the email is sent to, it keeps send history of user.
+// Reference id is id of related record, empty if there is none. Email is skipped if recipient is unsubscribed after bounce or complaint,
+// or unsubscribed from its category. Non-urgent email queued while email quota is exhausted is deferred until quota is reset
+func (p *Provider) enqueueEmail(ctx context.Context, kind, userID, recipient, referenceID string, req any) error {
+	// security emails are not sent to addresses unsubscribed after bounce or complaint either
+	unsubscribed, err := p.userRepo.IsEmailUnsubscribed(ctx, recipient)
+	if err != nil {
+		return fmt.Errorf("check email unsubscribe status: %w", err)
+	}
+	if unsubscribed {
+		p.log.Info("email is unsubscribed after bounce or complaint, skipping email", zap.String("kind", kind), zap.String("email", recipient))
+		return nil
+	}
+
+	subscribed, err := p.isSubscribedToEmailCategory(ctx, recipient, model.EmailKindCategory(kind))
+	if err != nil {
+		return err
+	}
+	if !subscribed {
+		p.log.Info("email is unsubscribed from category, skipping email", zap.String("kind", kind), zap.String("email", recipient))
+		return nil
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshal email payload: %w", err)
+	}
+
+	payload, err := p.secretCipher.Encrypt(data)
+	if err != nil {
+		return fmt.Errorf("encrypt email payload: %w", err)
+	}
+
+	msg := database.NewEmailOutboxMessage(kind, userID, recipient, referenceID, payload)
+	// notices queued while email quota is exhausted are sent after urgent emails queued in the meantime
+	if until, paused := p.emailQuota.isOpen(time.Now()); paused && !model.IsUrgentEmailKind(kind) {
+		msg.NextAttemptAt = until
+	}
+
+	if err = p.userRepo.CreateEmailOutboxMessage(ctx, msg); err != nil {
+		return fmt.Errorf("create email outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// DispatchEmailOutbox sends up to batchSize pending outgoing emails and returns number of processed emails.
+// Failed sends are retried with growing delay until MaxEmailOutboxAttempts is reached.
+// Sending is paused while email quota is exhausted, emails stay queued until quota is reset
+func (p *Provider) DispatchEmailOutbox(ctx context.Context, batchSize int) (int, error) {
+	for i := range batchSize {
+		if _, paused := p.emailQuota.isOpen(time.Now()); paused {
+			return i, nil
+		}
+
+		processed, err := p.dispatchNextEmail(ctx)
+		if err != nil {
+			return i, err
+		}
+		if !processed {
+			return i, nil
+		}
+	}
+
+	return batchSize, nil
+}
+
+// sends the next pending outgoing email. Returns false if there is no email to send
+func (p *Provider) dispatchNextEmail(ctx context.Context) (bool, error) {
+	var processed bool
+
+	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		msg, err := p.userRepo.GetNextPendingEmailOutboxMessage(ctx)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return nil
+			}
+			return fmt.Errorf("get pending email outbox message: %w", err)
+		}
+		processed = true
+
+		template, result, sendErr := p.sendOutboxEmail(ctx, msg)
+
+		// record send attempt in email history. Email may be already sent, so failed record doesn't roll back the dispatch
+		var sendErrMsg string
+		if sendErr != nil {
+			sendErrMsg = sendErr.Error()
+		}
+		if err = p.userRepo.CreateEmailMessage(ctx, database.NewEmailMessage(msg, template, result.Provider, result.MessageID, sendErrMsg)); err != nil {
+			p.log.Error("create email message", zap.String("outboxID", msg.ID), zap.Error(err))
+		}
+
+		if sendErr != nil {
+			return p.registerFailedEmailSend(ctx, msg, sendErr)
+		}
+
+		if err = p.userRepo.SetEmailOutboxMessageSent(ctx, msg.ID, result.MessageID); err != nil {
+			return fmt.Errorf("set email outbox message sent: %w", err)
+		}
+
+		// set message id of related record
+		switch msg.Kind {
+		case model.EmailKindVerification:
+			if err = p.userRepo.SetEmailVerificationMessageID(ctx, msg.ReferenceID.String, result.MessageID, result.Provider); err != nil {
+				return fmt.Errorf("set email verification message_id: %w", err)
+			}
+		case model.EmailKindSignIn:
+			if err = p.userRepo.SetEmailSignInMessageID(ctx, msg.ReferenceID.String, result.MessageID); err != nil {
+				return fmt.Errorf("set email sign in message_id: %w", err)
+			}
+		case model.EmailKindEmailChange:
+			if err = p.userRepo.SetEmailChangeMessageID(ctx, msg.ReferenceID.String, result.MessageID); err != nil {
+				return fmt.Errorf("set email change message_id: %w", err)
+			}
+		}
+
+		return nil
+	})
+	return processed, txErr
+}
+
+// decodes payload of outgoing email and sends it. Returns name of sent template and send result
+func (p *Provider) sendOutboxEmail(ctx context.Context, msg database.EmailOutboxMessage) (string, mailer.SendResult, error) {
+	template := msg.Kind
+
+	data, err := p.secretCipher.Decrypt(msg.Payload)
+	if err != nil {
+		return template, mailer.SendResult{}, fmt.Errorf("%w: decrypt: %w", errInvalidEmailPayload, err)
+	}
+
+	var result mailer.SendResult
+	switch msg.Kind {
+	case model.EmailKindVerification:
+		var req mailer.SendEmailVerificationRequest
+		if err = json.Unmarshal(data, &req); err != nil {
+			return template, mailer.SendResult{}, fmt.Errorf("%w: %w", errInvalidEmailPayload, err)
+		}
+		result, err = p.emailSender.SendEmailVerification(ctx, req)
+	case model.EmailKindSignIn:
+		var req mailer.SendEmailSignInRequest
+		if err = json.Unmarshal(data, &req); err != nil {
+			return template, mailer.SendResult{}, fmt.Errorf("%w: %w", errInvalidEmailPayload, err)
+		}
+		result, err = p.emailSender.SendEmailSignIn(ctx, req)
+	case model.EmailKindRecoveryCodeUsed:
+		var req mailer.SendRecoveryCodeUsedRequest
+		if err = json.Unmarshal(data, &req); err != nil {
+			return template, mailer.SendResult{}, fmt.Errorf("%w: %w", errInvalidEmailPayload, err)
+		}
+		result, err = p.emailSender.SendRecoveryCodeUsed(ctx, req)
+	case model.EmailKindEmailChange:
+		var req mailer.SendEmailChangeRequest
+		if err = json.Unmarshal(data, &req); err != nil {
+			return template, mailer.SendResult{}, fmt.Errorf("%w: %w", errInvalidEmailPayload, err)
+		}
+		result, err = p.emailSender.SendEmailChange(ctx, req)
+	case model.EmailKindEmailChanged:
+		var req mailer.SendEmailChangedRequest
+		if err = json.Unmarshal(data, &req); err != nil {
+			return template, mailer.SendResult{}, fmt.Errorf("%w: %w", errInvalidEmailPayload, err)
+		}
+		result, err = p.emailSender.SendEmailChanged(ctx, req)
+	case model.EmailKindSecurityNotice:
+		var req mailer.SendSecurityNoticeRequest
+		if err = json.Unmarshal(data, &req); err != nil {
+			return template, mailer.SendResult{}, fmt.Errorf("%w: %w", errInvalidEmailPayload, err)
+		}
+		// security notices have template per event
+		template = req.Event
+		result, err = p.emailSender.SendSecurityNotice(ctx, req)
+	default:
+		return template, mailer.SendResult{}, fmt.Errorf("%w: unknown email kind %q", errInvalidEmailPayload, msg.Kind)
+	}
+
+	return template, result, err
+}
+
+// records failed send of outgoing email. Email is rescheduled or marked as failed if attempts are exhausted or payload is invalid.
+// Email failed on exhausted email quota pauses sending and is deferred until quota is reset without counting the attempt.
+// Email not sent because every email provider is skipped is deferred until provider is tried again without counting the attempt
+func (p *Provider) registerFailedEmailSend(ctx context.Context, msg database.EmailOutboxMessage, sendErr error) error {
+	if skippedErr := mailer.AsProvidersSkippedError(sendErr); skippedErr != nil {
+		p.log.Warn("email providers are skipped, email is deferred", zap.String("outboxID", msg.ID), zap.Time("until", skippedErr.RetryAt))
+		if err := p.userRepo.DeferEmailOutboxMessage(ctx, msg.ID, sendErr.Error(), skippedErr.RetryAt); err != nil {
+			return fmt.Errorf("defer email outbox message: %w", err)
+		}
+		return nil
+	}
+
+	if quotaErr := mailer.AsQuotaExceededError(sendErr); quotaErr != nil {
+		resetAt := emailQuotaResetAt(quotaErr.ResetAt, time.Now())
+		p.emailQuota.open(resetAt)
+		p.log.Warn("email quota exceeded, sending is paused", zap.String("outboxID", msg.ID), zap.Time("until", resetAt))
+		if err := p.userRepo.DeferEmailOutboxMessage(ctx, msg.ID, sendErr.Error(), resetAt); err != nil {
+			return fmt.Errorf("defer email outbox message: %w", err)
+		}
+		return nil
+	}
+
+	attempt := msg.Attempts + 1
+
+	if attempt >= model.MaxEmailOutboxAttempts || errors.Is(sendErr, errInvalidEmailPayload) {
+		p.log.Error("send email failed", zap.String("outboxID", msg.ID), zap.String("kind", msg.Kind),
+			zap.Int("attempt", attempt), zap.Error(sendErr))
+		if err := p.userRepo.SetEmailOutboxMessageFailed(ctx, msg.ID, sendErr.Error()); err != nil {
+			return fmt.Errorf("set email outbox message failed: %w", err)
+		}
+		return nil
+	}
+
+	p.log.Warn("send email, retry scheduled", zap.String("outboxID", msg.ID), zap.String("kind", msg.Kind),
+		zap.Int("attempt", attempt), zap.Error(sendErr))
+	if err := p.userRepo.RescheduleEmailOutboxMessage(ctx, msg.ID, sendErr.Error(), time.Now().Add(emailRetryDelay(attempt))); err != nil {
+		return fmt.Errorf("reschedule email outbox message: %w", err)
+	}
+	return nil
+}
+
+// returns delay before the next send attempt after provided number of failed attempts
+func emailRetryDelay(attempt int) time.Duration {
+	delay := model.EmailOutboxRetryInitialDelay
+	for range attempt - 1 {
+		delay *= 2
+		if delay >= model.EmailOutboxRetryMaxDelay {
+			return model.EmailOutboxRetryMaxDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,253 @@
+package facade_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	mocks "github.com/OutOfStack/game-library-auth/internal/facade/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/mock/gomock"
+)
+
+// newTestOutboxMessage returns pending outgoing email with encrypted payload
+func newTestOutboxMessage(t *testing.T, kind, referenceID string, req any) database.EmailOutboxMessage {
+	t.Helper()
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("marshal email payload: %v", err)
+	}
+	payload, err := newTestSecretCipher(t).Encrypt(data)
+	if err != nil {
+		t.Fatalf("encrypt email payload: %v", err)
+	}
+
+	msg := database.NewEmailOutboxMessage(kind, "user-123", "test@example.com", referenceID, payload)
+	msg.ID = "outbox-123"
+	return msg
+}
+
+// testSendResult is result of email sent by email sender mock
+var testSendResult = mailer.SendResult{MessageID: "message-id-123", Provider: "resend"}
+
+// expectEmailMessage sets expectation for send attempt of outbox-123 email recorded in email history
+func expectEmailMessage(t *testing.T, mockUserRepo *mocks.MockUserRepo, template string, result mailer.SendResult, status string) {
+	t.Helper()
+
+	mockUserRepo.EXPECT().
+		CreateEmailMessage(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, msg database.EmailMessage) error {
+			if msg.OutboxID != "outbox-123" || msg.UserID.String != "user-123" || msg.Recipient != "test@example.com" {
+				t.Errorf("unexpected email message: %+v", msg)
+			}
+			if msg.Template != template || msg.Provider.String != result.Provider || msg.ProviderMessageID.String != result.MessageID || msg.Status != status {
+				t.Errorf("expected email message with template %s, result %+v and status %s, got %+v", template, result, status, msg)
+			}
+			if (status == model.EmailMessageStatusFailed) != msg.Error.Valid {
+				t.Errorf("unexpected email message error %+v", msg.Error)
+			}
+			return nil
+		})
+}
+
+func TestProvider_DispatchEmailOutbox(t *testing.T) {
+	ctx := context.Background()
+	verificationReq := mailer.SendEmailVerificationRequest{
+		Email:            "test@example.com",
+		Username:         "testuser",
+		VerificationCode: "123456",
+		CodeTTL:          24 * time.Hour,
+	}
+
+	t.Run("no pending emails", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetNextPendingEmailOutboxMessage(gomock.Any()).Return(database.EmailOutboxMessage{}, database.ErrNotFound)
+
+		n, err := provider.DispatchEmailOutbox(ctx, 10)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n != 0 {
+			t.Errorf("expected 0 processed emails, got %d", n)
+		}
+	})
+
+	t.Run("email sent", func(t *testing.T) {
+		provider, mockUserRepo, mockEmailSender, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		msg := newTestOutboxMessage(t, model.EmailKindVerification, "verification-123", verificationReq)
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetNextPendingEmailOutboxMessage(gomock.Any()).Return(msg, nil)
+		mockEmailSender.EXPECT().
+			SendEmailVerification(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req mailer.SendEmailVerificationRequest) (mailer.SendResult, error) {
+				if req != verificationReq {
+					t.Errorf("unexpected email request: %+v", req)
+				}
+				return testSendResult, nil
+			})
+		expectEmailMessage(t, mockUserRepo, "email_verification", testSendResult, model.EmailMessageStatusSent)
+		mockUserRepo.EXPECT().SetEmailOutboxMessageSent(gomock.Any(), "outbox-123", "message-id-123").Return(nil)
+		mockUserRepo.EXPECT().SetEmailVerificationMessageID(gomock.Any(), "verification-123", "message-id-123", "resend").Return(nil)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetNextPendingEmailOutboxMessage(gomock.Any()).Return(database.EmailOutboxMessage{}, database.ErrNotFound)
+
+		n, err := provider.DispatchEmailOutbox(ctx, 10)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n != 1 {
+			t.Errorf("expected 1 processed email, got %d", n)
+		}
+	})
+
+	t.Run("email history error doesn't roll back sent email", func(t *testing.T) {
+		provider, mockUserRepo, mockEmailSender, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		msg := newTestOutboxMessage(t, model.EmailKindVerification, "verification-123", verificationReq)
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetNextPendingEmailOutboxMessage(gomock.Any()).Return(msg, nil)
+		mockEmailSender.EXPECT().SendEmailVerification(gomock.Any(), gomock.Any()).Return(testSendResult, nil)
+		mockUserRepo.EXPECT().CreateEmailMessage(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+		mockUserRepo.EXPECT().SetEmailOutboxMessageSent(gomock.Any(), "outbox-123", "message-id-123").Return(nil)
+		mockUserRepo.EXPECT().SetEmailVerificationMessageID(gomock.Any(), "verification-123", "message-id-123", "resend").Return(nil)
+
+		n, err := provider.DispatchEmailOutbox(ctx, 1)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n != 1 {
+			t.Errorf("expected 1 processed email, got %d", n)
+		}
+	})
+
+	t.Run("send error reschedules email", func(t *testing.T) {
+		provider, mockUserRepo, mockEmailSender, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		msg := newTestOutboxMessage(t, model.EmailKindVerification, "verification-123", verificationReq)
+		msg.Attempts = 1
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetNextPendingEmailOutboxMessage(gomock.Any()).Return(msg, nil)
+		mockEmailSender.EXPECT().SendEmailVerification(gomock.Any(), gomock.Any()).Return(mailer.SendResult{}, errors.New("provider unavailable"))
+		expectEmailMessage(t, mockUserRepo, "email_verification", mailer.SendResult{}, model.EmailMessageStatusFailed)
+		mockUserRepo.EXPECT().
+			RescheduleEmailOutboxMessage(gomock.Any(), "outbox-123", "provider unavailable", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, nextAttemptAt time.Time) error {
+				// second failed attempt doubles initial delay
+				if delay := time.Until(nextAttemptAt); delay <= model.EmailOutboxRetryInitialDelay || delay > 2*model.EmailOutboxRetryInitialDelay {
+					t.Errorf("unexpected retry delay %v", delay)
+				}
+				return nil
+			})
+
+		n, err := provider.DispatchEmailOutbox(ctx, 1)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n != 1 {
+			t.Errorf("expected 1 processed email, got %d", n)
+		}
+	})
+
+	t.Run("skipped email providers defer email without counting attempt", func(t *testing.T) {
+		provider, mockUserRepo, mockEmailSender, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		msg := newTestOutboxMessage(t, model.EmailKindVerification, "verification-123", verificationReq)
+		msg.Attempts = model.MaxEmailOutboxAttempts - 1
+		retryAt := time.Now().Add(time.Minute)
+		skippedErr := &mailer.ProvidersSkippedError{RetryAt: retryAt}
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetNextPendingEmailOutboxMessage(gomock.Any()).Return(msg, nil)
+		mockEmailSender.EXPECT().SendEmailVerification(gomock.Any(), gomock.Any()).Return(mailer.SendResult{}, skippedErr)
+		expectEmailMessage(t, mockUserRepo, "email_verification", mailer.SendResult{}, model.EmailMessageStatusFailed)
+		mockUserRepo.EXPECT().DeferEmailOutboxMessage(gomock.Any(), "outbox-123", skippedErr.Error(), retryAt).Return(nil)
+
+		if _, err := provider.DispatchEmailOutbox(ctx, 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("send error on last attempt fails email", func(t *testing.T) {
+		provider, mockUserRepo, mockEmailSender, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		msg := newTestOutboxMessage(t, model.EmailKindSignIn, "signin-123", mailer.SendEmailSignInRequest{Email: "test@example.com"})
+		msg.Attempts = model.MaxEmailOutboxAttempts - 1
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetNextPendingEmailOutboxMessage(gomock.Any()).Return(msg, nil)
+		mockEmailSender.EXPECT().SendEmailSignIn(gomock.Any(), gomock.Any()).Return(mailer.SendResult{}, errors.New("provider unavailable"))
+		expectEmailMessage(t, mockUserRepo, "email_sign_in", mailer.SendResult{}, model.EmailMessageStatusFailed)
+		mockUserRepo.EXPECT().SetEmailOutboxMessageFailed(gomock.Any(), "outbox-123", "provider unavailable").Return(nil)
+
+		if _, err := provider.DispatchEmailOutbox(ctx, 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("invalid payload fails email without retry", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		msg := database.NewEmailOutboxMessage(model.EmailKindVerification, "user-123", "test@example.com", "verification-123", []byte("not encrypted"))
+		msg.ID = "outbox-123"
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetNextPendingEmailOutboxMessage(gomock.Any()).Return(msg, nil)
+		expectEmailMessage(t, mockUserRepo, "email_verification", mailer.SendResult{}, model.EmailMessageStatusFailed)
+		mockUserRepo.EXPECT().SetEmailOutboxMessageFailed(gomock.Any(), "outbox-123", gomock.Any()).Return(nil)
+
+		if _, err := provider.DispatchEmailOutbox(ctx, 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("security notice recorded with event template", func(t *testing.T) {
+		provider, mockUserRepo, mockEmailSender, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		msg := newTestOutboxMessage(t, model.EmailKindSecurityNotice, "", mailer.SendSecurityNoticeRequest{
+			Email: "test@example.com",
+			Event: model.SecurityEventPasswordChanged,
+		})
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetNextPendingEmailOutboxMessage(gomock.Any()).Return(msg, nil)
+		mockEmailSender.EXPECT().SendSecurityNotice(gomock.Any(), gomock.Any()).Return(testSendResult, nil)
+		expectEmailMessage(t, mockUserRepo, model.SecurityEventPasswordChanged, testSendResult, model.EmailMessageStatusSent)
+		mockUserRepo.EXPECT().SetEmailOutboxMessageSent(gomock.Any(), "outbox-123", "message-id-123").Return(nil)
+
+		if _, err := provider.DispatchEmailOutbox(ctx, 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		dbErr := errors.New("database error")
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetNextPendingEmailOutboxMessage(gomock.Any()).Return(database.EmailOutboxMessage{}, dbErr)
+
+		if _, err := provider.DispatchEmailOutbox(ctx, 10); !errors.Is(err, dbErr) {
+			t.Errorf("expected database error, got %v", err)
+		}
+	})
+}
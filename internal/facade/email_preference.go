@@ -0,0 +1,86 @@
+package facade
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/zap"
+)
+
+// GetEmailPreferences returns subscriptions of an email address to all email categories
+func (p *Provider) GetEmailPreferences(ctx context.Context, email string) ([]model.EmailPreference, error) {
+	stored, err := p.userRepo.GetEmailPreferences(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("get email preferences: %w", err)
+	}
+
+	prefs := make([]model.EmailPreference, 0, len(model.EmailCategories))
+	for _, category := range model.EmailCategories {
+		pref := model.EmailPreference{
+			Category:   category,
+			Subscribed: true,
+		}
+		idx := slices.IndexFunc(stored, func(s database.EmailPreference) bool { return s.Category == category })
+		if idx >= 0 && model.IsEmailCategorySuppressible(category) {
+			pref.Subscribed = stored[idx].Subscribed
+		}
+		prefs = append(prefs, pref)
+	}
+
+	return prefs, nil
+}
+
+// UpdateEmailPreferences subscribes email address of unsubscribe token to provided email categories
+// and unsubscribes it from the rest. Security emails are always sent. Returns email address
+func (p *Provider) UpdateEmailPreferences(ctx context.Context, token string, subscribed []string) (string, error) {
+	email, err := p.unsubscribeTokenGenerator.ValidateToken(token)
+	if err != nil {
+		return "", fmt.Errorf("invalid unsubscribe token: %w", err)
+	}
+
+	if err = p.setEmailPreferences(ctx, email, func(category string) bool {
+		return slices.Contains(subscribed, category)
+	}); err != nil {
+		return "", err
+	}
+
+	p.log.Info("email preferences updated", zap.String("email", email), zap.Strings("subscribed", subscribed))
+	return email, nil
+}
+
+// sets subscription of email address to every email category that can be unsubscribed from
+func (p *Provider) setEmailPreferences(ctx context.Context, email string, subscribed func(category string) bool) error {
+	return p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		for _, category := range model.EmailCategories {
+			if !model.IsEmailCategorySuppressible(category) {
+				continue
+			}
+			if err := p.userRepo.UpsertEmailPreference(ctx, database.NewEmailPreference(email, category, subscribed(category))); err != nil {
+				return fmt.Errorf("upsert email preference: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// checks if email address is subscribed to emails of category
+func (p *Provider) isSubscribedToEmailCategory(ctx context.Context, email, category string) (bool, error) {
+	if !model.IsEmailCategorySuppressible(category) {
+		return true, nil
+	}
+
+	prefs, err := p.userRepo.GetEmailPreferences(ctx, email)
+	if err != nil {
+		return false, fmt.Errorf("get email preferences: %w", err)
+	}
+	for _, pref := range prefs {
+		if pref.Category == category {
+			return pref.Subscribed, nil
+		}
+	}
+
+	return true, nil
+}
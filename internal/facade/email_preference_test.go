@@ -0,0 +1,102 @@
+package facade_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+)
+
+func TestProvider_GetEmailPreferences(t *testing.T) {
+	provider, mockUserRepo, _, _, ctrl := setupTest(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	email := "test@example.com"
+
+	t.Run("stored preferences override defaults", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetEmailPreferences(ctx, email).Return([]database.EmailPreference{
+			database.NewEmailPreference(email, model.EmailCategoryProductNews, false),
+			// security emails can't be unsubscribed from
+			database.NewEmailPreference(email, model.EmailCategorySecurity, false),
+		}, nil)
+
+		prefs, err := provider.GetEmailPreferences(ctx, email)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		expected := []model.EmailPreference{
+			{Category: model.EmailCategorySecurity, Subscribed: true},
+			{Category: model.EmailCategoryProductNews, Subscribed: false},
+			{Category: model.EmailCategoryPublisherReports, Subscribed: true},
+		}
+		if len(prefs) != len(expected) {
+			t.Fatalf("expected %d preferences, got %d", len(expected), len(prefs))
+		}
+		for i := range expected {
+			if prefs[i] != expected[i] {
+				t.Errorf("expected preference %v, got %v", expected[i], prefs[i])
+			}
+		}
+	})
+
+	t.Run("repo error", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetEmailPreferences(ctx, email).Return(nil, errors.New("db error"))
+
+		if _, err := provider.GetEmailPreferences(ctx, email); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestProvider_UpdateEmailPreferences(t *testing.T) {
+	provider, mockUserRepo, _, _, ctrl := setupTest(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	email := "test@example.com"
+	tokenGen := auth.NewUnsubscribeTokenGenerator(newTestSignedTokens(t, "test-secret-key"), nil)
+
+	t.Run("success", func(t *testing.T) {
+		token := tokenGen.GenerateToken(email, time.Now().Add(time.Hour))
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			UpsertEmailPreference(ctx, database.NewEmailPreference(email, model.EmailCategoryProductNews, false)).
+			Return(nil)
+		mockUserRepo.EXPECT().
+			UpsertEmailPreference(ctx, database.NewEmailPreference(email, model.EmailCategoryPublisherReports, true)).
+			Return(nil)
+
+		result, err := provider.UpdateEmailPreferences(ctx, token, []string{model.EmailCategorySecurity, model.EmailCategoryPublisherReports})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result != email {
+			t.Errorf("expected email %s, got %s", email, result)
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		if _, err := provider.UpdateEmailPreferences(ctx, "invalid-token", nil); err == nil {
+			t.Error("expected error for invalid token")
+		}
+	})
+
+	t.Run("upsert error", func(t *testing.T) {
+		token := tokenGen.GenerateToken(email, time.Now().Add(time.Hour))
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().UpsertEmailPreference(ctx, database.NewEmailPreference(email, model.EmailCategoryProductNews, false)).
+			Return(errors.New("db error"))
+
+		if _, err := provider.UpdateEmailPreferences(ctx, token, nil); err == nil {
+			t.Error("expected error when UpsertEmailPreference fails")
+		}
+	})
+}
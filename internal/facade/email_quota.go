@@ -0,0 +1,52 @@
+package facade
+
+import (
+	"sync"
+	"time"
+)
+
+// EmailDeliveryDelay returns time left until exhausted sending quota of email provider is reset.
+// Returns false if emails are sent without delay.
+// Quota state is kept in memory of this instance, instances that haven't hit the quota yet report no delay
+func (p *Provider) EmailDeliveryDelay() (time.Duration, bool) {
+	now := time.Now()
+	until, paused := p.emailQuota.isOpen(now)
+	if !paused {
+		return 0, false
+	}
+	return until.Sub(now), true
+}
+
+// emailQuotaCircuit pauses sending of emails while sending quota of email provider is exhausted.
+// Circuit is opened when provider reports exhausted quota and closes by itself once quota is reset
+type emailQuotaCircuit struct {
+	mu        sync.Mutex
+	openUntil time.Time
+}
+
+// opens circuit until provided time. Circuit which is already open longer is not changed
+func (c *emailQuotaCircuit) open(until time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if until.After(c.openUntil) {
+		c.openUntil = until
+	}
+}
+
+// returns time circuit is open until and whether it is open at provided time
+func (c *emailQuotaCircuit) isOpen(now time.Time) (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.openUntil, now.Before(c.openUntil)
+}
+
+// returns reset time of exhausted email quota. Quota which reset time is unknown is considered daily quota
+// and is reset at the start of the next UTC day
+func emailQuotaResetAt(resetAt, now time.Time) time.Time {
+	if resetAt.After(now) {
+		return resetAt
+	}
+	return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
@@ -0,0 +1,144 @@
+package facade_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	mocks "github.com/OutOfStack/game-library-auth/internal/facade/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// exhaustEmailQuota dispatches outgoing email failing on exhausted email quota, which pauses sending.
+// Returns time the failed email is deferred to
+func exhaustEmailQuota(t *testing.T, provider *facade.Provider, mockUserRepo *mocks.MockUserRepo, mockEmailSender *mocks.MockEmailSender,
+	resetAt time.Time) time.Time {
+	t.Helper()
+
+	msg := newTestOutboxMessage(t, model.EmailKindVerification, "verification-123", mailer.SendEmailVerificationRequest{Email: "test@example.com"})
+
+	var deferredTo time.Time
+	expectTx(mockUserRepo)
+	mockUserRepo.EXPECT().GetNextPendingEmailOutboxMessage(gomock.Any()).Return(msg, nil)
+	mockEmailSender.EXPECT().SendEmailVerification(gomock.Any(), gomock.Any()).Return(mailer.SendResult{}, &mailer.QuotaExceededError{ResetAt: resetAt})
+	expectEmailMessage(t, mockUserRepo, "email_verification", mailer.SendResult{}, model.EmailMessageStatusFailed)
+	mockUserRepo.EXPECT().
+		DeferEmailOutboxMessage(gomock.Any(), "outbox-123", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, nextAttemptAt time.Time) error {
+			deferredTo = nextAttemptAt
+			return nil
+		})
+
+	// sending stops after email failed on quota
+	n, err := provider.DispatchEmailOutbox(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 processed email, got %d", n)
+	}
+
+	return deferredTo
+}
+
+func TestProvider_EmailQuota(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sending is paused until reported reset", func(t *testing.T) {
+		provider, mockUserRepo, mockEmailSender, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		if _, delayed := provider.EmailDeliveryDelay(); delayed {
+			t.Fatal("expected no delay before quota is exhausted")
+		}
+
+		resetAt := time.Now().Add(time.Hour)
+		deferredTo := exhaustEmailQuota(t, provider, mockUserRepo, mockEmailSender, resetAt)
+		if !deferredTo.Equal(resetAt) {
+			t.Errorf("expected email deferred to %v, got %v", resetAt, deferredTo)
+		}
+
+		delay, delayed := provider.EmailDeliveryDelay()
+		if !delayed || delay <= 59*time.Minute || delay > time.Hour {
+			t.Errorf("expected delay of about an hour, got %v (%t)", delay, delayed)
+		}
+
+		// no emails are taken from outbox while paused
+		n, err := provider.DispatchEmailOutbox(ctx, 10)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n != 0 {
+			t.Errorf("expected 0 processed emails, got %d", n)
+		}
+	})
+
+	t.Run("unknown reset is start of next UTC day", func(t *testing.T) {
+		provider, mockUserRepo, mockEmailSender, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		deferredTo := exhaustEmailQuota(t, provider, mockUserRepo, mockEmailSender, time.Time{})
+		expected := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+		if !deferredTo.Equal(expected) {
+			t.Errorf("expected email deferred to %v, got %v", expected, deferredTo)
+		}
+	})
+
+	t.Run("notices queued while paused are deferred", func(t *testing.T) {
+		provider, mockUserRepo, mockEmailSender, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		resetAt := time.Now().Add(time.Hour)
+		exhaustEmailQuota(t, provider, mockUserRepo, mockEmailSender, resetAt)
+
+		passwordHash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+		user := database.User{ID: "user-123", Username: "testuser", PasswordHash: passwordHash}
+		user.SetEmail("test@example.com", true)
+		userTOTP, _ := newTestUserTOTP(t, "user-123", true)
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserByID(gomock.Any(), "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetUserTOTP(gomock.Any(), "user-123").Return(userTOTP, nil)
+		expectRecoveryCodesReplaced(mockUserRepo, "user-123")
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), gomock.Any()).Return(false, nil)
+		mockUserRepo.EXPECT().
+			CreateEmailOutboxMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg database.EmailOutboxMessage) error {
+				if !msg.NextAttemptAt.Equal(resetAt) {
+					t.Errorf("expected notice deferred to %v, got %v", resetAt, msg.NextAttemptAt)
+				}
+				return nil
+			})
+
+		if _, err := provider.RegenerateRecoveryCodes(ctx, "user-123", "password123"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("verification resend is delayed", func(t *testing.T) {
+		provider, mockUserRepo, mockEmailSender, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		exhaustEmailQuota(t, provider, mockUserRepo, mockEmailSender, time.Now().Add(time.Hour))
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(database.User{
+			ID:    "user-123",
+			Email: sql.NullString{String: "test@example.com", Valid: true},
+		}, nil)
+
+		err := provider.ResendVerificationEmail(ctx, "user-123")
+		delayedErr := facade.AsEmailDelayedError(err)
+		if delayedErr == nil {
+			t.Fatalf("expected email delayed error, got %v", err)
+		}
+		if delayedErr.RetryAfter <= 59*time.Minute || delayedErr.RetryAfter > time.Hour {
+			t.Errorf("expected retry after about an hour, got %v", delayedErr.RetryAfter)
+		}
+	})
+}
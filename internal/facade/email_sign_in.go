@@ -0,0 +1,256 @@
+package facade
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/blake2b"
+)
+
+// signInTokenBytes - number of random bytes in passwordless sign in link token
+const signInTokenBytes = 32
+
+// errors
+var (
+	ErrEmailSignInUnavailable      = errors.New("email sign in: no account with verified email")
+	ErrEmailSignInInvalidOrExpired = errors.New("email sign in: invalid or expired code")
+	ErrEmailSignInTooManyAttempts  = errors.New("email sign in: too many failed attempts")
+)
+
+// RequestEmailSignIn sends single-use passwordless sign in code and link to user with provided email.
+// Returns ErrEmailSignInUnavailable if there is no account with such verified email or email is unsubscribed,
+// and TooManyRequestsError if sign in email was sent recently
+func (p *Provider) RequestEmailSignIn(ctx context.Context, email string) error {
+	user, err := p.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrEmailSignInUnavailable
+		}
+		p.log.Error("get user by email", zap.Error(err))
+		return err
+	}
+
+	// only accounts with verified email can sign in by email
+	if !user.Email.Valid || !user.EmailVerified {
+		return ErrEmailSignInUnavailable
+	}
+
+	// check if email is unsubscribed after bounce or complaint
+	isUnsubscribed, err := p.userRepo.IsEmailUnsubscribed(ctx, user.Email.String)
+	if err != nil {
+		p.log.Error("check email unsubscribe status", zap.String("userID", user.ID), zap.Error(err))
+		return fmt.Errorf("check email unsubscribe status: %w", err)
+	}
+	if isUnsubscribed {
+		p.log.Info("email is unsubscribed, skipping sign in email", zap.String("userID", user.ID))
+		return ErrEmailSignInUnavailable
+	}
+
+	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		// check if code was already sent
+		signIn, err := p.userRepo.GetEmailSignInByUserID(ctx, user.ID)
+		if err != nil && !errors.Is(err, database.ErrNotFound) {
+			return fmt.Errorf("get email sign in record: %w", err)
+		}
+		if err == nil {
+			if err = checkResendCooldown(signIn.DateCreated, model.ResendEmailSignInCodeCooldown); err != nil {
+				return err
+			}
+
+			// previous code is replaced by a new one
+			if err = p.userRepo.SetEmailSignInUsed(ctx, signIn.ID, false); err != nil {
+				return fmt.Errorf("clear email sign in: %w", err)
+			}
+		}
+
+		code, codeHash, err := generateHashedCode(emailSignInCodeLen, numericCodeChars)
+		if err != nil {
+			return err
+		}
+		token, err := generateSignInToken()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		signIn = database.NewEmailSignIn(user.ID, codeHash, hashSignInToken(token), now)
+		if err = p.userRepo.CreateEmailSignIn(ctx, signIn); err != nil {
+			return fmt.Errorf("create email sign in record: %w", err)
+		}
+
+		unsubscribeToken := p.unsubscribeTokenGenerator.GenerateToken(user.Email.String, now.Add(p.emailCfg.UnsubscribeTokenTTL))
+
+		// queue sign in email. Email is sent by outbox dispatcher after transaction is committed
+		err = p.enqueueEmail(ctx, model.EmailKindSignIn, user.ID, user.Email.String, signIn.ID, mailer.SendEmailSignInRequest{
+			Email:            user.Email.String,
+			Username:         user.Username,
+			Locale:           user.Locale,
+			SignInCode:       code,
+			SignInToken:      token,
+			UnsubscribeToken: unsubscribeToken,
+		})
+		if err != nil {
+			return fmt.Errorf("queue sign in email: %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		if AsTooManyRequestsError(txErr) == nil {
+			p.log.Error("request email sign in", zap.String("userID", user.ID), zap.Error(txErr))
+		}
+		return txErr
+	}
+
+	return nil
+}
+
+// CompleteEmailSignIn signs in user with email and single-use code sent by RequestEmailSignIn.
+// Code is invalidated after too many failed attempts and ErrEmailSignInTooManyAttempts is returned
+func (p *Provider) CompleteEmailSignIn(ctx context.Context, email, code string) (model.User, error) {
+	var user database.User
+	// failed sign in result. Transaction is committed to persist failed attempts and invalidated codes
+	var signInErr error
+
+	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		user, err = p.userRepo.GetUserByEmail(ctx, email)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return ErrEmailSignInInvalidOrExpired
+			}
+			p.log.Error("get user by email", zap.Error(err))
+			return err
+		}
+
+		signIn, err := p.userRepo.GetEmailSignInByUserID(ctx, user.ID)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return ErrEmailSignInInvalidOrExpired
+			}
+			p.log.Error("get email sign in", zap.String("userID", user.ID), zap.Error(err))
+			return err
+		}
+
+		// compare codes
+		if err = bcrypt.CompareHashAndPassword([]byte(signIn.CodeHash.String), []byte(code)); err != nil {
+			invalidated, aErr := p.registerFailedEmailSignInAttempt(ctx, signIn.ID)
+			if aErr != nil {
+				return aErr
+			}
+			signInErr = ErrEmailSignInInvalidOrExpired
+			if invalidated {
+				signInErr = ErrEmailSignInTooManyAttempts
+			}
+			return nil
+		}
+
+		return p.useEmailSignIn(ctx, user, signIn)
+	})
+	if txErr != nil {
+		return model.User{}, txErr
+	}
+	if signInErr != nil {
+		return model.User{}, signInErr
+	}
+
+	return mapDBUserToUser(user), nil
+}
+
+// CompleteEmailSignInWithToken signs in user with single-use token from sign in link sent by RequestEmailSignIn
+func (p *Provider) CompleteEmailSignInWithToken(ctx context.Context, token string) (model.User, error) {
+	var user database.User
+
+	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		signIn, err := p.userRepo.GetEmailSignInByTokenHash(ctx, hashSignInToken(token))
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return ErrEmailSignInInvalidOrExpired
+			}
+			p.log.Error("get email sign in by token", zap.Error(err))
+			return err
+		}
+
+		user, err = p.userRepo.GetUserByID(ctx, signIn.UserID)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return ErrEmailSignInInvalidOrExpired
+			}
+			p.log.Error("get user by id", zap.String("userID", signIn.UserID), zap.Error(err))
+			return err
+		}
+
+		return p.useEmailSignIn(ctx, user, signIn)
+	})
+	if txErr != nil {
+		return model.User{}, txErr
+	}
+
+	return mapDBUserToUser(user), nil
+}
+
+// checks that passwordless sign in is still valid for user and marks it as used
+func (p *Provider) useEmailSignIn(ctx context.Context, user database.User, signIn database.EmailSignIn) error {
+	// email could become unverified after sign in was requested
+	if !user.EmailVerified {
+		return ErrEmailSignInInvalidOrExpired
+	}
+
+	// check expiration
+	if signIn.IsExpired() {
+		if err := p.userRepo.SetEmailSignInUsed(ctx, signIn.ID, false); err != nil {
+			p.log.Error("clear expired email sign in", zap.String("signInID", signIn.ID), zap.Error(err))
+			return err
+		}
+		return ErrEmailSignInInvalidOrExpired
+	}
+
+	if err := p.userRepo.SetEmailSignInUsed(ctx, signIn.ID, true); err != nil {
+		p.log.Error("mark email sign in used", zap.String("signInID", signIn.ID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// counts failed attempt to enter sign in code. Returns true if code was invalidated after too many failed attempts
+func (p *Provider) registerFailedEmailSignInAttempt(ctx context.Context, signInID string) (bool, error) {
+	failedAttempts, err := p.userRepo.IncrementEmailSignInFailedAttempts(ctx, signInID)
+	if err != nil {
+		p.log.Error("increment email sign in failed attempts", zap.String("signInID", signInID), zap.Error(err))
+		return false, err
+	}
+	if failedAttempts < model.MaxEmailSignInCodeAttempts {
+		return false, nil
+	}
+
+	if err = p.userRepo.SetEmailSignInUsed(ctx, signInID, false); err != nil {
+		p.log.Error("invalidate email sign in", zap.String("signInID", signInID), zap.Error(err))
+		return false, err
+	}
+	return true, nil
+}
+
+// generates a secure random token for passwordless sign in link
+func generateSignInToken() (string, error) {
+	b := make([]byte, signInTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate sign in token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashSignInToken(token string) string {
+	hash := blake2b.Sum384([]byte(token))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
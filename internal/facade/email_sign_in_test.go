@@ -0,0 +1,454 @@
+package facade_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestProvider_RequestEmailSignIn(t *testing.T) {
+	ctx := context.Background()
+
+	verifiedUser := database.User{
+		ID:            "user-123",
+		Username:      "testuser",
+		Email:         sql.NullString{String: "test@example.com", Valid: true},
+		EmailVerified: true,
+		Role:          model.UserRoleName,
+	}
+
+	t.Run("successful request", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			GetUserByEmail(ctx, "test@example.com").
+			Return(verifiedUser, nil)
+		mockUserRepo.EXPECT().
+			IsEmailUnsubscribed(ctx, "test@example.com").
+			Return(false, nil)
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().
+			GetEmailSignInByUserID(ctx, "user-123").
+			Return(database.EmailSignIn{}, database.ErrNotFound)
+
+		var stored database.EmailSignIn
+		mockUserRepo.EXPECT().
+			CreateEmailSignIn(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, signIn database.EmailSignIn) error {
+				stored = signIn
+				return nil
+			})
+
+		var sent mailer.SendEmailSignInRequest
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), gomock.Any()).Return(false, nil)
+		mockUserRepo.EXPECT().
+			CreateEmailOutboxMessage(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg database.EmailOutboxMessage) error {
+				if msg.Kind != model.EmailKindSignIn || msg.Recipient != "test@example.com" || msg.ReferenceID.String != stored.ID {
+					t.Errorf("unexpected queued email %q to %q (%q)", msg.Kind, msg.Recipient, msg.ReferenceID.String)
+				}
+				decodeOutboxPayload(t, msg, &sent)
+				return nil
+			})
+
+		err := provider.RequestEmailSignIn(ctx, "test@example.com")
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if sent.Email != "test@example.com" || sent.Username != "testuser" {
+			t.Errorf("unexpected email recipient %q (%q)", sent.Email, sent.Username)
+		}
+		if len(sent.SignInCode) != 6 {
+			t.Errorf("expected 6-digit sign in code, got %q", sent.SignInCode)
+		}
+		if sent.SignInToken == "" || sent.UnsubscribeToken == "" {
+			t.Error("expected sign in and unsubscribe tokens to be set")
+		}
+		if stored.UserID != "user-123" {
+			t.Errorf("expected sign in record of user-123, got %q", stored.UserID)
+		}
+		if err = bcrypt.CompareHashAndPassword([]byte(stored.CodeHash.String), []byte(sent.SignInCode)); err != nil {
+			t.Errorf("expected stored code hash to match sent code: %v", err)
+		}
+		if stored.TokenHash.String == "" || stored.TokenHash.String == sent.SignInToken {
+			t.Error("expected sign in token to be stored hashed")
+		}
+	})
+
+	t.Run("previous code replaced after cooldown", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		previous := database.EmailSignIn{
+			ID:          "signin-old",
+			UserID:      "user-123",
+			DateCreated: time.Now().Add(-model.ResendEmailSignInCodeCooldown - time.Second),
+		}
+
+		mockUserRepo.EXPECT().GetUserByEmail(ctx, "test@example.com").Return(verifiedUser, nil)
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(ctx, "test@example.com").Return(false, nil)
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().GetEmailSignInByUserID(ctx, "user-123").Return(previous, nil)
+		mockUserRepo.EXPECT().SetEmailSignInUsed(ctx, "signin-old", false).Return(nil)
+		mockUserRepo.EXPECT().CreateEmailSignIn(ctx, gomock.Any()).Return(nil)
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), gomock.Any()).Return(false, nil)
+		mockUserRepo.EXPECT().CreateEmailOutboxMessage(ctx, gomock.Any()).Return(nil)
+
+		err := provider.RequestEmailSignIn(ctx, "test@example.com")
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("code sent recently", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		previous := database.EmailSignIn{
+			ID:          "signin-old",
+			UserID:      "user-123",
+			DateCreated: time.Now().Add(-10 * time.Second),
+		}
+
+		mockUserRepo.EXPECT().GetUserByEmail(ctx, "test@example.com").Return(verifiedUser, nil)
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(ctx, "test@example.com").Return(false, nil)
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().GetEmailSignInByUserID(ctx, "user-123").Return(previous, nil)
+
+		err := provider.RequestEmailSignIn(ctx, "test@example.com")
+
+		tooManyRequestsErr := facade.AsTooManyRequestsError(err)
+		if tooManyRequestsErr == nil {
+			t.Fatalf("expected TooManyRequestsError, got %v", err)
+		}
+		if tooManyRequestsErr.RetryAfter <= 0 || tooManyRequestsErr.RetryAfter > model.ResendEmailSignInCodeCooldown {
+			t.Errorf("unexpected retry after %v", tooManyRequestsErr.RetryAfter)
+		}
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			GetUserByEmail(ctx, "unknown@example.com").
+			Return(database.User{}, database.ErrNotFound)
+
+		err := provider.RequestEmailSignIn(ctx, "unknown@example.com")
+
+		if !errors.Is(err, facade.ErrEmailSignInUnavailable) {
+			t.Errorf("expected ErrEmailSignInUnavailable, got %v", err)
+		}
+	})
+
+	t.Run("email not verified", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := verifiedUser
+		user.EmailVerified = false
+		mockUserRepo.EXPECT().
+			GetUserByEmail(ctx, "test@example.com").
+			Return(user, nil)
+
+		err := provider.RequestEmailSignIn(ctx, "test@example.com")
+
+		if !errors.Is(err, facade.ErrEmailSignInUnavailable) {
+			t.Errorf("expected ErrEmailSignInUnavailable, got %v", err)
+		}
+	})
+
+	t.Run("email unsubscribed", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().GetUserByEmail(ctx, "test@example.com").Return(verifiedUser, nil)
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(ctx, "test@example.com").Return(true, nil)
+
+		err := provider.RequestEmailSignIn(ctx, "test@example.com")
+
+		if !errors.Is(err, facade.ErrEmailSignInUnavailable) {
+			t.Errorf("expected ErrEmailSignInUnavailable, got %v", err)
+		}
+	})
+}
+
+func TestProvider_CompleteEmailSignIn(t *testing.T) {
+	ctx := context.Background()
+
+	user := database.User{
+		ID:            "user-123",
+		Username:      "testuser",
+		Email:         sql.NullString{String: "test@example.com", Valid: true},
+		EmailVerified: true,
+		Role:          model.UserRoleName,
+	}
+	code := "123456"
+	codeHash, _ := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+
+	newSignIn := func(createdAt time.Time) database.EmailSignIn {
+		return database.EmailSignIn{
+			ID:          "signin-123",
+			UserID:      "user-123",
+			CodeHash:    sql.NullString{String: string(codeHash), Valid: true},
+			DateCreated: createdAt,
+		}
+	}
+
+	t.Run("successful sign in", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().GetUserByEmail(ctx, "test@example.com").Return(user, nil)
+		mockUserRepo.EXPECT().GetEmailSignInByUserID(ctx, "user-123").Return(newSignIn(time.Now()), nil)
+		mockUserRepo.EXPECT().SetEmailSignInUsed(ctx, "signin-123", true).Return(nil)
+
+		result, err := provider.CompleteEmailSignIn(ctx, "test@example.com", code)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result.ID != "user-123" {
+			t.Errorf("expected user-123, got %q", result.ID)
+		}
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().GetUserByEmail(ctx, "test@example.com").Return(user, nil)
+		mockUserRepo.EXPECT().GetEmailSignInByUserID(ctx, "user-123").Return(newSignIn(time.Now()), nil)
+		mockUserRepo.EXPECT().IncrementEmailSignInFailedAttempts(ctx, "signin-123").Return(1, nil)
+
+		_, err := provider.CompleteEmailSignIn(ctx, "test@example.com", "654321")
+
+		if !errors.Is(err, facade.ErrEmailSignInInvalidOrExpired) {
+			t.Errorf("expected ErrEmailSignInInvalidOrExpired, got %v", err)
+		}
+	})
+
+	t.Run("too many failed attempts", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().GetUserByEmail(ctx, "test@example.com").Return(user, nil)
+		mockUserRepo.EXPECT().GetEmailSignInByUserID(ctx, "user-123").Return(newSignIn(time.Now()), nil)
+		mockUserRepo.EXPECT().
+			IncrementEmailSignInFailedAttempts(ctx, "signin-123").
+			Return(model.MaxEmailSignInCodeAttempts, nil)
+		mockUserRepo.EXPECT().SetEmailSignInUsed(ctx, "signin-123", false).Return(nil)
+
+		_, err := provider.CompleteEmailSignIn(ctx, "test@example.com", "654321")
+
+		if !errors.Is(err, facade.ErrEmailSignInTooManyAttempts) {
+			t.Errorf("expected ErrEmailSignInTooManyAttempts, got %v", err)
+		}
+	})
+
+	t.Run("expired code", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().GetUserByEmail(ctx, "test@example.com").Return(user, nil)
+		mockUserRepo.EXPECT().
+			GetEmailSignInByUserID(ctx, "user-123").
+			Return(newSignIn(time.Now().Add(-model.EmailSignInCodeTTL-time.Minute)), nil)
+		mockUserRepo.EXPECT().SetEmailSignInUsed(ctx, "signin-123", false).Return(nil)
+
+		_, err := provider.CompleteEmailSignIn(ctx, "test@example.com", code)
+
+		if !errors.Is(err, facade.ErrEmailSignInInvalidOrExpired) {
+			t.Errorf("expected ErrEmailSignInInvalidOrExpired, got %v", err)
+		}
+	})
+
+	t.Run("email no longer verified", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		unverifiedUser := user
+		unverifiedUser.EmailVerified = false
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().GetUserByEmail(ctx, "test@example.com").Return(unverifiedUser, nil)
+		mockUserRepo.EXPECT().GetEmailSignInByUserID(ctx, "user-123").Return(newSignIn(time.Now()), nil)
+
+		_, err := provider.CompleteEmailSignIn(ctx, "test@example.com", code)
+
+		if !errors.Is(err, facade.ErrEmailSignInInvalidOrExpired) {
+			t.Errorf("expected ErrEmailSignInInvalidOrExpired, got %v", err)
+		}
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().
+			GetUserByEmail(ctx, "unknown@example.com").
+			Return(database.User{}, database.ErrNotFound)
+
+		_, err := provider.CompleteEmailSignIn(ctx, "unknown@example.com", code)
+
+		if !errors.Is(err, facade.ErrEmailSignInInvalidOrExpired) {
+			t.Errorf("expected ErrEmailSignInInvalidOrExpired, got %v", err)
+		}
+	})
+}
+
+func TestProvider_CompleteEmailSignInWithToken(t *testing.T) {
+	ctx := context.Background()
+
+	user := database.User{
+		ID:            "user-123",
+		Username:      "testuser",
+		Email:         sql.NullString{String: "test@example.com", Valid: true},
+		EmailVerified: true,
+		Role:          model.UserRoleName,
+	}
+
+	t.Run("successful sign in", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		signIn := database.EmailSignIn{ID: "signin-123", UserID: "user-123", DateCreated: time.Now()}
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().GetEmailSignInByTokenHash(ctx, gomock.Not("sign-in-token")).Return(signIn, nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().SetEmailSignInUsed(ctx, "signin-123", true).Return(nil)
+
+		result, err := provider.CompleteEmailSignInWithToken(ctx, "sign-in-token")
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result.ID != "user-123" {
+			t.Errorf("expected user-123, got %q", result.ID)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().
+			GetEmailSignInByTokenHash(ctx, gomock.Any()).
+			Return(database.EmailSignIn{}, database.ErrNotFound)
+
+		_, err := provider.CompleteEmailSignInWithToken(ctx, "unknown-token")
+
+		if !errors.Is(err, facade.ErrEmailSignInInvalidOrExpired) {
+			t.Errorf("expected ErrEmailSignInInvalidOrExpired, got %v", err)
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		signIn := database.EmailSignIn{
+			ID:          "signin-123",
+			UserID:      "user-123",
+			DateCreated: time.Now().Add(-model.EmailSignInCodeTTL - time.Minute),
+		}
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().GetEmailSignInByTokenHash(ctx, gomock.Any()).Return(signIn, nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().SetEmailSignInUsed(ctx, "signin-123", false).Return(nil)
+
+		_, err := provider.CompleteEmailSignInWithToken(ctx, "sign-in-token")
+
+		if !errors.Is(err, facade.ErrEmailSignInInvalidOrExpired) {
+			t.Errorf("expected ErrEmailSignInInvalidOrExpired, got %v", err)
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().
+			GetEmailSignInByTokenHash(ctx, gomock.Any()).
+			Return(database.EmailSignIn{}, errors.New("database error"))
+
+		_, err := provider.CompleteEmailSignInWithToken(ctx, "sign-in-token")
+
+		if err == nil || errors.Is(err, facade.ErrEmailSignInInvalidOrExpired) {
+			t.Errorf("expected database error, got %v", err)
+		}
+	})
+}
@@ -5,15 +5,13 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
-	"math"
 	"math/big"
-	"strconv"
+	"strings"
 	"time"
 
-	"github.com/OutOfStack/game-library-auth/internal/client/resendapi"
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
 	"github.com/OutOfStack/game-library-auth/internal/database"
 	"github.com/OutOfStack/game-library-auth/internal/model"
-	"github.com/cenkalti/backoff/v4"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -25,11 +23,17 @@ var (
 	ErrVerifyEmailAlreadyVerified  = errors.New("verify email: already verified")
 	ErrVerifyEmailInvalidOrExpired = errors.New("verify email: invalid or expired code")
 	ErrSendVerifyEmailUnsubscribed = errors.New("send verify email: user is unsubscribed")
+	ErrVerifyEmailTooManyAttempts  = errors.New("verify email: too many failed attempts")
+	ErrAddEmailAlreadySet          = errors.New("add email: user already has an email")
+	ErrAddEmailTaken               = errors.New("add email: email is already in use")
 )
 
-// VerifyEmail verifies user email by provided code
+// VerifyEmail verifies user email by provided code.
+// Code is invalidated after too many failed attempts and ErrVerifyEmailTooManyAttempts is returned
 func (p *Provider) VerifyEmail(ctx context.Context, userID string, code string) (model.User, error) {
 	var user database.User
+	// failed verification result. Transaction is committed to persist failed attempts and invalidated codes
+	var verifyErr error
 
 	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
 		var err error
@@ -59,7 +63,7 @@ func (p *Provider) VerifyEmail(ctx context.Context, userID string, code string)
 		}
 
 		// check expiration
-		if verification.IsExpired() {
+		if verification.IsExpired(p.emailCfg.VerificationCodeTTL) {
 			if err = p.userRepo.SetEmailVerificationUsed(ctx, verification.ID, false); err != nil {
 				p.log.Error("clear expired verification", zap.String("verificationID", verification.ID), zap.Error(err))
 				return err
@@ -67,34 +71,73 @@ func (p *Provider) VerifyEmail(ctx context.Context, userID string, code string)
 			return ErrVerifyEmailInvalidOrExpired
 		}
 
-		// compare codes
-		if err = bcrypt.CompareHashAndPassword([]byte(verification.CodeHash.String), []byte(code)); err != nil {
-			return ErrVerifyEmailInvalidOrExpired
+		// compare codes. Alphanumeric codes are case-insensitive
+		if err = bcrypt.CompareHashAndPassword([]byte(verification.CodeHash.String), []byte(strings.ToUpper(code))); err != nil {
+			invalidated, aErr := p.registerFailedVerificationAttempt(ctx, verification.ID)
+			if aErr != nil {
+				return aErr
+			}
+			verifyErr = ErrVerifyEmailInvalidOrExpired
+			if invalidated {
+				verifyErr = ErrVerifyEmailTooManyAttempts
+			}
+			return nil
 		}
 
-		// set verified
-		if err = p.userRepo.SetUserEmailVerified(ctx, userID); err != nil {
-			p.log.Error("set user email verified", zap.String("userID", verification.UserID), zap.Error(err))
+		if err = p.completeEmailVerification(ctx, verification); err != nil {
 			return err
 		}
 		user.EmailVerified = true
 
-		// mark verification as used
-		if err = p.userRepo.SetEmailVerificationUsed(ctx, verification.ID, true); err != nil {
-			p.log.Error("mark email verification used", zap.String("verificationID", verification.ID), zap.Error(err))
-			return err
-		}
-
 		return nil
 	})
 	if txErr != nil {
 		return model.User{}, txErr
 	}
+	if verifyErr != nil {
+		return model.User{}, verifyErr
+	}
 
 	return mapDBUserToUser(user), nil
 }
 
-// ResendVerificationEmail resends email verification code to a user
+// sets user email verified and marks verification as used, so neither code nor link can be used again
+func (p *Provider) completeEmailVerification(ctx context.Context, verification database.EmailVerification) error {
+	// set verified
+	if err := p.userRepo.SetUserEmailVerified(ctx, verification.UserID); err != nil {
+		p.log.Error("set user email verified", zap.String("userID", verification.UserID), zap.Error(err))
+		return err
+	}
+
+	// mark verification as used
+	if err := p.userRepo.SetEmailVerificationUsed(ctx, verification.ID, true); err != nil {
+		p.log.Error("mark email verification used", zap.String("verificationID", verification.ID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// counts failed attempt to enter verification code. Returns true if code was invalidated after too many failed attempts
+func (p *Provider) registerFailedVerificationAttempt(ctx context.Context, verificationID string) (bool, error) {
+	failedAttempts, err := p.userRepo.IncrementEmailVerificationFailedAttempts(ctx, verificationID)
+	if err != nil {
+		p.log.Error("increment email verification failed attempts", zap.String("verificationID", verificationID), zap.Error(err))
+		return false, err
+	}
+	if failedAttempts < model.MaxVerificationCodeAttempts {
+		return false, nil
+	}
+
+	if err = p.userRepo.SetEmailVerificationUsed(ctx, verificationID, false); err != nil {
+		p.log.Error("invalidate email verification", zap.String("verificationID", verificationID), zap.Error(err))
+		return false, err
+	}
+	return true, nil
+}
+
+// ResendVerificationEmail resends email verification code to a user.
+// Returns EmailDelayedError while sending quota of email provider is exhausted
 func (p *Provider) ResendVerificationEmail(ctx context.Context, userID string) error {
 	// get user
 	user, err := p.userRepo.GetUserByID(ctx, userID)
@@ -103,28 +146,67 @@ func (p *Provider) ResendVerificationEmail(ctx context.Context, userID string) e
 		return err
 	}
 
-	// check if already verified.
-	// only publishers require email verification
-	if user.EmailVerified || user.Role != model.PublisherRoleName {
-		return ErrVerifyEmailAlreadyVerified
-	}
-
 	// check if user has an email address
 	if !user.Email.Valid {
 		return ErrResendVerificationNoEmail
 	}
 
+	// check if already verified
+	if user.EmailVerified {
+		return ErrVerifyEmailAlreadyVerified
+	}
+
+	// new code is not sent until email quota is reset, code sent earlier stays valid
+	if delay, delayed := p.EmailDeliveryDelay(); delayed {
+		return NewEmailDelayedError(delay)
+	}
+
 	// send verification email
-	if err = p.sendVerificationEmail(ctx, user.ID, user.Email.String, user.Username); err != nil {
+	if err = p.sendVerificationEmail(ctx, user.ID, user.Email.String, user.Username, user.Locale); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddEmail adds email address to user who doesn't have one and sends verification code to it.
+// Email address of user who already has one is changed with RequestEmailChange
+func (p *Provider) AddEmail(ctx context.Context, userID, email string) error {
+	// unique index on email is case-sensitive, address is stored in lower case
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	user, err := p.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		p.log.Error("get user by id", zap.String("userID", userID), zap.Error(err))
 		return err
 	}
 
+	if user.Email.Valid {
+		return ErrAddEmailAlreadySet
+	}
+
+	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		if err = p.userRepo.UpdateUserEmail(ctx, user.ID, email, false); err != nil {
+			if errors.Is(err, database.ErrUserExists) {
+				return ErrAddEmailTaken
+			}
+			p.log.Error("set user email", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+
+		return p.sendVerificationEmail(ctx, user.ID, email, user.Username, user.Locale)
+	})
+	if txErr != nil {
+		return txErr
+	}
+
 	return nil
 }
 
-// sends verification email. Returns ErrTooManyRequests if email was sent recently
-func (p *Provider) sendVerificationEmail(ctx context.Context, userID string, email, username string) error {
-	// check if email is unsubscribed
+// sends verification email. Returns ErrTooManyRequests if email was sent recently.
+// Verification email is security email, so it is skipped only for addresses unsubscribed after bounce or complaint
+func (p *Provider) sendVerificationEmail(ctx context.Context, userID string, email, username, locale string) error {
+	// check if email is unsubscribed after bounce or complaint
 	isUnsubscribed, uErr := p.userRepo.IsEmailUnsubscribed(ctx, email)
 	if uErr != nil {
 		p.log.Error("check email unsubscribe status", zap.String("email", email), zap.Error(uErr))
@@ -144,9 +226,8 @@ func (p *Provider) sendVerificationEmail(ctx context.Context, userID string, ema
 		if err == nil {
 			// if sent before resend cooldown, don't resend
 			// if sent after resend cooldown, resend
-			if time.Since(vrfRecord.DateCreated) < model.ResendVerificationCodeCooldown {
-				retryAfter := model.ResendVerificationCodeCooldown - time.Since(vrfRecord.DateCreated)
-				return NewTooManyRequestsError(retryAfter)
+			if err = checkResendCooldown(vrfRecord.DateCreated, p.emailCfg.ResendVerificationCooldown); err != nil {
+				return err
 			}
 
 			// mark verification as used
@@ -161,16 +242,18 @@ func (p *Provider) sendVerificationEmail(ctx context.Context, userID string, ema
 			return fmt.Errorf("create verification record: %w", err)
 		}
 
-		// send verification email
-		messageID, err := p.sendVerificationEmailWithRetry(ctx, email, username, result.Code, result.UnsubscribeToken)
-		if err != nil {
-			return fmt.Errorf("send verification email: %w", err)
-		}
-
-		// set message id
-		err = p.userRepo.SetEmailVerificationMessageID(ctx, result.ID, messageID)
+		// queue verification email. Email is sent by outbox dispatcher after transaction is committed
+		err = p.enqueueEmail(ctx, model.EmailKindVerification, userID, email, result.ID, mailer.SendEmailVerificationRequest{
+			Email:             email,
+			Username:          username,
+			Locale:            locale,
+			VerificationCode:  result.Code,
+			VerificationToken: result.VerificationToken,
+			CodeTTL:           p.emailCfg.VerificationCodeTTL,
+			UnsubscribeToken:  result.UnsubscribeToken,
+		})
 		if err != nil {
-			return fmt.Errorf("set email verification message_id: %w", err)
+			return fmt.Errorf("queue verification email: %w", err)
 		}
 
 		return nil
@@ -184,60 +267,72 @@ func (p *Provider) sendVerificationEmail(ctx context.Context, userID string, ema
 
 // creates a new email verification record and returns the result
 func (p *Provider) createEmailVerificationRecord(ctx context.Context, userID, email string) (emailVerificationResult, error) {
-	code := generate6DigitCode()
-
-	// hash the code
-	codeHash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	code, codeHash, err := generateHashedCode(p.emailCfg.VerificationCodeLength, verificationCodeChars(p.emailCfg.VerificationCodeAlphabet))
 	if err != nil {
-		return emailVerificationResult{}, fmt.Errorf("hash verification code: %w", err)
+		return emailVerificationResult{}, err
 	}
 
 	// generate unsubscribe token
 	now := time.Now()
-	expiresAt := now.Add(model.UnsubscribeTokenTTL)
+	expiresAt := now.Add(p.emailCfg.UnsubscribeTokenTTL)
 	unsubscribeToken := p.unsubscribeTokenGenerator.GenerateToken(email, expiresAt)
 
-	verification := database.NewEmailVerification(userID, string(codeHash), unsubscribeToken, now)
+	verification := database.NewEmailVerification(userID, codeHash, unsubscribeToken, now)
 
 	if err = p.userRepo.CreateEmailVerification(ctx, verification); err != nil {
 		return emailVerificationResult{}, err
 	}
 
 	return emailVerificationResult{
-		ID:               verification.ID,
-		Code:             code,
-		UnsubscribeToken: unsubscribeToken,
+		ID:   verification.ID,
+		Code: code,
+		// verification link shares expiration and single use with the code
+		VerificationToken: p.verificationTokenGenerator.GenerateToken(verification.ID, now.Add(p.emailCfg.VerificationCodeTTL)),
+		UnsubscribeToken:  unsubscribeToken,
 	}, nil
 }
 
-// sends verification email with retry logic and returns message id
-func (p *Provider) sendVerificationEmailWithRetry(ctx context.Context, email, username, code, unsubscribeToken string) (messageID string, err error) {
-	op := func() error {
-		messageID, err = p.emailSender.SendEmailVerification(ctx, resendapi.SendEmailVerificationRequest{
-			Email:            email,
-			Username:         username,
-			VerificationCode: code,
-			UnsubscribeToken: unsubscribeToken,
-		})
-		return err
+// returns TooManyRequestsError if code sent at provided time is still within resend cooldown
+func checkResendCooldown(sentAt time.Time, cooldown time.Duration) error {
+	if sinceSent := time.Since(sentAt); sinceSent < cooldown {
+		return NewTooManyRequestsError(cooldown - sinceSent)
+	}
+	return nil
+}
+
+// generates a secure random code of provided length from provided characters and returns it with its hash
+func generateHashedCode(length int, chars string) (code string, codeHash string, err error) {
+	code, err = generateCode(length, chars)
+	if err != nil {
+		return "", "", err
 	}
 
-	bo := backoff.NewExponentialBackOff([]backoff.ExponentialBackOffOpts{
-		backoff.WithInitialInterval(30 * time.Millisecond),
-		backoff.WithMaxInterval(500 * time.Millisecond),
-		backoff.WithMaxElapsedTime(3 * time.Second),
-	}...)
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return "", "", fmt.Errorf("hash code: %w", err)
+	}
 
-	err = backoff.Retry(op, backoff.WithContext(bo, ctx))
-	return messageID, err
+	return code, string(hash), nil
 }
 
-// generates a secure random 6-digit verification code
-func generate6DigitCode() string {
-	var minVal = int64(math.Pow10(defaultVrfCodeLen - 1))  // min codeLen len value
-	var maxValPlus1 = int64(math.Pow10(defaultVrfCodeLen)) // max codeLen len value + 1, but it is exclusive
+// generates a secure random code of provided length from provided characters
+func generateCode(length int, chars string) (string, error) {
+	code := make([]byte, length)
+	maxIdx := big.NewInt(int64(len(chars)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, maxIdx)
+		if err != nil {
+			return "", fmt.Errorf("generate code: %w", err)
+		}
+		code[i] = chars[n.Int64()]
+	}
+	return string(code), nil
+}
 
-	// generate random number with codeLen
-	n, _ := rand.Int(rand.Reader, big.NewInt(maxValPlus1-minVal)) // for codeLen = 2: range [0, 90)
-	return strconv.FormatInt(n.Int64()+minVal, 10)                // for codeLen = 2: [0, 90] + 10 = [10; 100)
+// returns characters of verification code alphabet
+func verificationCodeChars(alphabet string) string {
+	if alphabet == model.VerificationCodeAlphabetAlphanumeric {
+		return alphanumericCodeChars
+	}
+	return numericCodeChars
 }
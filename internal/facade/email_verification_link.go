@@ -0,0 +1,88 @@
+package facade
+
+import (
+	"context"
+	"errors"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"go.uber.org/zap"
+)
+
+// CheckEmailVerificationLink checks email verification link token and returns email address to be verified.
+// Returns ErrVerifyEmailInvalidOrExpired if link is invalid, expired or verification was already used
+func (p *Provider) CheckEmailVerificationLink(ctx context.Context, token string) (string, error) {
+	verification, user, err := p.getEmailVerificationByLink(ctx, token)
+	if err != nil {
+		return "", err
+	}
+
+	if user.EmailVerified {
+		return "", ErrVerifyEmailAlreadyVerified
+	}
+	if verification.IsExpired(p.emailCfg.VerificationCodeTTL) {
+		return "", ErrVerifyEmailInvalidOrExpired
+	}
+
+	return user.Email.String, nil
+}
+
+// VerifyEmailWithLink verifies user email by email verification link token. User doesn't have to be signed in.
+// Link and code sent in the same email are single-use together: using one invalidates the other
+func (p *Provider) VerifyEmailWithLink(ctx context.Context, token string) (string, error) {
+	var email string
+
+	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		verification, user, err := p.getEmailVerificationByLink(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		if user.EmailVerified {
+			return ErrVerifyEmailAlreadyVerified
+		}
+		if verification.IsExpired(p.emailCfg.VerificationCodeTTL) {
+			return ErrVerifyEmailInvalidOrExpired
+		}
+
+		if err = p.completeEmailVerification(ctx, verification); err != nil {
+			return err
+		}
+		email = user.Email.String
+
+		return nil
+	})
+	if txErr != nil {
+		return "", txErr
+	}
+
+	return email, nil
+}
+
+// validates email verification link token and returns unused verification and its user
+func (p *Provider) getEmailVerificationByLink(ctx context.Context, token string) (database.EmailVerification, database.User, error) {
+	verificationID, err := p.verificationTokenGenerator.ValidateToken(token)
+	if err != nil {
+		p.log.Info("validate email verification token", zap.Error(err))
+		return database.EmailVerification{}, database.User{}, ErrVerifyEmailInvalidOrExpired
+	}
+
+	verification, err := p.userRepo.GetEmailVerificationByID(ctx, verificationID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return database.EmailVerification{}, database.User{}, ErrVerifyEmailInvalidOrExpired
+		}
+		p.log.Error("get email verification by id", zap.String("verificationID", verificationID), zap.Error(err))
+		return database.EmailVerification{}, database.User{}, err
+	}
+
+	user, err := p.userRepo.GetUserByID(ctx, verification.UserID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return database.EmailVerification{}, database.User{}, ErrVerifyEmailUserNotFound
+		}
+		p.log.Error("get user by id", zap.String("userID", verification.UserID), zap.Error(err))
+		return database.EmailVerification{}, database.User{}, err
+	}
+
+	return verification, user, nil
+}
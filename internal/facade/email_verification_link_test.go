@@ -0,0 +1,157 @@
+package facade_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProvider_VerifyEmailWithLink(t *testing.T) {
+	ctx := context.Background()
+	tokenGenerator := auth.NewEmailVerificationTokenGenerator(newTestSignedTokens(t, testVerificationSecret), nil)
+	token := tokenGenerator.GenerateToken("verification-123", time.Now().Add(time.Hour))
+
+	user := database.User{
+		ID:            "user-123",
+		Username:      "testuser",
+		Email:         sql.NullString{String: "test@example.com", Valid: true},
+		EmailVerified: false,
+		Role:          model.PublisherRoleName,
+	}
+	verification := database.EmailVerification{
+		ID:          "verification-123",
+		UserID:      "user-123",
+		CodeHash:    sql.NullString{String: "code-hash", Valid: true},
+		DateCreated: time.Now(),
+	}
+
+	t.Run("successful verification", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().GetEmailVerificationByID(ctx, "verification-123").Return(verification, nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().SetUserEmailVerified(ctx, "user-123").Return(nil)
+		mockUserRepo.EXPECT().SetEmailVerificationUsed(ctx, "verification-123", true).Return(nil)
+
+		email, err := provider.VerifyEmailWithLink(ctx, token)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if email != "test@example.com" {
+			t.Errorf("expected email test@example.com, got %s", email)
+		}
+	})
+
+	t.Run("invalid token signature", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		otherToken := auth.NewEmailVerificationTokenGenerator(newTestSignedTokens(t, "other-secret"), nil).GenerateToken("verification-123", time.Now().Add(time.Hour))
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+
+		_, err := provider.VerifyEmailWithLink(ctx, otherToken)
+
+		if !errors.Is(err, facade.ErrVerifyEmailInvalidOrExpired) {
+			t.Errorf("expected ErrVerifyEmailInvalidOrExpired, got %v", err)
+		}
+	})
+
+	t.Run("verification already used by code", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().
+			GetEmailVerificationByID(ctx, "verification-123").
+			Return(database.EmailVerification{}, database.ErrNotFound)
+
+		_, err := provider.VerifyEmailWithLink(ctx, token)
+
+		if !errors.Is(err, facade.ErrVerifyEmailInvalidOrExpired) {
+			t.Errorf("expected ErrVerifyEmailInvalidOrExpired, got %v", err)
+		}
+	})
+
+	t.Run("email already verified", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		verifiedUser := user
+		verifiedUser.EmailVerified = true
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().GetEmailVerificationByID(ctx, "verification-123").Return(verification, nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(verifiedUser, nil)
+
+		_, err := provider.VerifyEmailWithLink(ctx, token)
+
+		if !errors.Is(err, facade.ErrVerifyEmailAlreadyVerified) {
+			t.Errorf("expected ErrVerifyEmailAlreadyVerified, got %v", err)
+		}
+	})
+}
+
+func TestProvider_CheckEmailVerificationLink(t *testing.T) {
+	ctx := context.Background()
+	tokenGenerator := auth.NewEmailVerificationTokenGenerator(newTestSignedTokens(t, testVerificationSecret), nil)
+
+	t.Run("valid link", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			GetEmailVerificationByID(ctx, "verification-123").
+			Return(database.EmailVerification{ID: "verification-123", UserID: "user-123", DateCreated: time.Now()}, nil)
+		mockUserRepo.EXPECT().
+			GetUserByID(ctx, "user-123").
+			Return(database.User{ID: "user-123", Email: sql.NullString{String: "test@example.com", Valid: true}}, nil)
+
+		email, err := provider.CheckEmailVerificationLink(ctx, tokenGenerator.GenerateToken("verification-123", time.Now().Add(time.Hour)))
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if email != "test@example.com" {
+			t.Errorf("expected email test@example.com, got %s", email)
+		}
+	})
+
+	t.Run("expired link", func(t *testing.T) {
+		provider, _, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		_, err := provider.CheckEmailVerificationLink(ctx, tokenGenerator.GenerateToken("verification-123", time.Now().Add(-time.Minute)))
+
+		if !errors.Is(err, facade.ErrVerifyEmailInvalidOrExpired) {
+			t.Errorf("expected ErrVerifyEmailInvalidOrExpired, got %v", err)
+		}
+	})
+}
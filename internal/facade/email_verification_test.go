@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"regexp"
 	"testing"
 	"time"
 
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
 	"github.com/OutOfStack/game-library-auth/internal/database"
 	"github.com/OutOfStack/game-library-auth/internal/facade"
 	"github.com/OutOfStack/game-library-auth/internal/model"
@@ -70,6 +72,42 @@ func TestProvider_VerifyEmail(t *testing.T) {
 		}
 	})
 
+	t.Run("alphanumeric code is case-insensitive", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := database.User{
+			ID:            "user-123",
+			Username:      "testuser",
+			EmailVerified: false,
+			Role:          model.UserRoleName,
+		}
+
+		codeHash, _ := bcrypt.GenerateFromPassword([]byte("AB3D5F"), bcrypt.DefaultCost)
+		verification := database.EmailVerification{
+			ID:          "verification-123",
+			UserID:      "user-123",
+			CodeHash:    sql.NullString{String: string(codeHash), Valid: true},
+			DateCreated: time.Now(),
+		}
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetEmailVerificationByUserID(ctx, "user-123").Return(verification, nil)
+		mockUserRepo.EXPECT().SetUserEmailVerified(ctx, "user-123").Return(nil)
+		mockUserRepo.EXPECT().SetEmailVerificationUsed(ctx, "verification-123", true).Return(nil)
+
+		_, err := provider.VerifyEmail(ctx, "user-123", "ab3d5f")
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
 	t.Run("user not found", func(t *testing.T) {
 		provider, mockUserRepo, _, _, ctrl := setupTest(t)
 		defer ctrl.Finish()
@@ -230,19 +268,72 @@ func TestProvider_VerifyEmail(t *testing.T) {
 			GetEmailVerificationByUserID(ctx, "user-123").
 			Return(verification, nil)
 
+		mockUserRepo.EXPECT().
+			IncrementEmailVerificationFailedAttempts(ctx, "verification-123").
+			Return(1, nil)
+
 		_, err := provider.VerifyEmail(ctx, "user-123", "wrongcode")
 
 		if !errors.Is(err, facade.ErrVerifyEmailInvalidOrExpired) {
 			t.Errorf("expected ErrVerifyEmailInvalidOrExpired, got %v", err)
 		}
 	})
+
+	t.Run("too many failed attempts", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := database.User{
+			ID:            "user-123",
+			Username:      "testuser",
+			EmailVerified: false,
+			Role:          model.UserRoleName,
+		}
+
+		codeHash, _ := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
+		verification := database.EmailVerification{
+			ID:             "verification-123",
+			UserID:         "user-123",
+			CodeHash:       sql.NullString{String: string(codeHash), Valid: true},
+			FailedAttempts: model.MaxVerificationCodeAttempts - 1,
+			DateCreated:    time.Now(),
+		}
+
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+
+		mockUserRepo.EXPECT().
+			GetUserByID(ctx, "user-123").
+			Return(user, nil)
+
+		mockUserRepo.EXPECT().
+			GetEmailVerificationByUserID(ctx, "user-123").
+			Return(verification, nil)
+
+		mockUserRepo.EXPECT().
+			IncrementEmailVerificationFailedAttempts(ctx, "verification-123").
+			Return(model.MaxVerificationCodeAttempts, nil)
+
+		mockUserRepo.EXPECT().
+			SetEmailVerificationUsed(ctx, "verification-123", false).
+			Return(nil)
+
+		_, err := provider.VerifyEmail(ctx, "user-123", "654321")
+
+		if !errors.Is(err, facade.ErrVerifyEmailTooManyAttempts) {
+			t.Errorf("expected ErrVerifyEmailTooManyAttempts, got %v", err)
+		}
+	})
 }
 
 func TestProvider_ResendVerificationEmail(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("successful resend with email sender enabled", func(t *testing.T) {
-		provider, mockUserRepo, mockEmailSender, _, ctrl := setupTest(t)
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
 		defer ctrl.Finish()
 
 		user := database.User{
@@ -279,14 +370,10 @@ func TestProvider_ResendVerificationEmail(t *testing.T) {
 			CreateEmailVerification(ctx, gomock.Any()).
 			Return(nil)
 
-		// mock email sending
-		mockEmailSender.EXPECT().
-			SendEmailVerification(ctx, gomock.Any()).
-			Return("message-id-123", nil)
-
-		// mock setting message ID
+		// mock queueing email
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), gomock.Any()).Return(false, nil)
 		mockUserRepo.EXPECT().
-			SetEmailVerificationMessageID(ctx, gomock.Any(), "message-id-123").
+			CreateEmailOutboxMessage(ctx, gomock.Any()).
 			Return(nil)
 
 		err := provider.ResendVerificationEmail(ctx, "user-123")
@@ -296,6 +383,65 @@ func TestProvider_ResendVerificationEmail(t *testing.T) {
 		}
 	})
 
+	t.Run("alphanumeric code of configured length", func(t *testing.T) {
+		emailCfg := testEmailCfg
+		emailCfg.VerificationCodeLength = 8
+		emailCfg.VerificationCodeAlphabet = model.VerificationCodeAlphabetAlphanumeric
+		provider, mockUserRepo, _, _, ctrl := setupTestWithEmailCfg(t, emailCfg)
+		defer ctrl.Finish()
+
+		user := database.User{
+			ID:            "user-123",
+			Username:      "testuser",
+			Email:         sql.NullString{String: "test@example.com", Valid: true},
+			EmailVerified: false,
+			Role:          model.PublisherRoleName,
+		}
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(ctx, "test@example.com").Return(false, nil)
+		mockUserRepo.EXPECT().
+			RunWithTx(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+				return f(ctx)
+			})
+		mockUserRepo.EXPECT().
+			GetEmailVerificationByUserID(ctx, "user-123").
+			Return(database.EmailVerification{}, database.ErrNotFound)
+
+		var codeHash string
+		mockUserRepo.EXPECT().
+			CreateEmailVerification(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, verification database.EmailVerification) error {
+				codeHash = verification.CodeHash.String
+				return nil
+			})
+
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), gomock.Any()).Return(false, nil)
+		mockUserRepo.EXPECT().
+			CreateEmailOutboxMessage(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg database.EmailOutboxMessage) error {
+				var req mailer.SendEmailVerificationRequest
+				decodeOutboxPayload(t, msg, &req)
+				if !regexp.MustCompile(`^[A-HJ-NP-Z2-9]{8}$`).MatchString(req.VerificationCode) {
+					t.Errorf("expected 8 characters alphanumeric code, got %q", req.VerificationCode)
+				}
+				if err := bcrypt.CompareHashAndPassword([]byte(codeHash), []byte(req.VerificationCode)); err != nil {
+					t.Errorf("expected code to match stored hash: %v", err)
+				}
+				if req.CodeTTL != emailCfg.VerificationCodeTTL {
+					t.Errorf("expected code ttl %v, got %v", emailCfg.VerificationCodeTTL, req.CodeTTL)
+				}
+				return nil
+			})
+
+		err := provider.ResendVerificationEmail(ctx, "user-123")
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
 	t.Run("email already verified", func(t *testing.T) {
 		provider, mockUserRepo, _, _, ctrl := setupTest(t)
 		defer ctrl.Finish()
@@ -342,7 +488,7 @@ func TestProvider_ResendVerificationEmail(t *testing.T) {
 		}
 	})
 
-	t.Run("regular user does not require verification", func(t *testing.T) {
+	t.Run("regular user with unverified email", func(t *testing.T) {
 		provider, mockUserRepo, _, _, ctrl := setupTest(t)
 		defer ctrl.Finish()
 
@@ -354,14 +500,25 @@ func TestProvider_ResendVerificationEmail(t *testing.T) {
 			Role:          model.UserRoleName,
 		}
 
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(ctx, "test@example.com").Return(false, nil)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetEmailVerificationByUserID(ctx, "user-123").Return(database.EmailVerification{}, database.ErrNotFound)
+		mockUserRepo.EXPECT().CreateEmailVerification(ctx, gomock.Any()).Return(nil)
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), gomock.Any()).Return(false, nil)
 		mockUserRepo.EXPECT().
-			GetUserByID(ctx, "user-123").
-			Return(user, nil)
+			CreateEmailOutboxMessage(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg database.EmailOutboxMessage) error {
+				if msg.Kind != model.EmailKindVerification || msg.Recipient != "test@example.com" {
+					t.Errorf("unexpected queued email %q to %q", msg.Kind, msg.Recipient)
+				}
+				return nil
+			})
 
 		err := provider.ResendVerificationEmail(ctx, "user-123")
 
-		if !errors.Is(err, facade.ErrVerifyEmailAlreadyVerified) {
-			t.Errorf("expected ErrVerifyEmailAlreadyVerified, got %v", err)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
 		}
 	})
 
@@ -380,3 +537,104 @@ func TestProvider_ResendVerificationEmail(t *testing.T) {
 		}
 	})
 }
+
+func TestProvider_AddEmail(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("regular user adds email in lower case", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := database.User{
+			ID:       "user-123",
+			Username: "testuser",
+			Role:     model.UserRoleName,
+		}
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().UpdateUserEmail(ctx, "user-123", "test@example.com", false).Return(nil)
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(ctx, "test@example.com").Return(false, nil)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetEmailVerificationByUserID(ctx, "user-123").Return(database.EmailVerification{}, database.ErrNotFound)
+		mockUserRepo.EXPECT().CreateEmailVerification(ctx, gomock.Any()).Return(nil)
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), gomock.Any()).Return(false, nil)
+		mockUserRepo.EXPECT().
+			CreateEmailOutboxMessage(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg database.EmailOutboxMessage) error {
+				if msg.Kind != model.EmailKindVerification || msg.Recipient != "test@example.com" {
+					t.Errorf("unexpected queued email %q to %q", msg.Kind, msg.Recipient)
+				}
+				return nil
+			})
+
+		err := provider.AddEmail(ctx, "user-123", " Test@Example.com ")
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("user already has email", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := database.User{
+			ID:       "user-123",
+			Username: "testuser",
+			Email:    sql.NullString{String: "old@example.com", Valid: true},
+			Role:     model.UserRoleName,
+		}
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+
+		err := provider.AddEmail(ctx, "user-123", "test@example.com")
+
+		if !errors.Is(err, facade.ErrAddEmailAlreadySet) {
+			t.Errorf("expected ErrAddEmailAlreadySet, got %v", err)
+		}
+	})
+
+	t.Run("email is taken", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := database.User{
+			ID:       "user-123",
+			Username: "testuser",
+			Role:     model.UserRoleName,
+		}
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().UpdateUserEmail(ctx, "user-123", "test@example.com", false).Return(database.ErrUserExists)
+
+		err := provider.AddEmail(ctx, "user-123", "test@example.com")
+
+		if !errors.Is(err, facade.ErrAddEmailTaken) {
+			t.Errorf("expected ErrAddEmailTaken, got %v", err)
+		}
+	})
+
+	t.Run("email is unsubscribed", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := database.User{
+			ID:       "user-123",
+			Username: "testuser",
+			Role:     model.UserRoleName,
+		}
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().UpdateUserEmail(ctx, "user-123", "test@example.com", false).Return(nil)
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(ctx, "test@example.com").Return(true, nil)
+
+		err := provider.AddEmail(ctx, "user-123", "test@example.com")
+
+		if !errors.Is(err, facade.ErrSendVerifyEmailUnsubscribed) {
+			t.Errorf("expected ErrSendVerifyEmailUnsubscribed, got %v", err)
+		}
+	})
+}
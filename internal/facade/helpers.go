@@ -12,8 +12,21 @@ func mapDBUserToUser(user database.User) model.User {
 		DisplayName:   user.DisplayName,
 		Email:         user.Email.String,
 		EmailVerified: user.EmailVerified,
+		Locale:        user.Locale,
 		Role:          string(user.Role),
 		OAuthProvider: user.OAuthProvider.String,
 		OAuthID:       user.OAuthID.String,
 	}
 }
+
+func mapDBWebAuthnCredentialToPasskey(credential database.WebAuthnCredential) model.Passkey {
+	passkey := model.Passkey{
+		ID:          credential.ID,
+		Name:        credential.Name,
+		DateCreated: credential.DateCreated,
+	}
+	if credential.LastUsedAt.Valid {
+		passkey.LastUsedAt = &credential.LastUsedAt.Time
+	}
+	return passkey
+}
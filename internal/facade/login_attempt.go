@@ -0,0 +1,99 @@
+package facade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/zap"
+)
+
+// signInSubject - subject failed sign in attempts are tracked for
+type signInSubject struct {
+	kind         string
+	subject      string
+	freeAttempts int
+}
+
+// returns subjects to track failed sign in attempts for. Client ip is skipped if empty
+func signInSubjects(username, clientIP string) []signInSubject {
+	subjects := []signInSubject{
+		{kind: model.LoginAttemptKindUsername, subject: username, freeAttempts: model.UsernameLoginFreeAttempts},
+	}
+	if clientIP != "" {
+		subjects = append(subjects, signInSubject{kind: model.LoginAttemptKindIP, subject: clientIP, freeAttempts: model.IPLoginFreeAttempts})
+	}
+	return subjects
+}
+
+// checks if sign in is locked for username or client ip. Returns TooManyRequestsError if it is
+func (p *Provider) checkSignInLockout(ctx context.Context, username, clientIP string) error {
+	for _, s := range signInSubjects(username, clientIP) {
+		attempt, err := p.userRepo.GetLoginAttempt(ctx, s.kind, s.subject)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				continue
+			}
+			return fmt.Errorf("get login attempt: %w", err)
+		}
+		if attempt.IsLocked() {
+			return NewTooManyRequestsError(time.Until(attempt.LockedUntil.Time))
+		}
+	}
+
+	return nil
+}
+
+// registers failed sign in attempt for username and client ip and locks sign in when free attempts are exceeded
+func (p *Provider) registerFailedSignIn(ctx context.Context, username, clientIP string) {
+	now := time.Now()
+	for _, s := range signInSubjects(username, clientIP) {
+		attempt, err := p.userRepo.AddFailedLoginAttempt(ctx, s.kind, s.subject, now.Add(-model.LoginFailuresResetWindow))
+		if err != nil {
+			p.log.Error("add failed login attempt", zap.String("kind", s.kind), zap.String("subject", s.subject), zap.Error(err))
+			continue
+		}
+
+		delay := lockoutDelay(attempt.FailedCount, s.freeAttempts)
+		if delay == 0 {
+			continue
+		}
+		if err = p.userRepo.SetLoginAttemptLockedUntil(ctx, s.kind, s.subject, now.Add(delay)); err != nil {
+			p.log.Error("set login attempt locked until", zap.String("kind", s.kind), zap.String("subject", s.subject), zap.Error(err))
+			continue
+		}
+		p.log.Info("sign in locked", zap.String("kind", s.kind), zap.String("subject", s.subject),
+			zap.Int("failedCount", attempt.FailedCount), zap.Duration("delay", delay))
+	}
+}
+
+// resets failed sign in attempts for username after successful sign in.
+// Client ip counter is not reset, so a single valid account can't be used to unlock brute-forcing from the same ip
+func (p *Provider) resetFailedSignIns(ctx context.Context, username string) {
+	if err := p.userRepo.DeleteLoginAttempt(ctx, model.LoginAttemptKindUsername, username); err != nil {
+		p.log.Error("delete login attempt", zap.String("username", username), zap.Error(err))
+	}
+}
+
+// returns lock duration for provided number of failed attempts.
+// No delay is applied within free attempts, after that delay doubles with every failure up to max delay
+func lockoutDelay(failedCount, freeAttempts int) time.Duration {
+	if failedCount <= freeAttempts {
+		return 0
+	}
+
+	exp := failedCount - freeAttempts - 1
+	if exp >= 32 {
+		return model.LoginLockoutMaxDelay
+	}
+
+	delay := model.LoginLockoutBaseDelay << exp
+	if delay <= 0 || delay > model.LoginLockoutMaxDelay {
+		return model.LoginLockoutMaxDelay
+	}
+
+	return delay
+}
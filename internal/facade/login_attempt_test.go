@@ -0,0 +1,181 @@
+package facade_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	mocks "github.com/OutOfStack/game-library-auth/internal/facade/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// expectNoSignInLockout sets expectations for sign in lockout check with no lockout records
+func expectNoSignInLockout(mockUserRepo *mocks.MockUserRepo, username, clientIP string) {
+	mockUserRepo.EXPECT().
+		GetLoginAttempt(gomock.Any(), model.LoginAttemptKindUsername, username).
+		Return(database.LoginAttempt{}, database.ErrNotFound)
+	mockUserRepo.EXPECT().
+		GetLoginAttempt(gomock.Any(), model.LoginAttemptKindIP, clientIP).
+		Return(database.LoginAttempt{}, database.ErrNotFound)
+}
+
+// expectFailedSignIn sets expectations for registering failed sign in attempt with provided failed count
+func expectFailedSignIn(mockUserRepo *mocks.MockUserRepo, username, clientIP string, failedCount int) {
+	mockUserRepo.EXPECT().
+		AddFailedLoginAttempt(gomock.Any(), model.LoginAttemptKindUsername, username, gomock.Any()).
+		Return(database.LoginAttempt{Kind: model.LoginAttemptKindUsername, Subject: username, FailedCount: failedCount}, nil)
+	mockUserRepo.EXPECT().
+		AddFailedLoginAttempt(gomock.Any(), model.LoginAttemptKindIP, clientIP, gomock.Any()).
+		Return(database.LoginAttempt{Kind: model.LoginAttemptKindIP, Subject: clientIP, FailedCount: failedCount}, nil)
+}
+
+func TestProvider_SignIn_Lockout(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("locked username", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			GetLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser").
+			Return(database.LoginAttempt{
+				FailedCount: model.UsernameLoginFreeAttempts + 1,
+				LockedUntil: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+			}, nil)
+
+		_, err := provider.SignIn(ctx, "testuser", "password", "127.0.0.1")
+
+		tooManyRequestsErr := facade.AsTooManyRequestsError(err)
+		if tooManyRequestsErr == nil {
+			t.Fatalf("expected TooManyRequestsError, got %v", err)
+		}
+		if tooManyRequestsErr.RetryAfter <= 0 || tooManyRequestsErr.RetryAfter > time.Minute {
+			t.Errorf("expected retry after within 1m, got %v", tooManyRequestsErr.RetryAfter)
+		}
+	})
+
+	t.Run("locked ip", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			GetLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser").
+			Return(database.LoginAttempt{}, database.ErrNotFound)
+		mockUserRepo.EXPECT().
+			GetLoginAttempt(ctx, model.LoginAttemptKindIP, "127.0.0.1").
+			Return(database.LoginAttempt{
+				FailedCount: model.IPLoginFreeAttempts + 1,
+				LockedUntil: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+			}, nil)
+
+		_, err := provider.SignIn(ctx, "testuser", "password", "127.0.0.1")
+
+		if facade.AsTooManyRequestsError(err) == nil {
+			t.Fatalf("expected TooManyRequestsError, got %v", err)
+		}
+	})
+
+	t.Run("expired lock allows sign in", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		passwordHash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+
+		mockUserRepo.EXPECT().
+			GetLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser").
+			Return(database.LoginAttempt{
+				FailedCount: model.UsernameLoginFreeAttempts + 1,
+				LockedUntil: sql.NullTime{Time: time.Now().Add(-time.Second), Valid: true},
+			}, nil)
+		mockUserRepo.EXPECT().
+			GetLoginAttempt(ctx, model.LoginAttemptKindIP, "127.0.0.1").
+			Return(database.LoginAttempt{}, database.ErrNotFound)
+		mockUserRepo.EXPECT().
+			GetUserByUsername(ctx, "testuser").
+			Return(database.User{ID: "user-123", Username: "testuser", PasswordHash: passwordHash, Role: model.UserRoleName}, nil)
+		mockUserRepo.EXPECT().
+			DeleteLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser").
+			Return(nil)
+
+		_, err := provider.SignIn(ctx, "testuser", "password", "127.0.0.1")
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("failure exceeding free attempts locks username", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectNoSignInLockout(mockUserRepo, "testuser", "127.0.0.1")
+
+		mockUserRepo.EXPECT().
+			GetUserByUsername(ctx, "testuser").
+			Return(database.User{}, database.ErrNotFound)
+
+		mockUserRepo.EXPECT().
+			AddFailedLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser", gomock.Any()).
+			Return(database.LoginAttempt{FailedCount: model.UsernameLoginFreeAttempts + 3}, nil)
+		mockUserRepo.EXPECT().
+			AddFailedLoginAttempt(ctx, model.LoginAttemptKindIP, "127.0.0.1", gomock.Any()).
+			Return(database.LoginAttempt{FailedCount: model.UsernameLoginFreeAttempts + 3}, nil)
+
+		before := time.Now()
+		mockUserRepo.EXPECT().
+			SetLoginAttemptLockedUntil(ctx, model.LoginAttemptKindUsername, "testuser", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, lockedUntil time.Time) error {
+				// third failure over free attempts: base delay doubled twice
+				delay := lockedUntil.Sub(before)
+				if delay < 4*model.LoginLockoutBaseDelay || delay > 5*model.LoginLockoutBaseDelay {
+					t.Errorf("expected lock for ~%v, got %v", 4*model.LoginLockoutBaseDelay, delay)
+				}
+				return nil
+			})
+
+		_, err := provider.SignIn(ctx, "testuser", "password", "127.0.0.1")
+
+		if !errors.Is(err, facade.ErrSignInInvalidCredentials) {
+			t.Errorf("expected ErrSignInInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("lock is capped by max delay", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			GetLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser").
+			Return(database.LoginAttempt{}, database.ErrNotFound)
+
+		mockUserRepo.EXPECT().
+			GetUserByUsername(ctx, "testuser").
+			Return(database.User{}, database.ErrNotFound)
+
+		mockUserRepo.EXPECT().
+			AddFailedLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser", gomock.Any()).
+			Return(database.LoginAttempt{FailedCount: 1000}, nil)
+
+		before := time.Now()
+		mockUserRepo.EXPECT().
+			SetLoginAttemptLockedUntil(ctx, model.LoginAttemptKindUsername, "testuser", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, lockedUntil time.Time) error {
+				if delay := lockedUntil.Sub(before); delay < model.LoginLockoutMaxDelay || delay > model.LoginLockoutMaxDelay+time.Second {
+					t.Errorf("expected lock for %v, got %v", model.LoginLockoutMaxDelay, delay)
+				}
+				return nil
+			})
+
+		_, err := provider.SignIn(ctx, "testuser", "password", "")
+
+		if !errors.Is(err, facade.ErrSignInInvalidCredentials) {
+			t.Errorf("expected ErrSignInInvalidCredentials, got %v", err)
+		}
+	})
+}
@@ -15,7 +15,7 @@ import (
 	time "time"
 
 	auth "github.com/OutOfStack/game-library-auth/internal/auth"
-	resendapi "github.com/OutOfStack/game-library-auth/internal/client/resendapi"
+	mailer "github.com/OutOfStack/game-library-auth/internal/client/mailer"
 	database "github.com/OutOfStack/game-library-auth/internal/database"
 	model "github.com/OutOfStack/game-library-auth/internal/model"
 	jwt "github.com/golang-jwt/jwt/v4"
@@ -60,6 +60,21 @@ func (mr *MockAuthMockRecorder) CreateUserClaims(user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserClaims", reflect.TypeOf((*MockAuth)(nil).CreateUserClaims), user)
 }
 
+// GenerateChallengeToken mocks base method.
+func (m *MockAuth) GenerateChallengeToken(userID string, ttl time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateChallengeToken", userID, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateChallengeToken indicates an expected call of GenerateChallengeToken.
+func (mr *MockAuthMockRecorder) GenerateChallengeToken(userID, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateChallengeToken", reflect.TypeOf((*MockAuth)(nil).GenerateChallengeToken), userID, ttl)
+}
+
 // GenerateRefreshToken mocks base method.
 func (m *MockAuth) GenerateRefreshToken() (string, time.Time, error) {
 	m.ctrl.T.Helper()
@@ -91,6 +106,21 @@ func (mr *MockAuthMockRecorder) GenerateToken(claims any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockAuth)(nil).GenerateToken), claims)
 }
 
+// ValidateChallengeToken mocks base method.
+func (m *MockAuth) ValidateChallengeToken(tokenStr string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateChallengeToken", tokenStr)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateChallengeToken indicates an expected call of ValidateChallengeToken.
+func (mr *MockAuthMockRecorder) ValidateChallengeToken(tokenStr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateChallengeToken", reflect.TypeOf((*MockAuth)(nil).ValidateChallengeToken), tokenStr)
+}
+
 // ValidateToken mocks base method.
 func (m *MockAuth) ValidateToken(tokenStr string) (auth.Claims, error) {
 	m.ctrl.T.Helper()
@@ -130,6 +160,21 @@ func (m *MockUserRepo) EXPECT() *MockUserRepoMockRecorder {
 	return m.recorder
 }
 
+// AddFailedLoginAttempt mocks base method.
+func (m *MockUserRepo) AddFailedLoginAttempt(ctx context.Context, kind, subject string, resetBefore time.Time) (database.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFailedLoginAttempt", ctx, kind, subject, resetBefore)
+	ret0, _ := ret[0].(database.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddFailedLoginAttempt indicates an expected call of AddFailedLoginAttempt.
+func (mr *MockUserRepoMockRecorder) AddFailedLoginAttempt(ctx, kind, subject, resetBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFailedLoginAttempt", reflect.TypeOf((*MockUserRepo)(nil).AddFailedLoginAttempt), ctx, kind, subject, resetBefore)
+}
+
 // CheckUserExists mocks base method.
 func (m *MockUserRepo) CheckUserExists(ctx context.Context, name string, role model.Role) (bool, error) {
 	m.ctrl.T.Helper()
@@ -145,6 +190,135 @@ func (mr *MockUserRepoMockRecorder) CheckUserExists(ctx, name, role any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUserExists", reflect.TypeOf((*MockUserRepo)(nil).CheckUserExists), ctx, name, role)
 }
 
+// ConfirmUserTOTP mocks base method.
+func (m *MockUserRepo) ConfirmUserTOTP(ctx context.Context, userID string, usedStep int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmUserTOTP", ctx, userID, usedStep)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmUserTOTP indicates an expected call of ConfirmUserTOTP.
+func (mr *MockUserRepoMockRecorder) ConfirmUserTOTP(ctx, userID, usedStep any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmUserTOTP", reflect.TypeOf((*MockUserRepo)(nil).ConfirmUserTOTP), ctx, userID, usedStep)
+}
+
+// ConsumeWebAuthnSession mocks base method.
+func (m *MockUserRepo) ConsumeWebAuthnSession(ctx context.Context, id, kind string) (database.WebAuthnSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeWebAuthnSession", ctx, id, kind)
+	ret0, _ := ret[0].(database.WebAuthnSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeWebAuthnSession indicates an expected call of ConsumeWebAuthnSession.
+func (mr *MockUserRepoMockRecorder) ConsumeWebAuthnSession(ctx, id, kind any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeWebAuthnSession", reflect.TypeOf((*MockUserRepo)(nil).ConsumeWebAuthnSession), ctx, id, kind)
+}
+
+// CountUnusedRecoveryCodes mocks base method.
+func (m *MockUserRepo) CountUnusedRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnusedRecoveryCodes", ctx, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnusedRecoveryCodes indicates an expected call of CountUnusedRecoveryCodes.
+func (mr *MockUserRepoMockRecorder) CountUnusedRecoveryCodes(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnusedRecoveryCodes", reflect.TypeOf((*MockUserRepo)(nil).CountUnusedRecoveryCodes), ctx, userID)
+}
+
+// CreateEmailChange mocks base method.
+func (m *MockUserRepo) CreateEmailChange(ctx context.Context, change database.EmailChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailChange", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEmailChange indicates an expected call of CreateEmailChange.
+func (mr *MockUserRepoMockRecorder) CreateEmailChange(ctx, change any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailChange", reflect.TypeOf((*MockUserRepo)(nil).CreateEmailChange), ctx, change)
+}
+
+// CreateEmailDeliveryEvent mocks base method.
+func (m *MockUserRepo) CreateEmailDeliveryEvent(ctx context.Context, event database.EmailDeliveryEvent) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailDeliveryEvent", ctx, event)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmailDeliveryEvent indicates an expected call of CreateEmailDeliveryEvent.
+func (mr *MockUserRepoMockRecorder) CreateEmailDeliveryEvent(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailDeliveryEvent", reflect.TypeOf((*MockUserRepo)(nil).CreateEmailDeliveryEvent), ctx, event)
+}
+
+// CreateEmailMessage mocks base method.
+func (m *MockUserRepo) CreateEmailMessage(ctx context.Context, msg database.EmailMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailMessage", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEmailMessage indicates an expected call of CreateEmailMessage.
+func (mr *MockUserRepoMockRecorder) CreateEmailMessage(ctx, msg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailMessage", reflect.TypeOf((*MockUserRepo)(nil).CreateEmailMessage), ctx, msg)
+}
+
+// CreateEmailOutboxMessage mocks base method.
+func (m *MockUserRepo) CreateEmailOutboxMessage(ctx context.Context, msg database.EmailOutboxMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailOutboxMessage", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEmailOutboxMessage indicates an expected call of CreateEmailOutboxMessage.
+func (mr *MockUserRepoMockRecorder) CreateEmailOutboxMessage(ctx, msg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailOutboxMessage", reflect.TypeOf((*MockUserRepo)(nil).CreateEmailOutboxMessage), ctx, msg)
+}
+
+// CreateEmailResubscribe mocks base method.
+func (m *MockUserRepo) CreateEmailResubscribe(ctx context.Context, resubscribe database.EmailResubscribe) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailResubscribe", ctx, resubscribe)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEmailResubscribe indicates an expected call of CreateEmailResubscribe.
+func (mr *MockUserRepoMockRecorder) CreateEmailResubscribe(ctx, resubscribe any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailResubscribe", reflect.TypeOf((*MockUserRepo)(nil).CreateEmailResubscribe), ctx, resubscribe)
+}
+
+// CreateEmailSignIn mocks base method.
+func (m *MockUserRepo) CreateEmailSignIn(ctx context.Context, signIn database.EmailSignIn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailSignIn", ctx, signIn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEmailSignIn indicates an expected call of CreateEmailSignIn.
+func (mr *MockUserRepoMockRecorder) CreateEmailSignIn(ctx, signIn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailSignIn", reflect.TypeOf((*MockUserRepo)(nil).CreateEmailSignIn), ctx, signIn)
+}
+
 // CreateEmailUnsubscribe mocks base method.
 func (m *MockUserRepo) CreateEmailUnsubscribe(ctx context.Context, unsubscribe database.EmailUnsubscribe) error {
 	m.ctrl.T.Helper()
@@ -173,6 +347,20 @@ func (mr *MockUserRepoMockRecorder) CreateEmailVerification(ctx, verification an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailVerification", reflect.TypeOf((*MockUserRepo)(nil).CreateEmailVerification), ctx, verification)
 }
 
+// CreateRecoveryCode mocks base method.
+func (m *MockUserRepo) CreateRecoveryCode(ctx context.Context, recoveryCode database.RecoveryCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCode", ctx, recoveryCode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRecoveryCode indicates an expected call of CreateRecoveryCode.
+func (mr *MockUserRepoMockRecorder) CreateRecoveryCode(ctx, recoveryCode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCode", reflect.TypeOf((*MockUserRepo)(nil).CreateRecoveryCode), ctx, recoveryCode)
+}
+
 // CreateRefreshToken mocks base method.
 func (m *MockUserRepo) CreateRefreshToken(ctx context.Context, refreshToken database.RefreshToken) error {
 	m.ctrl.T.Helper()
@@ -201,6 +389,90 @@ func (mr *MockUserRepoMockRecorder) CreateUser(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepo)(nil).CreateUser), ctx, user)
 }
 
+// CreateWebAuthnCredential mocks base method.
+func (m *MockUserRepo) CreateWebAuthnCredential(ctx context.Context, credential database.WebAuthnCredential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebAuthnCredential", ctx, credential)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebAuthnCredential indicates an expected call of CreateWebAuthnCredential.
+func (mr *MockUserRepoMockRecorder) CreateWebAuthnCredential(ctx, credential any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebAuthnCredential", reflect.TypeOf((*MockUserRepo)(nil).CreateWebAuthnCredential), ctx, credential)
+}
+
+// CreateWebAuthnSession mocks base method.
+func (m *MockUserRepo) CreateWebAuthnSession(ctx context.Context, session database.WebAuthnSession) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebAuthnSession", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebAuthnSession indicates an expected call of CreateWebAuthnSession.
+func (mr *MockUserRepoMockRecorder) CreateWebAuthnSession(ctx, session any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebAuthnSession", reflect.TypeOf((*MockUserRepo)(nil).CreateWebAuthnSession), ctx, session)
+}
+
+// DeferEmailOutboxMessage mocks base method.
+func (m *MockUserRepo) DeferEmailOutboxMessage(ctx context.Context, id, lastError string, nextAttemptAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeferEmailOutboxMessage", ctx, id, lastError, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeferEmailOutboxMessage indicates an expected call of DeferEmailOutboxMessage.
+func (mr *MockUserRepoMockRecorder) DeferEmailOutboxMessage(ctx, id, lastError, nextAttemptAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeferEmailOutboxMessage", reflect.TypeOf((*MockUserRepo)(nil).DeferEmailOutboxMessage), ctx, id, lastError, nextAttemptAt)
+}
+
+// DeleteEmailUnsubscribe mocks base method.
+func (m *MockUserRepo) DeleteEmailUnsubscribe(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEmailUnsubscribe", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEmailUnsubscribe indicates an expected call of DeleteEmailUnsubscribe.
+func (mr *MockUserRepoMockRecorder) DeleteEmailUnsubscribe(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEmailUnsubscribe", reflect.TypeOf((*MockUserRepo)(nil).DeleteEmailUnsubscribe), ctx, email)
+}
+
+// DeleteLoginAttempt mocks base method.
+func (m *MockUserRepo) DeleteLoginAttempt(ctx context.Context, kind, subject string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginAttempt", ctx, kind, subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoginAttempt indicates an expected call of DeleteLoginAttempt.
+func (mr *MockUserRepoMockRecorder) DeleteLoginAttempt(ctx, kind, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginAttempt", reflect.TypeOf((*MockUserRepo)(nil).DeleteLoginAttempt), ctx, kind, subject)
+}
+
+// DeleteRecoveryCodesByUserID mocks base method.
+func (m *MockUserRepo) DeleteRecoveryCodesByUserID(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodesByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodesByUserID indicates an expected call of DeleteRecoveryCodesByUserID.
+func (mr *MockUserRepoMockRecorder) DeleteRecoveryCodesByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodesByUserID", reflect.TypeOf((*MockUserRepo)(nil).DeleteRecoveryCodesByUserID), ctx, userID)
+}
+
 // DeleteRefreshToken mocks base method.
 func (m *MockUserRepo) DeleteRefreshToken(ctx context.Context, token string) error {
 	m.ctrl.T.Helper()
@@ -243,6 +515,139 @@ func (mr *MockUserRepoMockRecorder) DeleteUser(ctx, userID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserRepo)(nil).DeleteUser), ctx, userID)
 }
 
+// DeleteUserTOTP mocks base method.
+func (m *MockUserRepo) DeleteUserTOTP(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTOTP", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTOTP indicates an expected call of DeleteUserTOTP.
+func (mr *MockUserRepoMockRecorder) DeleteUserTOTP(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTOTP", reflect.TypeOf((*MockUserRepo)(nil).DeleteUserTOTP), ctx, userID)
+}
+
+// DeleteWebAuthnCredential mocks base method.
+func (m *MockUserRepo) DeleteWebAuthnCredential(ctx context.Context, userID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebAuthnCredential", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebAuthnCredential indicates an expected call of DeleteWebAuthnCredential.
+func (mr *MockUserRepoMockRecorder) DeleteWebAuthnCredential(ctx, userID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebAuthnCredential", reflect.TypeOf((*MockUserRepo)(nil).DeleteWebAuthnCredential), ctx, userID, id)
+}
+
+// GetEmailChangeByUserID mocks base method.
+func (m *MockUserRepo) GetEmailChangeByUserID(ctx context.Context, userID string) (database.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailChangeByUserID", ctx, userID)
+	ret0, _ := ret[0].(database.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailChangeByUserID indicates an expected call of GetEmailChangeByUserID.
+func (mr *MockUserRepoMockRecorder) GetEmailChangeByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailChangeByUserID", reflect.TypeOf((*MockUserRepo)(nil).GetEmailChangeByUserID), ctx, userID)
+}
+
+// GetEmailMessagesByUserID mocks base method.
+func (m *MockUserRepo) GetEmailMessagesByUserID(ctx context.Context, userID string, limit int) ([]database.EmailMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailMessagesByUserID", ctx, userID, limit)
+	ret0, _ := ret[0].([]database.EmailMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailMessagesByUserID indicates an expected call of GetEmailMessagesByUserID.
+func (mr *MockUserRepoMockRecorder) GetEmailMessagesByUserID(ctx, userID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailMessagesByUserID", reflect.TypeOf((*MockUserRepo)(nil).GetEmailMessagesByUserID), ctx, userID, limit)
+}
+
+// GetEmailPreferences mocks base method.
+func (m *MockUserRepo) GetEmailPreferences(ctx context.Context, email string) ([]database.EmailPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailPreferences", ctx, email)
+	ret0, _ := ret[0].([]database.EmailPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailPreferences indicates an expected call of GetEmailPreferences.
+func (mr *MockUserRepoMockRecorder) GetEmailPreferences(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailPreferences", reflect.TypeOf((*MockUserRepo)(nil).GetEmailPreferences), ctx, email)
+}
+
+// GetEmailSignInByTokenHash mocks base method.
+func (m *MockUserRepo) GetEmailSignInByTokenHash(ctx context.Context, tokenHash string) (database.EmailSignIn, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailSignInByTokenHash", ctx, tokenHash)
+	ret0, _ := ret[0].(database.EmailSignIn)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailSignInByTokenHash indicates an expected call of GetEmailSignInByTokenHash.
+func (mr *MockUserRepoMockRecorder) GetEmailSignInByTokenHash(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailSignInByTokenHash", reflect.TypeOf((*MockUserRepo)(nil).GetEmailSignInByTokenHash), ctx, tokenHash)
+}
+
+// GetEmailSignInByUserID mocks base method.
+func (m *MockUserRepo) GetEmailSignInByUserID(ctx context.Context, userID string) (database.EmailSignIn, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailSignInByUserID", ctx, userID)
+	ret0, _ := ret[0].(database.EmailSignIn)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailSignInByUserID indicates an expected call of GetEmailSignInByUserID.
+func (mr *MockUserRepoMockRecorder) GetEmailSignInByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailSignInByUserID", reflect.TypeOf((*MockUserRepo)(nil).GetEmailSignInByUserID), ctx, userID)
+}
+
+// GetEmailUnsubscribe mocks base method.
+func (m *MockUserRepo) GetEmailUnsubscribe(ctx context.Context, email string) (database.EmailUnsubscribe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailUnsubscribe", ctx, email)
+	ret0, _ := ret[0].(database.EmailUnsubscribe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailUnsubscribe indicates an expected call of GetEmailUnsubscribe.
+func (mr *MockUserRepoMockRecorder) GetEmailUnsubscribe(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailUnsubscribe", reflect.TypeOf((*MockUserRepo)(nil).GetEmailUnsubscribe), ctx, email)
+}
+
+// GetEmailVerificationByID mocks base method.
+func (m *MockUserRepo) GetEmailVerificationByID(ctx context.Context, id string) (database.EmailVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailVerificationByID", ctx, id)
+	ret0, _ := ret[0].(database.EmailVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailVerificationByID indicates an expected call of GetEmailVerificationByID.
+func (mr *MockUserRepoMockRecorder) GetEmailVerificationByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailVerificationByID", reflect.TypeOf((*MockUserRepo)(nil).GetEmailVerificationByID), ctx, id)
+}
+
 // GetEmailVerificationByUserID mocks base method.
 func (m *MockUserRepo) GetEmailVerificationByUserID(ctx context.Context, userID string) (database.EmailVerification, error) {
 	m.ctrl.T.Helper()
@@ -258,6 +663,51 @@ func (mr *MockUserRepoMockRecorder) GetEmailVerificationByUserID(ctx, userID any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailVerificationByUserID", reflect.TypeOf((*MockUserRepo)(nil).GetEmailVerificationByUserID), ctx, userID)
 }
 
+// GetLatestEmailDeliveryEvent mocks base method.
+func (m *MockUserRepo) GetLatestEmailDeliveryEvent(ctx context.Context, email string) (database.EmailDeliveryEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestEmailDeliveryEvent", ctx, email)
+	ret0, _ := ret[0].(database.EmailDeliveryEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestEmailDeliveryEvent indicates an expected call of GetLatestEmailDeliveryEvent.
+func (mr *MockUserRepoMockRecorder) GetLatestEmailDeliveryEvent(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestEmailDeliveryEvent", reflect.TypeOf((*MockUserRepo)(nil).GetLatestEmailDeliveryEvent), ctx, email)
+}
+
+// GetLoginAttempt mocks base method.
+func (m *MockUserRepo) GetLoginAttempt(ctx context.Context, kind, subject string) (database.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempt", ctx, kind, subject)
+	ret0, _ := ret[0].(database.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempt indicates an expected call of GetLoginAttempt.
+func (mr *MockUserRepoMockRecorder) GetLoginAttempt(ctx, kind, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempt", reflect.TypeOf((*MockUserRepo)(nil).GetLoginAttempt), ctx, kind, subject)
+}
+
+// GetNextPendingEmailOutboxMessage mocks base method.
+func (m *MockUserRepo) GetNextPendingEmailOutboxMessage(ctx context.Context) (database.EmailOutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNextPendingEmailOutboxMessage", ctx)
+	ret0, _ := ret[0].(database.EmailOutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNextPendingEmailOutboxMessage indicates an expected call of GetNextPendingEmailOutboxMessage.
+func (mr *MockUserRepoMockRecorder) GetNextPendingEmailOutboxMessage(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNextPendingEmailOutboxMessage", reflect.TypeOf((*MockUserRepo)(nil).GetNextPendingEmailOutboxMessage), ctx)
+}
+
 // GetRefreshTokenByHash mocks base method.
 func (m *MockUserRepo) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (database.RefreshToken, error) {
 	m.ctrl.T.Helper()
@@ -333,6 +783,124 @@ func (mr *MockUserRepoMockRecorder) GetUserByUsername(ctx, username any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUserRepo)(nil).GetUserByUsername), ctx, username)
 }
 
+// GetUserTOTP mocks base method.
+func (m *MockUserRepo) GetUserTOTP(ctx context.Context, userID string) (database.UserTOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTOTP", ctx, userID)
+	ret0, _ := ret[0].(database.UserTOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTOTP indicates an expected call of GetUserTOTP.
+func (mr *MockUserRepoMockRecorder) GetUserTOTP(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTOTP", reflect.TypeOf((*MockUserRepo)(nil).GetUserTOTP), ctx, userID)
+}
+
+// GetWebAuthnCredentialsByUserID mocks base method.
+func (m *MockUserRepo) GetWebAuthnCredentialsByUserID(ctx context.Context, userID string) ([]database.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebAuthnCredentialsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]database.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebAuthnCredentialsByUserID indicates an expected call of GetWebAuthnCredentialsByUserID.
+func (mr *MockUserRepoMockRecorder) GetWebAuthnCredentialsByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebAuthnCredentialsByUserID", reflect.TypeOf((*MockUserRepo)(nil).GetWebAuthnCredentialsByUserID), ctx, userID)
+}
+
+// HasUserDevices mocks base method.
+func (m *MockUserRepo) HasUserDevices(ctx context.Context, userID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasUserDevices", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasUserDevices indicates an expected call of HasUserDevices.
+func (mr *MockUserRepoMockRecorder) HasUserDevices(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasUserDevices", reflect.TypeOf((*MockUserRepo)(nil).HasUserDevices), ctx, userID)
+}
+
+// IncrementEmailChangeFailedAttempts mocks base method.
+func (m *MockUserRepo) IncrementEmailChangeFailedAttempts(ctx context.Context, id string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementEmailChangeFailedAttempts", ctx, id)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementEmailChangeFailedAttempts indicates an expected call of IncrementEmailChangeFailedAttempts.
+func (mr *MockUserRepoMockRecorder) IncrementEmailChangeFailedAttempts(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementEmailChangeFailedAttempts", reflect.TypeOf((*MockUserRepo)(nil).IncrementEmailChangeFailedAttempts), ctx, id)
+}
+
+// IncrementEmailSignInFailedAttempts mocks base method.
+func (m *MockUserRepo) IncrementEmailSignInFailedAttempts(ctx context.Context, id string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementEmailSignInFailedAttempts", ctx, id)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementEmailSignInFailedAttempts indicates an expected call of IncrementEmailSignInFailedAttempts.
+func (mr *MockUserRepoMockRecorder) IncrementEmailSignInFailedAttempts(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementEmailSignInFailedAttempts", reflect.TypeOf((*MockUserRepo)(nil).IncrementEmailSignInFailedAttempts), ctx, id)
+}
+
+// IncrementEmailVerificationFailedAttempts mocks base method.
+func (m *MockUserRepo) IncrementEmailVerificationFailedAttempts(ctx context.Context, id string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementEmailVerificationFailedAttempts", ctx, id)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementEmailVerificationFailedAttempts indicates an expected call of IncrementEmailVerificationFailedAttempts.
+func (mr *MockUserRepoMockRecorder) IncrementEmailVerificationFailedAttempts(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementEmailVerificationFailedAttempts", reflect.TypeOf((*MockUserRepo)(nil).IncrementEmailVerificationFailedAttempts), ctx, id)
+}
+
+// InvalidateEmailSignIns mocks base method.
+func (m *MockUserRepo) InvalidateEmailSignIns(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateEmailSignIns", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateEmailSignIns indicates an expected call of InvalidateEmailSignIns.
+func (mr *MockUserRepoMockRecorder) InvalidateEmailSignIns(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateEmailSignIns", reflect.TypeOf((*MockUserRepo)(nil).InvalidateEmailSignIns), ctx, userID)
+}
+
+// InvalidateEmailVerifications mocks base method.
+func (m *MockUserRepo) InvalidateEmailVerifications(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateEmailVerifications", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateEmailVerifications indicates an expected call of InvalidateEmailVerifications.
+func (mr *MockUserRepoMockRecorder) InvalidateEmailVerifications(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateEmailVerifications", reflect.TypeOf((*MockUserRepo)(nil).InvalidateEmailVerifications), ctx, userID)
+}
+
 // IsEmailUnsubscribed mocks base method.
 func (m *MockUserRepo) IsEmailUnsubscribed(ctx context.Context, email string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -348,6 +916,20 @@ func (mr *MockUserRepoMockRecorder) IsEmailUnsubscribed(ctx, email any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEmailUnsubscribed", reflect.TypeOf((*MockUserRepo)(nil).IsEmailUnsubscribed), ctx, email)
 }
 
+// RescheduleEmailOutboxMessage mocks base method.
+func (m *MockUserRepo) RescheduleEmailOutboxMessage(ctx context.Context, id, lastError string, nextAttemptAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleEmailOutboxMessage", ctx, id, lastError, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RescheduleEmailOutboxMessage indicates an expected call of RescheduleEmailOutboxMessage.
+func (mr *MockUserRepoMockRecorder) RescheduleEmailOutboxMessage(ctx, id, lastError, nextAttemptAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleEmailOutboxMessage", reflect.TypeOf((*MockUserRepo)(nil).RescheduleEmailOutboxMessage), ctx, id, lastError, nextAttemptAt)
+}
+
 // RunWithTx mocks base method.
 func (m *MockUserRepo) RunWithTx(ctx context.Context, f func(context.Context) error) error {
 	m.ctrl.T.Helper()
@@ -362,18 +944,116 @@ func (mr *MockUserRepoMockRecorder) RunWithTx(ctx, f any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunWithTx", reflect.TypeOf((*MockUserRepo)(nil).RunWithTx), ctx, f)
 }
 
+// SetEmailChangeMessageID mocks base method.
+func (m *MockUserRepo) SetEmailChangeMessageID(ctx context.Context, id, messageID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmailChangeMessageID", ctx, id, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmailChangeMessageID indicates an expected call of SetEmailChangeMessageID.
+func (mr *MockUserRepoMockRecorder) SetEmailChangeMessageID(ctx, id, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailChangeMessageID", reflect.TypeOf((*MockUserRepo)(nil).SetEmailChangeMessageID), ctx, id, messageID)
+}
+
+// SetEmailChangeUsed mocks base method.
+func (m *MockUserRepo) SetEmailChangeUsed(ctx context.Context, id string, used bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmailChangeUsed", ctx, id, used)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmailChangeUsed indicates an expected call of SetEmailChangeUsed.
+func (mr *MockUserRepoMockRecorder) SetEmailChangeUsed(ctx, id, used any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailChangeUsed", reflect.TypeOf((*MockUserRepo)(nil).SetEmailChangeUsed), ctx, id, used)
+}
+
+// SetEmailMessageStatus mocks base method.
+func (m *MockUserRepo) SetEmailMessageStatus(ctx context.Context, providerMessageID, status string, updatedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmailMessageStatus", ctx, providerMessageID, status, updatedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmailMessageStatus indicates an expected call of SetEmailMessageStatus.
+func (mr *MockUserRepoMockRecorder) SetEmailMessageStatus(ctx, providerMessageID, status, updatedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailMessageStatus", reflect.TypeOf((*MockUserRepo)(nil).SetEmailMessageStatus), ctx, providerMessageID, status, updatedAt)
+}
+
+// SetEmailOutboxMessageFailed mocks base method.
+func (m *MockUserRepo) SetEmailOutboxMessageFailed(ctx context.Context, id, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmailOutboxMessageFailed", ctx, id, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmailOutboxMessageFailed indicates an expected call of SetEmailOutboxMessageFailed.
+func (mr *MockUserRepoMockRecorder) SetEmailOutboxMessageFailed(ctx, id, lastError any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailOutboxMessageFailed", reflect.TypeOf((*MockUserRepo)(nil).SetEmailOutboxMessageFailed), ctx, id, lastError)
+}
+
+// SetEmailOutboxMessageSent mocks base method.
+func (m *MockUserRepo) SetEmailOutboxMessageSent(ctx context.Context, id, messageID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmailOutboxMessageSent", ctx, id, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmailOutboxMessageSent indicates an expected call of SetEmailOutboxMessageSent.
+func (mr *MockUserRepoMockRecorder) SetEmailOutboxMessageSent(ctx, id, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailOutboxMessageSent", reflect.TypeOf((*MockUserRepo)(nil).SetEmailOutboxMessageSent), ctx, id, messageID)
+}
+
+// SetEmailSignInMessageID mocks base method.
+func (m *MockUserRepo) SetEmailSignInMessageID(ctx context.Context, id, messageID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmailSignInMessageID", ctx, id, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmailSignInMessageID indicates an expected call of SetEmailSignInMessageID.
+func (mr *MockUserRepoMockRecorder) SetEmailSignInMessageID(ctx, id, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailSignInMessageID", reflect.TypeOf((*MockUserRepo)(nil).SetEmailSignInMessageID), ctx, id, messageID)
+}
+
+// SetEmailSignInUsed mocks base method.
+func (m *MockUserRepo) SetEmailSignInUsed(ctx context.Context, id string, used bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmailSignInUsed", ctx, id, used)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmailSignInUsed indicates an expected call of SetEmailSignInUsed.
+func (mr *MockUserRepoMockRecorder) SetEmailSignInUsed(ctx, id, used any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailSignInUsed", reflect.TypeOf((*MockUserRepo)(nil).SetEmailSignInUsed), ctx, id, used)
+}
+
 // SetEmailVerificationMessageID mocks base method.
-func (m *MockUserRepo) SetEmailVerificationMessageID(ctx context.Context, verificationID, messageID string) error {
+func (m *MockUserRepo) SetEmailVerificationMessageID(ctx context.Context, verificationID, messageID, provider string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetEmailVerificationMessageID", ctx, verificationID, messageID)
+	ret := m.ctrl.Call(m, "SetEmailVerificationMessageID", ctx, verificationID, messageID, provider)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetEmailVerificationMessageID indicates an expected call of SetEmailVerificationMessageID.
-func (mr *MockUserRepoMockRecorder) SetEmailVerificationMessageID(ctx, verificationID, messageID any) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) SetEmailVerificationMessageID(ctx, verificationID, messageID, provider any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailVerificationMessageID", reflect.TypeOf((*MockUserRepo)(nil).SetEmailVerificationMessageID), ctx, verificationID, messageID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailVerificationMessageID", reflect.TypeOf((*MockUserRepo)(nil).SetEmailVerificationMessageID), ctx, verificationID, messageID, provider)
 }
 
 // SetEmailVerificationUsed mocks base method.
@@ -390,6 +1070,20 @@ func (mr *MockUserRepoMockRecorder) SetEmailVerificationUsed(ctx, id, verified a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailVerificationUsed", reflect.TypeOf((*MockUserRepo)(nil).SetEmailVerificationUsed), ctx, id, verified)
 }
 
+// SetLoginAttemptLockedUntil mocks base method.
+func (m *MockUserRepo) SetLoginAttemptLockedUntil(ctx context.Context, kind, subject string, lockedUntil time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLoginAttemptLockedUntil", ctx, kind, subject, lockedUntil)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLoginAttemptLockedUntil indicates an expected call of SetLoginAttemptLockedUntil.
+func (mr *MockUserRepoMockRecorder) SetLoginAttemptLockedUntil(ctx, kind, subject, lockedUntil any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLoginAttemptLockedUntil", reflect.TypeOf((*MockUserRepo)(nil).SetLoginAttemptLockedUntil), ctx, kind, subject, lockedUntil)
+}
+
 // SetUnsubscribeToken mocks base method.
 func (m *MockUserRepo) SetUnsubscribeToken(ctx context.Context, id, token string) error {
 	m.ctrl.T.Helper()
@@ -418,6 +1112,20 @@ func (mr *MockUserRepoMockRecorder) SetUserEmailVerified(ctx, userID any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserEmailVerified", reflect.TypeOf((*MockUserRepo)(nil).SetUserEmailVerified), ctx, userID)
 }
 
+// SetUserTOTPLastUsedStep mocks base method.
+func (m *MockUserRepo) SetUserTOTPLastUsedStep(ctx context.Context, userID string, usedStep int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserTOTPLastUsedStep", ctx, userID, usedStep)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserTOTPLastUsedStep indicates an expected call of SetUserTOTPLastUsedStep.
+func (mr *MockUserRepoMockRecorder) SetUserTOTPLastUsedStep(ctx, userID, usedStep any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserTOTPLastUsedStep", reflect.TypeOf((*MockUserRepo)(nil).SetUserTOTPLastUsedStep), ctx, userID, usedStep)
+}
+
 // UpdateUser mocks base method.
 func (m *MockUserRepo) UpdateUser(ctx context.Context, user database.User) error {
 	m.ctrl.T.Helper()
@@ -432,6 +1140,91 @@ func (mr *MockUserRepoMockRecorder) UpdateUser(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUserRepo)(nil).UpdateUser), ctx, user)
 }
 
+// UpdateUserEmail mocks base method.
+func (m *MockUserRepo) UpdateUserEmail(ctx context.Context, userID, email string, verified bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserEmail", ctx, userID, email, verified)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserEmail indicates an expected call of UpdateUserEmail.
+func (mr *MockUserRepoMockRecorder) UpdateUserEmail(ctx, userID, email, verified any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserEmail", reflect.TypeOf((*MockUserRepo)(nil).UpdateUserEmail), ctx, userID, email, verified)
+}
+
+// UpdateWebAuthnCredentialUsage mocks base method.
+func (m *MockUserRepo) UpdateWebAuthnCredentialUsage(ctx context.Context, id string, data []byte, usedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebAuthnCredentialUsage", ctx, id, data, usedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebAuthnCredentialUsage indicates an expected call of UpdateWebAuthnCredentialUsage.
+func (mr *MockUserRepoMockRecorder) UpdateWebAuthnCredentialUsage(ctx, id, data, usedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebAuthnCredentialUsage", reflect.TypeOf((*MockUserRepo)(nil).UpdateWebAuthnCredentialUsage), ctx, id, data, usedAt)
+}
+
+// UpsertEmailPreference mocks base method.
+func (m *MockUserRepo) UpsertEmailPreference(ctx context.Context, pref database.EmailPreference) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertEmailPreference", ctx, pref)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertEmailPreference indicates an expected call of UpsertEmailPreference.
+func (mr *MockUserRepoMockRecorder) UpsertEmailPreference(ctx, pref any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertEmailPreference", reflect.TypeOf((*MockUserRepo)(nil).UpsertEmailPreference), ctx, pref)
+}
+
+// UpsertUserDevice mocks base method.
+func (m *MockUserRepo) UpsertUserDevice(ctx context.Context, device database.UserDevice) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertUserDevice", ctx, device)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertUserDevice indicates an expected call of UpsertUserDevice.
+func (mr *MockUserRepoMockRecorder) UpsertUserDevice(ctx, device any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertUserDevice", reflect.TypeOf((*MockUserRepo)(nil).UpsertUserDevice), ctx, device)
+}
+
+// UpsertUserTOTP mocks base method.
+func (m *MockUserRepo) UpsertUserTOTP(ctx context.Context, userTOTP database.UserTOTP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertUserTOTP", ctx, userTOTP)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertUserTOTP indicates an expected call of UpsertUserTOTP.
+func (mr *MockUserRepoMockRecorder) UpsertUserTOTP(ctx, userTOTP any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertUserTOTP", reflect.TypeOf((*MockUserRepo)(nil).UpsertUserTOTP), ctx, userTOTP)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockUserRepo) UseRecoveryCode(ctx context.Context, userID, codeHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockUserRepoMockRecorder) UseRecoveryCode(ctx, userID, codeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockUserRepo)(nil).UseRecoveryCode), ctx, userID, codeHash)
+}
+
 // MockEmailSender is a mock of EmailSender interface.
 type MockEmailSender struct {
 	ctrl     *gomock.Controller
@@ -456,11 +1249,56 @@ func (m *MockEmailSender) EXPECT() *MockEmailSenderMockRecorder {
 	return m.recorder
 }
 
+// SendEmailChange mocks base method.
+func (m *MockEmailSender) SendEmailChange(ctx context.Context, req mailer.SendEmailChangeRequest) (mailer.SendResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmailChange", ctx, req)
+	ret0, _ := ret[0].(mailer.SendResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendEmailChange indicates an expected call of SendEmailChange.
+func (mr *MockEmailSenderMockRecorder) SendEmailChange(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmailChange", reflect.TypeOf((*MockEmailSender)(nil).SendEmailChange), ctx, req)
+}
+
+// SendEmailChanged mocks base method.
+func (m *MockEmailSender) SendEmailChanged(ctx context.Context, req mailer.SendEmailChangedRequest) (mailer.SendResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmailChanged", ctx, req)
+	ret0, _ := ret[0].(mailer.SendResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendEmailChanged indicates an expected call of SendEmailChanged.
+func (mr *MockEmailSenderMockRecorder) SendEmailChanged(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmailChanged", reflect.TypeOf((*MockEmailSender)(nil).SendEmailChanged), ctx, req)
+}
+
+// SendEmailSignIn mocks base method.
+func (m *MockEmailSender) SendEmailSignIn(ctx context.Context, req mailer.SendEmailSignInRequest) (mailer.SendResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmailSignIn", ctx, req)
+	ret0, _ := ret[0].(mailer.SendResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendEmailSignIn indicates an expected call of SendEmailSignIn.
+func (mr *MockEmailSenderMockRecorder) SendEmailSignIn(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmailSignIn", reflect.TypeOf((*MockEmailSender)(nil).SendEmailSignIn), ctx, req)
+}
+
 // SendEmailVerification mocks base method.
-func (m *MockEmailSender) SendEmailVerification(ctx context.Context, req resendapi.SendEmailVerificationRequest) (string, error) {
+func (m *MockEmailSender) SendEmailVerification(ctx context.Context, req mailer.SendEmailVerificationRequest) (mailer.SendResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SendEmailVerification", ctx, req)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(mailer.SendResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -470,3 +1308,33 @@ func (mr *MockEmailSenderMockRecorder) SendEmailVerification(ctx, req any) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmailVerification", reflect.TypeOf((*MockEmailSender)(nil).SendEmailVerification), ctx, req)
 }
+
+// SendRecoveryCodeUsed mocks base method.
+func (m *MockEmailSender) SendRecoveryCodeUsed(ctx context.Context, req mailer.SendRecoveryCodeUsedRequest) (mailer.SendResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendRecoveryCodeUsed", ctx, req)
+	ret0, _ := ret[0].(mailer.SendResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendRecoveryCodeUsed indicates an expected call of SendRecoveryCodeUsed.
+func (mr *MockEmailSenderMockRecorder) SendRecoveryCodeUsed(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendRecoveryCodeUsed", reflect.TypeOf((*MockEmailSender)(nil).SendRecoveryCodeUsed), ctx, req)
+}
+
+// SendSecurityNotice mocks base method.
+func (m *MockEmailSender) SendSecurityNotice(ctx context.Context, req mailer.SendSecurityNoticeRequest) (mailer.SendResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSecurityNotice", ctx, req)
+	ret0, _ := ret[0].(mailer.SendResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSecurityNotice indicates an expected call of SendSecurityNotice.
+func (mr *MockEmailSenderMockRecorder) SendSecurityNotice(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSecurityNotice", reflect.TypeOf((*MockEmailSender)(nil).SendSecurityNotice), ctx, req)
+}
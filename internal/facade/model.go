@@ -8,9 +8,24 @@ import (
 const (
 	maxUsernameLen = 32
 
-	defaultVrfCodeLen = 6
+	emailSignInCodeLen = 6
+	emailChangeCodeLen = 6
+
+	numericCodeChars = "0123456789"
+	// alphanumeric code characters without easily confused 0/O and 1/I
+	alphanumericCodeChars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
 )
 
+// EmailCfg represents email verification policy
+type EmailCfg struct {
+	VerificationCodeTTL    time.Duration
+	VerificationCodeLength int
+	// VerificationCodeAlphabet - one of model.VerificationCodeAlphabetNumeric, model.VerificationCodeAlphabetAlphanumeric
+	VerificationCodeAlphabet   string
+	ResendVerificationCooldown time.Duration
+	UnsubscribeTokenTTL        time.Duration
+}
+
 // TooManyRequestsError - error with retry after
 type TooManyRequestsError struct {
 	RetryAfter time.Duration
@@ -22,8 +37,8 @@ func (e TooManyRequestsError) Error() string {
 }
 
 // NewTooManyRequestsError - creates a new ErrTooManyRequestsRetryAfter
-func NewTooManyRequestsError(retryAfter time.Duration) TooManyRequestsError {
-	return TooManyRequestsError{
+func NewTooManyRequestsError(retryAfter time.Duration) *TooManyRequestsError {
+	return &TooManyRequestsError{
 		RetryAfter: retryAfter,
 	}
 }
@@ -37,11 +52,38 @@ func AsTooManyRequestsError(err error) *TooManyRequestsError {
 	return nil
 }
 
+// EmailDelayedError - error of email that can't be sent until sending quota of email provider is reset
+type EmailDelayedError struct {
+	RetryAfter time.Duration
+}
+
+// Error implements error interface
+func (e EmailDelayedError) Error() string {
+	return "email delivery is delayed, retry after " + e.RetryAfter.String()
+}
+
+// NewEmailDelayedError - creates a new EmailDelayedError
+func NewEmailDelayedError(retryAfter time.Duration) *EmailDelayedError {
+	return &EmailDelayedError{
+		RetryAfter: retryAfter,
+	}
+}
+
+// AsEmailDelayedError - returns *EmailDelayedError if err is of type EmailDelayedError
+func AsEmailDelayedError(err error) *EmailDelayedError {
+	var emailDelayedErr *EmailDelayedError
+	if errors.As(err, &emailDelayedErr) {
+		return emailDelayedErr
+	}
+	return nil
+}
+
 // emailVerificationResult - result of creating an email verification record
 type emailVerificationResult struct {
-	ID               string
-	Code             string
-	UnsubscribeToken string
+	ID                string
+	Code              string
+	VerificationToken string
+	UnsubscribeToken  string
 }
 
 // RefreshToken represents a refresh token
@@ -34,7 +34,7 @@ func TestAsTooManyRequestsError(t *testing.T) {
 		retryAfter := 5 * time.Minute
 		err := facade.NewTooManyRequestsError(retryAfter)
 
-		result := facade.AsTooManyRequestsError(&err)
+		result := facade.AsTooManyRequestsError(err)
 		if result == nil {
 			t.Fatal("expected non-nil result")
 			return
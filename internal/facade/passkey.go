@@ -0,0 +1,302 @@
+package facade
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// defaultPasskeyName - name of passkey when user didn't provide one
+const defaultPasskeyName = "Passkey"
+
+// errors
+var (
+	ErrPasskeyUserNotFound       = errors.New("passkey: user not found")
+	ErrPasskeyNotFound           = errors.New("passkey: credential not found")
+	ErrPasskeyInvalidSession     = errors.New("passkey: invalid or expired ceremony session")
+	ErrPasskeyVerificationFailed = errors.New("passkey: credential verification failed")
+)
+
+// webAuthnUser adapts user with registered passkeys to webauthn.User
+type webAuthnUser struct {
+	user        database.User
+	records     []database.WebAuthnCredential
+	credentials []webauthn.Credential
+}
+
+// WebAuthnID returns user handle. User id is used as it is random and contains no personal data
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return []byte(u.user.ID)
+}
+
+// WebAuthnName returns username
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Username
+}
+
+// WebAuthnDisplayName returns user display name
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.user.DisplayName
+}
+
+// WebAuthnCredentials returns registered passkey credentials of user
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// returns stored record of passkey credential with specified credential id
+func (u *webAuthnUser) record(credentialID []byte) (database.WebAuthnCredential, bool) {
+	for _, record := range u.records {
+		if bytes.Equal(record.CredentialID, credentialID) {
+			return record, true
+		}
+	}
+	return database.WebAuthnCredential{}, false
+}
+
+// BeginPasskeyRegistration starts passkey registration ceremony for user.
+// Returns credential creation options for authenticator and id of ceremony session
+func (p *Provider) BeginPasskeyRegistration(ctx context.Context, userID string) (*protocol.CredentialCreation, string, error) {
+	user, err := p.getWebAuthnUser(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	creation, sessionData, err := p.webAuthn.BeginRegistration(user,
+		webauthn.WithExclusions(webauthn.Credentials(user.credentials).CredentialDescriptors()),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		p.log.Error("begin passkey registration", zap.String("userID", userID), zap.Error(err))
+		return nil, "", err
+	}
+
+	sessionID, err := p.createWebAuthnSession(ctx, userID, model.WebAuthnSessionKindRegistration, sessionData)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return creation, sessionID, nil
+}
+
+// FinishPasskeyRegistration verifies authenticator response for passkey registration ceremony and stores new passkey
+func (p *Provider) FinishPasskeyRegistration(ctx context.Context, userID, sessionID, name string, response *protocol.ParsedCredentialCreationData) (model.Passkey, error) {
+	session, sessionData, err := p.consumeWebAuthnSession(ctx, sessionID, model.WebAuthnSessionKindRegistration)
+	if err != nil {
+		return model.Passkey{}, err
+	}
+	if session.UserID.String != userID {
+		return model.Passkey{}, ErrPasskeyInvalidSession
+	}
+
+	user, err := p.getWebAuthnUser(ctx, userID)
+	if err != nil {
+		return model.Passkey{}, err
+	}
+
+	credential, err := p.webAuthn.CreateCredential(user, sessionData, response)
+	if err != nil {
+		p.log.Info("verify passkey registration", zap.String("userID", userID), zap.Error(err))
+		return model.Passkey{}, ErrPasskeyVerificationFailed
+	}
+
+	data, err := json.Marshal(credential)
+	if err != nil {
+		p.log.Error("marshal passkey credential", zap.String("userID", userID), zap.Error(err))
+		return model.Passkey{}, err
+	}
+
+	if name == "" {
+		name = defaultPasskeyName
+	}
+	record := database.NewWebAuthnCredential(userID, credential.ID, name, data)
+	if err = p.userRepo.CreateWebAuthnCredential(ctx, record); err != nil {
+		p.log.Error("create webauthn credential", zap.String("userID", userID), zap.Error(err))
+		return model.Passkey{}, err
+	}
+	record.DateCreated = time.Now()
+
+	return mapDBWebAuthnCredentialToPasskey(record), nil
+}
+
+// BeginPasskeySignIn starts passkey sign in ceremony. Passkey is discovered by authenticator, so no username is needed.
+// Returns credential request options for authenticator and id of ceremony session
+func (p *Provider) BeginPasskeySignIn(ctx context.Context) (*protocol.CredentialAssertion, string, error) {
+	assertion, sessionData, err := p.webAuthn.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		p.log.Error("begin passkey sign in", zap.Error(err))
+		return nil, "", err
+	}
+
+	sessionID, err := p.createWebAuthnSession(ctx, "", model.WebAuthnSessionKindSignIn, sessionData)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return assertion, sessionID, nil
+}
+
+// FinishPasskeySignIn verifies authenticator response for passkey sign in ceremony and returns signed in user.
+// Passkey requires user verification, so no second factor is requested
+func (p *Provider) FinishPasskeySignIn(ctx context.Context, sessionID string, response *protocol.ParsedCredentialAssertionData) (model.User, error) {
+	_, sessionData, err := p.consumeWebAuthnSession(ctx, sessionID, model.WebAuthnSessionKindSignIn)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	var user *webAuthnUser
+	var lookupErr error
+	handler := func(_, userHandle []byte) (webauthn.User, error) {
+		// user handle is provided by client, so it's checked to be a valid user id before lookup
+		if _, pErr := uuid.ParseBytes(userHandle); pErr != nil {
+			lookupErr = ErrPasskeyUserNotFound
+			return nil, lookupErr
+		}
+		user, lookupErr = p.getWebAuthnUser(ctx, string(userHandle))
+		return user, lookupErr
+	}
+
+	_, credential, err := p.webAuthn.ValidatePasskeyLogin(handler, sessionData, response)
+	if lookupErr != nil && !errors.Is(lookupErr, ErrPasskeyUserNotFound) {
+		return model.User{}, lookupErr
+	}
+	if err != nil {
+		p.log.Info("verify passkey sign in", zap.Error(err))
+		return model.User{}, ErrPasskeyVerificationFailed
+	}
+	if credential.Authenticator.CloneWarning {
+		p.log.Warn("passkey signature counter indicates cloned authenticator", zap.String("userID", user.user.ID))
+		return model.User{}, ErrPasskeyVerificationFailed
+	}
+
+	record, ok := user.record(credential.ID)
+	if !ok {
+		return model.User{}, ErrPasskeyVerificationFailed
+	}
+
+	// store updated signature counter and flags
+	data, err := json.Marshal(credential)
+	if err != nil {
+		p.log.Error("marshal passkey credential", zap.String("userID", user.user.ID), zap.Error(err))
+		return model.User{}, err
+	}
+	if err = p.userRepo.UpdateWebAuthnCredentialUsage(ctx, record.ID, data, time.Now()); err != nil {
+		p.log.Error("update webauthn credential usage", zap.String("userID", user.user.ID), zap.Error(err))
+		return model.User{}, err
+	}
+
+	return mapDBUserToUser(user.user), nil
+}
+
+// ListPasskeys returns passkeys registered by user
+func (p *Provider) ListPasskeys(ctx context.Context, userID string) ([]model.Passkey, error) {
+	records, err := p.userRepo.GetWebAuthnCredentialsByUserID(ctx, userID)
+	if err != nil {
+		p.log.Error("get webauthn credentials", zap.String("userID", userID), zap.Error(err))
+		return nil, err
+	}
+
+	passkeys := make([]model.Passkey, 0, len(records))
+	for _, record := range records {
+		passkeys = append(passkeys, mapDBWebAuthnCredentialToPasskey(record))
+	}
+
+	return passkeys, nil
+}
+
+// DeletePasskey deletes passkey of user
+func (p *Provider) DeletePasskey(ctx context.Context, userID, passkeyID string) error {
+	if err := p.userRepo.DeleteWebAuthnCredential(ctx, userID, passkeyID); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrPasskeyNotFound
+		}
+		p.log.Error("delete webauthn credential", zap.String("userID", userID), zap.String("passkeyID", passkeyID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// returns user with registered passkey credentials
+func (p *Provider) getWebAuthnUser(ctx context.Context, userID string) (*webAuthnUser, error) {
+	user, err := p.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, ErrPasskeyUserNotFound
+		}
+		p.log.Error("get user by id", zap.String("userID", userID), zap.Error(err))
+		return nil, err
+	}
+
+	records, err := p.userRepo.GetWebAuthnCredentialsByUserID(ctx, userID)
+	if err != nil {
+		p.log.Error("get webauthn credentials", zap.String("userID", userID), zap.Error(err))
+		return nil, err
+	}
+
+	credentials := make([]webauthn.Credential, 0, len(records))
+	for _, record := range records {
+		var credential webauthn.Credential
+		if err = json.Unmarshal(record.Data, &credential); err != nil {
+			p.log.Error("unmarshal passkey credential", zap.String("userID", userID), zap.String("passkeyID", record.ID), zap.Error(err))
+			return nil, fmt.Errorf("unmarshal passkey credential: %w", err)
+		}
+		credentials = append(credentials, credential)
+	}
+
+	return &webAuthnUser{
+		user:        user,
+		records:     records,
+		credentials: credentials,
+	}, nil
+}
+
+// stores state of started passkey ceremony and returns session id
+func (p *Provider) createWebAuthnSession(ctx context.Context, userID, kind string, sessionData *webauthn.SessionData) (string, error) {
+	data, err := json.Marshal(sessionData)
+	if err != nil {
+		p.log.Error("marshal webauthn session", zap.String("userID", userID), zap.Error(err))
+		return "", err
+	}
+
+	session := database.NewWebAuthnSession(userID, kind, data, time.Now().Add(model.WebAuthnCeremonyTTL))
+	if err = p.userRepo.CreateWebAuthnSession(ctx, session); err != nil {
+		p.log.Error("create webauthn session", zap.String("userID", userID), zap.Error(err))
+		return "", err
+	}
+
+	return session.ID, nil
+}
+
+// returns state of passkey ceremony and deletes it, so ceremony can be completed only once
+func (p *Provider) consumeWebAuthnSession(ctx context.Context, sessionID, kind string) (database.WebAuthnSession, webauthn.SessionData, error) {
+	session, err := p.userRepo.ConsumeWebAuthnSession(ctx, sessionID, kind)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return database.WebAuthnSession{}, webauthn.SessionData{}, ErrPasskeyInvalidSession
+		}
+		p.log.Error("consume webauthn session", zap.String("sessionID", sessionID), zap.Error(err))
+		return database.WebAuthnSession{}, webauthn.SessionData{}, err
+	}
+	if session.IsExpired() {
+		return database.WebAuthnSession{}, webauthn.SessionData{}, ErrPasskeyInvalidSession
+	}
+
+	var sessionData webauthn.SessionData
+	if err = json.Unmarshal(session.Data, &sessionData); err != nil {
+		p.log.Error("unmarshal webauthn session", zap.String("sessionID", sessionID), zap.Error(err))
+		return database.WebAuthnSession{}, webauthn.SessionData{}, fmt.Errorf("unmarshal webauthn session: %w", err)
+	}
+
+	return session, sessionData, nil
+}
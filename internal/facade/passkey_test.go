@@ -0,0 +1,576 @@
+package facade_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	mocks "github.com/OutOfStack/game-library-auth/internal/facade/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+)
+
+// authenticator data flags
+const (
+	authFlagUserPresent            = 0x01
+	authFlagUserVerified           = 0x04
+	authFlagAttestedCredentialData = 0x40
+)
+
+// softAuthenticator is a software passkey authenticator for tests
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate authenticator key: %v", err)
+	}
+	credentialID := make([]byte, 16)
+	if _, err = rand.Read(credentialID); err != nil {
+		t.Fatalf("generate credential id: %v", err)
+	}
+
+	return &softAuthenticator{
+		key:          key,
+		credentialID: credentialID,
+	}
+}
+
+// create responds to registration ceremony options with a new credential and "none" attestation
+func (a *softAuthenticator) create(t *testing.T, creation *protocol.CredentialCreation) *protocol.ParsedCredentialCreationData {
+	t.Helper()
+
+	switch id := creation.Response.User.ID.(type) {
+	case protocol.URLEncodedBase64:
+		a.userHandle = id
+	case string:
+		a.userHandle = []byte(id)
+	}
+
+	clientData := a.clientData(t, protocol.CreateCeremony, creation.Response.Challenge)
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: a.key.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+
+	attestedCredentialData := make([]byte, 16) // zero aaguid
+	attestedCredentialData = binary.BigEndian.AppendUint16(attestedCredentialData, uint16(len(a.credentialID)))
+	attestedCredentialData = append(attestedCredentialData, a.credentialID...)
+	attestedCredentialData = append(attestedCredentialData, publicKey...)
+
+	authData := a.authData(creation.Response.RelyingParty.ID, authFlagUserPresent|authFlagUserVerified|authFlagAttestedCredentialData, attestedCredentialData)
+	attestationObject, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	if err != nil {
+		t.Fatalf("marshal attestation object: %v", err)
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"id":    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"rawId": base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientData),
+			"attestationObject": base64.RawURLEncoding.EncodeToString(attestationObject),
+		},
+	})
+	if err != nil {
+		t.Fatalf("marshal creation response: %v", err)
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(body)
+	if err != nil {
+		t.Fatalf("parse creation response: %v", err)
+	}
+
+	return parsed
+}
+
+// get responds to sign in ceremony options with an assertion signed by credential key
+func (a *softAuthenticator) get(t *testing.T, assertion *protocol.CredentialAssertion) *protocol.ParsedCredentialAssertionData {
+	t.Helper()
+
+	a.signCount++
+	clientData := a.clientData(t, protocol.AssertCeremony, assertion.Response.Challenge)
+	authData := a.authData(assertion.Response.RelyingPartyID, authFlagUserPresent|authFlagUserVerified, nil)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("sign assertion: %v", err)
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"id":    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"rawId": base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientData),
+			"authenticatorData": base64.RawURLEncoding.EncodeToString(authData),
+			"signature":         base64.RawURLEncoding.EncodeToString(signature),
+			"userHandle":        base64.RawURLEncoding.EncodeToString(a.userHandle),
+		},
+	})
+	if err != nil {
+		t.Fatalf("marshal assertion response: %v", err)
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(body)
+	if err != nil {
+		t.Fatalf("parse assertion response: %v", err)
+	}
+
+	return parsed
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremony protocol.CeremonyType, challenge protocol.URLEncodedBase64) []byte {
+	t.Helper()
+
+	clientData, err := json.Marshal(map[string]string{
+		"type":      string(ceremony),
+		"challenge": challenge.String(),
+		"origin":    testWebAuthnOrigin,
+	})
+	if err != nil {
+		t.Fatalf("marshal client data: %v", err)
+	}
+
+	return clientData
+}
+
+func (a *softAuthenticator) authData(rpID string, flags byte, attestedCredentialData []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+
+	authData := append(rpIDHash[:], flags)
+	authData = binary.BigEndian.AppendUint32(authData, a.signCount)
+	return append(authData, attestedCredentialData...)
+}
+
+// expectWebAuthnSessionCreated sets expectation for storing passkey ceremony session and returns pointer to stored session
+func expectWebAuthnSessionCreated(mockUserRepo *mocks.MockUserRepo) *database.WebAuthnSession {
+	var stored database.WebAuthnSession
+	mockUserRepo.EXPECT().
+		CreateWebAuthnSession(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, session database.WebAuthnSession) error {
+			stored = session
+			return nil
+		})
+	return &stored
+}
+
+// registerTestPasskey runs passkey registration ceremony with authenticator and returns stored credential record
+func registerTestPasskey(t *testing.T, provider *facade.Provider, mockUserRepo *mocks.MockUserRepo, authenticator *softAuthenticator, user database.User) database.WebAuthnCredential {
+	t.Helper()
+
+	ctx := context.Background()
+
+	mockUserRepo.EXPECT().GetUserByID(ctx, user.ID).Return(user, nil).Times(2)
+	mockUserRepo.EXPECT().GetWebAuthnCredentialsByUserID(ctx, user.ID).Return(nil, nil).Times(2)
+	session := expectWebAuthnSessionCreated(mockUserRepo)
+
+	creation, sessionID, err := provider.BeginPasskeyRegistration(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("begin passkey registration: %v", err)
+	}
+
+	var record database.WebAuthnCredential
+	mockUserRepo.EXPECT().ConsumeWebAuthnSession(ctx, sessionID, model.WebAuthnSessionKindRegistration).Return(*session, nil)
+	mockUserRepo.EXPECT().
+		CreateWebAuthnCredential(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, credential database.WebAuthnCredential) error {
+			record = credential
+			return nil
+		})
+
+	if _, err = provider.FinishPasskeyRegistration(ctx, user.ID, sessionID, "My laptop", authenticator.create(t, creation)); err != nil {
+		t.Fatalf("finish passkey registration: %v", err)
+	}
+
+	return record
+}
+
+func newTestPasskeyUser() database.User {
+	return database.User{ID: uuid.New().String(), Username: "testuser", DisplayName: "Test User"}
+}
+
+func TestProvider_BeginPasskeyRegistration(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := newTestPasskeyUser()
+		existing := database.NewWebAuthnCredential(user.ID, []byte("existing-credential"), "Phone", []byte(`{"id":"ZXhpc3RpbmctY3JlZGVudGlhbA=="}`))
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, user.ID).Return(user, nil)
+		mockUserRepo.EXPECT().GetWebAuthnCredentialsByUserID(ctx, user.ID).Return([]database.WebAuthnCredential{existing}, nil)
+		session := expectWebAuthnSessionCreated(mockUserRepo)
+
+		creation, sessionID, err := provider.BeginPasskeyRegistration(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if sessionID == "" || sessionID != session.ID {
+			t.Errorf("expected returned session id to match stored session")
+		}
+		if session.UserID.String != user.ID || session.Kind != model.WebAuthnSessionKindRegistration {
+			t.Errorf("unexpected stored session: %+v", session)
+		}
+		if creation.Response.RelyingParty.ID != testWebAuthnRPID {
+			t.Errorf("expected relying party id %s, got %s", testWebAuthnRPID, creation.Response.RelyingParty.ID)
+		}
+		// registered passkeys are excluded to prevent registering same authenticator twice
+		if len(creation.Response.CredentialExcludeList) != 1 {
+			t.Errorf("expected 1 excluded credential, got %d", len(creation.Response.CredentialExcludeList))
+		}
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(database.User{}, database.ErrNotFound)
+
+		_, _, err := provider.BeginPasskeyRegistration(ctx, "user-123")
+		if !errors.Is(err, facade.ErrPasskeyUserNotFound) {
+			t.Errorf("expected ErrPasskeyUserNotFound, got %v", err)
+		}
+	})
+}
+
+func TestProvider_FinishPasskeyRegistration(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := newTestPasskeyUser()
+		authenticator := newSoftAuthenticator(t)
+
+		record := registerTestPasskey(t, provider, mockUserRepo, authenticator, user)
+		if record.UserID != user.ID || record.Name != "My laptop" {
+			t.Errorf("unexpected stored credential: %+v", record)
+		}
+		if string(record.CredentialID) != string(authenticator.credentialID) {
+			t.Errorf("expected stored credential id to match authenticator credential id")
+		}
+	})
+
+	t.Run("session of other user", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		session := database.NewWebAuthnSession("other-user", model.WebAuthnSessionKindRegistration, []byte(`{}`), time.Now().Add(time.Minute))
+		mockUserRepo.EXPECT().ConsumeWebAuthnSession(ctx, session.ID, model.WebAuthnSessionKindRegistration).Return(session, nil)
+
+		_, err := provider.FinishPasskeyRegistration(ctx, "user-123", session.ID, "", &protocol.ParsedCredentialCreationData{})
+		if !errors.Is(err, facade.ErrPasskeyInvalidSession) {
+			t.Errorf("expected ErrPasskeyInvalidSession, got %v", err)
+		}
+	})
+
+	t.Run("expired session", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		session := database.NewWebAuthnSession("user-123", model.WebAuthnSessionKindRegistration, []byte(`{}`), time.Now().Add(-time.Minute))
+		mockUserRepo.EXPECT().ConsumeWebAuthnSession(ctx, session.ID, model.WebAuthnSessionKindRegistration).Return(session, nil)
+
+		_, err := provider.FinishPasskeyRegistration(ctx, "user-123", session.ID, "", &protocol.ParsedCredentialCreationData{})
+		if !errors.Is(err, facade.ErrPasskeyInvalidSession) {
+			t.Errorf("expected ErrPasskeyInvalidSession, got %v", err)
+		}
+	})
+
+	t.Run("session not found", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().ConsumeWebAuthnSession(ctx, "session-id", model.WebAuthnSessionKindRegistration).Return(database.WebAuthnSession{}, database.ErrNotFound)
+
+		_, err := provider.FinishPasskeyRegistration(ctx, "user-123", "session-id", "", &protocol.ParsedCredentialCreationData{})
+		if !errors.Is(err, facade.ErrPasskeyInvalidSession) {
+			t.Errorf("expected ErrPasskeyInvalidSession, got %v", err)
+		}
+	})
+
+	t.Run("response to other ceremony", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := newTestPasskeyUser()
+		authenticator := newSoftAuthenticator(t)
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, user.ID).Return(user, nil).Times(3)
+		mockUserRepo.EXPECT().GetWebAuthnCredentialsByUserID(ctx, user.ID).Return(nil, nil).Times(3)
+		firstSession := expectWebAuthnSessionCreated(mockUserRepo)
+		_, firstSessionID, err := provider.BeginPasskeyRegistration(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("begin passkey registration: %v", err)
+		}
+		storedFirstSession := *firstSession
+
+		expectWebAuthnSessionCreated(mockUserRepo)
+		secondCreation, _, err := provider.BeginPasskeyRegistration(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("begin passkey registration: %v", err)
+		}
+
+		// authenticator signs challenge of second ceremony, but first ceremony is completed
+		mockUserRepo.EXPECT().ConsumeWebAuthnSession(ctx, firstSessionID, model.WebAuthnSessionKindRegistration).Return(storedFirstSession, nil)
+
+		_, err = provider.FinishPasskeyRegistration(ctx, user.ID, firstSessionID, "", authenticator.create(t, secondCreation))
+		if !errors.Is(err, facade.ErrPasskeyVerificationFailed) {
+			t.Errorf("expected ErrPasskeyVerificationFailed, got %v", err)
+		}
+	})
+}
+
+func TestProvider_FinishPasskeySignIn(t *testing.T) {
+	ctx := context.Background()
+
+	// beginSignIn starts sign in ceremony and returns assertion options and stored session
+	beginSignIn := func(t *testing.T, provider *facade.Provider, mockUserRepo *mocks.MockUserRepo) (*protocol.CredentialAssertion, database.WebAuthnSession) {
+		t.Helper()
+
+		session := expectWebAuthnSessionCreated(mockUserRepo)
+		assertion, sessionID, err := provider.BeginPasskeySignIn(ctx)
+		if err != nil {
+			t.Fatalf("begin passkey sign in: %v", err)
+		}
+		if sessionID != session.ID || session.UserID.Valid || session.Kind != model.WebAuthnSessionKindSignIn {
+			t.Fatalf("unexpected stored session: %+v", session)
+		}
+		if assertion.Response.UserVerification != protocol.VerificationRequired {
+			t.Errorf("expected user verification to be required")
+		}
+
+		return assertion, *session
+	}
+
+	t.Run("success", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := newTestPasskeyUser()
+		authenticator := newSoftAuthenticator(t)
+		record := registerTestPasskey(t, provider, mockUserRepo, authenticator, user)
+		assertion, session := beginSignIn(t, provider, mockUserRepo)
+
+		mockUserRepo.EXPECT().ConsumeWebAuthnSession(ctx, session.ID, model.WebAuthnSessionKindSignIn).Return(session, nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, user.ID).Return(user, nil)
+		mockUserRepo.EXPECT().GetWebAuthnCredentialsByUserID(ctx, user.ID).Return([]database.WebAuthnCredential{record}, nil)
+		mockUserRepo.EXPECT().
+			UpdateWebAuthnCredentialUsage(ctx, record.ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, data []byte, _ time.Time) error {
+				var credential struct {
+					Authenticator struct {
+						SignCount uint32 `json:"signCount"`
+					} `json:"authenticator"`
+				}
+				if err := json.Unmarshal(data, &credential); err != nil {
+					t.Fatalf("unmarshal stored credential: %v", err)
+				}
+				if credential.Authenticator.SignCount != authenticator.signCount {
+					t.Errorf("expected stored sign count %d, got %d", authenticator.signCount, credential.Authenticator.SignCount)
+				}
+				return nil
+			})
+
+		result, err := provider.FinishPasskeySignIn(ctx, session.ID, authenticator.get(t, assertion))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result.ID != user.ID {
+			t.Errorf("expected user id %s, got %s", user.ID, result.ID)
+		}
+	})
+
+	t.Run("unknown passkey", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := newTestPasskeyUser()
+		authenticator := newSoftAuthenticator(t)
+		registerTestPasskey(t, provider, mockUserRepo, authenticator, user)
+		assertion, session := beginSignIn(t, provider, mockUserRepo)
+
+		// passkey was deleted after registration
+		mockUserRepo.EXPECT().ConsumeWebAuthnSession(ctx, session.ID, model.WebAuthnSessionKindSignIn).Return(session, nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, user.ID).Return(user, nil)
+		mockUserRepo.EXPECT().GetWebAuthnCredentialsByUserID(ctx, user.ID).Return(nil, nil)
+
+		_, err := provider.FinishPasskeySignIn(ctx, session.ID, authenticator.get(t, assertion))
+		if !errors.Is(err, facade.ErrPasskeyVerificationFailed) {
+			t.Errorf("expected ErrPasskeyVerificationFailed, got %v", err)
+		}
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := newTestPasskeyUser()
+		authenticator := newSoftAuthenticator(t)
+		registerTestPasskey(t, provider, mockUserRepo, authenticator, user)
+		assertion, session := beginSignIn(t, provider, mockUserRepo)
+
+		mockUserRepo.EXPECT().ConsumeWebAuthnSession(ctx, session.ID, model.WebAuthnSessionKindSignIn).Return(session, nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, user.ID).Return(database.User{}, database.ErrNotFound)
+
+		_, err := provider.FinishPasskeySignIn(ctx, session.ID, authenticator.get(t, assertion))
+		if !errors.Is(err, facade.ErrPasskeyVerificationFailed) {
+			t.Errorf("expected ErrPasskeyVerificationFailed, got %v", err)
+		}
+	})
+
+	t.Run("cloned authenticator", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := newTestPasskeyUser()
+		authenticator := newSoftAuthenticator(t)
+		record := registerTestPasskey(t, provider, mockUserRepo, authenticator, user)
+		assertion, session := beginSignIn(t, provider, mockUserRepo)
+
+		// stored counter is ahead of authenticator counter
+		var data map[string]any
+		if err := json.Unmarshal(record.Data, &data); err != nil {
+			t.Fatalf("unmarshal stored credential: %v", err)
+		}
+		data["authenticator"].(map[string]any)["signCount"] = 10
+		record.Data, _ = json.Marshal(data)
+
+		mockUserRepo.EXPECT().ConsumeWebAuthnSession(ctx, session.ID, model.WebAuthnSessionKindSignIn).Return(session, nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, user.ID).Return(user, nil)
+		mockUserRepo.EXPECT().GetWebAuthnCredentialsByUserID(ctx, user.ID).Return([]database.WebAuthnCredential{record}, nil)
+
+		_, err := provider.FinishPasskeySignIn(ctx, session.ID, authenticator.get(t, assertion))
+		if !errors.Is(err, facade.ErrPasskeyVerificationFailed) {
+			t.Errorf("expected ErrPasskeyVerificationFailed, got %v", err)
+		}
+	})
+
+	t.Run("registration session", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().ConsumeWebAuthnSession(ctx, "session-id", model.WebAuthnSessionKindSignIn).Return(database.WebAuthnSession{}, database.ErrNotFound)
+
+		_, err := provider.FinishPasskeySignIn(ctx, "session-id", &protocol.ParsedCredentialAssertionData{})
+		if !errors.Is(err, facade.ErrPasskeyInvalidSession) {
+			t.Errorf("expected ErrPasskeyInvalidSession, got %v", err)
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := newTestPasskeyUser()
+		authenticator := newSoftAuthenticator(t)
+		registerTestPasskey(t, provider, mockUserRepo, authenticator, user)
+		assertion, session := beginSignIn(t, provider, mockUserRepo)
+
+		dbErr := errors.New("database error")
+		mockUserRepo.EXPECT().ConsumeWebAuthnSession(ctx, session.ID, model.WebAuthnSessionKindSignIn).Return(session, nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, user.ID).Return(database.User{}, dbErr)
+
+		_, err := provider.FinishPasskeySignIn(ctx, session.ID, authenticator.get(t, assertion))
+		if !errors.Is(err, dbErr) {
+			t.Errorf("expected database error, got %v", err)
+		}
+	})
+}
+
+func TestProvider_ListPasskeys(t *testing.T) {
+	ctx := context.Background()
+
+	provider, mockUserRepo, _, _, ctrl := setupTest(t)
+	defer ctrl.Finish()
+
+	usedAt := time.Now()
+	records := []database.WebAuthnCredential{
+		{ID: "passkey-1", UserID: "user-123", Name: "Laptop", DateCreated: usedAt.Add(-time.Hour)},
+		{ID: "passkey-2", UserID: "user-123", Name: "Phone", DateCreated: usedAt.Add(-time.Hour), LastUsedAt: sql.NullTime{Time: usedAt, Valid: true}},
+	}
+	mockUserRepo.EXPECT().GetWebAuthnCredentialsByUserID(ctx, "user-123").Return(records, nil)
+
+	passkeys, err := provider.ListPasskeys(ctx, "user-123")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(passkeys) != 2 {
+		t.Fatalf("expected 2 passkeys, got %d", len(passkeys))
+	}
+	if passkeys[0].LastUsedAt != nil {
+		t.Errorf("expected first passkey to be never used")
+	}
+	if passkeys[1].LastUsedAt == nil || !passkeys[1].LastUsedAt.Equal(usedAt) {
+		t.Errorf("expected second passkey last used at %v, got %v", usedAt, passkeys[1].LastUsedAt)
+	}
+}
+
+func TestProvider_DeletePasskey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().DeleteWebAuthnCredential(ctx, "user-123", "passkey-1").Return(nil)
+
+		if err := provider.DeletePasskey(ctx, "user-123", "passkey-1"); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().DeleteWebAuthnCredential(ctx, "user-123", "passkey-1").Return(database.ErrNotFound)
+
+		err := provider.DeletePasskey(ctx, "user-123", "passkey-1")
+		if !errors.Is(err, facade.ErrPasskeyNotFound) {
+			t.Errorf("expected ErrPasskeyNotFound, got %v", err)
+		}
+	})
+}
@@ -5,30 +5,43 @@ import (
 	"time"
 
 	"github.com/OutOfStack/game-library-auth/internal/auth"
-	"github.com/OutOfStack/game-library-auth/internal/client/resendapi"
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
 	"github.com/OutOfStack/game-library-auth/internal/database"
 	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/pkg/crypto"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/golang-jwt/jwt/v4"
 	"go.uber.org/zap"
 )
 
 // Provider represents dependencies for facade layer
 type Provider struct {
-	log                       *zap.Logger
-	userRepo                  UserRepo
-	emailSender               EmailSender
-	auth                      Auth
-	unsubscribeTokenGenerator *auth.UnsubscribeTokenGenerator
+	log                        *zap.Logger
+	userRepo                   UserRepo
+	emailSender                EmailSender
+	auth                       Auth
+	unsubscribeTokenGenerator  *auth.UnsubscribeTokenGenerator
+	verificationTokenGenerator *auth.EmailVerificationTokenGenerator
+	secretCipher               *crypto.Cipher
+	webAuthn                   *webauthn.WebAuthn
+	emailCfg                   EmailCfg
+	emailQuota                 *emailQuotaCircuit
 }
 
 // New creates a new facade provider
-func New(log *zap.Logger, userRepo UserRepo, emailSender EmailSender, authService Auth, unsubscribeTokenGenerator *auth.UnsubscribeTokenGenerator) *Provider {
+func New(log *zap.Logger, userRepo UserRepo, emailSender EmailSender, authService Auth, unsubscribeTokenGenerator *auth.UnsubscribeTokenGenerator,
+	verificationTokenGenerator *auth.EmailVerificationTokenGenerator, secretCipher *crypto.Cipher, webAuthn *webauthn.WebAuthn, emailCfg EmailCfg) *Provider {
 	return &Provider{
-		log:                       log,
-		userRepo:                  userRepo,
-		emailSender:               emailSender,
-		auth:                      authService,
-		unsubscribeTokenGenerator: unsubscribeTokenGenerator,
+		log:                        log,
+		userRepo:                   userRepo,
+		emailSender:                emailSender,
+		auth:                       authService,
+		unsubscribeTokenGenerator:  unsubscribeTokenGenerator,
+		verificationTokenGenerator: verificationTokenGenerator,
+		secretCipher:               secretCipher,
+		webAuthn:                   webAuthn,
+		emailCfg:                   emailCfg,
+		emailQuota:                 &emailQuotaCircuit{},
 	}
 }
 
@@ -38,6 +51,8 @@ type Auth interface {
 	GenerateRefreshToken() (string, time.Time, error)
 	CreateUserClaims(user model.User) jwt.Claims
 	ValidateToken(tokenStr string) (auth.Claims, error)
+	GenerateChallengeToken(userID string, ttl time.Duration) (string, error)
+	ValidateChallengeToken(tokenStr string) (string, error)
 }
 
 // UserRepo provides methods for working with user repo
@@ -53,23 +68,92 @@ type UserRepo interface {
 	GetUserByOAuth(ctx context.Context, provider string, oauthID string) (database.User, error)
 	CheckUserExists(ctx context.Context, name string, role model.Role) (bool, error)
 	SetUserEmailVerified(ctx context.Context, userID string) error
+	UpdateUserEmail(ctx context.Context, userID, email string, verified bool) error
 
 	CreateEmailVerification(ctx context.Context, verification database.EmailVerification) error
 	GetEmailVerificationByUserID(ctx context.Context, userID string) (database.EmailVerification, error)
-	SetEmailVerificationMessageID(ctx context.Context, verificationID string, messageID string) error
+	GetEmailVerificationByID(ctx context.Context, id string) (database.EmailVerification, error)
+	SetEmailVerificationMessageID(ctx context.Context, verificationID string, messageID, provider string) error
 	SetEmailVerificationUsed(ctx context.Context, id string, verified bool) error
+	IncrementEmailVerificationFailedAttempts(ctx context.Context, id string) (int, error)
+	InvalidateEmailVerifications(ctx context.Context, userID string) error
 	SetUnsubscribeToken(ctx context.Context, id string, token string) error
 
+	CreateEmailSignIn(ctx context.Context, signIn database.EmailSignIn) error
+	GetEmailSignInByUserID(ctx context.Context, userID string) (database.EmailSignIn, error)
+	GetEmailSignInByTokenHash(ctx context.Context, tokenHash string) (database.EmailSignIn, error)
+	SetEmailSignInMessageID(ctx context.Context, id string, messageID string) error
+	SetEmailSignInUsed(ctx context.Context, id string, used bool) error
+	IncrementEmailSignInFailedAttempts(ctx context.Context, id string) (int, error)
+	InvalidateEmailSignIns(ctx context.Context, userID string) error
+
+	CreateEmailChange(ctx context.Context, change database.EmailChange) error
+	GetEmailChangeByUserID(ctx context.Context, userID string) (database.EmailChange, error)
+	SetEmailChangeMessageID(ctx context.Context, id string, messageID string) error
+	SetEmailChangeUsed(ctx context.Context, id string, used bool) error
+	IncrementEmailChangeFailedAttempts(ctx context.Context, id string) (int, error)
+
+	CreateEmailOutboxMessage(ctx context.Context, msg database.EmailOutboxMessage) error
+	GetNextPendingEmailOutboxMessage(ctx context.Context) (database.EmailOutboxMessage, error)
+	SetEmailOutboxMessageSent(ctx context.Context, id, messageID string) error
+	RescheduleEmailOutboxMessage(ctx context.Context, id, lastError string, nextAttemptAt time.Time) error
+	DeferEmailOutboxMessage(ctx context.Context, id, lastError string, nextAttemptAt time.Time) error
+	SetEmailOutboxMessageFailed(ctx context.Context, id, lastError string) error
+
+	CreateEmailMessage(ctx context.Context, msg database.EmailMessage) error
+	SetEmailMessageStatus(ctx context.Context, providerMessageID, status string, updatedAt time.Time) error
+	GetEmailMessagesByUserID(ctx context.Context, userID string, limit int) ([]database.EmailMessage, error)
+
 	CreateEmailUnsubscribe(ctx context.Context, unsubscribe database.EmailUnsubscribe) error
 	IsEmailUnsubscribed(ctx context.Context, email string) (bool, error)
+	GetEmailUnsubscribe(ctx context.Context, email string) (database.EmailUnsubscribe, error)
+	DeleteEmailUnsubscribe(ctx context.Context, email string) error
+	CreateEmailResubscribe(ctx context.Context, resubscribe database.EmailResubscribe) error
+
+	UpsertEmailPreference(ctx context.Context, pref database.EmailPreference) error
+	GetEmailPreferences(ctx context.Context, email string) ([]database.EmailPreference, error)
+
+	CreateEmailDeliveryEvent(ctx context.Context, event database.EmailDeliveryEvent) (bool, error)
+	GetLatestEmailDeliveryEvent(ctx context.Context, email string) (database.EmailDeliveryEvent, error)
 
 	CreateRefreshToken(ctx context.Context, refreshToken database.RefreshToken) error
 	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (database.RefreshToken, error)
 	DeleteRefreshToken(ctx context.Context, token string) error
 	DeleteRefreshTokensByUserID(ctx context.Context, userID string) error
+
+	GetLoginAttempt(ctx context.Context, kind, subject string) (database.LoginAttempt, error)
+	AddFailedLoginAttempt(ctx context.Context, kind, subject string, resetBefore time.Time) (database.LoginAttempt, error)
+	SetLoginAttemptLockedUntil(ctx context.Context, kind, subject string, lockedUntil time.Time) error
+	DeleteLoginAttempt(ctx context.Context, kind, subject string) error
+
+	UpsertUserTOTP(ctx context.Context, userTOTP database.UserTOTP) error
+	GetUserTOTP(ctx context.Context, userID string) (database.UserTOTP, error)
+	ConfirmUserTOTP(ctx context.Context, userID string, usedStep int64) error
+	SetUserTOTPLastUsedStep(ctx context.Context, userID string, usedStep int64) error
+	DeleteUserTOTP(ctx context.Context, userID string) error
+
+	CreateRecoveryCode(ctx context.Context, recoveryCode database.RecoveryCode) error
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) error
+	CountUnusedRecoveryCodes(ctx context.Context, userID string) (int, error)
+	DeleteRecoveryCodesByUserID(ctx context.Context, userID string) error
+
+	UpsertUserDevice(ctx context.Context, device database.UserDevice) (bool, error)
+	HasUserDevices(ctx context.Context, userID string) (bool, error)
+
+	CreateWebAuthnCredential(ctx context.Context, credential database.WebAuthnCredential) error
+	GetWebAuthnCredentialsByUserID(ctx context.Context, userID string) ([]database.WebAuthnCredential, error)
+	UpdateWebAuthnCredentialUsage(ctx context.Context, id string, data []byte, usedAt time.Time) error
+	DeleteWebAuthnCredential(ctx context.Context, userID, id string) error
+	CreateWebAuthnSession(ctx context.Context, session database.WebAuthnSession) error
+	ConsumeWebAuthnSession(ctx context.Context, id, kind string) (database.WebAuthnSession, error)
 }
 
 // EmailSender provides methods for sending emails
 type EmailSender interface {
-	SendEmailVerification(ctx context.Context, req resendapi.SendEmailVerificationRequest) (string, error)
+	SendEmailVerification(ctx context.Context, req mailer.SendEmailVerificationRequest) (mailer.SendResult, error)
+	SendEmailSignIn(ctx context.Context, req mailer.SendEmailSignInRequest) (mailer.SendResult, error)
+	SendRecoveryCodeUsed(ctx context.Context, req mailer.SendRecoveryCodeUsedRequest) (mailer.SendResult, error)
+	SendEmailChange(ctx context.Context, req mailer.SendEmailChangeRequest) (mailer.SendResult, error)
+	SendEmailChanged(ctx context.Context, req mailer.SendEmailChangedRequest) (mailer.SendResult, error)
+	SendSecurityNotice(ctx context.Context, req mailer.SendSecurityNoticeRequest) (mailer.SendResult, error)
 }
@@ -0,0 +1,154 @@
+package facade
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/blake2b"
+)
+
+// recoveryCodeAlphabet - alphabet of recovery codes without easily confused characters (0/o, 1/l/i)
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// RegenerateRecoveryCodes generates a new batch of recovery codes invalidating the old ones. Requires current password
+func (p *Provider) RegenerateRecoveryCodes(ctx context.Context, userID, password string) ([]string, error) {
+	var codes []string
+
+	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		user, err := p.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return ErrTwoFactorUserNotFound
+			}
+			p.log.Error("get user by id", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+		if err = bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(password)); err != nil {
+			return ErrTwoFactorInvalidPassword
+		}
+
+		userTOTP, err := p.userRepo.GetUserTOTP(ctx, userID)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return ErrTwoFactorNotEnabled
+			}
+			p.log.Error("get user totp", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+		if !userTOTP.IsConfirmed() {
+			return ErrTwoFactorNotEnabled
+		}
+
+		codes, err = p.replaceRecoveryCodes(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return p.onSecurityEvent(ctx, user, model.SecurityEvent{Type: model.SecurityEventRecoveryCodesRegenerated})
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	return codes, nil
+}
+
+// replaces recovery codes of user with a new batch and returns new codes in plain text
+func (p *Provider) replaceRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	if err := p.userRepo.DeleteRecoveryCodesByUserID(ctx, userID); err != nil {
+		p.log.Error("delete recovery codes", zap.String("userID", userID), zap.Error(err))
+		return nil, err
+	}
+
+	codes := make([]string, 0, model.RecoveryCodesCount)
+	for range model.RecoveryCodesCount {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			p.log.Error("generate recovery code", zap.String("userID", userID), zap.Error(err))
+			return nil, err
+		}
+		if err = p.userRepo.CreateRecoveryCode(ctx, database.NewRecoveryCode(userID, hashRecoveryCode(code))); err != nil {
+			p.log.Error("create recovery code", zap.String("userID", userID), zap.Error(err))
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// queues security notice about used recovery code. Errors are only logged as the notice is not critical
+func (p *Provider) notifyRecoveryCodeUsed(ctx context.Context, user database.User, clientIP string) {
+	if !user.Email.Valid || !user.EmailVerified {
+		return
+	}
+
+	codesLeft, err := p.userRepo.CountUnusedRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		p.log.Error("count unused recovery codes", zap.String("userID", user.ID), zap.Error(err))
+		return
+	}
+
+	err = p.enqueueEmail(ctx, model.EmailKindRecoveryCodeUsed, user.ID, user.Email.String, "", mailer.SendRecoveryCodeUsedRequest{
+		Email:             user.Email.String,
+		Username:          user.Username,
+		Locale:            user.Locale,
+		RecoveryCodesLeft: codesLeft,
+		ClientIP:          clientIP,
+		UsedAt:            time.Now(),
+	})
+	if err != nil {
+		p.log.Error("queue recovery code used notice", zap.String("userID", user.ID), zap.Error(err))
+	}
+}
+
+// generates a secure random recovery code formatted as two groups separated by hyphen, e.g. abcde-fgh23
+func generateRecoveryCode() (string, error) {
+	alphabetLen := big.NewInt(int64(len(recoveryCodeAlphabet)))
+
+	var sb strings.Builder
+	for i := range model.RecoveryCodeLength {
+		if i == model.RecoveryCodeLength/2 {
+			sb.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, alphabetLen)
+		if err != nil {
+			return "", fmt.Errorf("generate random number: %w", err)
+		}
+		sb.WriteByte(recoveryCodeAlphabet[n.Int64()])
+	}
+
+	return sb.String(), nil
+}
+
+// normalizes user input of recovery code: removes separators and whitespace, converts to lower case
+func normalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(code)))
+}
+
+// checks if code has recovery code format rather than TOTP code format
+func isRecoveryCode(code string) bool {
+	return len(normalizeRecoveryCode(code)) == model.RecoveryCodeLength
+}
+
+// recovery codes have enough entropy to use fast hash, which allows lookup by hash
+func hashRecoveryCode(code string) string {
+	hash := blake2b.Sum384([]byte(normalizeRecoveryCode(code)))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
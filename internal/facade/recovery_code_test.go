@@ -0,0 +1,108 @@
+package facade_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestProvider_RegenerateRecoveryCodes(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := database.User{ID: "user-123", Username: "testuser", PasswordHash: passwordHash}
+	user.SetEmail("test@example.com", true)
+
+	t.Run("success", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, _ := newTestUserTOTP(t, "user-123", true)
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserByID(gomock.Any(), "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetUserTOTP(gomock.Any(), "user-123").Return(userTOTP, nil)
+		mockUserRepo.EXPECT().DeleteRecoveryCodesByUserID(gomock.Any(), "user-123").Return(nil)
+
+		var stored []database.RecoveryCode
+		mockUserRepo.EXPECT().
+			CreateRecoveryCode(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, code database.RecoveryCode) error {
+				stored = append(stored, code)
+				return nil
+			}).
+			Times(model.RecoveryCodesCount)
+		expectSecurityNotice(t, mockUserRepo, "test@example.com", model.SecurityEventRecoveryCodesRegenerated)
+
+		codes, err := provider.RegenerateRecoveryCodes(ctx, "user-123", "password123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(codes) != model.RecoveryCodesCount {
+			t.Fatalf("expected %d codes, got %d", model.RecoveryCodesCount, len(codes))
+		}
+
+		unique := make(map[string]struct{}, len(codes))
+		for i, code := range codes {
+			if len(strings.ReplaceAll(code, "-", "")) != model.RecoveryCodeLength {
+				t.Errorf("unexpected recovery code format: %s", code)
+			}
+			// codes must be stored hashed
+			if stored[i].CodeHash == code || stored[i].UserID != "user-123" {
+				t.Errorf("unexpected stored recovery code: %+v", stored[i])
+			}
+			unique[code] = struct{}{}
+		}
+		if len(unique) != len(codes) {
+			t.Errorf("expected unique recovery codes")
+		}
+	})
+
+	t.Run("invalid password", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserByID(gomock.Any(), "user-123").Return(user, nil)
+
+		_, err := provider.RegenerateRecoveryCodes(ctx, "user-123", "wrongpassword")
+		if !errors.Is(err, facade.ErrTwoFactorInvalidPassword) {
+			t.Errorf("expected ErrTwoFactorInvalidPassword, got %v", err)
+		}
+	})
+
+	t.Run("two-factor not enabled", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, _ := newTestUserTOTP(t, "user-123", false)
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserByID(gomock.Any(), "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().GetUserTOTP(gomock.Any(), "user-123").Return(userTOTP, nil)
+
+		_, err := provider.RegenerateRecoveryCodes(ctx, "user-123", "password123")
+		if !errors.Is(err, facade.ErrTwoFactorNotEnabled) {
+			t.Errorf("expected ErrTwoFactorNotEnabled, got %v", err)
+		}
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserByID(gomock.Any(), "user-123").Return(database.User{}, database.ErrNotFound)
+
+		_, err := provider.RegenerateRecoveryCodes(ctx, "user-123", "password123")
+		if !errors.Is(err, facade.ErrTwoFactorUserNotFound) {
+			t.Errorf("expected ErrTwoFactorUserNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package facade
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/blake2b"
+)
+
+// CreateSignInTokens creates access token and refresh token for a user signed in from client.
+// User is notified if client device is not recognized
+func (p *Provider) CreateSignInTokens(ctx context.Context, user model.User, client model.ClientInfo) (TokenPair, error) {
+	tokens, err := p.CreateTokens(ctx, user)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	p.registerSignInDevice(ctx, user.ID, client)
+
+	return tokens, nil
+}
+
+// onSecurityEvent is the hook facade methods call on security relevant account events.
+// It queues security notice to user email, so it should be called in transaction of the event.
+// Unverified address may not belong to user, so it is not notified
+func (p *Provider) onSecurityEvent(ctx context.Context, user database.User, event model.SecurityEvent) error {
+	if !user.Email.Valid || !user.EmailVerified {
+		return nil
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	var err error
+	switch event.Type {
+	case model.SecurityEventEmailChanged:
+		// notice is sent to the previous address
+		err = p.enqueueEmail(ctx, model.EmailKindEmailChanged, user.ID, user.Email.String, "", mailer.SendEmailChangedRequest{
+			Email:     user.Email.String,
+			Username:  user.Username,
+			Locale:    user.Locale,
+			NewEmail:  event.NewEmail,
+			ChangedAt: event.OccurredAt,
+		})
+	default:
+		err = p.enqueueEmail(ctx, model.EmailKindSecurityNotice, user.ID, user.Email.String, "", mailer.SendSecurityNoticeRequest{
+			Email:      user.Email.String,
+			Username:   user.Username,
+			Locale:     user.Locale,
+			Event:      event.Type,
+			ClientIP:   event.ClientIP,
+			UserAgent:  event.UserAgent,
+			OccurredAt: event.OccurredAt,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("queue %s notice: %w", event.Type, err)
+	}
+
+	return nil
+}
+
+// records device user signed in from. Sign in from device not seen before is a security event,
+// except for the first recorded device of user. Errors are only logged as sign in is already completed
+func (p *Provider) registerSignInDevice(ctx context.Context, userID string, client model.ClientInfo) {
+	userAgent := []rune(client.UserAgent)
+	if len(userAgent) > model.MaxUserAgentLength {
+		userAgent = userAgent[:model.MaxUserAgentLength]
+	}
+	device := database.NewUserDevice(userID, hashUserAgent(client.UserAgent), string(userAgent), client.IP)
+
+	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		hasDevices, err := p.userRepo.HasUserDevices(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		created, err := p.userRepo.UpsertUserDevice(ctx, device)
+		if err != nil {
+			return err
+		}
+		if !created || !hasDevices {
+			return nil
+		}
+
+		user, err := p.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("get user by id: %w", err)
+		}
+
+		return p.onSecurityEvent(ctx, user, model.SecurityEvent{
+			Type:      model.SecurityEventNewSignIn,
+			ClientIP:  client.IP,
+			UserAgent: device.UserAgent,
+		})
+	})
+	if txErr != nil {
+		p.log.Error("register sign in device", zap.String("userID", userID), zap.Error(txErr))
+	}
+}
+
+// returns hash identifying device by its user agent
+func hashUserAgent(userAgent string) string {
+	hash := blake2b.Sum256([]byte(userAgent))
+	return hex.EncodeToString(hash[:])
+}
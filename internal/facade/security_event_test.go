@@ -0,0 +1,163 @@
+package facade_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	mocks "github.com/OutOfStack/game-library-auth/internal/facade/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/mock/gomock"
+)
+
+// expectSecurityNotice sets expectation for security notice about event queued to email
+func expectSecurityNotice(t *testing.T, mockUserRepo *mocks.MockUserRepo, email, event string) *mailer.SendSecurityNoticeRequest {
+	t.Helper()
+
+	var req mailer.SendSecurityNoticeRequest
+	mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), email).Return(false, nil)
+	mockUserRepo.EXPECT().
+		CreateEmailOutboxMessage(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, msg database.EmailOutboxMessage) error {
+			if msg.Kind != model.EmailKindSecurityNotice {
+				t.Errorf("expected email kind %s, got %s", model.EmailKindSecurityNotice, msg.Kind)
+			}
+			if msg.Recipient != email {
+				t.Errorf("expected recipient %s, got %s", email, msg.Recipient)
+			}
+			decodeOutboxPayload(t, msg, &req)
+			if req.Event != event {
+				t.Errorf("expected event %s, got %s", event, req.Event)
+			}
+			return nil
+		})
+
+	return &req
+}
+
+func TestProvider_CreateSignInTokens(t *testing.T) {
+	ctx := context.Background()
+
+	user := model.User{ID: "user-123", Username: "testuser", Email: "test@example.com", EmailVerified: true}
+	dbUser := database.User{ID: "user-123", Username: "testuser"}
+	dbUser.SetEmail("test@example.com", true)
+	client := model.ClientInfo{IP: "10.0.0.1", UserAgent: "Mozilla/5.0 Firefox/140.0"}
+
+	expectTokensCreated := func(mockUserRepo *mocks.MockUserRepo, mockAuth *mocks.MockAuth) {
+		mockAuth.EXPECT().CreateUserClaims(user).Return(auth.Claims{UserID: user.ID})
+		mockAuth.EXPECT().GenerateToken(gomock.Any()).Return("access-token", nil)
+		mockAuth.EXPECT().GenerateRefreshToken().Return("refresh-token", time.Now().Add(time.Hour), nil)
+		mockUserRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+	}
+
+	t.Run("unrecognized device", func(t *testing.T) {
+		provider, mockUserRepo, _, mockAuth, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTokensCreated(mockUserRepo, mockAuth)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().HasUserDevices(gomock.Any(), "user-123").Return(true, nil)
+		mockUserRepo.EXPECT().
+			UpsertUserDevice(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, device database.UserDevice) (bool, error) {
+				if device.UserAgent != client.UserAgent || device.ClientIP.String != client.IP {
+					t.Errorf("unexpected device %+v", device)
+				}
+				return true, nil
+			})
+		mockUserRepo.EXPECT().GetUserByID(gomock.Any(), "user-123").Return(dbUser, nil)
+		req := expectSecurityNotice(t, mockUserRepo, "test@example.com", model.SecurityEventNewSignIn)
+
+		tokens, err := provider.CreateSignInTokens(ctx, user, client)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if tokens.AccessToken != "access-token" {
+			t.Errorf("expected access token 'access-token', got '%s'", tokens.AccessToken)
+		}
+		if req.ClientIP != client.IP || req.UserAgent != client.UserAgent {
+			t.Errorf("expected notice with client %+v, got ip %s and user agent %s", client, req.ClientIP, req.UserAgent)
+		}
+	})
+
+	t.Run("unrecognized device with complained email", func(t *testing.T) {
+		provider, mockUserRepo, _, mockAuth, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTokensCreated(mockUserRepo, mockAuth)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().HasUserDevices(gomock.Any(), "user-123").Return(true, nil)
+		mockUserRepo.EXPECT().UpsertUserDevice(gomock.Any(), gomock.Any()).Return(true, nil)
+		mockUserRepo.EXPECT().GetUserByID(gomock.Any(), "user-123").Return(dbUser, nil)
+		// notice is not queued to address unsubscribed after complaint
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), "test@example.com").Return(true, nil)
+		mockUserRepo.EXPECT().CreateEmailOutboxMessage(gomock.Any(), gomock.Any()).Times(0)
+
+		if _, err := provider.CreateSignInTokens(ctx, user, client); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("recognized device", func(t *testing.T) {
+		provider, mockUserRepo, _, mockAuth, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTokensCreated(mockUserRepo, mockAuth)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().HasUserDevices(gomock.Any(), "user-123").Return(true, nil)
+		mockUserRepo.EXPECT().UpsertUserDevice(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		if _, err := provider.CreateSignInTokens(ctx, user, client); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("first device", func(t *testing.T) {
+		provider, mockUserRepo, _, mockAuth, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTokensCreated(mockUserRepo, mockAuth)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().HasUserDevices(gomock.Any(), "user-123").Return(false, nil)
+		mockUserRepo.EXPECT().UpsertUserDevice(gomock.Any(), gomock.Any()).Return(true, nil)
+
+		if _, err := provider.CreateSignInTokens(ctx, user, client); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("unverified email", func(t *testing.T) {
+		provider, mockUserRepo, _, mockAuth, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		unverifiedUser := dbUser
+		unverifiedUser.SetEmail("test@example.com", false)
+
+		expectTokensCreated(mockUserRepo, mockAuth)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().HasUserDevices(gomock.Any(), "user-123").Return(true, nil)
+		mockUserRepo.EXPECT().UpsertUserDevice(gomock.Any(), gomock.Any()).Return(true, nil)
+		mockUserRepo.EXPECT().GetUserByID(gomock.Any(), "user-123").Return(unverifiedUser, nil)
+
+		if _, err := provider.CreateSignInTokens(ctx, user, client); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("device error doesn't fail sign in", func(t *testing.T) {
+		provider, mockUserRepo, _, mockAuth, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTokensCreated(mockUserRepo, mockAuth)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().HasUserDevices(gomock.Any(), "user-123").Return(false, errors.New("db error"))
+
+		if _, err := provider.CreateSignInTokens(ctx, user, client); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
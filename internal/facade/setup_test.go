@@ -1,25 +1,110 @@
 package facade_test
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/database"
 	"github.com/OutOfStack/game-library-auth/internal/facade"
 	mocks "github.com/OutOfStack/game-library-auth/internal/facade/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/pkg/crypto"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
 )
 
+// base64-encoded 32 bytes key for secrets encryption in tests
+const testSecretCipherKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+// secret for signing email verification links in tests
+const testVerificationSecret = "test-verification-secret"
+
+// relying party of passkeys in tests
+const (
+	testWebAuthnRPID   = "localhost"
+	testWebAuthnOrigin = "http://localhost:3000"
+)
+
+// email verification policy in tests
+var testEmailCfg = facade.EmailCfg{
+	VerificationCodeTTL:        24 * time.Hour,
+	VerificationCodeLength:     6,
+	VerificationCodeAlphabet:   model.VerificationCodeAlphabetNumeric,
+	ResendVerificationCooldown: 120 * time.Second,
+	UnsubscribeTokenTTL:        7 * 24 * time.Hour,
+}
+
 func setupTest(t *testing.T) (*facade.Provider, *mocks.MockUserRepo, *mocks.MockEmailSender, *mocks.MockAuth, *gomock.Controller) {
 	t.Helper()
 
+	return setupTestWithEmailCfg(t, testEmailCfg)
+}
+
+func setupTestWithEmailCfg(t *testing.T, emailCfg facade.EmailCfg) (*facade.Provider, *mocks.MockUserRepo, *mocks.MockEmailSender, *mocks.MockAuth, *gomock.Controller) {
+	t.Helper()
+
 	ctrl := gomock.NewController(t)
 	mockUserRepo := mocks.NewMockUserRepo(ctrl)
 	mockEmailSender := mocks.NewMockEmailSender(ctrl)
 	mockAuth := mocks.NewMockAuth(ctrl)
-	unsubscribeTokenGenerator := auth.NewUnsubscribeTokenGenerator([]byte("test-secret-key"))
+	unsubscribeTokenGenerator := auth.NewUnsubscribeTokenGenerator(newTestSignedTokens(t, "test-secret-key"), nil)
+	verificationTokenGenerator := auth.NewEmailVerificationTokenGenerator(newTestSignedTokens(t, testVerificationSecret), nil)
 
-	provider := facade.New(zap.NewNop(), mockUserRepo, mockEmailSender, mockAuth, unsubscribeTokenGenerator)
+	provider := facade.New(zap.NewNop(), mockUserRepo, mockEmailSender, mockAuth, unsubscribeTokenGenerator, verificationTokenGenerator, newTestSecretCipher(t),
+		newTestWebAuthn(t), emailCfg)
 
 	return provider, mockUserRepo, mockEmailSender, mockAuth, ctrl
 }
+
+func newTestSignedTokens(t *testing.T, secret string) *auth.SignedTokens {
+	t.Helper()
+
+	signedTokens, err := auth.NewSignedTokens(auth.SigningKey{ID: "test", Secret: []byte(secret)})
+	if err != nil {
+		t.Fatalf("create signed tokens: %v", err)
+	}
+
+	return signedTokens
+}
+
+func newTestSecretCipher(t *testing.T) *crypto.Cipher {
+	t.Helper()
+
+	secretCipher, err := crypto.NewCipher(testSecretCipherKey)
+	if err != nil {
+		t.Fatalf("create secret cipher: %v", err)
+	}
+
+	return secretCipher
+}
+
+// decodes encrypted payload of queued email into request
+func decodeOutboxPayload(t *testing.T, msg database.EmailOutboxMessage, req any) {
+	t.Helper()
+
+	data, err := newTestSecretCipher(t).Decrypt(msg.Payload)
+	if err != nil {
+		t.Fatalf("decrypt email payload: %v", err)
+	}
+	if err = json.Unmarshal(data, req); err != nil {
+		t.Fatalf("unmarshal email payload: %v", err)
+	}
+}
+
+func newTestWebAuthn(t *testing.T) *webauthn.WebAuthn {
+	t.Helper()
+
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          testWebAuthnRPID,
+		RPDisplayName: "Game Library",
+		RPOrigins:     []string{testWebAuthnOrigin},
+	})
+	if err != nil {
+		t.Fatalf("create webauthn: %v", err)
+	}
+
+	return webAuthn
+}
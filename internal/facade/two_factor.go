@@ -0,0 +1,316 @@
+package facade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// errors
+var (
+	ErrTwoFactorUserNotFound     = errors.New("two-factor: user not found")
+	ErrTwoFactorNotAllowed       = errors.New("two-factor: not available for oauth users")
+	ErrTwoFactorAlreadyEnabled   = errors.New("two-factor: already enabled")
+	ErrTwoFactorNotEnrolled      = errors.New("two-factor: enrollment not started")
+	ErrTwoFactorNotEnabled       = errors.New("two-factor: not enabled")
+	ErrTwoFactorInvalidCode      = errors.New("two-factor: invalid code")
+	ErrTwoFactorInvalidPassword  = errors.New("two-factor: invalid password")
+	ErrTwoFactorInvalidChallenge = errors.New("two-factor: invalid or expired challenge token")
+)
+
+// EnrollTOTP starts TOTP enrollment for user and returns secret with provisioning uri.
+// Enrollment replaces previous unconfirmed one and has to be confirmed with a code from authenticator app
+func (p *Provider) EnrollTOTP(ctx context.Context, userID string) (model.TOTPEnrollment, error) {
+	var enrollment model.TOTPEnrollment
+
+	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		user, err := p.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return ErrTwoFactorUserNotFound
+			}
+			p.log.Error("get user by id", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+		if user.OAuthProvider.Valid {
+			return ErrTwoFactorNotAllowed
+		}
+
+		userTOTP, err := p.userRepo.GetUserTOTP(ctx, userID)
+		if err != nil && !errors.Is(err, database.ErrNotFound) {
+			p.log.Error("get user totp", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+		if err == nil && userTOTP.IsConfirmed() {
+			return ErrTwoFactorAlreadyEnabled
+		}
+
+		secret, uri, err := auth.GenerateTOTPKey(model.TOTPIssuer, user.Username)
+		if err != nil {
+			p.log.Error("generate totp key", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+
+		encryptedSecret, err := p.secretCipher.Encrypt([]byte(secret))
+		if err != nil {
+			p.log.Error("encrypt totp secret", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+
+		if err = p.userRepo.UpsertUserTOTP(ctx, database.NewUserTOTP(userID, encryptedSecret)); err != nil {
+			p.log.Error("upsert user totp", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+
+		enrollment = model.TOTPEnrollment{
+			Secret: secret,
+			URI:    uri,
+		}
+		return nil
+	})
+	if txErr != nil {
+		return model.TOTPEnrollment{}, txErr
+	}
+
+	return enrollment, nil
+}
+
+// ConfirmTOTP confirms TOTP enrollment with a code from authenticator app and enables two-factor authentication.
+// Returns a batch of single-use recovery codes
+func (p *Provider) ConfirmTOTP(ctx context.Context, userID, code string) ([]string, error) {
+	var recoveryCodes []string
+
+	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		userTOTP, err := p.userRepo.GetUserTOTP(ctx, userID)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return ErrTwoFactorNotEnrolled
+			}
+			p.log.Error("get user totp", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+		if userTOTP.IsConfirmed() {
+			return ErrTwoFactorAlreadyEnabled
+		}
+
+		step, err := p.validateTOTPCode(userTOTP, code)
+		if err != nil {
+			return err
+		}
+
+		if err = p.userRepo.ConfirmUserTOTP(ctx, userID, step); err != nil {
+			p.log.Error("confirm user totp", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+
+		recoveryCodes, err = p.replaceRecoveryCodes(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		user, err := p.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			p.log.Error("get user by id", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+
+		return p.onSecurityEvent(ctx, user, model.SecurityEvent{Type: model.SecurityEventTwoFactorEnabled})
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	return recoveryCodes, nil
+}
+
+// DisableTOTP disables two-factor authentication and deletes recovery codes.
+// Requires current password and a valid TOTP code or recovery code
+func (p *Provider) DisableTOTP(ctx context.Context, userID, password, code string) error {
+	var user database.User
+	var usedRecoveryCode bool
+
+	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = p.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return ErrTwoFactorUserNotFound
+			}
+			p.log.Error("get user by id", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+		if err = bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(password)); err != nil {
+			return ErrTwoFactorInvalidPassword
+		}
+
+		userTOTP, err := p.userRepo.GetUserTOTP(ctx, userID)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return ErrTwoFactorNotEnabled
+			}
+			p.log.Error("get user totp", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+		if !userTOTP.IsConfirmed() {
+			return ErrTwoFactorNotEnabled
+		}
+
+		if usedRecoveryCode, err = p.verifySecondFactor(ctx, userTOTP, code); err != nil {
+			return err
+		}
+
+		if err = p.userRepo.DeleteUserTOTP(ctx, userID); err != nil {
+			p.log.Error("delete user totp", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+		if err = p.userRepo.DeleteRecoveryCodesByUserID(ctx, userID); err != nil {
+			p.log.Error("delete recovery codes", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+
+		return p.onSecurityEvent(ctx, user, model.SecurityEvent{Type: model.SecurityEventTwoFactorDisabled})
+	})
+	if txErr != nil {
+		return txErr
+	}
+
+	if usedRecoveryCode {
+		p.notifyRecoveryCodeUsed(ctx, user, "")
+	}
+
+	return nil
+}
+
+// StartTwoFactorChallenge returns challenge token if user has two-factor authentication enabled.
+// Returned required flag is false when no second factor is needed
+func (p *Provider) StartTwoFactorChallenge(ctx context.Context, userID string) (challengeToken string, required bool, err error) {
+	userTOTP, err := p.userRepo.GetUserTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return "", false, nil
+		}
+		p.log.Error("get user totp", zap.String("userID", userID), zap.Error(err))
+		return "", false, err
+	}
+	if !userTOTP.IsConfirmed() {
+		return "", false, nil
+	}
+
+	challengeToken, err = p.auth.GenerateChallengeToken(userID, model.TwoFactorChallengeTTL)
+	if err != nil {
+		p.log.Error("generate challenge token", zap.String("userID", userID), zap.Error(err))
+		return "", false, err
+	}
+
+	return challengeToken, true, nil
+}
+
+// CompleteTwoFactorSignIn completes sign in with challenge token and TOTP code or recovery code.
+// Failed codes are counted towards sign in lockout. Returns TooManyRequestsError if sign in is locked
+func (p *Provider) CompleteTwoFactorSignIn(ctx context.Context, challengeToken, code, clientIP string) (model.User, error) {
+	userID, err := p.auth.ValidateChallengeToken(challengeToken)
+	if err != nil {
+		return model.User{}, ErrTwoFactorInvalidChallenge
+	}
+
+	user, err := p.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return model.User{}, ErrTwoFactorInvalidChallenge
+		}
+		p.log.Error("get user by id", zap.String("userID", userID), zap.Error(err))
+		return model.User{}, err
+	}
+
+	// check if sign in is locked
+	if err = p.checkSignInLockout(ctx, user.Username, clientIP); err != nil {
+		if AsTooManyRequestsError(err) == nil {
+			p.log.Error("check sign in lockout", zap.String("username", user.Username), zap.Error(err))
+		}
+		return model.User{}, err
+	}
+
+	var usedRecoveryCode bool
+	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		userTOTP, gErr := p.userRepo.GetUserTOTP(ctx, userID)
+		if gErr != nil {
+			if errors.Is(gErr, database.ErrNotFound) {
+				return ErrTwoFactorNotEnabled
+			}
+			p.log.Error("get user totp", zap.String("userID", userID), zap.Error(gErr))
+			return gErr
+		}
+		if !userTOTP.IsConfirmed() {
+			return ErrTwoFactorNotEnabled
+		}
+
+		var vErr error
+		usedRecoveryCode, vErr = p.verifySecondFactor(ctx, userTOTP, code)
+		return vErr
+	})
+	if txErr != nil {
+		if errors.Is(txErr, ErrTwoFactorInvalidCode) {
+			p.registerFailedSignIn(ctx, user.Username, clientIP)
+		}
+		return model.User{}, txErr
+	}
+
+	p.resetFailedSignIns(ctx, user.Username)
+
+	if usedRecoveryCode {
+		p.notifyRecoveryCodeUsed(ctx, user, clientIP)
+	}
+
+	return mapDBUserToUser(user), nil
+}
+
+// verifies second factor code which is either TOTP code or recovery code and marks it as used.
+// Returns whether recovery code was used
+func (p *Provider) verifySecondFactor(ctx context.Context, userTOTP database.UserTOTP, code string) (usedRecoveryCode bool, err error) {
+	if isRecoveryCode(code) {
+		if err = p.userRepo.UseRecoveryCode(ctx, userTOTP.UserID, hashRecoveryCode(code)); err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return false, ErrTwoFactorInvalidCode
+			}
+			p.log.Error("use recovery code", zap.String("userID", userTOTP.UserID), zap.Error(err))
+			return false, err
+		}
+		return true, nil
+	}
+
+	step, err := p.validateTOTPCode(userTOTP, code)
+	if err != nil {
+		return false, err
+	}
+
+	if err = p.userRepo.SetUserTOTPLastUsedStep(ctx, userTOTP.UserID, step); err != nil {
+		p.log.Error("set user totp last used step", zap.String("userID", userTOTP.UserID), zap.Error(err))
+		return false, err
+	}
+
+	return false, nil
+}
+
+// validates TOTP code against stored secret and returns matched time step.
+// Codes of already used time steps are rejected to prevent replay
+func (p *Provider) validateTOTPCode(userTOTP database.UserTOTP, code string) (int64, error) {
+	secret, err := p.secretCipher.Decrypt(userTOTP.Secret)
+	if err != nil {
+		p.log.Error("decrypt totp secret", zap.String("userID", userTOTP.UserID), zap.Error(err))
+		return 0, fmt.Errorf("decrypt totp secret: %w", err)
+	}
+
+	step, valid := auth.ValidateTOTPCode(string(secret), code, time.Now())
+	if !valid || step <= userTOTP.LastUsedStep {
+		return 0, ErrTwoFactorInvalidCode
+	}
+
+	return step, nil
+}
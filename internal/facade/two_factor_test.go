@@ -0,0 +1,581 @@
+package facade_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	mocks "github.com/OutOfStack/game-library-auth/internal/facade/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/pquerna/otp/totp"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// expectTx sets expectation for running function in transaction
+func expectTx(mockUserRepo *mocks.MockUserRepo) {
+	mockUserRepo.EXPECT().
+		RunWithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, f func(context.Context) error) error {
+			return f(ctx)
+		})
+}
+
+// newTestUserTOTP returns user totp record with encrypted secret and secret itself
+func newTestUserTOTP(t *testing.T, userID string, confirmed bool) (database.UserTOTP, string) {
+	t.Helper()
+
+	secret, _, err := auth.GenerateTOTPKey(model.TOTPIssuer, "testuser")
+	if err != nil {
+		t.Fatalf("generate totp key: %v", err)
+	}
+	encryptedSecret, err := newTestSecretCipher(t).Encrypt([]byte(secret))
+	if err != nil {
+		t.Fatalf("encrypt secret: %v", err)
+	}
+
+	userTOTP := database.NewUserTOTP(userID, encryptedSecret)
+	if confirmed {
+		userTOTP.ConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+
+	return userTOTP, secret
+}
+
+// expectRecoveryCodesReplaced sets expectations for replacing recovery codes of user with a new batch
+func expectRecoveryCodesReplaced(mockUserRepo *mocks.MockUserRepo, userID string) {
+	mockUserRepo.EXPECT().
+		DeleteRecoveryCodesByUserID(gomock.Any(), userID).
+		Return(nil)
+	mockUserRepo.EXPECT().
+		CreateRecoveryCode(gomock.Any(), gomock.Any()).
+		Return(nil).
+		Times(model.RecoveryCodesCount)
+}
+
+func generateTestTOTPCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	if err != nil {
+		t.Fatalf("generate totp code: %v", err)
+	}
+
+	return code
+}
+
+func TestProvider_EnrollTOTP(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserByID(gomock.Any(), "user-123").
+			Return(database.User{ID: "user-123", Username: "testuser"}, nil)
+		mockUserRepo.EXPECT().
+			GetUserTOTP(gomock.Any(), "user-123").
+			Return(database.UserTOTP{}, database.ErrNotFound)
+
+		var stored database.UserTOTP
+		mockUserRepo.EXPECT().
+			UpsertUserTOTP(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, userTOTP database.UserTOTP) error {
+				stored = userTOTP
+				return nil
+			})
+
+		enrollment, err := provider.EnrollTOTP(ctx, "user-123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if enrollment.Secret == "" || enrollment.URI == "" {
+			t.Fatalf("expected secret and uri, got %+v", enrollment)
+		}
+		if stored.UserID != "user-123" {
+			t.Errorf("expected user id user-123, got %s", stored.UserID)
+		}
+
+		// secret must be stored encrypted
+		decrypted, err := newTestSecretCipher(t).Decrypt(stored.Secret)
+		if err != nil {
+			t.Fatalf("decrypt stored secret: %v", err)
+		}
+		if string(decrypted) != enrollment.Secret {
+			t.Errorf("expected stored secret to decrypt to enrollment secret")
+		}
+		if string(stored.Secret) == enrollment.Secret {
+			t.Errorf("expected stored secret to be encrypted")
+		}
+	})
+
+	t.Run("already enabled", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, _ := newTestUserTOTP(t, "user-123", true)
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserByID(gomock.Any(), "user-123").
+			Return(database.User{ID: "user-123", Username: "testuser"}, nil)
+		mockUserRepo.EXPECT().
+			GetUserTOTP(gomock.Any(), "user-123").
+			Return(userTOTP, nil)
+
+		_, err := provider.EnrollTOTP(ctx, "user-123")
+		if !errors.Is(err, facade.ErrTwoFactorAlreadyEnabled) {
+			t.Errorf("expected ErrTwoFactorAlreadyEnabled, got %v", err)
+		}
+	})
+
+	t.Run("oauth user", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		user := database.User{ID: "user-123", Username: "testuser"}
+		user.SetOAuthID(model.GoogleAuthTokenProvider, "google-id")
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserByID(gomock.Any(), "user-123").
+			Return(user, nil)
+
+		_, err := provider.EnrollTOTP(ctx, "user-123")
+		if !errors.Is(err, facade.ErrTwoFactorNotAllowed) {
+			t.Errorf("expected ErrTwoFactorNotAllowed, got %v", err)
+		}
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserByID(gomock.Any(), "user-123").
+			Return(database.User{}, database.ErrNotFound)
+
+		_, err := provider.EnrollTOTP(ctx, "user-123")
+		if !errors.Is(err, facade.ErrTwoFactorUserNotFound) {
+			t.Errorf("expected ErrTwoFactorUserNotFound, got %v", err)
+		}
+	})
+}
+
+func TestProvider_ConfirmTOTP(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, secret := newTestUserTOTP(t, "user-123", false)
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserTOTP(gomock.Any(), "user-123").
+			Return(userTOTP, nil)
+		mockUserRepo.EXPECT().
+			ConfirmUserTOTP(gomock.Any(), "user-123", gomock.Any()).
+			Return(nil)
+		expectRecoveryCodesReplaced(mockUserRepo, "user-123")
+		user := database.User{ID: "user-123", Username: "testuser"}
+		user.SetEmail("test@example.com", true)
+		mockUserRepo.EXPECT().
+			GetUserByID(gomock.Any(), "user-123").
+			Return(user, nil)
+		expectSecurityNotice(t, mockUserRepo, "test@example.com", model.SecurityEventTwoFactorEnabled)
+
+		recoveryCodes, err := provider.ConfirmTOTP(ctx, "user-123", generateTestTOTPCode(t, secret))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(recoveryCodes) != model.RecoveryCodesCount {
+			t.Errorf("expected %d recovery codes, got %d", model.RecoveryCodesCount, len(recoveryCodes))
+		}
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, _ := newTestUserTOTP(t, "user-123", false)
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserTOTP(gomock.Any(), "user-123").
+			Return(userTOTP, nil)
+
+		_, err := provider.ConfirmTOTP(ctx, "user-123", "000000")
+		if !errors.Is(err, facade.ErrTwoFactorInvalidCode) {
+			t.Errorf("expected ErrTwoFactorInvalidCode, got %v", err)
+		}
+	})
+
+	t.Run("not enrolled", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserTOTP(gomock.Any(), "user-123").
+			Return(database.UserTOTP{}, database.ErrNotFound)
+
+		_, err := provider.ConfirmTOTP(ctx, "user-123", "123456")
+		if !errors.Is(err, facade.ErrTwoFactorNotEnrolled) {
+			t.Errorf("expected ErrTwoFactorNotEnrolled, got %v", err)
+		}
+	})
+}
+
+func TestProvider_DisableTOTP(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := database.User{ID: "user-123", Username: "testuser", PasswordHash: passwordHash}
+
+	t.Run("success", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, secret := newTestUserTOTP(t, "user-123", true)
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserByID(gomock.Any(), "user-123").
+			Return(user, nil)
+		mockUserRepo.EXPECT().
+			GetUserTOTP(gomock.Any(), "user-123").
+			Return(userTOTP, nil)
+		mockUserRepo.EXPECT().
+			SetUserTOTPLastUsedStep(gomock.Any(), "user-123", gomock.Any()).
+			Return(nil)
+		mockUserRepo.EXPECT().
+			DeleteUserTOTP(gomock.Any(), "user-123").
+			Return(nil)
+		mockUserRepo.EXPECT().
+			DeleteRecoveryCodesByUserID(gomock.Any(), "user-123").
+			Return(nil)
+
+		if err := provider.DisableTOTP(ctx, "user-123", "password123", generateTestTOTPCode(t, secret)); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("success with recovery code", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, _ := newTestUserTOTP(t, "user-123", true)
+		userWithEmail := user
+		userWithEmail.SetEmail("test@example.com", true)
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserByID(gomock.Any(), "user-123").
+			Return(userWithEmail, nil)
+		mockUserRepo.EXPECT().
+			GetUserTOTP(gomock.Any(), "user-123").
+			Return(userTOTP, nil)
+		mockUserRepo.EXPECT().
+			UseRecoveryCode(gomock.Any(), "user-123", gomock.Any()).
+			Return(nil)
+		mockUserRepo.EXPECT().
+			DeleteUserTOTP(gomock.Any(), "user-123").
+			Return(nil)
+		mockUserRepo.EXPECT().
+			DeleteRecoveryCodesByUserID(gomock.Any(), "user-123").
+			Return(nil)
+		expectSecurityNotice(t, mockUserRepo, "test@example.com", model.SecurityEventTwoFactorDisabled)
+		mockUserRepo.EXPECT().
+			CountUnusedRecoveryCodes(gomock.Any(), "user-123").
+			Return(0, nil)
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), gomock.Any()).Return(false, nil)
+		mockUserRepo.EXPECT().
+			CreateEmailOutboxMessage(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		if err := provider.DisableTOTP(ctx, "user-123", "password123", "abcde-fgh23"); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("invalid password", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserByID(gomock.Any(), "user-123").
+			Return(user, nil)
+
+		err := provider.DisableTOTP(ctx, "user-123", "wrongpassword", "123456")
+		if !errors.Is(err, facade.ErrTwoFactorInvalidPassword) {
+			t.Errorf("expected ErrTwoFactorInvalidPassword, got %v", err)
+		}
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, _ := newTestUserTOTP(t, "user-123", false)
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserByID(gomock.Any(), "user-123").
+			Return(user, nil)
+		mockUserRepo.EXPECT().
+			GetUserTOTP(gomock.Any(), "user-123").
+			Return(userTOTP, nil)
+
+		err := provider.DisableTOTP(ctx, "user-123", "password123", "123456")
+		if !errors.Is(err, facade.ErrTwoFactorNotEnabled) {
+			t.Errorf("expected ErrTwoFactorNotEnabled, got %v", err)
+		}
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, _ := newTestUserTOTP(t, "user-123", true)
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserByID(gomock.Any(), "user-123").
+			Return(user, nil)
+		mockUserRepo.EXPECT().
+			GetUserTOTP(gomock.Any(), "user-123").
+			Return(userTOTP, nil)
+
+		err := provider.DisableTOTP(ctx, "user-123", "password123", "000000")
+		if !errors.Is(err, facade.ErrTwoFactorInvalidCode) {
+			t.Errorf("expected ErrTwoFactorInvalidCode, got %v", err)
+		}
+	})
+}
+
+func TestProvider_StartTwoFactorChallenge(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("not enabled", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			GetUserTOTP(ctx, "user-123").
+			Return(database.UserTOTP{}, database.ErrNotFound)
+
+		token, required, err := provider.StartTwoFactorChallenge(ctx, "user-123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if required || token != "" {
+			t.Errorf("expected no challenge, got required=%v token=%q", required, token)
+		}
+	})
+
+	t.Run("enrollment not confirmed", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, _ := newTestUserTOTP(t, "user-123", false)
+		mockUserRepo.EXPECT().
+			GetUserTOTP(ctx, "user-123").
+			Return(userTOTP, nil)
+
+		_, required, err := provider.StartTwoFactorChallenge(ctx, "user-123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if required {
+			t.Error("expected no challenge for unconfirmed enrollment")
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		provider, mockUserRepo, _, mockAuth, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, _ := newTestUserTOTP(t, "user-123", true)
+		mockUserRepo.EXPECT().
+			GetUserTOTP(ctx, "user-123").
+			Return(userTOTP, nil)
+		mockAuth.EXPECT().
+			GenerateChallengeToken("user-123", model.TwoFactorChallengeTTL).
+			Return("challenge-token", nil)
+
+		token, required, err := provider.StartTwoFactorChallenge(ctx, "user-123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !required || token != "challenge-token" {
+			t.Errorf("expected challenge-token, got required=%v token=%q", required, token)
+		}
+	})
+}
+
+func TestProvider_CompleteTwoFactorSignIn(t *testing.T) {
+	ctx := context.Background()
+	user := database.User{ID: "user-123", Username: "testuser"}
+
+	t.Run("success", func(t *testing.T) {
+		provider, mockUserRepo, _, mockAuth, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, secret := newTestUserTOTP(t, "user-123", true)
+
+		mockAuth.EXPECT().ValidateChallengeToken("challenge-token").Return("user-123", nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		expectNoSignInLockout(mockUserRepo, "testuser", "127.0.0.1")
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserTOTP(gomock.Any(), "user-123").Return(userTOTP, nil)
+		mockUserRepo.EXPECT().SetUserTOTPLastUsedStep(gomock.Any(), "user-123", gomock.Any()).Return(nil)
+		mockUserRepo.EXPECT().DeleteLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser").Return(nil)
+
+		result, err := provider.CompleteTwoFactorSignIn(ctx, "challenge-token", generateTestTOTPCode(t, secret), "127.0.0.1")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result.ID != "user-123" {
+			t.Errorf("expected user id user-123, got %s", result.ID)
+		}
+	})
+
+	t.Run("success with recovery code", func(t *testing.T) {
+		provider, mockUserRepo, _, mockAuth, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, _ := newTestUserTOTP(t, "user-123", true)
+		userWithEmail := user
+		userWithEmail.SetEmail("test@example.com", true)
+
+		mockAuth.EXPECT().ValidateChallengeToken("challenge-token").Return("user-123", nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(userWithEmail, nil)
+		expectNoSignInLockout(mockUserRepo, "testuser", "127.0.0.1")
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserTOTP(gomock.Any(), "user-123").Return(userTOTP, nil)
+		mockUserRepo.EXPECT().UseRecoveryCode(gomock.Any(), "user-123", gomock.Any()).Return(nil)
+		mockUserRepo.EXPECT().DeleteLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser").Return(nil)
+		mockUserRepo.EXPECT().CountUnusedRecoveryCodes(ctx, "user-123").Return(9, nil)
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), gomock.Any()).Return(false, nil)
+		mockUserRepo.EXPECT().
+			CreateEmailOutboxMessage(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg database.EmailOutboxMessage) error {
+				var req mailer.SendRecoveryCodeUsedRequest
+				decodeOutboxPayload(t, msg, &req)
+				if req.Email != "test@example.com" || req.RecoveryCodesLeft != 9 || req.ClientIP != "127.0.0.1" {
+					t.Errorf("unexpected notice request: %+v", req)
+				}
+				return nil
+			})
+
+		result, err := provider.CompleteTwoFactorSignIn(ctx, "challenge-token", " ABCDE-FGH23 ", "127.0.0.1")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result.ID != "user-123" {
+			t.Errorf("expected user id user-123, got %s", result.ID)
+		}
+	})
+
+	t.Run("used recovery code", func(t *testing.T) {
+		provider, mockUserRepo, _, mockAuth, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, _ := newTestUserTOTP(t, "user-123", true)
+
+		mockAuth.EXPECT().ValidateChallengeToken("challenge-token").Return("user-123", nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		expectNoSignInLockout(mockUserRepo, "testuser", "127.0.0.1")
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserTOTP(gomock.Any(), "user-123").Return(userTOTP, nil)
+		mockUserRepo.EXPECT().UseRecoveryCode(gomock.Any(), "user-123", gomock.Any()).Return(database.ErrNotFound)
+		expectFailedSignIn(mockUserRepo, "testuser", "127.0.0.1", 1)
+
+		_, err := provider.CompleteTwoFactorSignIn(ctx, "challenge-token", "abcde-fgh23", "127.0.0.1")
+		if !errors.Is(err, facade.ErrTwoFactorInvalidCode) {
+			t.Errorf("expected ErrTwoFactorInvalidCode, got %v", err)
+		}
+	})
+
+	t.Run("invalid challenge token", func(t *testing.T) {
+		provider, _, _, mockAuth, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockAuth.EXPECT().ValidateChallengeToken("bad-token").Return("", errors.New("token expired"))
+
+		_, err := provider.CompleteTwoFactorSignIn(ctx, "bad-token", "123456", "127.0.0.1")
+		if !errors.Is(err, facade.ErrTwoFactorInvalidChallenge) {
+			t.Errorf("expected ErrTwoFactorInvalidChallenge, got %v", err)
+		}
+	})
+
+	t.Run("invalid code registers failed attempt", func(t *testing.T) {
+		provider, mockUserRepo, _, mockAuth, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, _ := newTestUserTOTP(t, "user-123", true)
+
+		mockAuth.EXPECT().ValidateChallengeToken("challenge-token").Return("user-123", nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		expectNoSignInLockout(mockUserRepo, "testuser", "127.0.0.1")
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserTOTP(gomock.Any(), "user-123").Return(userTOTP, nil)
+		expectFailedSignIn(mockUserRepo, "testuser", "127.0.0.1", 1)
+
+		_, err := provider.CompleteTwoFactorSignIn(ctx, "challenge-token", "000000", "127.0.0.1")
+		if !errors.Is(err, facade.ErrTwoFactorInvalidCode) {
+			t.Errorf("expected ErrTwoFactorInvalidCode, got %v", err)
+		}
+	})
+
+	t.Run("reused code", func(t *testing.T) {
+		provider, mockUserRepo, _, mockAuth, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		userTOTP, secret := newTestUserTOTP(t, "user-123", true)
+		// codes up to the next time step were already used
+		userTOTP.LastUsedStep = time.Now().Unix()/30 + 1
+
+		mockAuth.EXPECT().ValidateChallengeToken("challenge-token").Return("user-123", nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		expectNoSignInLockout(mockUserRepo, "testuser", "127.0.0.1")
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetUserTOTP(gomock.Any(), "user-123").Return(userTOTP, nil)
+		expectFailedSignIn(mockUserRepo, "testuser", "127.0.0.1", 1)
+
+		_, err := provider.CompleteTwoFactorSignIn(ctx, "challenge-token", generateTestTOTPCode(t, secret), "127.0.0.1")
+		if !errors.Is(err, facade.ErrTwoFactorInvalidCode) {
+			t.Errorf("expected ErrTwoFactorInvalidCode, got %v", err)
+		}
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		provider, mockUserRepo, _, mockAuth, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockAuth.EXPECT().ValidateChallengeToken("challenge-token").Return("user-123", nil)
+		mockUserRepo.EXPECT().GetUserByID(ctx, "user-123").Return(user, nil)
+		mockUserRepo.EXPECT().
+			GetLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser").
+			Return(database.LoginAttempt{
+				FailedCount: model.UsernameLoginFreeAttempts + 1,
+				LockedUntil: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+			}, nil)
+
+		_, err := provider.CompleteTwoFactorSignIn(ctx, "challenge-token", "123456", "127.0.0.1")
+		if facade.AsTooManyRequestsError(err) == nil {
+			t.Errorf("expected TooManyRequestsError, got %v", err)
+		}
+	})
+}
@@ -2,13 +2,21 @@ package facade
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
 	"go.uber.org/zap"
 )
 
-// UnsubscribeEmail unsubscribes an email address from all notifications
+// errors
+var (
+	ErrResubscribeNoVerifiedEmail = errors.New("resubscribe: user has no verified email")
+)
+
+// UnsubscribeEmail unsubscribes an email address from all email categories except security.
+// Security emails, e.g. verification codes, are still sent
 func (p *Provider) UnsubscribeEmail(ctx context.Context, token string) (string, error) {
 	// validate token and extract email
 	email, err := p.unsubscribeTokenGenerator.ValidateToken(token)
@@ -16,17 +24,78 @@ func (p *Provider) UnsubscribeEmail(ctx context.Context, token string) (string,
 		return "", fmt.Errorf("invalid unsubscribe token: %w", err)
 	}
 
-	// create unsubscribe record
-	unsubscribe := database.NewEmailUnsubscribe(email)
-	if err = p.userRepo.CreateEmailUnsubscribe(ctx, unsubscribe); err != nil {
-		return "", fmt.Errorf("create email unsubscribe: %w", err)
+	if err = p.setEmailPreferences(ctx, email, func(string) bool { return false }); err != nil {
+		return "", err
 	}
 
 	p.log.Info("email unsubscribed", zap.String("email", email))
 	return email, nil
 }
 
-// IsEmailUnsubscribed checks if an email address is unsubscribed from all notifications
-func (p *Provider) IsEmailUnsubscribed(ctx context.Context, email string) (bool, error) {
-	return p.userRepo.IsEmailUnsubscribed(ctx, email)
+// ResubscribeEmail resubscribes email address of resubscribe token to all email categories. Returns email address.
+// Unsubscribe made after bounce or complaint is kept, as the link can be used by anyone holding an old email
+func (p *Provider) ResubscribeEmail(ctx context.Context, token string) (string, error) {
+	// validate token and extract email
+	email, err := p.unsubscribeTokenGenerator.ValidateResubscribeToken(token)
+	if err != nil {
+		return "", fmt.Errorf("invalid resubscribe token: %w", err)
+	}
+
+	if err = p.resubscribeEmail(ctx, email, model.ResubscribeSourceLink, "", false); err != nil {
+		return "", err
+	}
+
+	return email, nil
+}
+
+// ResubscribeUserEmail resubscribes verified email address of user to all emails, lifting unsubscribe made after bounce or complaint.
+// Returns ErrResubscribeNoVerifiedEmail if user has no verified email
+func (p *Provider) ResubscribeUserEmail(ctx context.Context, userID string) error {
+	user, err := p.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		p.log.Error("get user by id", zap.String("userID", userID), zap.Error(err))
+		return err
+	}
+
+	if !user.Email.Valid || !user.EmailVerified {
+		return ErrResubscribeNoVerifiedEmail
+	}
+
+	return p.resubscribeEmail(ctx, user.Email.String, model.ResubscribeSourceAccount, user.ID, true)
+}
+
+// subscribes email address to all email categories. If liftUnsubscribe is set, unsubscribe record of email address made
+// after bounce or complaint is lifted too. Resubscribe is recorded along with lifted unsubscribe record.
+// User id is empty if resubscribe was made by link
+func (p *Provider) resubscribeEmail(ctx context.Context, email, source, userID string, liftUnsubscribe bool) error {
+	return p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		resubscribe := database.NewEmailResubscribe(email, source, userID)
+
+		if liftUnsubscribe {
+			unsubscribe, err := p.userRepo.GetEmailUnsubscribe(ctx, email)
+			switch {
+			case err == nil:
+				resubscribe.SetLiftedUnsubscribe(unsubscribe)
+				if err = p.userRepo.DeleteEmailUnsubscribe(ctx, email); err != nil && !errors.Is(err, database.ErrNotFound) {
+					p.log.Error("delete email unsubscribe", zap.String("email", email), zap.Error(err))
+					return fmt.Errorf("delete email unsubscribe: %w", err)
+				}
+			case !errors.Is(err, database.ErrNotFound):
+				p.log.Error("get email unsubscribe", zap.String("email", email), zap.Error(err))
+				return fmt.Errorf("get email unsubscribe: %w", err)
+			}
+		}
+
+		if err := p.setEmailPreferences(ctx, email, func(string) bool { return true }); err != nil {
+			return err
+		}
+
+		if err := p.userRepo.CreateEmailResubscribe(ctx, resubscribe); err != nil {
+			p.log.Error("create email resubscribe", zap.String("email", email), zap.Error(err))
+			return fmt.Errorf("create email resubscribe: %w", err)
+		}
+
+		p.log.Info("email resubscribed", zap.String("email", email), zap.String("source", source))
+		return nil
+	})
 }
@@ -2,11 +2,15 @@ package facade_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/model"
 	"go.uber.org/mock/gomock"
 )
 
@@ -18,11 +22,15 @@ func TestProvider_UnsubscribeEmail_Success(t *testing.T) {
 
 	email := "test@example.com"
 	expiresAt := time.Now().Add(24 * time.Hour)
-	tokenGen := auth.NewUnsubscribeTokenGenerator([]byte("test-secret-key"))
+	tokenGen := auth.NewUnsubscribeTokenGenerator(newTestSignedTokens(t, "test-secret-key"), nil)
 	token := tokenGen.GenerateToken(email, expiresAt)
 
+	expectTx(mockUserRepo)
 	mockUserRepo.EXPECT().
-		CreateEmailUnsubscribe(ctx, gomock.Any()).
+		UpsertEmailPreference(ctx, database.NewEmailPreference(email, model.EmailCategoryProductNews, false)).
+		Return(nil)
+	mockUserRepo.EXPECT().
+		UpsertEmailPreference(ctx, database.NewEmailPreference(email, model.EmailCategoryPublisherReports, false)).
 		Return(nil)
 
 	resultEmail, err := provider.UnsubscribeEmail(ctx, token)
@@ -57,7 +65,7 @@ func TestProvider_UnsubscribeEmail_ExpiredToken(t *testing.T) {
 
 	email := "test@example.com"
 	expiresAt := time.Now().Add(-1 * time.Hour) // Expired token
-	tokenGen := auth.NewUnsubscribeTokenGenerator([]byte("test-secret-key"))
+	tokenGen := auth.NewUnsubscribeTokenGenerator(newTestSignedTokens(t, "test-secret-key"), nil)
 	token := tokenGen.GenerateToken(email, expiresAt)
 
 	_, err := provider.UnsubscribeEmail(ctx, token)
@@ -66,7 +74,7 @@ func TestProvider_UnsubscribeEmail_ExpiredToken(t *testing.T) {
 	}
 }
 
-func TestProvider_UnsubscribeEmail_CreateUnsubscribeFails(t *testing.T) {
+func TestProvider_UnsubscribeEmail_UpsertPreferenceFails(t *testing.T) {
 	provider, mockUserRepo, _, _, ctrl := setupTest(t)
 	defer ctrl.Finish()
 
@@ -74,16 +82,157 @@ func TestProvider_UnsubscribeEmail_CreateUnsubscribeFails(t *testing.T) {
 
 	email := "test@example.com"
 	expiresAt := time.Now().Add(24 * time.Hour)
-	tokenGen := auth.NewUnsubscribeTokenGenerator([]byte("test-secret-key"))
+	tokenGen := auth.NewUnsubscribeTokenGenerator(newTestSignedTokens(t, "test-secret-key"), nil)
 	token := tokenGen.GenerateToken(email, expiresAt)
 
-	createError := errors.New("failed to create unsubscribe record")
+	upsertError := errors.New("failed to upsert email preference")
+	expectTx(mockUserRepo)
 	mockUserRepo.EXPECT().
-		CreateEmailUnsubscribe(ctx, gomock.Any()).
-		Return(createError)
+		UpsertEmailPreference(ctx, gomock.Any()).
+		Return(upsertError)
 
 	_, err := provider.UnsubscribeEmail(ctx, token)
 	if err == nil {
-		t.Error("expected error when CreateEmailUnsubscribe fails")
+		t.Error("expected error when UpsertEmailPreference fails")
 	}
 }
+
+func TestProvider_ResubscribeEmail(t *testing.T) {
+	provider, mockUserRepo, _, _, ctrl := setupTest(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	email := "test@example.com"
+	tokenGen := auth.NewUnsubscribeTokenGenerator(newTestSignedTokens(t, "test-secret-key"), nil)
+
+	t.Run("keeps unsubscribe after complaint", func(t *testing.T) {
+		token := tokenGen.GenerateResubscribeToken(email, time.Now().Add(time.Hour))
+
+		// unsubscribe record is neither read nor deleted, preferences are set in nested transaction
+		expectTx(mockUserRepo)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetEmailUnsubscribe(gomock.Any(), gomock.Any()).Times(0)
+		mockUserRepo.EXPECT().DeleteEmailUnsubscribe(gomock.Any(), gomock.Any()).Times(0)
+		mockUserRepo.EXPECT().
+			UpsertEmailPreference(ctx, database.NewEmailPreference(email, model.EmailCategoryProductNews, true)).
+			Return(nil)
+		mockUserRepo.EXPECT().
+			UpsertEmailPreference(ctx, database.NewEmailPreference(email, model.EmailCategoryPublisherReports, true)).
+			Return(nil)
+		mockUserRepo.EXPECT().
+			CreateEmailResubscribe(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, resubscribe database.EmailResubscribe) error {
+				if resubscribe.Email != email || resubscribe.Source != model.ResubscribeSourceLink || resubscribe.UserID.Valid ||
+					resubscribe.UnsubscribeReason.Valid {
+					t.Errorf("unexpected resubscribe record %+v", resubscribe)
+				}
+				return nil
+			})
+
+		result, err := provider.ResubscribeEmail(ctx, token)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result != email {
+			t.Errorf("expected email %s, got %s", email, result)
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		if _, err := provider.ResubscribeEmail(ctx, "invalid-token"); err == nil {
+			t.Error("expected error for invalid token")
+		}
+	})
+
+	t.Run("unsubscribe token", func(t *testing.T) {
+		token := tokenGen.GenerateToken(email, time.Now().Add(time.Hour))
+		if _, err := provider.ResubscribeEmail(ctx, token); !errors.Is(err, auth.ErrTokenPurposeMismatch) {
+			t.Errorf("expected ErrTokenPurposeMismatch, got %v", err)
+		}
+	})
+}
+
+func TestProvider_ResubscribeUserEmail(t *testing.T) {
+	provider, mockUserRepo, _, _, ctrl := setupTest(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	userID := "user-id"
+	email := "test@example.com"
+
+	t.Run("verified email without unsubscribe", func(t *testing.T) {
+		user := database.User{ID: userID, Email: sql.NullString{String: email, Valid: true}, EmailVerified: true}
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, userID).Return(user, nil)
+		// preferences are set in nested transaction
+		expectTx(mockUserRepo)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetEmailUnsubscribe(ctx, email).Return(database.EmailUnsubscribe{}, database.ErrNotFound)
+		mockUserRepo.EXPECT().UpsertEmailPreference(ctx, gomock.Any()).Return(nil).Times(2)
+		mockUserRepo.EXPECT().
+			CreateEmailResubscribe(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, resubscribe database.EmailResubscribe) error {
+				if resubscribe.Source != model.ResubscribeSourceAccount || resubscribe.UserID.String != userID || resubscribe.UnsubscribeReason.Valid {
+					t.Errorf("unexpected resubscribe record %+v", resubscribe)
+				}
+				return nil
+			})
+
+		if err := provider.ResubscribeUserEmail(ctx, userID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("lifts unsubscribe after complaint", func(t *testing.T) {
+		user := database.User{ID: userID, Email: sql.NullString{String: email, Valid: true}, EmailVerified: true}
+		unsubscribe := database.NewEmailUnsubscribe(email, model.UnsubscribeReasonComplaint)
+		unsubscribe.DateCreated = time.Now().Add(-time.Hour)
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, userID).Return(user, nil)
+		// preferences are set in nested transaction
+		expectTx(mockUserRepo)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetEmailUnsubscribe(ctx, email).Return(unsubscribe, nil)
+		mockUserRepo.EXPECT().DeleteEmailUnsubscribe(ctx, email).Return(nil)
+		mockUserRepo.EXPECT().UpsertEmailPreference(ctx, gomock.Any()).Return(nil).Times(2)
+		mockUserRepo.EXPECT().
+			CreateEmailResubscribe(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, resubscribe database.EmailResubscribe) error {
+				if resubscribe.UnsubscribeReason.String != model.UnsubscribeReasonComplaint ||
+					!resubscribe.DateUnsubscribed.Time.Equal(unsubscribe.DateCreated) {
+					t.Errorf("expected lifted unsubscribe to be recorded, got %+v", resubscribe)
+				}
+				return nil
+			})
+
+		if err := provider.ResubscribeUserEmail(ctx, userID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("unverified email", func(t *testing.T) {
+		user := database.User{ID: userID, Email: sql.NullString{String: email, Valid: true}}
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, userID).Return(user, nil)
+
+		if err := provider.ResubscribeUserEmail(ctx, userID); !errors.Is(err, facade.ErrResubscribeNoVerifiedEmail) {
+			t.Errorf("expected ErrResubscribeNoVerifiedEmail, got %v", err)
+		}
+	})
+
+	t.Run("create resubscribe error", func(t *testing.T) {
+		user := database.User{ID: userID, Email: sql.NullString{String: email, Valid: true}, EmailVerified: true}
+
+		mockUserRepo.EXPECT().GetUserByID(ctx, userID).Return(user, nil)
+		// preferences are set in nested transaction
+		expectTx(mockUserRepo)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().GetEmailUnsubscribe(ctx, email).Return(database.EmailUnsubscribe{}, database.ErrNotFound)
+		mockUserRepo.EXPECT().UpsertEmailPreference(ctx, gomock.Any()).Return(nil).Times(2)
+		mockUserRepo.EXPECT().CreateEmailResubscribe(ctx, gomock.Any()).Return(errors.New("db error"))
+
+		if err := provider.ResubscribeUserEmail(ctx, userID); err == nil {
+			t.Error("expected error when CreateEmailResubscribe fails")
+		}
+	})
+}
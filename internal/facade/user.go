@@ -16,6 +16,7 @@ import (
 var (
 	ErrInvalidEmail                 = errors.New("invalid email")
 	ErrOAuthSignInConflict          = errors.New("oauth sign in name conflict")
+	ErrGetProfileUserNotFound       = errors.New("get profile: user not found")
 	ErrUpdateProfileUserNotFound    = errors.New("update profile: user not found")
 	ErrUpdateProfileInvalidPassword = errors.New("update profile: invalid current password")
 	ErrUpdateProfileNotAllowed      = errors.New("update profile: password change not allowed for oauth users")
@@ -27,7 +28,7 @@ var (
 )
 
 // SignUp creates a new user with provided params and sends verification email if applicable
-func (p *Provider) SignUp(ctx context.Context, username, displayName, email, password string, isPublisher bool) (model.User, error) {
+func (p *Provider) SignUp(ctx context.Context, username, displayName, email, password, locale string, isPublisher bool) (model.User, error) {
 	// check if user exists
 	_, err := p.userRepo.GetUserByUsername(ctx, username)
 	if err != nil && !errors.Is(err, database.ErrNotFound) {
@@ -63,10 +64,11 @@ func (p *Provider) SignUp(ctx context.Context, username, displayName, email, pas
 		return model.User{}, err
 	}
 
-	// create user
+	// create user. Email is optional for regular users
 	user := database.NewUser(username, displayName, passwordHash, userRole)
-	if isPublisher {
-		user.SetEmail(email, false)
+	user.SetEmail(email, false)
+	if locale != "" {
+		user.Locale = locale
 	}
 
 	txErr := p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
@@ -78,9 +80,9 @@ func (p *Provider) SignUp(ctx context.Context, username, displayName, email, pas
 			return err
 		}
 
-		// send verification email only for publishers
-		if isPublisher {
-			if err = p.sendVerificationEmail(ctx, user.ID, user.Email.String, user.Username); err != nil {
+		// send verification email if email is provided
+		if user.Email.Valid {
+			if err = p.sendVerificationEmail(ctx, user.ID, user.Email.String, user.Username, user.Locale); err != nil {
 				switch {
 				case errors.Is(err, ErrSendVerifyEmailUnsubscribed):
 					// ignore 'user unsubscribed' error as emails are unique and this situation should not happen
@@ -103,12 +105,22 @@ func (p *Provider) SignUp(ctx context.Context, username, displayName, email, pas
 	return mapDBUserToUser(user), nil
 }
 
-// SignIn authenticates user by username/email and password
-func (p *Provider) SignIn(ctx context.Context, username, password string) (model.User, error) {
+// SignIn authenticates user by username/email and password.
+// Returns TooManyRequestsError if sign in is temporarily locked for username or client ip
+func (p *Provider) SignIn(ctx context.Context, username, password, clientIP string) (model.User, error) {
+	// check if sign in is locked
+	if err := p.checkSignInLockout(ctx, username, clientIP); err != nil {
+		if AsTooManyRequestsError(err) == nil {
+			p.log.Error("check sign in lockout", zap.String("username", username), zap.Error(err))
+		}
+		return model.User{}, err
+	}
+
 	// check if user exists
 	user, err := p.userRepo.GetUserByUsername(ctx, username)
 	if err != nil {
 		if errors.Is(err, database.ErrNotFound) {
+			p.registerFailedSignIn(ctx, username, clientIP)
 			return model.User{}, ErrSignInInvalidCredentials
 		}
 		p.log.Error("get user by username", zap.String("username", username), zap.Error(err))
@@ -117,12 +129,15 @@ func (p *Provider) SignIn(ctx context.Context, username, password string) (model
 
 	// check password
 	if err = bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(password)); err != nil {
+		p.registerFailedSignIn(ctx, username, clientIP)
 		return model.User{}, ErrSignInInvalidCredentials
 	}
 
+	p.resetFailedSignIns(ctx, username)
+
 	// send verification code to email if publisher has unverified email
 	if !user.EmailVerified && user.Role == model.PublisherRoleName {
-		if err = p.sendVerificationEmail(ctx, user.ID, user.Email.String, user.Username); err != nil {
+		if err = p.sendVerificationEmail(ctx, user.ID, user.Email.String, user.Username, user.Locale); err != nil {
 			switch {
 			case errors.Is(err, ErrSendVerifyEmailUnsubscribed):
 				// ignore 'user unsubscribed' error as such error will be displayed on resend email attempt
@@ -140,8 +155,8 @@ func (p *Provider) SignIn(ctx context.Context, username, password string) (model
 	return mapDBUserToUser(user), nil
 }
 
-// GoogleOAuth handles Google OAuth sign in
-func (p *Provider) GoogleOAuth(ctx context.Context, oauthID, email string) (model.User, error) {
+// GoogleOAuth handles Google OAuth sign in. User signed up with Google gets provided locale, if it is set
+func (p *Provider) GoogleOAuth(ctx context.Context, oauthID, email, locale string) (model.User, error) {
 	// check if user exists
 	user, err := p.userRepo.GetUserByOAuth(ctx, model.GoogleAuthTokenProvider, oauthID)
 	if err != nil && !errors.Is(err, database.ErrNotFound) {
@@ -161,6 +176,9 @@ func (p *Provider) GoogleOAuth(ctx context.Context, oauthID, email string) (mode
 	user = database.NewUser(username, username, nil, model.UserRoleName)
 	user.SetOAuthID(model.GoogleAuthTokenProvider, oauthID)
 	user.SetEmail(email, true)
+	if locale != "" {
+		user.Locale = locale
+	}
 
 	if err = p.userRepo.CreateUser(ctx, user); err != nil {
 		if errors.Is(err, database.ErrUserExists) {
@@ -174,6 +192,20 @@ func (p *Provider) GoogleOAuth(ctx context.Context, oauthID, email string) (mode
 	return mapDBUserToUser(user), nil
 }
 
+// GetUserProfile returns user profile
+func (p *Provider) GetUserProfile(ctx context.Context, userID string) (model.User, error) {
+	user, err := p.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return model.User{}, ErrGetProfileUserNotFound
+		}
+		p.log.Error("get user by id", zap.String("userID", userID), zap.Error(err))
+		return model.User{}, err
+	}
+
+	return mapDBUserToUser(user), nil
+}
+
 // UpdateUserProfile updates user profile
 func (p *Provider) UpdateUserProfile(ctx context.Context, userID string, params model.UpdateProfileParams) (model.User, error) {
 	var user database.User
@@ -211,6 +243,10 @@ func (p *Provider) UpdateUserProfile(ctx context.Context, userID string, params
 		if params.Name != nil {
 			user.DisplayName = *params.Name
 		}
+		// update locale if provided
+		if params.Locale != nil {
+			user.Locale = *params.Locale
+		}
 		if params.Password != nil {
 			err = p.userRepo.DeleteRefreshTokensByUserID(ctx, userID)
 			if err != nil {
@@ -225,6 +261,10 @@ func (p *Provider) UpdateUserProfile(ctx context.Context, userID string, params
 			return err
 		}
 
+		if params.Password != nil {
+			return p.onSecurityEvent(ctx, user, model.SecurityEvent{Type: model.SecurityEventPasswordChanged})
+		}
+
 		return nil
 	})
 	if txErr != nil {
@@ -234,9 +274,23 @@ func (p *Provider) UpdateUserProfile(ctx context.Context, userID string, params
 	return mapDBUserToUser(user), nil
 }
 
-// DeleteUser deletes user by id
+// DeleteUser deletes user by id. User is notified about account deletion
 func (p *Provider) DeleteUser(ctx context.Context, userID string) error {
-	return p.userRepo.DeleteUser(ctx, userID)
+	return p.userRepo.RunWithTx(ctx, func(ctx context.Context) error {
+		user, err := p.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		if err = p.onSecurityEvent(ctx, user, model.SecurityEvent{Type: model.SecurityEventAccountDeletionRequested}); err != nil {
+			return err
+		}
+
+		return p.userRepo.DeleteUser(ctx, userID)
+	})
 }
 
 // extracts and sanitizes username from email for OAuth users
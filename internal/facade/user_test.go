@@ -6,6 +6,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
 	"github.com/OutOfStack/game-library-auth/internal/database"
 	"github.com/OutOfStack/game-library-auth/internal/facade"
 	"github.com/OutOfStack/game-library-auth/internal/model"
@@ -31,7 +32,7 @@ func TestProvider_GoogleOAuth(t *testing.T) {
 			GetUserByOAuth(ctx, model.GoogleAuthTokenProvider, "oauth-123").
 			Return(expectedUser, nil)
 
-		result, err := provider.GoogleOAuth(ctx, "oauth-123", "test@example.com")
+		result, err := provider.GoogleOAuth(ctx, "oauth-123", "test@example.com", "")
 
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
@@ -51,9 +52,14 @@ func TestProvider_GoogleOAuth(t *testing.T) {
 
 		mockUserRepo.EXPECT().
 			CreateUser(ctx, gomock.Any()).
-			Return(nil)
+			DoAndReturn(func(_ context.Context, user database.User) error {
+				if user.Locale != "es-MX" {
+					t.Errorf("expected locale es-MX, got %s", user.Locale)
+				}
+				return nil
+			})
 
-		result, err := provider.GoogleOAuth(ctx, "oauth-123", "newuser@example.com")
+		result, err := provider.GoogleOAuth(ctx, "oauth-123", "newuser@example.com", "es-MX")
 
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
@@ -71,7 +77,7 @@ func TestProvider_GoogleOAuth(t *testing.T) {
 			GetUserByOAuth(ctx, model.GoogleAuthTokenProvider, "oauth-123").
 			Return(database.User{}, database.ErrNotFound)
 
-		_, err := provider.GoogleOAuth(ctx, "oauth-123", "invalid-email")
+		_, err := provider.GoogleOAuth(ctx, "oauth-123", "invalid-email", "")
 
 		if !errors.Is(err, facade.ErrInvalidEmail) {
 			t.Errorf("expected ErrInvalidEmail, got %v", err)
@@ -90,7 +96,7 @@ func TestProvider_GoogleOAuth(t *testing.T) {
 			CreateUser(ctx, gomock.Any()).
 			Return(database.ErrUserExists)
 
-		_, err := provider.GoogleOAuth(ctx, "oauth-123", "existing@example.com")
+		_, err := provider.GoogleOAuth(ctx, "oauth-123", "existing@example.com", "")
 
 		if !errors.Is(err, facade.ErrOAuthSignInConflict) {
 			t.Errorf("expected ErrOAuthSignInConflict, got %v", err)
@@ -98,6 +104,48 @@ func TestProvider_GoogleOAuth(t *testing.T) {
 	})
 }
 
+func TestProvider_GetUserProfile(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("regular user with unverified email", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			GetUserByID(ctx, "user-123").
+			Return(database.User{
+				ID:       "user-123",
+				Username: "testuser",
+				Email:    sql.NullString{String: "test@example.com", Valid: true},
+				Role:     model.UserRoleName,
+			}, nil)
+
+		user, err := provider.GetUserProfile(ctx, "user-123")
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if user.Email != "test@example.com" || user.EmailVerified {
+			t.Errorf("expected unverified email test@example.com, got %q verified=%v", user.Email, user.EmailVerified)
+		}
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().
+			GetUserByID(ctx, "nonexistent").
+			Return(database.User{}, database.ErrNotFound)
+
+		_, err := provider.GetUserProfile(ctx, "nonexistent")
+
+		if !errors.Is(err, facade.ErrGetProfileUserNotFound) {
+			t.Errorf("expected ErrGetProfileUserNotFound, got %v", err)
+		}
+	})
+}
+
 func TestProvider_UpdateUserProfile(t *testing.T) {
 	ctx := context.Background()
 
@@ -155,6 +203,7 @@ func TestProvider_UpdateUserProfile(t *testing.T) {
 			PasswordHash: oldPasswordHash,
 			Role:         model.UserRoleName,
 		}
+		existingUser.SetEmail("test@example.com", true)
 
 		newPassword := "newpass"
 		params := model.UpdateProfileParams{
@@ -180,6 +229,8 @@ func TestProvider_UpdateUserProfile(t *testing.T) {
 			UpdateUser(ctx, gomock.Any()).
 			Return(nil)
 
+		expectSecurityNotice(t, mockUserRepo, "test@example.com", model.SecurityEventPasswordChanged)
+
 		_, err := provider.UpdateUserProfile(ctx, "user-123", params)
 
 		if err != nil {
@@ -289,6 +340,14 @@ func TestProvider_DeleteUser(t *testing.T) {
 		provider, mockUserRepo, _, _, ctrl := setupTest(t)
 		defer ctrl.Finish()
 
+		user := database.User{ID: "user-123", Username: "testuser"}
+		user.SetEmail("test@example.com", true)
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserByID(ctx, "user-123").
+			Return(user, nil)
+		expectSecurityNotice(t, mockUserRepo, "test@example.com", model.SecurityEventAccountDeletionRequested)
 		mockUserRepo.EXPECT().
 			DeleteUser(ctx, "user-123").
 			Return(nil)
@@ -300,19 +359,39 @@ func TestProvider_DeleteUser(t *testing.T) {
 		}
 	})
 
+	t.Run("user not found", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserByID(ctx, "nonexistent").
+			Return(database.User{}, database.ErrNotFound)
+
+		err := provider.DeleteUser(ctx, "nonexistent")
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
 	t.Run("deletion failure", func(t *testing.T) {
 		provider, mockUserRepo, _, _, ctrl := setupTest(t)
 		defer ctrl.Finish()
 
-		expectedErr := database.ErrNotFound
+		expectedErr := errors.New("db error")
 
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			GetUserByID(ctx, "user-123").
+			Return(database.User{ID: "user-123"}, nil)
 		mockUserRepo.EXPECT().
-			DeleteUser(ctx, "nonexistent").
+			DeleteUser(ctx, "user-123").
 			Return(expectedErr)
 
-		err := provider.DeleteUser(ctx, "nonexistent")
+		err := provider.DeleteUser(ctx, "user-123")
 
-		if err != expectedErr {
+		if !errors.Is(err, expectedErr) {
 			t.Fatalf("expected %v, got %v", expectedErr, err)
 		}
 	})
@@ -335,11 +414,17 @@ func TestProvider_SignIn(t *testing.T) {
 			Role:         model.UserRoleName,
 		}
 
+		expectNoSignInLockout(mockUserRepo, "testuser", "127.0.0.1")
+
 		mockUserRepo.EXPECT().
 			GetUserByUsername(ctx, "testuser").
 			Return(existingUser, nil)
 
-		result, err := provider.SignIn(ctx, "testuser", password)
+		mockUserRepo.EXPECT().
+			DeleteLoginAttempt(ctx, model.LoginAttemptKindUsername, "testuser").
+			Return(nil)
+
+		result, err := provider.SignIn(ctx, "testuser", password, "127.0.0.1")
 
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
@@ -353,11 +438,15 @@ func TestProvider_SignIn(t *testing.T) {
 		provider, mockUserRepo, _, _, ctrl := setupTest(t)
 		defer ctrl.Finish()
 
+		expectNoSignInLockout(mockUserRepo, "nonexistent", "127.0.0.1")
+
 		mockUserRepo.EXPECT().
 			GetUserByUsername(ctx, "nonexistent").
 			Return(database.User{}, database.ErrNotFound)
 
-		_, err := provider.SignIn(ctx, "nonexistent", "password")
+		expectFailedSignIn(mockUserRepo, "nonexistent", "127.0.0.1", 1)
+
+		_, err := provider.SignIn(ctx, "nonexistent", "password", "127.0.0.1")
 
 		if !errors.Is(err, facade.ErrSignInInvalidCredentials) {
 			t.Errorf("expected ErrSignInInvalidCredentials, got %v", err)
@@ -378,11 +467,15 @@ func TestProvider_SignIn(t *testing.T) {
 			Role:         model.UserRoleName,
 		}
 
+		expectNoSignInLockout(mockUserRepo, "testuser", "127.0.0.1")
+
 		mockUserRepo.EXPECT().
 			GetUserByUsername(ctx, "testuser").
 			Return(existingUser, nil)
 
-		_, err := provider.SignIn(ctx, "testuser", "wrongpass")
+		expectFailedSignIn(mockUserRepo, "testuser", "127.0.0.1", 1)
+
+		_, err := provider.SignIn(ctx, "testuser", "wrongpass", "127.0.0.1")
 
 		if !errors.Is(err, facade.ErrSignInInvalidCredentials) {
 			t.Errorf("expected ErrSignInInvalidCredentials, got %v", err)
@@ -411,9 +504,9 @@ func TestProvider_SignUp(t *testing.T) {
 			CreateUser(ctx, gomock.Any()).
 			Return(nil)
 
-		// regular users do not provide email
+		// email is optional for regular users
 
-		result, err := provider.SignUp(ctx, "newuser", "New User", "", "password", false)
+		result, err := provider.SignUp(ctx, "newuser", "New User", "", "password", "", false)
 
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
@@ -429,8 +522,54 @@ func TestProvider_SignUp(t *testing.T) {
 		}
 	})
 
+	t.Run("regular user signup with email", func(t *testing.T) {
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
+		defer ctrl.Finish()
+
+		mockUserRepo.EXPECT().GetUserByUsername(ctx, "newuser").Return(database.User{}, database.ErrNotFound)
+		expectTx(mockUserRepo)
+		expectTx(mockUserRepo)
+		mockUserRepo.EXPECT().
+			CreateUser(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, user database.User) error {
+				if user.Locale != "es-MX" {
+					t.Errorf("expected locale es-MX, got %q", user.Locale)
+				}
+				return nil
+			})
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(ctx, "user@example.com").Return(false, nil)
+		mockUserRepo.EXPECT().GetEmailVerificationByUserID(ctx, gomock.Any()).Return(database.EmailVerification{}, database.ErrNotFound)
+		mockUserRepo.EXPECT().CreateEmailVerification(ctx, gomock.Any()).Return(nil)
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), gomock.Any()).Return(false, nil)
+		mockUserRepo.EXPECT().
+			CreateEmailOutboxMessage(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg database.EmailOutboxMessage) error {
+				if msg.Kind != model.EmailKindVerification || msg.Recipient != "user@example.com" {
+					t.Errorf("unexpected queued email %q to %q", msg.Kind, msg.Recipient)
+				}
+				var req mailer.SendEmailVerificationRequest
+				decodeOutboxPayload(t, msg, &req)
+				if req.Locale != "es-MX" {
+					t.Errorf("expected email locale es-MX, got %q", req.Locale)
+				}
+				return nil
+			})
+
+		result, err := provider.SignUp(ctx, "newuser", "New User", "user@example.com", "password", "es-MX", false)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result.Role != string(model.UserRoleName) {
+			t.Errorf("expected role %s, got %s", model.UserRoleName, result.Role)
+		}
+		if result.Email != "user@example.com" || result.EmailVerified {
+			t.Errorf("expected unverified email user@example.com, got %q (verified %t)", result.Email, result.EmailVerified)
+		}
+	})
+
 	t.Run("successful publisher signup", func(t *testing.T) {
-		provider, mockUserRepo, mockEmailSender, _, ctrl := setupTest(t)
+		provider, mockUserRepo, _, _, ctrl := setupTest(t)
 		defer ctrl.Finish()
 
 		mockUserRepo.EXPECT().
@@ -467,17 +606,13 @@ func TestProvider_SignUp(t *testing.T) {
 			Return(nil).
 			AnyTimes()
 
-		mockEmailSender.EXPECT().
-			SendEmailVerification(ctx, gomock.Any()).
-			Return("message-id-123", nil).
-			AnyTimes()
-
+		mockUserRepo.EXPECT().IsEmailUnsubscribed(gomock.Any(), gomock.Any()).Return(false, nil)
 		mockUserRepo.EXPECT().
-			SetEmailVerificationMessageID(ctx, gomock.Any(), "message-id-123").
+			CreateEmailOutboxMessage(ctx, gomock.Any()).
 			Return(nil).
 			AnyTimes()
 
-		result, err := provider.SignUp(ctx, "newpublisher", "Publisher Name", "pub@example.com", "password", true)
+		result, err := provider.SignUp(ctx, "newpublisher", "Publisher Name", "pub@example.com", "password", "", true)
 
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
@@ -500,7 +635,7 @@ func TestProvider_SignUp(t *testing.T) {
 			GetUserByUsername(ctx, "existinguser").
 			Return(existingUser, nil)
 
-		_, err := provider.SignUp(ctx, "existinguser", "Display Name", "email@example.com", "password", false)
+		_, err := provider.SignUp(ctx, "existinguser", "Display Name", "email@example.com", "password", "", false)
 
 		if !errors.Is(err, facade.ErrSignUpUsernameExists) {
 			t.Errorf("expected ErrSignUpUsernameExists, got %v", err)
@@ -519,7 +654,7 @@ func TestProvider_SignUp(t *testing.T) {
 			CheckUserExists(ctx, "Existing Publisher", model.PublisherRoleName).
 			Return(true, nil)
 
-		_, err := provider.SignUp(ctx, "newpublisher", "Existing Publisher", "pub@example.com", "password", true)
+		_, err := provider.SignUp(ctx, "newpublisher", "Existing Publisher", "pub@example.com", "password", "", true)
 
 		if !errors.Is(err, facade.ErrSignUpPublisherNameExists) {
 			t.Errorf("expected ErrSignUpPublisherNameExists, got %v", err)
@@ -544,7 +679,7 @@ func TestProvider_SignUp(t *testing.T) {
 			CreateUser(ctx, gomock.Any()).
 			Return(nil)
 
-		result, err := provider.SignUp(ctx, "newuser", "New User", "", "password", false)
+		result, err := provider.SignUp(ctx, "newuser", "New User", "", "password", "", false)
 
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
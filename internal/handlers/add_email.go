@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// AddEmailHandler godoc
+// @Summary      Add email address
+// @Description  Adds email address to user who doesn't have one and sends verification code to it
+// @Tags         auth
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        request body AddEmailReq true "Email address"
+// @Success      204 "Email is added and verification code is sent"
+// @Failure      400 {object} web.ErrResp "Invalid request, user already has an email or email is unsubscribed"
+// @Failure      401 {object} web.ErrResp "Invalid or missing authorization token"
+// @Failure      409 {object} web.ErrResp "Email is already taken"
+// @Failure      429 {object} web.ErrResp "Too many requests"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /account/email [post]
+func (a *AuthAPI) AddEmailHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "addEmail")
+	defer span.End()
+
+	claims, err := a.getClaims(c)
+	if err != nil {
+		a.log.Error("get claims", zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: invalidAuthTokenMsg,
+		})
+	}
+
+	var req AddEmailReq
+	if err = c.BodyParser(&req); err != nil {
+		a.log.Error("parsing data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: "Cannot parse request",
+		})
+	}
+
+	if fields, vErr := web.Validate(req); vErr != nil {
+		a.log.Info("validating add email data", zap.Error(vErr))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error:  validationErrorMsg,
+			Fields: fields,
+		})
+	}
+
+	if err = a.userFacade.AddEmail(ctx, claims.UserID, req.Email); err != nil {
+		var tooManyRequestErr *facade.TooManyRequestsError
+		switch {
+		case errors.Is(err, facade.ErrAddEmailAlreadySet):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: "User already has an email address",
+			})
+		case errors.Is(err, facade.ErrAddEmailTaken):
+			return c.Status(http.StatusConflict).JSON(web.ErrResp{
+				Error: "This email is already taken",
+			})
+		case errors.Is(err, facade.ErrSendVerifyEmailUnsubscribed):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: fmt.Sprintf("Email is unsubscribed. You may contact us at mailto:%s to resubscribe", a.cfg.ContactEmail),
+			})
+		case errors.As(err, &tooManyRequestErr):
+			setRetryAfterHeader(c, tooManyRequestErr.RetryAfter)
+			return c.Status(http.StatusTooManyRequests).JSON(web.ErrResp{
+				Error: "Please wait before requesting another code",
+			})
+		default:
+			a.log.Error("add email", zap.Error(err))
+			return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+				Error: internalErrorMsg,
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
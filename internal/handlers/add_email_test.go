@@ -0,0 +1,146 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/handlers"
+	mocks "github.com/OutOfStack/game-library-auth/internal/handlers/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAddEmailHandler(t *testing.T) {
+	userID := uuid.New().String()
+	email := "test@example.com"
+
+	tests := []struct {
+		name           string
+		request        interface{}
+		authHeader     string
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   *web.ErrResp
+	}{
+		{
+			name:       "successful add",
+			request:    handlers.AddEmailReq{Email: email},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().AddEmail(gomock.Any(), userID, email).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "missing auth header",
+			request:        handlers.AddEmailReq{Email: email},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp:   &web.ErrResp{Error: invalidAuthToken},
+		},
+		{
+			name:           "invalid email",
+			request:        handlers.AddEmailReq{Email: "not-an-email"},
+			authHeader:     "Bearer valid-token",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   &web.ErrResp{Error: "Validation error"},
+		},
+		{
+			name:       "email already set",
+			request:    handlers.AddEmailReq{Email: email},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().AddEmail(gomock.Any(), userID, email).Return(facade.ErrAddEmailAlreadySet)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   &web.ErrResp{Error: "User already has an email address"},
+		},
+		{
+			name:       "email taken",
+			request:    handlers.AddEmailReq{Email: email},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().AddEmail(gomock.Any(), userID, email).Return(facade.ErrAddEmailTaken)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedResp:   &web.ErrResp{Error: "This email is already taken"},
+		},
+		{
+			name:       "email unsubscribed",
+			request:    handlers.AddEmailReq{Email: email},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().AddEmail(gomock.Any(), userID, email).Return(facade.ErrSendVerifyEmailUnsubscribed)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "code sent recently",
+			request:    handlers.AddEmailReq{Email: email},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().AddEmail(gomock.Any(), userID, email).Return(facade.NewTooManyRequestsError(30 * time.Second))
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedResp:   &web.ErrResp{Error: "Please wait before requesting another code"},
+		},
+		{
+			name:       "facade error",
+			request:    handlers.AddEmailReq{Email: email},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().AddEmail(gomock.Any(), userID, email).Return(errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   &web.ErrResp{Error: internalErrorMsg},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.authHeader == "Bearer valid-token" {
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(auth.Claims{UserID: userID}, nil).
+					AnyTimes()
+			}
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/account/email", authAPI.AddEmailHandler)
+
+			reqBody, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/account/email", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			resp, err := app.Test(req, 5000)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedResp != nil {
+				var actual web.ErrResp
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+				assert.Equal(t, tt.expectedResp.Error, actual.Error)
+			}
+		})
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/OutOfStack/game-library-auth/internal/auth"
 	"github.com/OutOfStack/game-library-auth/internal/facade"
 	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/go-webauthn/webauthn/protocol"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 	"google.golang.org/api/idtoken"
@@ -20,17 +22,42 @@ type GoogleTokenValidator interface {
 
 // UserFacade provides methods for working with user facade
 type UserFacade interface {
-	GoogleOAuth(ctx context.Context, oauthID, email string) (model.User, error)
+	GoogleOAuth(ctx context.Context, oauthID, email, locale string) (model.User, error)
 	DeleteUser(ctx context.Context, userID string) error
+	GetUserProfile(ctx context.Context, userID string) (model.User, error)
 	UpdateUserProfile(ctx context.Context, userID string, params model.UpdateProfileParams) (model.User, error)
 	VerifyEmail(ctx context.Context, userID string, code string) (model.User, error)
+	CheckEmailVerificationLink(ctx context.Context, token string) (string, error)
+	VerifyEmailWithLink(ctx context.Context, token string) (string, error)
 	ResendVerificationEmail(ctx context.Context, userID string) error
-	SignIn(ctx context.Context, username, password string) (model.User, error)
-	SignUp(ctx context.Context, username, displayName, email, password string, isPublisher bool) (model.User, error)
+	EmailDeliveryDelay() (time.Duration, bool)
+	AddEmail(ctx context.Context, userID, email string) error
+	GetEmailDeliveryStatus(ctx context.Context, userID string) (model.EmailDeliveryStatus, error)
+	ResubscribeUserEmail(ctx context.Context, userID string) error
+	RequestEmailChange(ctx context.Context, userID, newEmail, password, code string) error
+	ConfirmEmailChange(ctx context.Context, userID, code string) (model.User, error)
+	SignIn(ctx context.Context, username, password, clientIP string) (model.User, error)
+	SignUp(ctx context.Context, username, displayName, email, password, locale string, isPublisher bool) (model.User, error)
 	CreateTokens(ctx context.Context, user model.User) (facade.TokenPair, error)
+	CreateSignInTokens(ctx context.Context, user model.User, client model.ClientInfo) (facade.TokenPair, error)
 	RefreshTokens(ctx context.Context, refreshTokenStr string) (facade.TokenPair, error)
 	RevokeRefreshToken(ctx context.Context, refreshTokenStr string) error
 	ValidateAccessToken(tokenStr string) (auth.Claims, error)
+	EnrollTOTP(ctx context.Context, userID string) (model.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID, code string) ([]string, error)
+	RegenerateRecoveryCodes(ctx context.Context, userID, password string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID, password, code string) error
+	StartTwoFactorChallenge(ctx context.Context, userID string) (challengeToken string, required bool, err error)
+	CompleteTwoFactorSignIn(ctx context.Context, challengeToken, code, clientIP string) (model.User, error)
+	RequestEmailSignIn(ctx context.Context, email string) error
+	CompleteEmailSignIn(ctx context.Context, email, code string) (model.User, error)
+	CompleteEmailSignInWithToken(ctx context.Context, token string) (model.User, error)
+	BeginPasskeyRegistration(ctx context.Context, userID string) (*protocol.CredentialCreation, string, error)
+	FinishPasskeyRegistration(ctx context.Context, userID, sessionID, name string, response *protocol.ParsedCredentialCreationData) (model.Passkey, error)
+	BeginPasskeySignIn(ctx context.Context) (*protocol.CredentialAssertion, string, error)
+	FinishPasskeySignIn(ctx context.Context, sessionID string, response *protocol.ParsedCredentialAssertionData) (model.User, error)
+	ListPasskeys(ctx context.Context, userID string) ([]model.Passkey, error)
+	DeletePasskey(ctx context.Context, userID, passkeyID string) error
 }
 
 // AuthAPICfg describes configuration for auth api
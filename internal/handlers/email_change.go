@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// ChangeEmailHandler godoc
+// @Summary      Request email address change
+// @Description  Sends code confirming the change to the new email address. Email is changed only after the code is confirmed.
+// @Description  Requires current password, and two-factor code if two-factor authentication is enabled. Not available for OAuth users
+// @Tags         auth
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        request body ChangeEmailReq true "New email address"
+// @Success      204 "Code is sent to the new email address"
+// @Failure      400 {object} web.ErrResp "Invalid request, invalid two-factor code or email is the same as current"
+// @Failure      401 {object} web.ErrResp "Invalid or missing authorization token or invalid password"
+// @Failure      403 {object} web.ErrResp "Email change is not available for OAuth users"
+// @Failure      409 {object} web.ErrResp "Email is already taken"
+// @Failure      429 {object} web.ErrResp "Too many requests"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /account/email/change [post]
+func (a *AuthAPI) ChangeEmailHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "changeEmail")
+	defer span.End()
+
+	claims, err := a.getClaims(c)
+	if err != nil {
+		a.log.Error("get claims", zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: invalidAuthTokenMsg,
+		})
+	}
+
+	var req ChangeEmailReq
+	if err = c.BodyParser(&req); err != nil {
+		a.log.Error("parsing data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: "Cannot parse request",
+		})
+	}
+
+	if fields, vErr := web.Validate(req); vErr != nil {
+		a.log.Info("validating change email data", zap.Error(vErr))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error:  validationErrorMsg,
+			Fields: fields,
+		})
+	}
+
+	if err = a.userFacade.RequestEmailChange(ctx, claims.UserID, req.Email, req.Password, req.Code); err != nil {
+		var tooManyRequestErr *facade.TooManyRequestsError
+		switch {
+		case errors.Is(err, facade.ErrChangeEmailNotAllowed):
+			return c.Status(http.StatusForbidden).JSON(web.ErrResp{
+				Error: "Email change is not available for OAuth users",
+			})
+		case errors.Is(err, facade.ErrChangeEmailInvalidPassword):
+			return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+				Error: "Invalid password",
+			})
+		case errors.Is(err, facade.ErrChangeEmailCodeRequired):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: "Two-factor authentication code is required",
+			})
+		case errors.Is(err, facade.ErrTwoFactorInvalidCode):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: invalidTwoFactorCodeMsg,
+			})
+		case errors.Is(err, facade.ErrChangeEmailSameAddress):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: "New email is the same as current",
+			})
+		case errors.Is(err, facade.ErrChangeEmailTaken):
+			return c.Status(http.StatusConflict).JSON(web.ErrResp{
+				Error: "This email is already taken",
+			})
+		case errors.As(err, &tooManyRequestErr):
+			setRetryAfterHeader(c, tooManyRequestErr.RetryAfter)
+			return c.Status(http.StatusTooManyRequests).JSON(web.ErrResp{
+				Error: "Please wait before requesting another code",
+			})
+		default:
+			a.log.Error("request email change", zap.Error(err))
+			return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+				Error: internalErrorMsg,
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// ConfirmEmailChangeHandler godoc
+// @Summary      Confirm email address change
+// @Description  Changes user email to the new address using the code sent to it. New email is verified and security notice is sent to the previous address
+// @Tags         auth
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        request body ConfirmEmailChangeReq true "Email change code"
+// @Success      200 {object} TokenResp
+// @Failure      400 {object} web.ErrResp "Invalid or expired code or too many failed attempts"
+// @Failure      401 {object} web.ErrResp "Invalid or missing authorization token"
+// @Failure      409 {object} web.ErrResp "Email is already taken"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /account/email/change/confirm [post]
+func (a *AuthAPI) ConfirmEmailChangeHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "confirmEmailChange")
+	defer span.End()
+
+	claims, err := a.getClaims(c)
+	if err != nil {
+		a.log.Error("get claims", zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: invalidAuthTokenMsg,
+		})
+	}
+
+	var req ConfirmEmailChangeReq
+	if err = c.BodyParser(&req); err != nil {
+		a.log.Error("parsing data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: "Cannot parse request",
+		})
+	}
+
+	if fields, vErr := web.Validate(req); vErr != nil {
+		a.log.Info("validating confirm email change data", zap.Error(vErr))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error:  validationErrorMsg,
+			Fields: fields,
+		})
+	}
+
+	user, err := a.userFacade.ConfirmEmailChange(ctx, claims.UserID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, facade.ErrChangeEmailInvalidOrExpired):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: invalidOrExpiredVrfCodeMsg,
+			})
+		case errors.Is(err, facade.ErrChangeEmailTooManyAttempts):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: "Too many failed attempts. Please request a new code",
+			})
+		case errors.Is(err, facade.ErrChangeEmailTaken):
+			return c.Status(http.StatusConflict).JSON(web.ErrResp{
+				Error: "This email is already taken",
+			})
+		default:
+			a.log.Error("confirm email change", zap.Error(err))
+			return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+				Error: internalErrorMsg,
+			})
+		}
+	}
+
+	// create tokens with updated email verification status
+	tokens, err := a.userFacade.CreateTokens(ctx, user)
+	if err != nil {
+		a.log.Error("creating tokens", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+
+	// set refresh token as a cookie
+	a.setRefreshTokenCookie(c, tokens.RefreshToken)
+
+	return c.JSON(TokenResp{
+		AccessToken: tokens.AccessToken,
+	})
+}
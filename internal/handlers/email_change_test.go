@@ -0,0 +1,322 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/handlers"
+	mocks "github.com/OutOfStack/game-library-auth/internal/handlers/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestChangeEmailHandler(t *testing.T) {
+	userID := uuid.New().String()
+	newEmail := "new@example.com"
+	password := "password123"
+
+	tests := []struct {
+		name           string
+		request        interface{}
+		authHeader     string
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   *web.ErrResp
+	}{
+		{
+			name:       "successful request",
+			request:    handlers.ChangeEmailReq{Email: newEmail, Password: password},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().RequestEmailChange(gomock.Any(), userID, newEmail, password, "").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "missing auth header",
+			request:        handlers.ChangeEmailReq{Email: newEmail, Password: password},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp:   &web.ErrResp{Error: invalidAuthToken},
+		},
+		{
+			name:           "invalid email",
+			request:        handlers.ChangeEmailReq{Email: "not-an-email", Password: password},
+			authHeader:     "Bearer valid-token",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   &web.ErrResp{Error: "Validation error"},
+		},
+		{
+			name:           "missing password",
+			request:        handlers.ChangeEmailReq{Email: newEmail},
+			authHeader:     "Bearer valid-token",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   &web.ErrResp{Error: "Validation error"},
+		},
+		{
+			name:       "successful request with two-factor code",
+			request:    handlers.ChangeEmailReq{Email: newEmail, Password: password, Code: "123456"},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().RequestEmailChange(gomock.Any(), userID, newEmail, password, "123456").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:       "invalid password",
+			request:    handlers.ChangeEmailReq{Email: newEmail, Password: password},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().RequestEmailChange(gomock.Any(), userID, newEmail, password, "").Return(facade.ErrChangeEmailInvalidPassword)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp:   &web.ErrResp{Error: "Invalid password"},
+		},
+		{
+			name:       "two-factor code required",
+			request:    handlers.ChangeEmailReq{Email: newEmail, Password: password},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().RequestEmailChange(gomock.Any(), userID, newEmail, password, "").Return(facade.ErrChangeEmailCodeRequired)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   &web.ErrResp{Error: "Two-factor authentication code is required"},
+		},
+		{
+			name:       "invalid two-factor code",
+			request:    handlers.ChangeEmailReq{Email: newEmail, Password: password, Code: "000000"},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().RequestEmailChange(gomock.Any(), userID, newEmail, password, "000000").Return(facade.ErrTwoFactorInvalidCode)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   &web.ErrResp{Error: "Invalid two-factor authentication code"},
+		},
+		{
+			name:       "oauth user",
+			request:    handlers.ChangeEmailReq{Email: newEmail, Password: password},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().RequestEmailChange(gomock.Any(), userID, newEmail, password, "").Return(facade.ErrChangeEmailNotAllowed)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   &web.ErrResp{Error: "Email change is not available for OAuth users"},
+		},
+		{
+			name:       "same email",
+			request:    handlers.ChangeEmailReq{Email: newEmail, Password: password},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().RequestEmailChange(gomock.Any(), userID, newEmail, password, "").Return(facade.ErrChangeEmailSameAddress)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   &web.ErrResp{Error: "New email is the same as current"},
+		},
+		{
+			name:       "email taken",
+			request:    handlers.ChangeEmailReq{Email: newEmail, Password: password},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().RequestEmailChange(gomock.Any(), userID, newEmail, password, "").Return(facade.ErrChangeEmailTaken)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedResp:   &web.ErrResp{Error: "This email is already taken"},
+		},
+		{
+			name:       "code sent recently",
+			request:    handlers.ChangeEmailReq{Email: newEmail, Password: password},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().RequestEmailChange(gomock.Any(), userID, newEmail, password, "").Return(facade.NewTooManyRequestsError(30 * time.Second))
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedResp:   &web.ErrResp{Error: "Please wait before requesting another code"},
+		},
+		{
+			name:       "facade error",
+			request:    handlers.ChangeEmailReq{Email: newEmail, Password: password},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().RequestEmailChange(gomock.Any(), userID, newEmail, password, "").Return(errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   &web.ErrResp{Error: internalErrorMsg},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.authHeader == "Bearer valid-token" {
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(auth.Claims{UserID: userID}, nil).
+					AnyTimes()
+			}
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/account/email/change", authAPI.ChangeEmailHandler)
+
+			reqBody, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/account/email/change", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			resp, err := app.Test(req, 5000)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedResp != nil {
+				var actual web.ErrResp
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+				assert.Equal(t, tt.expectedResp.Error, actual.Error)
+			}
+		})
+	}
+}
+
+func TestConfirmEmailChangeHandler(t *testing.T) {
+	userID := uuid.New().String()
+	code := "123456"
+
+	tests := []struct {
+		name           string
+		request        interface{}
+		authHeader     string
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:       "successful change",
+			request:    handlers.ConfirmEmailChangeReq{Code: code},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				u := model.User{ID: userID, Username: "testuser", Email: "new@example.com", EmailVerified: true}
+				mockUserFacade.EXPECT().ConfirmEmailChange(gomock.Any(), userID, code).Return(u, nil)
+				mockUserFacade.EXPECT().CreateTokens(gomock.Any(), u).Return(facade.TokenPair{
+					AccessToken:  "new.jwt.token",
+					RefreshToken: facade.RefreshToken{Token: "new.refresh.token"},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   handlers.TokenResp{AccessToken: "new.jwt.token"},
+		},
+		{
+			name:           "missing auth header",
+			request:        handlers.ConfirmEmailChangeReq{Code: code},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp:   web.ErrResp{Error: invalidAuthToken},
+		},
+		{
+			name:           "invalid code format",
+			request:        handlers.ConfirmEmailChangeReq{Code: "abc"},
+			authHeader:     "Bearer valid-token",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   web.ErrResp{Error: "Validation error"},
+		},
+		{
+			name:       "invalid or expired code",
+			request:    handlers.ConfirmEmailChangeReq{Code: code},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().ConfirmEmailChange(gomock.Any(), userID, code).Return(model.User{}, facade.ErrChangeEmailInvalidOrExpired)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   web.ErrResp{Error: "Invalid or expired verification code"},
+		},
+		{
+			name:       "too many failed attempts",
+			request:    handlers.ConfirmEmailChangeReq{Code: code},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().ConfirmEmailChange(gomock.Any(), userID, code).Return(model.User{}, facade.ErrChangeEmailTooManyAttempts)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   web.ErrResp{Error: "Too many failed attempts. Please request a new code"},
+		},
+		{
+			name:       "email taken",
+			request:    handlers.ConfirmEmailChangeReq{Code: code},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().ConfirmEmailChange(gomock.Any(), userID, code).Return(model.User{}, facade.ErrChangeEmailTaken)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedResp:   web.ErrResp{Error: "This email is already taken"},
+		},
+		{
+			name:       "facade error",
+			request:    handlers.ConfirmEmailChangeReq{Code: code},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().ConfirmEmailChange(gomock.Any(), userID, code).Return(model.User{}, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   web.ErrResp{Error: internalErrorMsg},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.authHeader == "Bearer valid-token" {
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(auth.Claims{UserID: userID}, nil).
+					AnyTimes()
+			}
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/account/email/change/confirm", authAPI.ConfirmEmailChangeHandler)
+
+			reqBody, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/account/email/change/confirm", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			resp, err := app.Test(req, 5000)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			switch expected := tt.expectedResp.(type) {
+			case web.ErrResp:
+				var actual web.ErrResp
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+				assert.Equal(t, expected.Error, actual.Error)
+			case handlers.TokenResp:
+				var actual handlers.TokenResp
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+				assert.Equal(t, expected.AccessToken, actual.AccessToken)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// supportTokenHeader - header of support token. Authorization header is taken by basic auth of debug ingress
+const supportTokenHeader = "X-Support-Token"
+
+// EmailHistoryAPI provides history of emails sent to users for support.
+// Requests are authorized by support token in X-Support-Token header
+type EmailHistoryAPI struct {
+	log          *zap.Logger
+	emailHistory EmailHistoryFacade
+	supportToken string
+}
+
+// EmailHistoryFacade provides methods for getting history of sent emails
+type EmailHistoryFacade interface {
+	GetUserEmailMessages(ctx context.Context, userID string) ([]model.EmailMessage, error)
+}
+
+// NewEmailHistoryAPI creates a new email history API instance
+func NewEmailHistoryAPI(log *zap.Logger, emailHistory EmailHistoryFacade, supportToken string) *EmailHistoryAPI {
+	return &EmailHistoryAPI{
+		log:          log,
+		emailHistory: emailHistory,
+		supportToken: supportToken,
+	}
+}
+
+// UserEmailHistoryHandler returns the latest send attempts of emails sent to user, newest first.
+// Route is served by debug app only and requires support token
+func (a *EmailHistoryAPI) UserEmailHistoryHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "userEmailHistory")
+	defer span.End()
+
+	if !a.isAuthorized(c) {
+		a.log.Warn("unauthorized email history request", zap.String("ip", c.IP()))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: "Invalid support token",
+		})
+	}
+
+	userID := c.Params("id")
+	if _, err := uuid.Parse(userID); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: "Invalid user ID",
+		})
+	}
+
+	messages, err := a.emailHistory.GetUserEmailMessages(ctx, userID)
+	if err != nil {
+		a.log.Error("get user email messages", zap.String("userId", userID), zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+
+	resp := EmailHistoryResp{
+		Messages: make([]EmailMessageResp, 0, len(messages)),
+	}
+	for _, msg := range messages {
+		resp.Messages = append(resp.Messages, EmailMessageResp{
+			ID:                msg.ID,
+			Template:          msg.Template,
+			Recipient:         msg.Recipient,
+			Provider:          msg.Provider,
+			ProviderMessageID: msg.ProviderMessageID,
+			Status:            msg.Status,
+			Error:             msg.Error,
+			DateCreated:       msg.DateCreated,
+			DateUpdated:       msg.DateUpdated,
+		})
+	}
+
+	return c.JSON(resp)
+}
+
+// isAuthorized reports whether request has support token. No request is authorized if token is not set
+func (a *EmailHistoryAPI) isAuthorized(c *fiber.Ctx) bool {
+	token := c.Get(supportTokenHeader)
+	if token == "" || a.supportToken == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(a.supportToken)) == 1
+}
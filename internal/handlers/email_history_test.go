@@ -0,0 +1,166 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/handlers"
+	mocks "github.com/OutOfStack/game-library-auth/internal/handlers/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestUserEmailHistoryHandler(t *testing.T) {
+	userID := "9a0b8f4e-5d2c-4b6a-8e1f-3c7d2a1b0e9f"
+	sentAt := time.Date(2026, 3, 4, 5, 6, 0, 0, time.UTC)
+	supportToken := "support-token"
+
+	tests := []struct {
+		name           string
+		userID         string
+		supportToken   string
+		setupMocks     func(*mocks.MockEmailHistoryFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:         "successful history",
+			userID:       userID,
+			supportToken: supportToken,
+			setupMocks: func(m *mocks.MockEmailHistoryFacade) {
+				m.EXPECT().GetUserEmailMessages(gomock.Any(), userID).Return([]model.EmailMessage{
+					{
+						ID:                "message-2",
+						Template:          "email_verification",
+						Recipient:         "test@example.com",
+						Provider:          "resend",
+						ProviderMessageID: "provider-id",
+						Status:            model.EmailDeliveryStatusDelivered,
+						DateCreated:       sentAt,
+						DateUpdated:       sentAt.Add(time.Minute),
+					},
+					{
+						ID:          "message-1",
+						Template:    "email_verification",
+						Recipient:   "test@example.com",
+						Status:      model.EmailMessageStatusFailed,
+						Error:       "provider unavailable",
+						DateCreated: sentAt.Add(-time.Minute),
+						DateUpdated: sentAt.Add(-time.Minute),
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.EmailHistoryResp{
+				Messages: []handlers.EmailMessageResp{
+					{
+						ID:                "message-2",
+						Template:          "email_verification",
+						Recipient:         "test@example.com",
+						Provider:          "resend",
+						ProviderMessageID: "provider-id",
+						Status:            model.EmailDeliveryStatusDelivered,
+						DateCreated:       sentAt,
+						DateUpdated:       sentAt.Add(time.Minute),
+					},
+					{
+						ID:          "message-1",
+						Template:    "email_verification",
+						Recipient:   "test@example.com",
+						Status:      model.EmailMessageStatusFailed,
+						Error:       "provider unavailable",
+						DateCreated: sentAt.Add(-time.Minute),
+						DateUpdated: sentAt.Add(-time.Minute),
+					},
+				},
+			},
+		},
+		{
+			name:         "empty history",
+			userID:       userID,
+			supportToken: supportToken,
+			setupMocks: func(m *mocks.MockEmailHistoryFacade) {
+				m.EXPECT().GetUserEmailMessages(gomock.Any(), userID).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   handlers.EmailHistoryResp{Messages: []handlers.EmailMessageResp{}},
+		},
+		{
+			name:           "missing support token",
+			userID:         userID,
+			setupMocks:     func(*mocks.MockEmailHistoryFacade) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp:   web.ErrResp{Error: "Invalid support token"},
+		},
+		{
+			name:           "invalid support token",
+			userID:         userID,
+			supportToken:   "wrong-token",
+			setupMocks:     func(*mocks.MockEmailHistoryFacade) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp:   web.ErrResp{Error: "Invalid support token"},
+		},
+		{
+			name:           "invalid user id",
+			userID:         "not-a-uuid",
+			supportToken:   supportToken,
+			setupMocks:     func(*mocks.MockEmailHistoryFacade) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   web.ErrResp{Error: "Invalid user ID"},
+		},
+		{
+			name:         "facade error",
+			userID:       userID,
+			supportToken: supportToken,
+			setupMocks: func(m *mocks.MockEmailHistoryFacade) {
+				m.EXPECT().GetUserEmailMessages(gomock.Any(), userID).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   web.ErrResp{Error: internalErrorMsg},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockFacade := mocks.NewMockEmailHistoryFacade(ctrl)
+			tt.setupMocks(mockFacade)
+
+			api := handlers.NewEmailHistoryAPI(zap.NewNop(), mockFacade, "support-token")
+			app := fiber.New()
+			app.Get("/admin/users/:id/emails", api.UserEmailHistoryHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/users/"+tt.userID+"/emails", nil)
+			if tt.supportToken != "" {
+				req.Header.Set("X-Support-Token", tt.supportToken)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			switch v := tt.expectedResp.(type) {
+			case handlers.EmailHistoryResp:
+				var actual handlers.EmailHistoryResp
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+				assert.Equal(t, v, actual)
+			case web.ErrResp:
+				var actual web.ErrResp
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+				assert.Equal(t, v, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// email preview formats
+const (
+	emailPreviewFormatHTML = "html"
+	emailPreviewFormatText = "text"
+)
+
+// EmailPreviewAPI renders email templates with sample data for preview
+type EmailPreviewAPI struct {
+	log      *zap.Logger
+	renderer EmailRenderer
+}
+
+// EmailRenderer provides methods for rendering emails
+type EmailRenderer interface {
+	Preview(name, locale string) (mailer.Message, error)
+}
+
+// NewEmailPreviewAPI creates a new email preview API instance
+func NewEmailPreviewAPI(log *zap.Logger, renderer EmailRenderer) *EmailPreviewAPI {
+	return &EmailPreviewAPI{
+		log:      log,
+		renderer: renderer,
+	}
+}
+
+// EmailPreviewHandler renders email template with sample data in requested locale.
+// Email is returned as HTML page or as plain text if format query param is "text". Route is served by debug app only
+func (a *EmailPreviewAPI) EmailPreviewHandler(c *fiber.Ctx) error {
+	format := c.Query("format", emailPreviewFormatHTML)
+	if format != emailPreviewFormatHTML && format != emailPreviewFormatText {
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: "Format must be one of html, text",
+		})
+	}
+
+	msg, err := a.renderer.Preview(c.Params("name"), c.Query("locale"))
+	if err != nil {
+		if errors.Is(err, mailer.ErrUnknownTemplate) {
+			return c.Status(http.StatusNotFound).JSON(web.ErrResp{
+				Error: err.Error(),
+			})
+		}
+		a.log.Error("render email preview", zap.String("name", c.Params("name")), zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+
+	c.Set("X-Email-Subject", msg.Subject)
+	if format == emailPreviewFormatText {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.SendString(msg.Text)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(msg.HTML)
+}
@@ -0,0 +1,119 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/mailer"
+	"github.com/OutOfStack/game-library-auth/internal/handlers"
+	mocks "github.com/OutOfStack/game-library-auth/internal/handlers/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestEmailPreviewHandler(t *testing.T) {
+	msg := mailer.Message{
+		Subject: "Verify Your Email Address - Game Library",
+		HTML:    "<p>Verify</p>",
+		Text:    "Verify",
+	}
+
+	tests := []struct {
+		name                string
+		query               string
+		setupMocks          func(*mocks.MockEmailRenderer)
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+		expectedResp        *web.ErrResp
+	}{
+		{
+			name:  "html by default",
+			query: "",
+			setupMocks: func(m *mocks.MockEmailRenderer) {
+				m.EXPECT().Preview("email_verification", "").Return(msg, nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: fiber.MIMETextHTMLCharsetUTF8,
+			expectedBody:        msg.HTML,
+		},
+		{
+			name:  "text in locale",
+			query: "?format=text&locale=es",
+			setupMocks: func(m *mocks.MockEmailRenderer) {
+				m.EXPECT().Preview("email_verification", "es").Return(msg, nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: fiber.MIMETextPlainCharsetUTF8,
+			expectedBody:        msg.Text,
+		},
+		{
+			name:           "invalid format",
+			query:          "?format=pdf",
+			setupMocks:     func(*mocks.MockEmailRenderer) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   &web.ErrResp{Error: "Format must be one of html, text"},
+		},
+		{
+			name:  "unknown template",
+			query: "",
+			setupMocks: func(m *mocks.MockEmailRenderer) {
+				m.EXPECT().Preview("email_verification", "").Return(mailer.Message{}, fmt.Errorf("%w %q", mailer.ErrUnknownTemplate, "email_verification"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp:   &web.ErrResp{Error: `unknown email template "email_verification"`},
+		},
+		{
+			name:  "render error",
+			query: "",
+			setupMocks: func(m *mocks.MockEmailRenderer) {
+				m.EXPECT().Preview("email_verification", "").Return(mailer.Message{}, errors.New("render error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   &web.ErrResp{Error: internalErrorMsg},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRenderer := mocks.NewMockEmailRenderer(ctrl)
+			tt.setupMocks(mockRenderer)
+
+			api := handlers.NewEmailPreviewAPI(zap.NewNop(), mockRenderer)
+			app := fiber.New()
+			app.Get("/emails/:name", api.EmailPreviewHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/emails/email_verification"+tt.query, nil)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedResp != nil {
+				var errResp web.ErrResp
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+				assert.Equal(t, *tt.expectedResp, errResp)
+				return
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedBody, string(body))
+			assert.Equal(t, tt.expectedContentType, resp.Header.Get(fiber.HeaderContentType))
+			assert.Equal(t, msg.Subject, resp.Header.Get("X-Email-Subject"))
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// EmailSignInHandler godoc
+// @Summary      Send sign in code by email
+// @Description  Sends single-use sign in code and link to email address. Only accounts with verified email can sign in by email.
+// @Description  Response doesn't reveal whether account with such email exists, new code isn't sent if previous one was sent recently
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        signin body EmailSignInReq true "Email address"
+// @Success      204 "Sign in email is sent if account exists"
+// @Failure      400 {object} web.ErrResp
+// @Failure      429 {object} web.ErrResp "Too many requests"
+// @Failure      500 {object} web.ErrResp
+// @Router       /signin/email [post]
+func (a *AuthAPI) EmailSignInHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "emailSignIn")
+	defer span.End()
+
+	var req EmailSignInReq
+	if err := c.BodyParser(&req); err != nil {
+		a.log.Error("parsing data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: "Error parsing data",
+		})
+	}
+
+	if fields, err := web.Validate(req); err != nil {
+		a.log.Info("validating email sign in data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error:  validationErrorMsg,
+			Fields: fields,
+		})
+	}
+
+	if err := a.userFacade.RequestEmailSignIn(ctx, req.Email); err != nil {
+		switch {
+		case errors.Is(err, facade.ErrEmailSignInUnavailable), facade.AsTooManyRequestsError(err) != nil:
+			// respond the same way as on success to not disclose registered emails.
+			// Code cooldown applies to existing accounts only, so it is not reported either
+			return c.SendStatus(http.StatusNoContent)
+		default:
+			a.log.Error("request email sign in", zap.Error(err))
+			return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+				Error: internalErrorMsg,
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// VerifyEmailSignInHandler godoc
+// @Summary      Sign in with code sent by email
+// @Description  Signs in with email and single-use code or with token from sign in link and returns an access token
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        signin body VerifyEmailSignInReq true "Email with code or sign in link token"
+// @Success      200 {object} TokenResp
+// @Success      202 {object} TwoFactorChallengeResp "Two-factor authentication is required"
+// @Failure      400 {object} web.ErrResp
+// @Failure      401 {object} web.ErrResp "Invalid or expired sign in code or too many failed attempts"
+// @Failure      429 {object} web.ErrResp "Too many requests"
+// @Failure      500 {object} web.ErrResp
+// @Router       /signin/email/verify [post]
+func (a *AuthAPI) VerifyEmailSignInHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "verifyEmailSignIn")
+	defer span.End()
+
+	var req VerifyEmailSignInReq
+	if err := c.BodyParser(&req); err != nil {
+		a.log.Error("parsing data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: "Error parsing data",
+		})
+	}
+
+	if fields, err := web.Validate(req); err != nil {
+		a.log.Info("validating verify email sign in data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error:  validationErrorMsg,
+			Fields: fields,
+		})
+	}
+
+	// sign in with link token or with email and code
+	var user model.User
+	var err error
+	if req.Token != "" {
+		user, err = a.userFacade.CompleteEmailSignInWithToken(ctx, req.Token)
+	} else {
+		user, err = a.userFacade.CompleteEmailSignIn(ctx, req.Email, req.Code)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, facade.ErrEmailSignInInvalidOrExpired):
+			return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+				Error: invalidOrExpiredSignInMsg,
+			})
+		case errors.Is(err, facade.ErrEmailSignInTooManyAttempts):
+			return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+				Error: "Too many failed attempts. Please request a new sign in code",
+			})
+		default:
+			a.log.Error("complete email sign in", zap.Error(err))
+			return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+				Error: internalErrorMsg,
+			})
+		}
+	}
+
+	log := a.log.With(zap.String("userId", user.ID))
+
+	// require second factor if user has two-factor authentication enabled
+	challengeToken, required, err := a.userFacade.StartTwoFactorChallenge(ctx, user.ID)
+	if err != nil {
+		log.Error("starting two-factor challenge", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+	if required {
+		return c.Status(http.StatusAccepted).JSON(TwoFactorChallengeResp{
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+		})
+	}
+
+	// create tokens
+	tokens, err := a.userFacade.CreateSignInTokens(ctx, user, getClientInfo(c))
+	if err != nil {
+		log.Error("creating tokens", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+
+	// set refresh token as a cookie
+	a.setRefreshTokenCookie(c, tokens.RefreshToken)
+
+	return c.JSON(TokenResp{
+		AccessToken: tokens.AccessToken,
+	})
+}
@@ -0,0 +1,240 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/handlers"
+	mocks "github.com/OutOfStack/game-library-auth/internal/handlers/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEmailSignInHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		request        handlers.EmailSignInReq
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:    "successful request",
+			request: handlers.EmailSignInReq{Email: "test@example.com"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().RequestEmailSignIn(gomock.Any(), "test@example.com").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:    "no account with verified email",
+			request: handlers.EmailSignInReq{Email: "unknown@example.com"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().RequestEmailSignIn(gomock.Any(), "unknown@example.com").Return(facade.ErrEmailSignInUnavailable)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "invalid email",
+			request:        handlers.EmailSignInReq{Email: "invalid"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   web.ErrResp{Error: "Validation error"},
+		},
+		{
+			name:    "code sent recently",
+			request: handlers.EmailSignInReq{Email: "test@example.com"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().RequestEmailSignIn(gomock.Any(), "test@example.com").Return(facade.NewTooManyRequestsError(90 * time.Second))
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:    "facade error",
+			request: handlers.EmailSignInReq{Email: "test@example.com"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().RequestEmailSignIn(gomock.Any(), "test@example.com").Return(errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   web.ErrResp{Error: internalErrorMsg},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/signin/email", authAPI.EmailSignInHandler)
+
+			reqBody, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/signin/email", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			// cooldown of existing account is not disclosed
+			assert.Empty(t, resp.Header.Get("Retry-After"))
+
+			if v, ok := tt.expectedResp.(web.ErrResp); ok {
+				var actual web.ErrResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.Error, actual.Error)
+			}
+		})
+	}
+}
+
+func TestVerifyEmailSignInHandler(t *testing.T) {
+	u := model.User{ID: "uid-1", Username: "testuser"}
+	tokens := facade.TokenPair{
+		AccessToken:  "valid.jwt.token",
+		RefreshToken: facade.RefreshToken{Token: "valid.refresh.token"},
+	}
+
+	tests := []struct {
+		name           string
+		request        handlers.VerifyEmailSignInReq
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:    "successful sign in with code",
+			request: handlers.VerifyEmailSignInReq{Email: "test@example.com", Code: "123456"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().CompleteEmailSignIn(gomock.Any(), "test@example.com", "123456").Return(u, nil)
+				mockUserFacade.EXPECT().StartTwoFactorChallenge(gomock.Any(), u.ID).Return("", false, nil)
+				mockUserFacade.EXPECT().CreateSignInTokens(gomock.Any(), u, gomock.Any()).Return(tokens, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   handlers.TokenResp{AccessToken: "valid.jwt.token"},
+		},
+		{
+			name:    "successful sign in with link token",
+			request: handlers.VerifyEmailSignInReq{Token: "sign-in-token"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().CompleteEmailSignInWithToken(gomock.Any(), "sign-in-token").Return(u, nil)
+				mockUserFacade.EXPECT().StartTwoFactorChallenge(gomock.Any(), u.ID).Return("", false, nil)
+				mockUserFacade.EXPECT().CreateSignInTokens(gomock.Any(), u, gomock.Any()).Return(tokens, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   handlers.TokenResp{AccessToken: "valid.jwt.token"},
+		},
+		{
+			name:    "two-factor authentication required",
+			request: handlers.VerifyEmailSignInReq{Token: "sign-in-token"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().CompleteEmailSignInWithToken(gomock.Any(), "sign-in-token").Return(u, nil)
+				mockUserFacade.EXPECT().StartTwoFactorChallenge(gomock.Any(), u.ID).Return("challenge.jwt.token", true, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedResp: handlers.TwoFactorChallengeResp{
+				TwoFactorRequired: true,
+				ChallengeToken:    "challenge.jwt.token",
+			},
+		},
+		{
+			name:           "missing code",
+			request:        handlers.VerifyEmailSignInReq{Email: "test@example.com"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   web.ErrResp{Error: "Validation error"},
+		},
+		{
+			name:           "both code and token",
+			request:        handlers.VerifyEmailSignInReq{Email: "test@example.com", Code: "123456", Token: "sign-in-token"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   web.ErrResp{Error: "Validation error"},
+		},
+		{
+			name:           "empty request",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   web.ErrResp{Error: "Validation error"},
+		},
+		{
+			name:    "invalid code",
+			request: handlers.VerifyEmailSignInReq{Email: "test@example.com", Code: "123456"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().CompleteEmailSignIn(gomock.Any(), "test@example.com", "123456").Return(model.User{}, facade.ErrEmailSignInInvalidOrExpired)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp:   web.ErrResp{Error: "Invalid or expired sign in code"},
+		},
+		{
+			name:    "too many failed attempts",
+			request: handlers.VerifyEmailSignInReq{Email: "test@example.com", Code: "123456"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().CompleteEmailSignIn(gomock.Any(), "test@example.com", "123456").Return(model.User{}, facade.ErrEmailSignInTooManyAttempts)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp:   web.ErrResp{Error: "Too many failed attempts. Please request a new sign in code"},
+		},
+		{
+			name:    "facade error",
+			request: handlers.VerifyEmailSignInReq{Token: "sign-in-token"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().CompleteEmailSignInWithToken(gomock.Any(), "sign-in-token").Return(model.User{}, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   web.ErrResp{Error: internalErrorMsg},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/signin/email/verify", authAPI.VerifyEmailSignInHandler)
+
+			reqBody, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/signin/email/verify", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			switch v := tt.expectedResp.(type) {
+			case handlers.TokenResp:
+				var actual handlers.TokenResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v, actual)
+				assert.Contains(t, resp.Header.Get("Set-Cookie"), "refresh_token=valid.refresh.token")
+			case handlers.TwoFactorChallengeResp:
+				var actual handlers.TwoFactorChallengeResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v, actual)
+			case web.ErrResp:
+				var actual web.ErrResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.Error, actual.Error)
+			}
+		})
+	}
+}
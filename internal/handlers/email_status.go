@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// EmailStatusHandler godoc
+// @Summary      Get email delivery status
+// @Description  Returns delivery status of user email address reported by email provider and whether it is unsubscribed
+// @Tags         auth
+// @Security     Bearer
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} EmailStatusResp
+// @Failure      401 {object} web.ErrResp "Invalid or missing authorization token"
+// @Failure      404 {object} web.ErrResp "User has no email"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /account/email/status [get]
+func (a *AuthAPI) EmailStatusHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "emailStatus")
+	defer span.End()
+
+	userID, err := a.getUserIDFromJWT(c)
+	if err != nil {
+		a.log.Error("extracting user ID from JWT", zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: invalidAuthTokenMsg,
+		})
+	}
+
+	status, err := a.userFacade.GetEmailDeliveryStatus(ctx, userID)
+	if err != nil {
+		if errors.Is(err, facade.ErrEmailDeliveryStatusNoEmail) {
+			return c.Status(http.StatusNotFound).JSON(web.ErrResp{
+				Error: "User has no email",
+			})
+		}
+		a.log.Error("get email delivery status", zap.String("userId", userID), zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+
+	return c.JSON(EmailStatusResp{
+		Email:             status.Email,
+		DeliveryStatus:    status.Status,
+		DeliveryStatusAt:  status.StatusAt,
+		Unsubscribed:      status.Unsubscribed,
+		UnsubscribeReason: status.UnsubscribeReason,
+	})
+}
@@ -0,0 +1,140 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/handlers"
+	mocks "github.com/OutOfStack/game-library-auth/internal/handlers/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEmailStatusHandler(t *testing.T) {
+	userID := uuid.New().String()
+	statusAt := time.Now().UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:       "bounced and unsubscribed email",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().GetEmailDeliveryStatus(gomock.Any(), userID).Return(model.EmailDeliveryStatus{
+					Email:             "test@example.com",
+					Status:            model.EmailDeliveryStatusBounced,
+					StatusAt:          &statusAt,
+					Unsubscribed:      true,
+					UnsubscribeReason: model.UnsubscribeReasonHardBounce,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.EmailStatusResp{
+				Email:             "test@example.com",
+				DeliveryStatus:    model.EmailDeliveryStatusBounced,
+				DeliveryStatusAt:  &statusAt,
+				Unsubscribed:      true,
+				UnsubscribeReason: model.UnsubscribeReasonHardBounce,
+			},
+		},
+		{
+			name:       "no delivery events",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().GetEmailDeliveryStatus(gomock.Any(), userID).Return(model.EmailDeliveryStatus{
+					Email: "test@example.com",
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   handlers.EmailStatusResp{Email: "test@example.com"},
+		},
+		{
+			name:           "missing auth header",
+			authHeader:     "",
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp:   web.ErrResp{Error: invalidAuthToken},
+		},
+		{
+			name:       "user has no email",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().GetEmailDeliveryStatus(gomock.Any(), userID).Return(model.EmailDeliveryStatus{}, facade.ErrEmailDeliveryStatusNoEmail)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp:   web.ErrResp{Error: "User has no email"},
+		},
+		{
+			name:       "facade error",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().GetEmailDeliveryStatus(gomock.Any(), userID).Return(model.EmailDeliveryStatus{}, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   web.ErrResp{Error: internalErrorMsg},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.authHeader == "Bearer valid-token" {
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(auth.Claims{UserID: userID}, nil).
+					AnyTimes()
+			}
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Get("/account/email/status", authAPI.EmailStatusHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/account/email/status", nil)
+			req.Header.Set("Authorization", tt.authHeader)
+
+			resp, err := app.Test(req, 5000)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			switch expected := tt.expectedResp.(type) {
+			case handlers.EmailStatusResp:
+				var actual handlers.EmailStatusResp
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+				assert.Equal(t, expected.Email, actual.Email)
+				assert.Equal(t, expected.DeliveryStatus, actual.DeliveryStatus)
+				assert.Equal(t, expected.Unsubscribed, actual.Unsubscribed)
+				assert.Equal(t, expected.UnsubscribeReason, actual.UnsubscribeReason)
+				if expected.DeliveryStatusAt == nil {
+					assert.Nil(t, actual.DeliveryStatusAt)
+				} else {
+					require.NotNil(t, actual.DeliveryStatusAt)
+					assert.True(t, expected.DeliveryStatusAt.Equal(*actual.DeliveryStatusAt))
+				}
+			case web.ErrResp:
+				var actual web.ErrResp
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+				assert.Equal(t, expected.Error, actual.Error)
+			}
+		})
+	}
+}
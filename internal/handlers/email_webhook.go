@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/resendapi"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// EmailWebhookAPI handles delivery webhooks of email provider
+type EmailWebhookAPI struct {
+	log            *zap.Logger
+	verifier       *resendapi.WebhookVerifier
+	deliveryFacade EmailDeliveryFacade
+}
+
+// EmailDeliveryFacade provides methods for recording email delivery events
+type EmailDeliveryFacade interface {
+	RecordEmailDeliveryEvent(ctx context.Context, event model.EmailDeliveryEvent) error
+}
+
+// NewEmailWebhookAPI creates a new email webhook API instance
+func NewEmailWebhookAPI(log *zap.Logger, verifier *resendapi.WebhookVerifier, deliveryFacade EmailDeliveryFacade) *EmailWebhookAPI {
+	return &EmailWebhookAPI{
+		log:            log,
+		verifier:       verifier,
+		deliveryFacade: deliveryFacade,
+	}
+}
+
+// ResendWebhookHandler godoc
+// @Summary      Receive Resend delivery webhook
+// @Description  Records delivered, bounced and complained events of sent emails. Hard-bounced and complained addresses are unsubscribed.
+// @Description  Request is signed by Svix, other event types are acknowledged and ignored
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        svix-id header string true "Webhook message id"
+// @Param        svix-timestamp header string true "Webhook timestamp"
+// @Param        svix-signature header string true "Webhook signature"
+// @Success      204
+// @Failure      400 {object} web.ErrResp "Invalid webhook payload"
+// @Failure      401 {object} web.ErrResp "Invalid webhook signature"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /webhooks/resend [post]
+func (a *EmailWebhookAPI) ResendWebhookHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "resendWebhook")
+	defer span.End()
+
+	msgID := c.Get("svix-id")
+	body := c.Body()
+	if err := a.verifier.Verify(msgID, c.Get("svix-timestamp"), c.Get("svix-signature"), body); err != nil {
+		a.log.Warn("verify resend webhook", zap.String("msgID", msgID), zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: "Invalid webhook signature",
+		})
+	}
+
+	event, err := resendapi.ParseWebhookEvent(body)
+	if err != nil {
+		a.log.Error("parse resend webhook", zap.String("msgID", msgID), zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: "Invalid webhook payload",
+		})
+	}
+
+	deliveryEvent, ok := mapWebhookEventToDeliveryEvent(msgID, event)
+	if !ok {
+		return c.SendStatus(http.StatusNoContent)
+	}
+
+	if err = a.deliveryFacade.RecordEmailDeliveryEvent(ctx, deliveryEvent); err != nil {
+		a.log.Error("record email delivery event", zap.String("msgID", msgID), zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// maps Resend webhook event to delivery event. Returns false for event types which are not recorded
+func mapWebhookEventToDeliveryEvent(msgID string, event resendapi.WebhookEvent) (model.EmailDeliveryEvent, bool) {
+	deliveryEvent := model.EmailDeliveryEvent{
+		EventID:    msgID,
+		MessageID:  event.Data.EmailID,
+		OccurredAt: event.CreatedAt,
+	}
+	if len(event.Data.To) > 0 {
+		deliveryEvent.Email = event.Data.To[0]
+	}
+
+	switch event.Type {
+	case resendapi.EventTypeDelivered:
+		deliveryEvent.Status = model.EmailDeliveryStatusDelivered
+	case resendapi.EventTypeBounced:
+		deliveryEvent.Status = model.EmailDeliveryStatusBounced
+		if event.Data.Bounce != nil {
+			deliveryEvent.HardBounce = event.Data.Bounce.Type == resendapi.BounceTypePermanent
+			deliveryEvent.Detail = event.Data.Bounce.Message
+		}
+	case resendapi.EventTypeComplained:
+		deliveryEvent.Status = model.EmailDeliveryStatusComplained
+	default:
+		return model.EmailDeliveryEvent{}, false
+	}
+
+	if deliveryEvent.Email == "" {
+		return model.EmailDeliveryEvent{}, false
+	}
+
+	return deliveryEvent, true
+}
@@ -0,0 +1,204 @@
+package handlers_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/client/resendapi"
+	"github.com/OutOfStack/game-library-auth/internal/handlers"
+	mocks "github.com/OutOfStack/game-library-auth/internal/handlers/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+var webhookTestKey = []byte("test-webhook-signing-key")
+
+func setupEmailWebhookTest(t *testing.T) (*fiber.App, *mocks.MockEmailDeliveryFacade, *gomock.Controller) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mockFacade := mocks.NewMockEmailDeliveryFacade(ctrl)
+	verifier, err := resendapi.NewWebhookVerifier("whsec_" + base64.StdEncoding.EncodeToString(webhookTestKey))
+	require.NoError(t, err)
+
+	api := handlers.NewEmailWebhookAPI(zap.NewNop(), verifier, mockFacade)
+	app := fiber.New()
+	app.Post("/webhooks/resend", api.ResendWebhookHandler)
+
+	return app, mockFacade, ctrl
+}
+
+func signWebhook(msgID, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, webhookTestKey)
+	mac.Write([]byte(msgID + "." + timestamp + "."))
+	mac.Write(body)
+	return "v1," + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestResendWebhookHandler(t *testing.T) {
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	msgID := "msg_test"
+
+	bouncedBody := `{"type":"email.bounced","created_at":"2026-01-02T03:04:05Z","data":{"email_id":"email-1","to":["test@example.com"],` +
+		`"bounce":{"type":"Permanent","subType":"General","message":"mailbox does not exist"}}}`
+	softBouncedBody := `{"type":"email.bounced","created_at":"2026-01-02T03:04:05Z","data":{"email_id":"email-1","to":["test@example.com"],` +
+		`"bounce":{"type":"Transient","subType":"MailboxFull","message":"mailbox is full"}}}`
+	complainedBody := `{"type":"email.complained","created_at":"2026-01-02T03:04:05Z","data":{"email_id":"email-1","to":["test@example.com"]}}`
+	deliveredBody := `{"type":"email.delivered","created_at":"2026-01-02T03:04:05Z","data":{"email_id":"email-1","to":["test@example.com"]}}`
+	openedBody := `{"type":"email.opened","created_at":"2026-01-02T03:04:05Z","data":{"email_id":"email-1","to":["test@example.com"]}}`
+
+	tests := []struct {
+		name           string
+		body           string
+		signature      string
+		timestamp      string
+		setupMocks     func(*mocks.MockEmailDeliveryFacade)
+		expectedStatus int
+		expectedResp   *web.ErrResp
+	}{
+		{
+			name: "hard bounce",
+			body: bouncedBody,
+			setupMocks: func(m *mocks.MockEmailDeliveryFacade) {
+				m.EXPECT().RecordEmailDeliveryEvent(gomock.Any(), model.EmailDeliveryEvent{
+					EventID:    msgID,
+					MessageID:  "email-1",
+					Email:      "test@example.com",
+					Status:     model.EmailDeliveryStatusBounced,
+					HardBounce: true,
+					Detail:     "mailbox does not exist",
+					OccurredAt: createdAt,
+				}).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "soft bounce",
+			body: softBouncedBody,
+			setupMocks: func(m *mocks.MockEmailDeliveryFacade) {
+				m.EXPECT().RecordEmailDeliveryEvent(gomock.Any(), model.EmailDeliveryEvent{
+					EventID:    msgID,
+					MessageID:  "email-1",
+					Email:      "test@example.com",
+					Status:     model.EmailDeliveryStatusBounced,
+					Detail:     "mailbox is full",
+					OccurredAt: createdAt,
+				}).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "complaint",
+			body: complainedBody,
+			setupMocks: func(m *mocks.MockEmailDeliveryFacade) {
+				m.EXPECT().RecordEmailDeliveryEvent(gomock.Any(), model.EmailDeliveryEvent{
+					EventID:    msgID,
+					MessageID:  "email-1",
+					Email:      "test@example.com",
+					Status:     model.EmailDeliveryStatusComplained,
+					OccurredAt: createdAt,
+				}).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "delivered",
+			body: deliveredBody,
+			setupMocks: func(m *mocks.MockEmailDeliveryFacade) {
+				m.EXPECT().RecordEmailDeliveryEvent(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ any, event model.EmailDeliveryEvent) error {
+						assert.Equal(t, model.EmailDeliveryStatusDelivered, event.Status)
+						assert.False(t, event.HardBounce)
+						return nil
+					})
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "ignored event type",
+			body:           openedBody,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "invalid signature",
+			body:           bouncedBody,
+			signature:      "v1," + base64.StdEncoding.EncodeToString([]byte("invalid")),
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp:   &web.ErrResp{Error: "Invalid webhook signature"},
+		},
+		{
+			name:           "expired timestamp",
+			body:           bouncedBody,
+			timestamp:      strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10),
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp:   &web.ErrResp{Error: "Invalid webhook signature"},
+		},
+		{
+			name:           "invalid payload",
+			body:           `{"type":"email.bounced"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   &web.ErrResp{Error: "Invalid webhook payload"},
+		},
+		{
+			name: "facade error",
+			body: complainedBody,
+			setupMocks: func(m *mocks.MockEmailDeliveryFacade) {
+				m.EXPECT().RecordEmailDeliveryEvent(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   &web.ErrResp{Error: internalErrorMsg},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, mockFacade, ctrl := setupEmailWebhookTest(t)
+			defer ctrl.Finish()
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockFacade)
+			}
+
+			timestamp := tt.timestamp
+			if timestamp == "" {
+				timestamp = strconv.FormatInt(time.Now().Unix(), 10)
+			}
+			signature := tt.signature
+			if signature == "" {
+				signature = signWebhook(msgID, timestamp, []byte(tt.body))
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/resend", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("svix-id", msgID)
+			req.Header.Set("svix-timestamp", timestamp)
+			req.Header.Set("svix-signature", signature)
+
+			resp, err := app.Test(req, 5000)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedResp != nil {
+				var actual web.ErrResp
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+				assert.Equal(t, tt.expectedResp.Error, actual.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// GetProfileHandler godoc
+// @Summary      Get user profile
+// @Description  Returns profile of the authenticated user including email address and its verification status
+// @Tags         auth
+// @Security     Bearer
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} ProfileResp
+// @Failure      401 {object} web.ErrResp "Invalid or missing authorization token"
+// @Failure      404 {object} web.ErrResp "User not found"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /account [get]
+func (a *AuthAPI) GetProfileHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "getProfile")
+	defer span.End()
+
+	userID, err := a.getUserIDFromJWT(c)
+	if err != nil {
+		a.log.Error("extracting user ID from JWT", zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: invalidAuthTokenMsg,
+		})
+	}
+
+	user, err := a.userFacade.GetUserProfile(ctx, userID)
+	if err != nil {
+		if errors.Is(err, facade.ErrGetProfileUserNotFound) {
+			return c.Status(http.StatusNotFound).JSON(web.ErrResp{
+				Error: "User does not exist",
+			})
+		}
+		a.log.Error("get user profile", zap.String("userId", userID), zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+
+	return c.JSON(ProfileResp{
+		ID:            user.ID,
+		Username:      user.Username,
+		Name:          user.DisplayName,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Locale:        user.Locale,
+		Role:          user.Role,
+	})
+}
@@ -0,0 +1,136 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/handlers"
+	mocks "github.com/OutOfStack/game-library-auth/internal/handlers/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetProfileHandler(t *testing.T) {
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:       "regular user with unverified email",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().GetUserProfile(gomock.Any(), userID).Return(model.User{
+					ID:          userID,
+					Username:    "testuser",
+					DisplayName: "Test User",
+					Email:       "test@example.com",
+					Role:        string(model.UserRoleName),
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.ProfileResp{
+				ID:       userID,
+				Username: "testuser",
+				Name:     "Test User",
+				Email:    "test@example.com",
+				Role:     string(model.UserRoleName),
+			},
+		},
+		{
+			name:       "user without email",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().GetUserProfile(gomock.Any(), userID).Return(model.User{
+					ID:          userID,
+					Username:    "testuser",
+					DisplayName: "Test User",
+					Role:        string(model.UserRoleName),
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.ProfileResp{
+				ID:       userID,
+				Username: "testuser",
+				Name:     "Test User",
+				Role:     string(model.UserRoleName),
+			},
+		},
+		{
+			name:           "missing auth header",
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp:   web.ErrResp{Error: invalidAuthToken},
+		},
+		{
+			name:       "user not found",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().GetUserProfile(gomock.Any(), userID).Return(model.User{}, facade.ErrGetProfileUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp:   web.ErrResp{Error: "User does not exist"},
+		},
+		{
+			name:       "facade error",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().GetUserProfile(gomock.Any(), userID).Return(model.User{}, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   web.ErrResp{Error: internalErrorMsg},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.authHeader == "Bearer valid-token" {
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(auth.Claims{UserID: userID}, nil).
+					AnyTimes()
+			}
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Get("/account", authAPI.GetProfileHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/account", nil)
+			req.Header.Set("Authorization", tt.authHeader)
+
+			resp, err := app.Test(req, 5000)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			switch expected := tt.expectedResp.(type) {
+			case handlers.ProfileResp:
+				var actual handlers.ProfileResp
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+				assert.Equal(t, expected, actual)
+			case web.ErrResp:
+				var actual web.ErrResp
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+				assert.Equal(t, expected.Error, actual.Error)
+			}
+		})
+	}
+}
@@ -3,12 +3,17 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/OutOfStack/game-library-auth/internal/auth"
 	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/model"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"golang.org/x/text/language"
 )
 
 // getClaims extracts and validates JWT from Authorization header and returns the claims
@@ -47,6 +52,41 @@ func (a *AuthAPI) getUserIDFromJWT(c *fiber.Ctx) (string, error) {
 	return claims.UserID, nil
 }
 
+// getLocale returns the most preferred locale from Accept-Language header.
+// Returns empty string if header is missing or invalid
+func getLocale(c *fiber.Ctx) string {
+	tags, _, err := language.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+	if err != nil || len(tags) == 0 || tags[0] == language.Und {
+		return ""
+	}
+
+	return normalizeLocale(tags[0])
+}
+
+// normalizeLocale returns locale of language tag consisting of language and region only, e.g. "es-MX".
+// Scripts, variants and extensions are dropped, region is kept only if it is set explicitly
+func normalizeLocale(tag language.Tag) string {
+	base, _ := tag.Base()
+	region, confidence := tag.Region()
+	if confidence != language.Exact {
+		return base.String()
+	}
+
+	locale, err := language.Compose(base, region)
+	if err != nil {
+		return base.String()
+	}
+	return locale.String()
+}
+
+// getClientInfo returns IP and user agent of the client making the request
+func getClientInfo(c *fiber.Ctx) model.ClientInfo {
+	return model.ClientInfo{
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
+
 // setRefreshTokenCookie sets the refresh token as an httpOnly cookie
 func (a *AuthAPI) setRefreshTokenCookie(c *fiber.Ctx, refreshToken facade.RefreshToken) {
 	c.Cookie(&fiber.Cookie{
@@ -59,3 +99,8 @@ func (a *AuthAPI) setRefreshTokenCookie(c *fiber.Ctx, refreshToken facade.Refres
 		Expires:  refreshToken.ExpiresAt,
 	})
 }
+
+// setRetryAfterHeader sets Retry-After header in seconds rounded up, so clients don't retry too early
+func setRetryAfterHeader(c *fiber.Ctx, retryAfter time.Duration) {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+}
@@ -12,10 +12,12 @@ package handlers_mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	auth "github.com/OutOfStack/game-library-auth/internal/auth"
 	facade "github.com/OutOfStack/game-library-auth/internal/facade"
 	model "github.com/OutOfStack/game-library-auth/internal/model"
+	protocol "github.com/go-webauthn/webauthn/protocol"
 	gomock "go.uber.org/mock/gomock"
 	idtoken "google.golang.org/api/idtoken"
 )
@@ -83,6 +85,157 @@ func (m *MockUserFacade) EXPECT() *MockUserFacadeMockRecorder {
 	return m.recorder
 }
 
+// AddEmail mocks base method.
+func (m *MockUserFacade) AddEmail(ctx context.Context, userID, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEmail", ctx, userID, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddEmail indicates an expected call of AddEmail.
+func (mr *MockUserFacadeMockRecorder) AddEmail(ctx, userID, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEmail", reflect.TypeOf((*MockUserFacade)(nil).AddEmail), ctx, userID, email)
+}
+
+// BeginPasskeyRegistration mocks base method.
+func (m *MockUserFacade) BeginPasskeyRegistration(ctx context.Context, userID string) (*protocol.CredentialCreation, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginPasskeyRegistration", ctx, userID)
+	ret0, _ := ret[0].(*protocol.CredentialCreation)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// BeginPasskeyRegistration indicates an expected call of BeginPasskeyRegistration.
+func (mr *MockUserFacadeMockRecorder) BeginPasskeyRegistration(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginPasskeyRegistration", reflect.TypeOf((*MockUserFacade)(nil).BeginPasskeyRegistration), ctx, userID)
+}
+
+// BeginPasskeySignIn mocks base method.
+func (m *MockUserFacade) BeginPasskeySignIn(ctx context.Context) (*protocol.CredentialAssertion, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginPasskeySignIn", ctx)
+	ret0, _ := ret[0].(*protocol.CredentialAssertion)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// BeginPasskeySignIn indicates an expected call of BeginPasskeySignIn.
+func (mr *MockUserFacadeMockRecorder) BeginPasskeySignIn(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginPasskeySignIn", reflect.TypeOf((*MockUserFacade)(nil).BeginPasskeySignIn), ctx)
+}
+
+// CheckEmailVerificationLink mocks base method.
+func (m *MockUserFacade) CheckEmailVerificationLink(ctx context.Context, token string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckEmailVerificationLink", ctx, token)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckEmailVerificationLink indicates an expected call of CheckEmailVerificationLink.
+func (mr *MockUserFacadeMockRecorder) CheckEmailVerificationLink(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckEmailVerificationLink", reflect.TypeOf((*MockUserFacade)(nil).CheckEmailVerificationLink), ctx, token)
+}
+
+// CompleteEmailSignIn mocks base method.
+func (m *MockUserFacade) CompleteEmailSignIn(ctx context.Context, email, code string) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteEmailSignIn", ctx, email, code)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteEmailSignIn indicates an expected call of CompleteEmailSignIn.
+func (mr *MockUserFacadeMockRecorder) CompleteEmailSignIn(ctx, email, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteEmailSignIn", reflect.TypeOf((*MockUserFacade)(nil).CompleteEmailSignIn), ctx, email, code)
+}
+
+// CompleteEmailSignInWithToken mocks base method.
+func (m *MockUserFacade) CompleteEmailSignInWithToken(ctx context.Context, token string) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteEmailSignInWithToken", ctx, token)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteEmailSignInWithToken indicates an expected call of CompleteEmailSignInWithToken.
+func (mr *MockUserFacadeMockRecorder) CompleteEmailSignInWithToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteEmailSignInWithToken", reflect.TypeOf((*MockUserFacade)(nil).CompleteEmailSignInWithToken), ctx, token)
+}
+
+// CompleteTwoFactorSignIn mocks base method.
+func (m *MockUserFacade) CompleteTwoFactorSignIn(ctx context.Context, challengeToken, code, clientIP string) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTwoFactorSignIn", ctx, challengeToken, code, clientIP)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteTwoFactorSignIn indicates an expected call of CompleteTwoFactorSignIn.
+func (mr *MockUserFacadeMockRecorder) CompleteTwoFactorSignIn(ctx, challengeToken, code, clientIP any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTwoFactorSignIn", reflect.TypeOf((*MockUserFacade)(nil).CompleteTwoFactorSignIn), ctx, challengeToken, code, clientIP)
+}
+
+// ConfirmEmailChange mocks base method.
+func (m *MockUserFacade) ConfirmEmailChange(ctx context.Context, userID, code string) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChange", ctx, userID, code)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmEmailChange indicates an expected call of ConfirmEmailChange.
+func (mr *MockUserFacadeMockRecorder) ConfirmEmailChange(ctx, userID, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChange", reflect.TypeOf((*MockUserFacade)(nil).ConfirmEmailChange), ctx, userID, code)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockUserFacade) ConfirmTOTP(ctx context.Context, userID, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockUserFacadeMockRecorder) ConfirmTOTP(ctx, userID, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockUserFacade)(nil).ConfirmTOTP), ctx, userID, code)
+}
+
+// CreateSignInTokens mocks base method.
+func (m *MockUserFacade) CreateSignInTokens(ctx context.Context, user model.User, client model.ClientInfo) (facade.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSignInTokens", ctx, user, client)
+	ret0, _ := ret[0].(facade.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSignInTokens indicates an expected call of CreateSignInTokens.
+func (mr *MockUserFacadeMockRecorder) CreateSignInTokens(ctx, user, client any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSignInTokens", reflect.TypeOf((*MockUserFacade)(nil).CreateSignInTokens), ctx, user, client)
+}
+
 // CreateTokens mocks base method.
 func (m *MockUserFacade) CreateTokens(ctx context.Context, user model.User) (facade.TokenPair, error) {
 	m.ctrl.T.Helper()
@@ -98,6 +251,20 @@ func (mr *MockUserFacadeMockRecorder) CreateTokens(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTokens", reflect.TypeOf((*MockUserFacade)(nil).CreateTokens), ctx, user)
 }
 
+// DeletePasskey mocks base method.
+func (m *MockUserFacade) DeletePasskey(ctx context.Context, userID, passkeyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasskey", ctx, userID, passkeyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasskey indicates an expected call of DeletePasskey.
+func (mr *MockUserFacadeMockRecorder) DeletePasskey(ctx, userID, passkeyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasskey", reflect.TypeOf((*MockUserFacade)(nil).DeletePasskey), ctx, userID, passkeyID)
+}
+
 // DeleteUser mocks base method.
 func (m *MockUserFacade) DeleteUser(ctx context.Context, userID string) error {
 	m.ctrl.T.Helper()
@@ -112,19 +279,138 @@ func (mr *MockUserFacadeMockRecorder) DeleteUser(ctx, userID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserFacade)(nil).DeleteUser), ctx, userID)
 }
 
+// DisableTOTP mocks base method.
+func (m *MockUserFacade) DisableTOTP(ctx context.Context, userID, password, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, userID, password, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockUserFacadeMockRecorder) DisableTOTP(ctx, userID, password, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockUserFacade)(nil).DisableTOTP), ctx, userID, password, code)
+}
+
+// EmailDeliveryDelay mocks base method.
+func (m *MockUserFacade) EmailDeliveryDelay() (time.Duration, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmailDeliveryDelay")
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// EmailDeliveryDelay indicates an expected call of EmailDeliveryDelay.
+func (mr *MockUserFacadeMockRecorder) EmailDeliveryDelay() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmailDeliveryDelay", reflect.TypeOf((*MockUserFacade)(nil).EmailDeliveryDelay))
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockUserFacade) EnrollTOTP(ctx context.Context, userID string) (model.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", ctx, userID)
+	ret0, _ := ret[0].(model.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockUserFacadeMockRecorder) EnrollTOTP(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockUserFacade)(nil).EnrollTOTP), ctx, userID)
+}
+
+// FinishPasskeyRegistration mocks base method.
+func (m *MockUserFacade) FinishPasskeyRegistration(ctx context.Context, userID, sessionID, name string, response *protocol.ParsedCredentialCreationData) (model.Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishPasskeyRegistration", ctx, userID, sessionID, name, response)
+	ret0, _ := ret[0].(model.Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishPasskeyRegistration indicates an expected call of FinishPasskeyRegistration.
+func (mr *MockUserFacadeMockRecorder) FinishPasskeyRegistration(ctx, userID, sessionID, name, response any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishPasskeyRegistration", reflect.TypeOf((*MockUserFacade)(nil).FinishPasskeyRegistration), ctx, userID, sessionID, name, response)
+}
+
+// FinishPasskeySignIn mocks base method.
+func (m *MockUserFacade) FinishPasskeySignIn(ctx context.Context, sessionID string, response *protocol.ParsedCredentialAssertionData) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishPasskeySignIn", ctx, sessionID, response)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishPasskeySignIn indicates an expected call of FinishPasskeySignIn.
+func (mr *MockUserFacadeMockRecorder) FinishPasskeySignIn(ctx, sessionID, response any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishPasskeySignIn", reflect.TypeOf((*MockUserFacade)(nil).FinishPasskeySignIn), ctx, sessionID, response)
+}
+
+// GetEmailDeliveryStatus mocks base method.
+func (m *MockUserFacade) GetEmailDeliveryStatus(ctx context.Context, userID string) (model.EmailDeliveryStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailDeliveryStatus", ctx, userID)
+	ret0, _ := ret[0].(model.EmailDeliveryStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailDeliveryStatus indicates an expected call of GetEmailDeliveryStatus.
+func (mr *MockUserFacadeMockRecorder) GetEmailDeliveryStatus(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailDeliveryStatus", reflect.TypeOf((*MockUserFacade)(nil).GetEmailDeliveryStatus), ctx, userID)
+}
+
+// GetUserProfile mocks base method.
+func (m *MockUserFacade) GetUserProfile(ctx context.Context, userID string) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserProfile", ctx, userID)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserProfile indicates an expected call of GetUserProfile.
+func (mr *MockUserFacadeMockRecorder) GetUserProfile(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserProfile", reflect.TypeOf((*MockUserFacade)(nil).GetUserProfile), ctx, userID)
+}
+
 // GoogleOAuth mocks base method.
-func (m *MockUserFacade) GoogleOAuth(ctx context.Context, oauthID, email string) (model.User, error) {
+func (m *MockUserFacade) GoogleOAuth(ctx context.Context, oauthID, email, locale string) (model.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GoogleOAuth", ctx, oauthID, email)
+	ret := m.ctrl.Call(m, "GoogleOAuth", ctx, oauthID, email, locale)
 	ret0, _ := ret[0].(model.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GoogleOAuth indicates an expected call of GoogleOAuth.
-func (mr *MockUserFacadeMockRecorder) GoogleOAuth(ctx, oauthID, email any) *gomock.Call {
+func (mr *MockUserFacadeMockRecorder) GoogleOAuth(ctx, oauthID, email, locale any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GoogleOAuth", reflect.TypeOf((*MockUserFacade)(nil).GoogleOAuth), ctx, oauthID, email, locale)
+}
+
+// ListPasskeys mocks base method.
+func (m *MockUserFacade) ListPasskeys(ctx context.Context, userID string) ([]model.Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPasskeys", ctx, userID)
+	ret0, _ := ret[0].([]model.Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPasskeys indicates an expected call of ListPasskeys.
+func (mr *MockUserFacadeMockRecorder) ListPasskeys(ctx, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GoogleOAuth", reflect.TypeOf((*MockUserFacade)(nil).GoogleOAuth), ctx, oauthID, email)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPasskeys", reflect.TypeOf((*MockUserFacade)(nil).ListPasskeys), ctx, userID)
 }
 
 // RefreshTokens mocks base method.
@@ -142,6 +428,49 @@ func (mr *MockUserFacadeMockRecorder) RefreshTokens(ctx, refreshTokenStr any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTokens", reflect.TypeOf((*MockUserFacade)(nil).RefreshTokens), ctx, refreshTokenStr)
 }
 
+// RegenerateRecoveryCodes mocks base method.
+func (m *MockUserFacade) RegenerateRecoveryCodes(ctx context.Context, userID, password string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegenerateRecoveryCodes", ctx, userID, password)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegenerateRecoveryCodes indicates an expected call of RegenerateRecoveryCodes.
+func (mr *MockUserFacadeMockRecorder) RegenerateRecoveryCodes(ctx, userID, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegenerateRecoveryCodes", reflect.TypeOf((*MockUserFacade)(nil).RegenerateRecoveryCodes), ctx, userID, password)
+}
+
+// RequestEmailChange mocks base method.
+func (m *MockUserFacade) RequestEmailChange(ctx context.Context, userID, newEmail, password, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmailChange", ctx, userID, newEmail, password, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestEmailChange indicates an expected call of RequestEmailChange.
+func (mr *MockUserFacadeMockRecorder) RequestEmailChange(ctx, userID, newEmail, password, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmailChange", reflect.TypeOf((*MockUserFacade)(nil).RequestEmailChange), ctx, userID, newEmail, password, code)
+}
+
+// RequestEmailSignIn mocks base method.
+func (m *MockUserFacade) RequestEmailSignIn(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmailSignIn", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestEmailSignIn indicates an expected call of RequestEmailSignIn.
+func (mr *MockUserFacadeMockRecorder) RequestEmailSignIn(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmailSignIn", reflect.TypeOf((*MockUserFacade)(nil).RequestEmailSignIn), ctx, email)
+}
+
 // ResendVerificationEmail mocks base method.
 func (m *MockUserFacade) ResendVerificationEmail(ctx context.Context, userID string) error {
 	m.ctrl.T.Helper()
@@ -156,6 +485,20 @@ func (mr *MockUserFacadeMockRecorder) ResendVerificationEmail(ctx, userID any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendVerificationEmail", reflect.TypeOf((*MockUserFacade)(nil).ResendVerificationEmail), ctx, userID)
 }
 
+// ResubscribeUserEmail mocks base method.
+func (m *MockUserFacade) ResubscribeUserEmail(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResubscribeUserEmail", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResubscribeUserEmail indicates an expected call of ResubscribeUserEmail.
+func (mr *MockUserFacadeMockRecorder) ResubscribeUserEmail(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResubscribeUserEmail", reflect.TypeOf((*MockUserFacade)(nil).ResubscribeUserEmail), ctx, userID)
+}
+
 // RevokeRefreshToken mocks base method.
 func (m *MockUserFacade) RevokeRefreshToken(ctx context.Context, refreshTokenStr string) error {
 	m.ctrl.T.Helper()
@@ -171,33 +514,49 @@ func (mr *MockUserFacadeMockRecorder) RevokeRefreshToken(ctx, refreshTokenStr an
 }
 
 // SignIn mocks base method.
-func (m *MockUserFacade) SignIn(ctx context.Context, username, password string) (model.User, error) {
+func (m *MockUserFacade) SignIn(ctx context.Context, username, password, clientIP string) (model.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignIn", ctx, username, password)
+	ret := m.ctrl.Call(m, "SignIn", ctx, username, password, clientIP)
 	ret0, _ := ret[0].(model.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SignIn indicates an expected call of SignIn.
-func (mr *MockUserFacadeMockRecorder) SignIn(ctx, username, password any) *gomock.Call {
+func (mr *MockUserFacadeMockRecorder) SignIn(ctx, username, password, clientIP any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockUserFacade)(nil).SignIn), ctx, username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockUserFacade)(nil).SignIn), ctx, username, password, clientIP)
 }
 
 // SignUp mocks base method.
-func (m *MockUserFacade) SignUp(ctx context.Context, username, displayName, email, password string, isPublisher bool) (model.User, error) {
+func (m *MockUserFacade) SignUp(ctx context.Context, username, displayName, email, password, locale string, isPublisher bool) (model.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignUp", ctx, username, displayName, email, password, isPublisher)
+	ret := m.ctrl.Call(m, "SignUp", ctx, username, displayName, email, password, locale, isPublisher)
 	ret0, _ := ret[0].(model.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SignUp indicates an expected call of SignUp.
-func (mr *MockUserFacadeMockRecorder) SignUp(ctx, username, displayName, email, password, isPublisher any) *gomock.Call {
+func (mr *MockUserFacadeMockRecorder) SignUp(ctx, username, displayName, email, password, locale, isPublisher any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockUserFacade)(nil).SignUp), ctx, username, displayName, email, password, isPublisher)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockUserFacade)(nil).SignUp), ctx, username, displayName, email, password, locale, isPublisher)
+}
+
+// StartTwoFactorChallenge mocks base method.
+func (m *MockUserFacade) StartTwoFactorChallenge(ctx context.Context, userID string) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartTwoFactorChallenge", ctx, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// StartTwoFactorChallenge indicates an expected call of StartTwoFactorChallenge.
+func (mr *MockUserFacadeMockRecorder) StartTwoFactorChallenge(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartTwoFactorChallenge", reflect.TypeOf((*MockUserFacade)(nil).StartTwoFactorChallenge), ctx, userID)
 }
 
 // UpdateUserProfile mocks base method.
@@ -244,3 +603,18 @@ func (mr *MockUserFacadeMockRecorder) VerifyEmail(ctx, userID, code any) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserFacade)(nil).VerifyEmail), ctx, userID, code)
 }
+
+// VerifyEmailWithLink mocks base method.
+func (m *MockUserFacade) VerifyEmailWithLink(ctx context.Context, token string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmailWithLink", ctx, token)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmailWithLink indicates an expected call of VerifyEmailWithLink.
+func (mr *MockUserFacadeMockRecorder) VerifyEmailWithLink(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmailWithLink", reflect.TypeOf((*MockUserFacade)(nil).VerifyEmailWithLink), ctx, token)
+}
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	model "github.com/OutOfStack/game-library-auth/internal/model"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,19 +41,34 @@ func (m *MockUnsubscribeFacade) EXPECT() *MockUnsubscribeFacadeMockRecorder {
 	return m.recorder
 }
 
-// IsEmailUnsubscribed mocks base method.
-func (m *MockUnsubscribeFacade) IsEmailUnsubscribed(ctx context.Context, email string) (bool, error) {
+// GetEmailPreferences mocks base method.
+func (m *MockUnsubscribeFacade) GetEmailPreferences(ctx context.Context, email string) ([]model.EmailPreference, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsEmailUnsubscribed", ctx, email)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "GetEmailPreferences", ctx, email)
+	ret0, _ := ret[0].([]model.EmailPreference)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// IsEmailUnsubscribed indicates an expected call of IsEmailUnsubscribed.
-func (mr *MockUnsubscribeFacadeMockRecorder) IsEmailUnsubscribed(ctx, email any) *gomock.Call {
+// GetEmailPreferences indicates an expected call of GetEmailPreferences.
+func (mr *MockUnsubscribeFacadeMockRecorder) GetEmailPreferences(ctx, email any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEmailUnsubscribed", reflect.TypeOf((*MockUnsubscribeFacade)(nil).IsEmailUnsubscribed), ctx, email)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailPreferences", reflect.TypeOf((*MockUnsubscribeFacade)(nil).GetEmailPreferences), ctx, email)
+}
+
+// ResubscribeEmail mocks base method.
+func (m *MockUnsubscribeFacade) ResubscribeEmail(ctx context.Context, token string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResubscribeEmail", ctx, token)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResubscribeEmail indicates an expected call of ResubscribeEmail.
+func (mr *MockUnsubscribeFacadeMockRecorder) ResubscribeEmail(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResubscribeEmail", reflect.TypeOf((*MockUnsubscribeFacade)(nil).ResubscribeEmail), ctx, token)
 }
 
 // UnsubscribeEmail mocks base method.
@@ -69,3 +85,18 @@ func (mr *MockUnsubscribeFacadeMockRecorder) UnsubscribeEmail(ctx, token any) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsubscribeEmail", reflect.TypeOf((*MockUnsubscribeFacade)(nil).UnsubscribeEmail), ctx, token)
 }
+
+// UpdateEmailPreferences mocks base method.
+func (m *MockUnsubscribeFacade) UpdateEmailPreferences(ctx context.Context, token string, subscribed []string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmailPreferences", ctx, token, subscribed)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateEmailPreferences indicates an expected call of UpdateEmailPreferences.
+func (mr *MockUnsubscribeFacadeMockRecorder) UpdateEmailPreferences(ctx, token, subscribed any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmailPreferences", reflect.TypeOf((*MockUnsubscribeFacade)(nil).UpdateEmailPreferences), ctx, token, subscribed)
+}
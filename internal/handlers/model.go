@@ -1,11 +1,28 @@
 package handlers
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
 const (
-	internalErrorMsg           = "Internal error"
-	validationErrorMsg         = "Validation error"
-	authErrorMsg               = "Incorrect username or password"
-	invalidAuthTokenMsg        = "Invalid or missing authorization token"
-	invalidOrExpiredVrfCodeMsg = "Invalid or expired verification code"
+	internalErrorMsg             = "Internal error"
+	validationErrorMsg           = "Validation error"
+	authErrorMsg                 = "Incorrect username or password"
+	invalidAuthTokenMsg          = "Invalid or missing authorization token"
+	invalidOrExpiredVrfCodeMsg   = "Invalid or expired verification code"
+	invalidOrExpiredVrfLinkMsg   = "Invalid or expired verification link. Please request a new verification email"
+	tooManySignInAttemptsMsg     = "Too many failed sign in attempts. Please try again later"
+	tooManyRequestsMsg           = "Too many requests. Please try again later"
+	invalidTwoFactorCodeMsg      = "Invalid two-factor authentication code"
+	invalidPasskeySessionMsg     = "Invalid or expired passkey session"
+	passkeyVerificationFailedMsg = "Passkey verification failed"
+	invalidPasskeyCredentialMsg  = "Invalid passkey credential"
+	passkeyNotFoundMsg           = "Passkey not found"
+	invalidOrExpiredSignInMsg    = "Invalid or expired sign in code"
+	emailDelayedMsg              = "Email delivery is delayed. Please try again later"
 
 	refreshTokenCookieName = "refresh_token"
 )
@@ -21,6 +38,110 @@ type TokenResp struct {
 	AccessToken string `json:"accessToken"`
 }
 
+// SignUpResp represents response to sign up. Verification email delayed is set if verification email
+// can't be sent right away, it is sent later and can be requested again after Retry-After of resend verification
+type SignUpResp struct {
+	AccessToken string `json:"accessToken"`
+	// Best-effort hint, set only if the instance handling sign up has seen exhausted email quota.
+	// Verification email may be delayed even if it is not set
+	VerificationEmailDelayed bool `json:"verificationEmailDelayed,omitempty"`
+}
+
+// TwoFactorChallengeResp represents response to sign in of user with two-factor authentication enabled.
+// Challenge token has to be exchanged for access token with a second factor code
+type TwoFactorChallengeResp struct {
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken"`
+}
+
+// SignInTwoFactorReq represents second step of sign in with two-factor authentication.
+// Code is either a 6-digit code from authenticator app or a recovery code
+type SignInTwoFactorReq struct {
+	ChallengeToken string `json:"challengeToken" validate:"required,jwt"`
+	Code           string `json:"code" validate:"required,min=6,max=16"`
+}
+
+// EmailSignInReq represents request to send passwordless sign in code and link to email
+type EmailSignInReq struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// VerifyEmailSignInReq represents passwordless sign in request.
+// Either email with 6-digit code or token from sign in link is required
+type VerifyEmailSignInReq struct {
+	Email string `json:"email" validate:"required_without=Token,excluded_with=Token,omitempty,email"`
+	Code  string `json:"code" validate:"required_with=Email,excluded_with=Token,omitempty,len=6,numeric"`
+	Token string `json:"token" validate:"required_without=Email,omitempty,max=64"`
+}
+
+// TOTPEnrollmentResp represents TOTP enrollment response
+type TOTPEnrollmentResp struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// ConfirmTOTPReq represents TOTP enrollment confirmation request
+type ConfirmTOTPReq struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// DisableTOTPReq represents request to disable TOTP two-factor authentication.
+// Code is either a 6-digit code from authenticator app or a recovery code
+type DisableTOTPReq struct {
+	Password string `json:"password" validate:"required,min=8,max=64"`
+	Code     string `json:"code" validate:"required,min=6,max=16"`
+}
+
+// RecoveryCodesResp represents response with single-use recovery codes. Codes are shown only once
+type RecoveryCodesResp struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// RegenerateRecoveryCodesReq represents request to regenerate recovery codes
+type RegenerateRecoveryCodesReq struct {
+	Password string `json:"password" validate:"required,min=8,max=64"`
+}
+
+// PasskeyRegistrationOptionsResp represents options for passkey registration ceremony.
+// Options are passed to navigator.credentials.create() and session id has to be sent back with authenticator response
+type PasskeyRegistrationOptionsResp struct {
+	SessionID string                       `json:"sessionId"`
+	Options   *protocol.CredentialCreation `json:"options" swaggertype:"object"`
+}
+
+// FinishPasskeyRegistrationReq represents authenticator response for passkey registration ceremony
+type FinishPasskeyRegistrationReq struct {
+	SessionID  string          `json:"sessionId" validate:"required,uuid"`
+	Name       string          `json:"name" validate:"max=64"`
+	Credential json.RawMessage `json:"credential" validate:"required" swaggertype:"object"`
+}
+
+// PasskeySignInOptionsResp represents options for passkey sign in ceremony.
+// Options are passed to navigator.credentials.get() and session id has to be sent back with authenticator response
+type PasskeySignInOptionsResp struct {
+	SessionID string                        `json:"sessionId"`
+	Options   *protocol.CredentialAssertion `json:"options" swaggertype:"object"`
+}
+
+// FinishPasskeySignInReq represents authenticator response for passkey sign in ceremony
+type FinishPasskeySignInReq struct {
+	SessionID  string          `json:"sessionId" validate:"required,uuid"`
+	Credential json.RawMessage `json:"credential" validate:"required" swaggertype:"object"`
+}
+
+// PasskeyResp represents registered passkey
+type PasskeyResp struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	DateCreated time.Time  `json:"dateCreated"`
+	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// PasskeysResp represents list of registered passkeys
+type PasskeysResp struct {
+	Passkeys []PasskeyResp `json:"passkeys"`
+}
+
 // SignUpReq represents user sign up request
 type SignUpReq struct {
 	Username        string `json:"username" validate:"required,min=4,usernameregex"`
@@ -37,6 +158,7 @@ type UpdateProfileReq struct {
 	Password           *string `json:"password" validate:"omitempty,min=8,max=64"`
 	NewPassword        *string `json:"newPassword" validate:"omitempty,min=8,max=64"`
 	ConfirmNewPassword *string `json:"confirmNewPassword" validate:"omitempty,min=8,max=64"`
+	Locale             *string `json:"locale" validate:"omitempty,max=35,bcp47_language_tag"`
 }
 
 // VerifyTokenReq represents verify JWT request
@@ -49,9 +171,69 @@ type VerifyTokenResp struct {
 	Valid bool `json:"valid"`
 }
 
-// VerifyEmailReq represents email verification request with 6-digit code
+// VerifyEmailReq represents email verification request with code sent by email.
+// Code length and alphabet are set by email verification policy
 type VerifyEmailReq struct {
-	Code string `json:"code" validate:"required,len=6"`
+	Code string `json:"code" validate:"required,min=4,max=12,alphanum"`
+}
+
+// ProfileResp represents user profile
+type ProfileResp struct {
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	Name          string `json:"name"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"emailVerified"`
+	Locale        string `json:"locale"`
+	Role          string `json:"role"`
+}
+
+// AddEmailReq represents request to add email address to user who doesn't have one
+type AddEmailReq struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ChangeEmailReq represents request to change user email address. Code confirming the change is sent to the new address.
+// Current password is required. Code is required if two-factor authentication is enabled, it is either a 6-digit code
+// from authenticator app or a recovery code
+type ChangeEmailReq struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8,max=64"`
+	Code     string `json:"code" validate:"omitempty,min=6,max=16"`
+}
+
+// ConfirmEmailChangeReq represents email change confirmation request with code sent to the new address
+type ConfirmEmailChangeReq struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// EmailStatusResp represents delivery status of user email address.
+// Delivery status is one of delivered, bounced, complained and is empty if provider reported no events yet
+type EmailStatusResp struct {
+	Email             string     `json:"email"`
+	DeliveryStatus    string     `json:"deliveryStatus,omitempty"`
+	DeliveryStatusAt  *time.Time `json:"deliveryStatusAt,omitempty"`
+	Unsubscribed      bool       `json:"unsubscribed"`
+	UnsubscribeReason string     `json:"unsubscribeReason,omitempty"`
+}
+
+// EmailHistoryResp represents history of emails sent to user
+type EmailHistoryResp struct {
+	Messages []EmailMessageResp `json:"messages"`
+}
+
+// EmailMessageResp represents send attempt of email.
+// Status is one of sent, failed or delivery status reported by email provider: delivered, bounced, complained
+type EmailMessageResp struct {
+	ID                string    `json:"id"`
+	Template          string    `json:"template"`
+	Recipient         string    `json:"recipient"`
+	Provider          string    `json:"provider,omitempty"`
+	ProviderMessageID string    `json:"providerMessageId,omitempty"`
+	Status            string    `json:"status"`
+	Error             string    `json:"error,omitempty"`
+	DateCreated       time.Time `json:"dateCreated"`
+	DateUpdated       time.Time `json:"dateUpdated"`
 }
 
 // GoogleOAuthRequest represents Google OAuth request
@@ -44,8 +44,8 @@ func (a *AuthAPI) GoogleOAuthHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// sign in or sign up
-	user, err := a.userFacade.GoogleOAuth(ctx, googleClaims.Sub, googleClaims.Email)
+	// sign in or sign up. Locale is stored for new users only
+	user, err := a.userFacade.GoogleOAuth(ctx, googleClaims.Sub, googleClaims.Email, getLocale(c))
 	if err != nil {
 		switch {
 		case errors.Is(err, facade.ErrInvalidEmail):
@@ -64,7 +64,7 @@ func (a *AuthAPI) GoogleOAuthHandler(c *fiber.Ctx) error {
 	}
 
 	// create tokens
-	tokens, err := a.userFacade.CreateTokens(ctx, user)
+	tokens, err := a.userFacade.CreateSignInTokens(ctx, user, getClientInfo(c))
 	if err != nil {
 		a.log.Error("creating tokens", zap.Error(err))
 		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
@@ -96,11 +96,11 @@ func TestGoogleOAuthHandler_Success(t *testing.T) {
 		// Mock facade Google OAuth
 		u := model.User{ID: "uid-1", Username: "test", Email: "test@example.com", OAuthProvider: "google", OAuthID: "google-sub-id"}
 		mockUserFacade.EXPECT().
-			GoogleOAuth(gomock.Any(), "google-sub-id", "test@example.com").
+			GoogleOAuth(gomock.Any(), "google-sub-id", "test@example.com", "es-MX").
 			Return(u, nil)
 
 		mockUserFacade.EXPECT().
-			CreateTokens(gomock.Any(), gomock.Any()).
+			CreateSignInTokens(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(facade.TokenPair{
 				AccessToken:  "test-jwt-token",
 				RefreshToken: facade.RefreshToken{Token: "refresh-token"},
@@ -113,6 +113,7 @@ func TestGoogleOAuthHandler_Success(t *testing.T) {
 
 		req := httptest.NewRequest(http.MethodPost, "/oauth/google", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", "es-MX,es;q=0.9,en;q=0.8")
 
 		resp, err := app.Test(req)
 		require.NoError(t, err)
@@ -148,11 +149,11 @@ func TestGoogleOAuthHandler_Success(t *testing.T) {
 
 		// Mock facade - user found
 		mockUserFacade.EXPECT().
-			GoogleOAuth(gomock.Any(), "google-sub-id", "existing@example.com").
+			GoogleOAuth(gomock.Any(), "google-sub-id", "existing@example.com", "").
 			Return(u, nil)
 
 		mockUserFacade.EXPECT().
-			CreateTokens(gomock.Any(), gomock.Any()).
+			CreateSignInTokens(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(facade.TokenPair{
 				AccessToken:  "test-jwt-token",
 				RefreshToken: facade.RefreshToken{Token: "refresh-token"},
@@ -198,7 +199,7 @@ func TestGoogleOAuthHandler_Success(t *testing.T) {
 
 		// Facade returns name conflict
 		mockUserFacade.EXPECT().
-			GoogleOAuth(gomock.Any(), "new-google-sub-id", "conflict@example.com").
+			GoogleOAuth(gomock.Any(), "new-google-sub-id", "conflict@example.com", "").
 			Return(model.User{}, facade.ErrOAuthSignInConflict)
 
 		reqBody := handlers.GoogleOAuthRequest{
@@ -264,7 +265,7 @@ func TestGoogleOAuthHandler_Success(t *testing.T) {
 
 		// Mock facade returns invalid email error
 		mockUserFacade.EXPECT().
-			GoogleOAuth(gomock.Any(), "google-sub-id", "invalid-email").
+			GoogleOAuth(gomock.Any(), "google-sub-id", "invalid-email", "").
 			Return(model.User{}, facade.ErrInvalidEmail)
 
 		reqBody := handlers.GoogleOAuthRequest{
@@ -309,7 +310,7 @@ func TestGoogleOAuthHandler_Success(t *testing.T) {
 
 		// Mock facade error
 		mockUserFacade.EXPECT().
-			GoogleOAuth(gomock.Any(), "google-sub-id", "test@example.com").
+			GoogleOAuth(gomock.Any(), "google-sub-id", "test@example.com", "").
 			Return(model.User{}, errors.New("database connection failed"))
 
 		reqBody := handlers.GoogleOAuthRequest{
@@ -345,12 +346,12 @@ func TestGoogleOAuthHandler_Success(t *testing.T) {
 			Return(mockPayload, nil)
 
 		mockUserFacade.EXPECT().
-			GoogleOAuth(gomock.Any(), "google-sub-id", "existing@example.com").
+			GoogleOAuth(gomock.Any(), "google-sub-id", "existing@example.com", "").
 			Return(u2, nil)
 
 		// Mock token generation failure
 		mockUserFacade.EXPECT().
-			CreateTokens(gomock.Any(), gomock.Any()).
+			CreateSignInTokens(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(facade.TokenPair{}, errors.New("token generation failed"))
 
 		reqBody := handlers.GoogleOAuthRequest{
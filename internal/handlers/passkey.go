@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// BeginPasskeyRegistrationHandler godoc
+// @Summary      Begin passkey registration
+// @Description  Starts passkey registration ceremony. Returned options are passed to navigator.credentials.create()
+// @Tags         auth
+// @Security     Bearer
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} PasskeyRegistrationOptionsResp
+// @Failure      401 {object} web.ErrResp "Invalid or missing authorization token"
+// @Failure      404 {object} web.ErrResp "User not found"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /account/passkeys/register/begin [post]
+func (a *AuthAPI) BeginPasskeyRegistrationHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "beginPasskeyRegistration")
+	defer span.End()
+
+	userID, err := a.getUserIDFromJWT(c)
+	if err != nil {
+		a.log.Error("extracting user ID from JWT", zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: invalidAuthTokenMsg,
+		})
+	}
+
+	log := a.log.With(zap.String("userId", userID))
+
+	options, sessionID, err := a.userFacade.BeginPasskeyRegistration(ctx, userID)
+	if err != nil {
+		if errors.Is(err, facade.ErrPasskeyUserNotFound) {
+			return c.Status(http.StatusNotFound).JSON(web.ErrResp{
+				Error: "User not found",
+			})
+		}
+		log.Error("begin passkey registration", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+
+	return c.JSON(PasskeyRegistrationOptionsResp{
+		SessionID: sessionID,
+		Options:   options,
+	})
+}
+
+// FinishPasskeyRegistrationHandler godoc
+// @Summary      Finish passkey registration
+// @Description  Verifies authenticator response to passkey registration ceremony and stores new passkey
+// @Tags         auth
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        params body FinishPasskeyRegistrationReq true "Session id, passkey name and authenticator response"
+// @Success      201 {object} PasskeyResp
+// @Failure      400 {object} web.ErrResp "Invalid session or passkey verification failed"
+// @Failure      401 {object} web.ErrResp "Invalid or missing authorization token"
+// @Failure      404 {object} web.ErrResp "User not found"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /account/passkeys/register/finish [post]
+func (a *AuthAPI) FinishPasskeyRegistrationHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "finishPasskeyRegistration")
+	defer span.End()
+
+	userID, err := a.getUserIDFromJWT(c)
+	if err != nil {
+		a.log.Error("extracting user ID from JWT", zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: invalidAuthTokenMsg,
+		})
+	}
+
+	var req FinishPasskeyRegistrationReq
+	if err = c.BodyParser(&req); err != nil {
+		a.log.Error("parsing data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: "Error parsing data",
+		})
+	}
+
+	log := a.log.With(zap.String("userId", userID))
+
+	if fields, vErr := web.Validate(req); vErr != nil {
+		log.Info("validating finish passkey registration data", zap.Error(vErr))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error:  validationErrorMsg,
+			Fields: fields,
+		})
+	}
+
+	response, err := protocol.ParseCredentialCreationResponseBytes(req.Credential)
+	if err != nil {
+		log.Info("parsing passkey registration response", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: invalidPasskeyCredentialMsg,
+		})
+	}
+
+	passkey, err := a.userFacade.FinishPasskeyRegistration(ctx, userID, req.SessionID, req.Name, response)
+	if err != nil {
+		switch {
+		case errors.Is(err, facade.ErrPasskeyUserNotFound):
+			return c.Status(http.StatusNotFound).JSON(web.ErrResp{
+				Error: "User not found",
+			})
+		case errors.Is(err, facade.ErrPasskeyInvalidSession):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: invalidPasskeySessionMsg,
+			})
+		case errors.Is(err, facade.ErrPasskeyVerificationFailed):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: passkeyVerificationFailedMsg,
+			})
+		default:
+			log.Error("finish passkey registration", zap.Error(err))
+			return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+				Error: internalErrorMsg,
+			})
+		}
+	}
+
+	return c.Status(http.StatusCreated).JSON(mapPasskeyToResp(passkey))
+}
+
+// ListPasskeysHandler godoc
+// @Summary      List passkeys
+// @Description  Returns passkeys registered by user
+// @Tags         auth
+// @Security     Bearer
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} PasskeysResp
+// @Failure      401 {object} web.ErrResp "Invalid or missing authorization token"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /account/passkeys [get]
+func (a *AuthAPI) ListPasskeysHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "listPasskeys")
+	defer span.End()
+
+	userID, err := a.getUserIDFromJWT(c)
+	if err != nil {
+		a.log.Error("extracting user ID from JWT", zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: invalidAuthTokenMsg,
+		})
+	}
+
+	passkeys, err := a.userFacade.ListPasskeys(ctx, userID)
+	if err != nil {
+		a.log.Error("list passkeys", zap.String("userId", userID), zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+
+	resp := PasskeysResp{
+		Passkeys: make([]PasskeyResp, 0, len(passkeys)),
+	}
+	for _, passkey := range passkeys {
+		resp.Passkeys = append(resp.Passkeys, mapPasskeyToResp(passkey))
+	}
+
+	return c.JSON(resp)
+}
+
+// DeletePasskeyHandler godoc
+// @Summary      Delete passkey
+// @Description  Deletes passkey registered by user
+// @Tags         auth
+// @Security     Bearer
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        id path string true "Passkey id"
+// @Success      204
+// @Failure      401 {object} web.ErrResp "Invalid or missing authorization token"
+// @Failure      404 {object} web.ErrResp "Passkey not found"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /account/passkeys/{id} [delete]
+func (a *AuthAPI) DeletePasskeyHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "deletePasskey")
+	defer span.End()
+
+	userID, err := a.getUserIDFromJWT(c)
+	if err != nil {
+		a.log.Error("extracting user ID from JWT", zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: invalidAuthTokenMsg,
+		})
+	}
+
+	passkeyID := c.Params("id")
+	if _, err = uuid.Parse(passkeyID); err != nil {
+		return c.Status(http.StatusNotFound).JSON(web.ErrResp{
+			Error: passkeyNotFoundMsg,
+		})
+	}
+
+	if err = a.userFacade.DeletePasskey(ctx, userID, passkeyID); err != nil {
+		if errors.Is(err, facade.ErrPasskeyNotFound) {
+			return c.Status(http.StatusNotFound).JSON(web.ErrResp{
+				Error: passkeyNotFoundMsg,
+			})
+		}
+		a.log.Error("delete passkey", zap.String("userId", userID), zap.String("passkeyId", passkeyID), zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// BeginPasskeySignInHandler godoc
+// @Summary      Begin sign in with passkey
+// @Description  Starts passkey sign in ceremony. Returned options are passed to navigator.credentials.get()
+// @Tags         auth
+// @Produce      json
+// @Success      200 {object} PasskeySignInOptionsResp
+// @Failure      429 {object} web.ErrResp "Too many requests"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /signin/passkey/begin [post]
+func (a *AuthAPI) BeginPasskeySignInHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "beginPasskeySignIn")
+	defer span.End()
+
+	options, sessionID, err := a.userFacade.BeginPasskeySignIn(ctx)
+	if err != nil {
+		a.log.Error("begin passkey sign in", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+
+	return c.JSON(PasskeySignInOptionsResp{
+		SessionID: sessionID,
+		Options:   options,
+	})
+}
+
+// FinishPasskeySignInHandler godoc
+// @Summary      Finish sign in with passkey
+// @Description  Verifies authenticator response to passkey sign in ceremony and returns an access token
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        signin body FinishPasskeySignInReq true "Session id and authenticator response"
+// @Success      200 {object} TokenResp
+// @Failure      400 {object} web.ErrResp
+// @Failure      401 {object} web.ErrResp "Invalid session or passkey verification failed"
+// @Failure      429 {object} web.ErrResp "Too many requests"
+// @Failure      500 {object} web.ErrResp
+// @Router       /signin/passkey/finish [post]
+func (a *AuthAPI) FinishPasskeySignInHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "finishPasskeySignIn")
+	defer span.End()
+
+	var req FinishPasskeySignInReq
+	if err := c.BodyParser(&req); err != nil {
+		a.log.Error("parsing data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: "Error parsing data",
+		})
+	}
+
+	if fields, err := web.Validate(req); err != nil {
+		a.log.Info("validating passkey sign in data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error:  validationErrorMsg,
+			Fields: fields,
+		})
+	}
+
+	response, err := protocol.ParseCredentialRequestResponseBytes(req.Credential)
+	if err != nil {
+		a.log.Info("parsing passkey sign in response", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: invalidPasskeyCredentialMsg,
+		})
+	}
+
+	user, err := a.userFacade.FinishPasskeySignIn(ctx, req.SessionID, response)
+	if err != nil {
+		switch {
+		case errors.Is(err, facade.ErrPasskeyInvalidSession):
+			return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+				Error: invalidPasskeySessionMsg,
+			})
+		case errors.Is(err, facade.ErrPasskeyVerificationFailed):
+			return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+				Error: passkeyVerificationFailedMsg,
+			})
+		default:
+			a.log.Error("finish passkey sign in", zap.Error(err))
+			return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+				Error: internalErrorMsg,
+			})
+		}
+	}
+
+	log := a.log.With(zap.String("userId", user.ID))
+
+	// create tokens
+	tokens, err := a.userFacade.CreateSignInTokens(ctx, user, getClientInfo(c))
+	if err != nil {
+		log.Error("creating tokens", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+
+	// set refresh token as a cookie
+	a.setRefreshTokenCookie(c, tokens.RefreshToken)
+
+	return c.JSON(TokenResp{
+		AccessToken: tokens.AccessToken,
+	})
+}
+
+func mapPasskeyToResp(passkey model.Passkey) PasskeyResp {
+	return PasskeyResp{
+		ID:          passkey.ID,
+		Name:        passkey.Name,
+		DateCreated: passkey.DateCreated,
+		LastUsedAt:  passkey.LastUsedAt,
+	}
+}
@@ -0,0 +1,755 @@
+package handlers_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	auth_ "github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/handlers"
+	mocks "github.com/OutOfStack/game-library-auth/internal/handlers/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// testPasskeyCredentials returns well-formed authenticator responses for passkey registration and sign in
+func testPasskeyCredentials(t *testing.T) (registration, signIn json.RawMessage) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	credentialID := []byte("test-credential")
+	rpIDHash := sha256.Sum256([]byte("localhost"))
+
+	clientData := func(ceremony protocol.CeremonyType) string {
+		data, mErr := json.Marshal(map[string]string{
+			"type":      string(ceremony),
+			"challenge": base64.RawURLEncoding.EncodeToString([]byte("challenge")),
+			"origin":    "http://localhost:3000",
+		})
+		require.NoError(t, mErr)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: key.X.FillBytes(make([]byte, 32)),
+		YCoord: key.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+
+	// rp id hash, user present, user verified and attested credential data flags, sign count
+	authData := append(rpIDHash[:], 0x45)
+	authData = binary.BigEndian.AppendUint32(authData, 0)
+	authData = append(authData, make([]byte, 16)...) // zero aaguid
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(credentialID)))
+	authData = append(authData, credentialID...)
+	authData = append(authData, publicKey...)
+	attestationObject, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	require.NoError(t, err)
+
+	registration, err = json.Marshal(map[string]any{
+		"id":    base64.RawURLEncoding.EncodeToString(credentialID),
+		"rawId": base64.RawURLEncoding.EncodeToString(credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    clientData(protocol.CreateCeremony),
+			"attestationObject": base64.RawURLEncoding.EncodeToString(attestationObject),
+		},
+	})
+	require.NoError(t, err)
+
+	// rp id hash, user present and user verified flags, sign count
+	assertionAuthData := append(rpIDHash[:], 0x05)
+	assertionAuthData = binary.BigEndian.AppendUint32(assertionAuthData, 1)
+	signIn, err = json.Marshal(map[string]any{
+		"id":    base64.RawURLEncoding.EncodeToString(credentialID),
+		"rawId": base64.RawURLEncoding.EncodeToString(credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    clientData(protocol.AssertCeremony),
+			"authenticatorData": base64.RawURLEncoding.EncodeToString(assertionAuthData),
+			"signature":         base64.RawURLEncoding.EncodeToString([]byte("signature")),
+			"userHandle":        base64.RawURLEncoding.EncodeToString([]byte(uuid.New().String())),
+		},
+	})
+	require.NoError(t, err)
+
+	return registration, signIn
+}
+
+func TestBeginPasskeyRegistrationHandler(t *testing.T) {
+	userID := uuid.New().String()
+	sessionID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:       "successful begin",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(auth_.Claims{UserID: userID}, nil)
+				mockUserFacade.EXPECT().
+					BeginPasskeyRegistration(gomock.Any(), userID).
+					Return(&protocol.CredentialCreation{}, sessionID, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.PasskeyRegistrationOptionsResp{
+				SessionID: sessionID,
+			},
+		},
+		{
+			name:           "missing token",
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp: web.ErrResp{
+				Error: "Invalid or missing authorization token",
+			},
+		},
+		{
+			name:       "user not found",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(auth_.Claims{UserID: userID}, nil)
+				mockUserFacade.EXPECT().
+					BeginPasskeyRegistration(gomock.Any(), userID).
+					Return(nil, "", facade.ErrPasskeyUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp: web.ErrResp{
+				Error: "User not found",
+			},
+		},
+		{
+			name:       "facade error",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(auth_.Claims{UserID: userID}, nil)
+				mockUserFacade.EXPECT().
+					BeginPasskeyRegistration(gomock.Any(), userID).
+					Return(nil, "", errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp: web.ErrResp{
+				Error: internalErrorMsg,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/account/passkeys/register/begin", authAPI.BeginPasskeyRegistrationHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/account/passkeys/register/begin", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			switch v := tt.expectedResp.(type) {
+			case handlers.PasskeyRegistrationOptionsResp:
+				var actual handlers.PasskeyRegistrationOptionsResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.SessionID, actual.SessionID)
+				assert.NotNil(t, actual.Options)
+			case web.ErrResp:
+				var actual web.ErrResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.Error, actual.Error)
+			}
+		})
+	}
+}
+
+func TestFinishPasskeyRegistrationHandler(t *testing.T) {
+	userID := uuid.New().String()
+	sessionID := uuid.New().String()
+	passkeyID := uuid.New().String()
+	dateCreated := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	credential, _ := testPasskeyCredentials(t)
+
+	tests := []struct {
+		name           string
+		request        handlers.FinishPasskeyRegistrationReq
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name: "successful registration",
+			request: handlers.FinishPasskeyRegistrationReq{
+				SessionID:  sessionID,
+				Name:       "Laptop",
+				Credential: credential,
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					FinishPasskeyRegistration(gomock.Any(), userID, sessionID, "Laptop", gomock.Any()).
+					Return(model.Passkey{ID: passkeyID, Name: "Laptop", DateCreated: dateCreated}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedResp: handlers.PasskeyResp{
+				ID:          passkeyID,
+				Name:        "Laptop",
+				DateCreated: dateCreated,
+			},
+		},
+		{
+			name: "invalid session id",
+			request: handlers.FinishPasskeyRegistrationReq{
+				SessionID:  "invalid",
+				Credential: credential,
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: "Validation error",
+			},
+		},
+		{
+			name: "malformed credential",
+			request: handlers.FinishPasskeyRegistrationReq{
+				SessionID:  sessionID,
+				Credential: json.RawMessage(`{}`),
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: "Invalid passkey credential",
+			},
+		},
+		{
+			name: "expired session",
+			request: handlers.FinishPasskeyRegistrationReq{
+				SessionID:  sessionID,
+				Credential: credential,
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					FinishPasskeyRegistration(gomock.Any(), userID, sessionID, "", gomock.Any()).
+					Return(model.Passkey{}, facade.ErrPasskeyInvalidSession)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: "Invalid or expired passkey session",
+			},
+		},
+		{
+			name: "verification failed",
+			request: handlers.FinishPasskeyRegistrationReq{
+				SessionID:  sessionID,
+				Credential: credential,
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					FinishPasskeyRegistration(gomock.Any(), userID, sessionID, "", gomock.Any()).
+					Return(model.Passkey{}, facade.ErrPasskeyVerificationFailed)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: "Passkey verification failed",
+			},
+		},
+		{
+			name: "facade error",
+			request: handlers.FinishPasskeyRegistrationReq{
+				SessionID:  sessionID,
+				Credential: credential,
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					FinishPasskeyRegistration(gomock.Any(), userID, sessionID, "", gomock.Any()).
+					Return(model.Passkey{}, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp: web.ErrResp{
+				Error: internalErrorMsg,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			mockUserFacade.EXPECT().
+				ValidateAccessToken("valid-token").
+				Return(auth_.Claims{UserID: userID}, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/account/passkeys/register/finish", authAPI.FinishPasskeyRegistrationHandler)
+
+			reqBody, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/account/passkeys/register/finish", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			switch v := tt.expectedResp.(type) {
+			case handlers.PasskeyResp:
+				var actual handlers.PasskeyResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v, actual)
+			case web.ErrResp:
+				var actual web.ErrResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.Error, actual.Error)
+			}
+		})
+	}
+}
+
+func TestListPasskeysHandler(t *testing.T) {
+	userID := uuid.New().String()
+	passkeyID := uuid.New().String()
+	dateCreated := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	lastUsedAt := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name: "successful list",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ListPasskeys(gomock.Any(), userID).
+					Return([]model.Passkey{{ID: passkeyID, Name: "Laptop", DateCreated: dateCreated, LastUsedAt: &lastUsedAt}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.PasskeysResp{
+				Passkeys: []handlers.PasskeyResp{
+					{ID: passkeyID, Name: "Laptop", DateCreated: dateCreated, LastUsedAt: &lastUsedAt},
+				},
+			},
+		},
+		{
+			name: "no passkeys",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ListPasskeys(gomock.Any(), userID).
+					Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.PasskeysResp{
+				Passkeys: []handlers.PasskeyResp{},
+			},
+		},
+		{
+			name: "facade error",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ListPasskeys(gomock.Any(), userID).
+					Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp: web.ErrResp{
+				Error: internalErrorMsg,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			mockUserFacade.EXPECT().
+				ValidateAccessToken("valid-token").
+				Return(auth_.Claims{UserID: userID}, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Get("/account/passkeys", authAPI.ListPasskeysHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/account/passkeys", nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			switch v := tt.expectedResp.(type) {
+			case handlers.PasskeysResp:
+				var actual handlers.PasskeysResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v, actual)
+			case web.ErrResp:
+				var actual web.ErrResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.Error, actual.Error)
+			}
+		})
+	}
+}
+
+func TestDeletePasskeyHandler(t *testing.T) {
+	userID := uuid.New().String()
+	passkeyID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		passkeyID      string
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:      "successful delete",
+			passkeyID: passkeyID,
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					DeletePasskey(gomock.Any(), userID, passkeyID).
+					Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "invalid passkey id",
+			passkeyID:      "invalid",
+			expectedStatus: http.StatusNotFound,
+			expectedResp: web.ErrResp{
+				Error: "Passkey not found",
+			},
+		},
+		{
+			name:      "passkey not found",
+			passkeyID: passkeyID,
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					DeletePasskey(gomock.Any(), userID, passkeyID).
+					Return(facade.ErrPasskeyNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp: web.ErrResp{
+				Error: "Passkey not found",
+			},
+		},
+		{
+			name:      "facade error",
+			passkeyID: passkeyID,
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					DeletePasskey(gomock.Any(), userID, passkeyID).
+					Return(errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp: web.ErrResp{
+				Error: internalErrorMsg,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			mockUserFacade.EXPECT().
+				ValidateAccessToken("valid-token").
+				Return(auth_.Claims{UserID: userID}, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Delete("/account/passkeys/:id", authAPI.DeletePasskeyHandler)
+
+			req := httptest.NewRequest(http.MethodDelete, "/account/passkeys/"+tt.passkeyID, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if v, ok := tt.expectedResp.(web.ErrResp); ok {
+				var actual web.ErrResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.Error, actual.Error)
+			}
+		})
+	}
+}
+
+func TestBeginPasskeySignInHandler(t *testing.T) {
+	sessionID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name: "successful begin",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					BeginPasskeySignIn(gomock.Any()).
+					Return(&protocol.CredentialAssertion{}, sessionID, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.PasskeySignInOptionsResp{
+				SessionID: sessionID,
+			},
+		},
+		{
+			name: "facade error",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					BeginPasskeySignIn(gomock.Any()).
+					Return(nil, "", errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp: web.ErrResp{
+				Error: internalErrorMsg,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/signin/passkey/begin", authAPI.BeginPasskeySignInHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/signin/passkey/begin", nil)
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			switch v := tt.expectedResp.(type) {
+			case handlers.PasskeySignInOptionsResp:
+				var actual handlers.PasskeySignInOptionsResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.SessionID, actual.SessionID)
+				assert.NotNil(t, actual.Options)
+			case web.ErrResp:
+				var actual web.ErrResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.Error, actual.Error)
+			}
+		})
+	}
+}
+
+func TestFinishPasskeySignInHandler(t *testing.T) {
+	sessionID := uuid.New().String()
+	_, credential := testPasskeyCredentials(t)
+
+	tests := []struct {
+		name           string
+		request        handlers.FinishPasskeySignInReq
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name: "successful sign in",
+			request: handlers.FinishPasskeySignInReq{
+				SessionID:  sessionID,
+				Credential: credential,
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				u := model.User{ID: "uid-1", Username: "testuser"}
+
+				mockUserFacade.EXPECT().
+					FinishPasskeySignIn(gomock.Any(), sessionID, gomock.Any()).
+					Return(u, nil)
+
+				mockUserFacade.EXPECT().
+					CreateSignInTokens(gomock.Any(), u, gomock.Any()).
+					Return(facade.TokenPair{
+						AccessToken:  "valid.jwt.token",
+						RefreshToken: facade.RefreshToken{Token: "valid.refresh.token"},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.TokenResp{
+				AccessToken: "valid.jwt.token",
+			},
+		},
+		{
+			name: "missing session id",
+			request: handlers.FinishPasskeySignInReq{
+				Credential: credential,
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: "Validation error",
+			},
+		},
+		{
+			name: "malformed credential",
+			request: handlers.FinishPasskeySignInReq{
+				SessionID:  sessionID,
+				Credential: json.RawMessage(`{"id":"test"}`),
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: "Invalid passkey credential",
+			},
+		},
+		{
+			name: "expired session",
+			request: handlers.FinishPasskeySignInReq{
+				SessionID:  sessionID,
+				Credential: credential,
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					FinishPasskeySignIn(gomock.Any(), sessionID, gomock.Any()).
+					Return(model.User{}, facade.ErrPasskeyInvalidSession)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp: web.ErrResp{
+				Error: "Invalid or expired passkey session",
+			},
+		},
+		{
+			name: "verification failed",
+			request: handlers.FinishPasskeySignInReq{
+				SessionID:  sessionID,
+				Credential: credential,
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					FinishPasskeySignIn(gomock.Any(), sessionID, gomock.Any()).
+					Return(model.User{}, facade.ErrPasskeyVerificationFailed)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp: web.ErrResp{
+				Error: "Passkey verification failed",
+			},
+		},
+		{
+			name: "facade error",
+			request: handlers.FinishPasskeySignInReq{
+				SessionID:  sessionID,
+				Credential: credential,
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					FinishPasskeySignIn(gomock.Any(), sessionID, gomock.Any()).
+					Return(model.User{}, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp: web.ErrResp{
+				Error: internalErrorMsg,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/signin/passkey/finish", authAPI.FinishPasskeySignInHandler)
+
+			reqBody, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/signin/passkey/finish", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			switch v := tt.expectedResp.(type) {
+			case handlers.TokenResp:
+				var actual handlers.TokenResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v, actual)
+				assert.Contains(t, resp.Header.Get("Set-Cookie"), "refresh_token=valid.refresh.token")
+			case web.ErrResp:
+				var actual web.ErrResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.Error, actual.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/ratelimit"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// standard rate limit headers (draft-ietf-httpapi-ratelimit-headers)
+const (
+	rateLimitLimitHeader     = "RateLimit-Limit"
+	rateLimitRemainingHeader = "RateLimit-Remaining"
+	rateLimitResetHeader     = "RateLimit-Reset"
+	rateLimitPolicyHeader    = "RateLimit-Policy"
+)
+
+// RateLimitKeyFunc returns key requests are counted by
+type RateLimitKeyFunc func(c *fiber.Ctx) string
+
+// RateLimiter limits requests rate per route
+type RateLimiter struct {
+	log   *zap.Logger
+	store ratelimit.Store
+}
+
+// NewRateLimiter creates a new rate limiter with provided store
+func NewRateLimiter(log *zap.Logger, store ratelimit.Store) *RateLimiter {
+	return &RateLimiter{
+		log:   log,
+		store: store,
+	}
+}
+
+// Limit returns middleware that limits requests to route to the provided limit per key.
+// Requests are passed through if rate limit store is unavailable
+func (rl *RateLimiter) Limit(route string, limit ratelimit.Limit, keyFunc RateLimitKeyFunc) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := route + ":" + keyFunc(c)
+
+		count, resetAt, err := rl.store.IncrementRateLimit(c.Context(), key, limit.Window)
+		if err != nil {
+			rl.log.Error("increment rate limit", zap.String("key", key), zap.Error(err))
+			return c.Next()
+		}
+
+		resetIn := time.Until(resetAt)
+		if resetIn < 0 {
+			resetIn = 0
+		}
+		remaining := limit.Requests - count
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		c.Set(rateLimitLimitHeader, strconv.Itoa(limit.Requests))
+		c.Set(rateLimitRemainingHeader, strconv.Itoa(remaining))
+		c.Set(rateLimitResetHeader, strconv.Itoa(int(math.Ceil(resetIn.Seconds()))))
+		c.Set(rateLimitPolicyHeader, fmt.Sprintf("%d;w=%d", limit.Requests, int(limit.Window.Seconds())))
+
+		if count > limit.Requests {
+			rl.log.Info("rate limit exceeded", zap.String("key", key), zap.Int("count", count))
+			setRetryAfterHeader(c, resetIn)
+			return c.Status(http.StatusTooManyRequests).JSON(web.ErrResp{
+				Error: tooManyRequestsMsg,
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// rateLimitKeyByIP returns client ip as rate limit key
+func rateLimitKeyByIP(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// rateLimitKeyByUser returns user id from access token as rate limit key. Falls back to client ip if token is missing or invalid
+func (a *AuthAPI) rateLimitKeyByUser(c *fiber.Ctx) string {
+	claims, err := a.getClaims(c)
+	if err != nil {
+		return rateLimitKeyByIP(c)
+	}
+	return "user:" + claims.UserID
+}
@@ -0,0 +1,95 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/handlers"
+	"github.com/OutOfStack/game-library-auth/internal/ratelimit"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) IncrementRateLimit(context.Context, string, time.Duration) (int, time.Time, error) {
+	return 0, time.Time{}, errors.New("store unavailable")
+}
+
+func TestRateLimiter_Limit(t *testing.T) {
+	rateLimiter := handlers.NewRateLimiter(zap.NewNop(), ratelimit.NewMemoryStore())
+	limit := ratelimit.Limit{Requests: 2, Window: time.Minute}
+
+	app := fiber.New()
+	app.Post("/signin", rateLimiter.Limit("signin", limit, func(*fiber.Ctx) string { return "ip:127.0.0.1" }), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+
+	expected := []struct {
+		status    int
+		remaining string
+	}{
+		{status: http.StatusOK, remaining: "1"},
+		{status: http.StatusOK, remaining: "0"},
+		{status: http.StatusTooManyRequests, remaining: "0"},
+	}
+
+	for i, exp := range expected {
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/signin", nil))
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, exp.status, resp.StatusCode, "request %d", i+1)
+		assert.Equal(t, "2", resp.Header.Get("RateLimit-Limit"))
+		assert.Equal(t, exp.remaining, resp.Header.Get("RateLimit-Remaining"))
+		assert.Equal(t, "60", resp.Header.Get("RateLimit-Reset"))
+		assert.Equal(t, "2;w=60", resp.Header.Get("RateLimit-Policy"))
+		if exp.status == http.StatusTooManyRequests {
+			assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+		}
+	}
+}
+
+func TestRateLimiter_Limit_SeparateKeys(t *testing.T) {
+	rateLimiter := handlers.NewRateLimiter(zap.NewNop(), ratelimit.NewMemoryStore())
+	limit := ratelimit.Limit{Requests: 1, Window: time.Minute}
+
+	app := fiber.New()
+	app.Post("/signin", rateLimiter.Limit("signin", limit, func(c *fiber.Ctx) string { return c.Get("X-Client") }), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+
+	for _, client := range []string{"a", "b"} {
+		req := httptest.NewRequest(http.MethodPost, "/signin", nil)
+		req.Header.Set("X-Client", client)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestRateLimiter_Limit_StoreError(t *testing.T) {
+	rateLimiter := handlers.NewRateLimiter(zap.NewNop(), failingRateLimitStore{})
+	limit := ratelimit.Limit{Requests: 1, Window: time.Minute}
+
+	app := fiber.New()
+	app.Post("/signin", rateLimiter.Limit("signin", limit, func(*fiber.Ctx) string { return "ip:127.0.0.1" }), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/signin", nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// requests are not blocked when store is unavailable
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("RateLimit-Limit"))
+}
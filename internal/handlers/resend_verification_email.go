@@ -22,6 +22,7 @@ import (
 // @Failure      401 {object} web.ErrResp "Invalid or missing authorization token"
 // @Failure      429 {object} web.ErrResp "Too many resend requests"
 // @Failure      500 {object} web.ErrResp "Internal server error"
+// @Failure      503 {object} web.ErrResp "Email delivery is delayed"
 // @Router       /resend-verification [post]
 func (a *AuthAPI) ResendVerificationEmailHandler(c *fiber.Ctx) error {
 	ctx, span := tracer.Start(c.Context(), "resendVerificationEmail")
@@ -38,6 +39,7 @@ func (a *AuthAPI) ResendVerificationEmailHandler(c *fiber.Ctx) error {
 	// resend verification email
 	if err = a.userFacade.ResendVerificationEmail(ctx, claims.UserID); err != nil {
 		var tooManyRequestErr *facade.TooManyRequestsError
+		var emailDelayedErr *facade.EmailDelayedError
 		switch {
 		case errors.Is(err, facade.ErrVerifyEmailAlreadyVerified):
 			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
@@ -48,10 +50,15 @@ func (a *AuthAPI) ResendVerificationEmailHandler(c *fiber.Ctx) error {
 				Error: "User does not have an email address",
 			})
 		case errors.As(err, &tooManyRequestErr):
-			c.Set("Retry-After", fmt.Sprintf("%.0f", tooManyRequestErr.RetryAfter.Seconds()))
+			setRetryAfterHeader(c, tooManyRequestErr.RetryAfter)
 			return c.Status(http.StatusTooManyRequests).JSON(web.ErrResp{
 				Error: "Please wait before requesting another code",
 			})
+		case errors.As(err, &emailDelayedErr):
+			setRetryAfterHeader(c, emailDelayedErr.RetryAfter)
+			return c.Status(http.StatusServiceUnavailable).JSON(web.ErrResp{
+				Error: emailDelayedMsg,
+			})
 		case errors.Is(err, facade.ErrSendVerifyEmailUnsubscribed):
 			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
 				Error: fmt.Sprintf("User is unsubscribed. You may contact us at mailto:%s to resubscribe", a.cfg.ContactEmail),
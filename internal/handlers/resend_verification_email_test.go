@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/OutOfStack/game-library-auth/internal/appconf"
 	"github.com/OutOfStack/game-library-auth/internal/auth"
@@ -77,6 +78,16 @@ func TestResendVerificationEmailHandler(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedResp:   web.ErrResp{Error: "User does not have an email address"},
 		},
+		{
+			name:                     "email delivery delayed",
+			authHeader:               "Bearer valid-token",
+			emailVerificationEnabled: true,
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().ResendVerificationEmail(gomock.Any(), userID).Return(facade.NewEmailDelayedError(90 * time.Second))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedResp:   web.ErrResp{Error: "Email delivery is delayed. Please try again later"},
+		},
 		{
 			name:                     "email sending failure",
 			authHeader:               "Bearer valid-token",
@@ -130,6 +141,9 @@ func TestResendVerificationEmailHandler(t *testing.T) {
 			defer resp.Body.Close()
 
 			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedStatus == http.StatusServiceUnavailable {
+				assert.Equal(t, "90", resp.Header.Get("Retry-After"))
+			}
 
 			if tt.expectedResp != nil {
 				var actual web.ErrResp
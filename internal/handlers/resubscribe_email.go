@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// ResubscribeEmailHandler godoc
+// @Summary      Resubscribe email address
+// @Description  Resubscribes verified email address of the user to all emails, including address unsubscribed after bounce or complaint
+// @Tags         auth
+// @Security     Bearer
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      204 "Email address resubscribed"
+// @Failure      400 {object} web.ErrResp "User has no verified email"
+// @Failure      401 {object} web.ErrResp "Invalid or missing authorization token"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /account/email/resubscribe [post]
+func (a *AuthAPI) ResubscribeEmailHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "resubscribeEmail")
+	defer span.End()
+
+	userID, err := a.getUserIDFromJWT(c)
+	if err != nil {
+		a.log.Error("extracting user ID from JWT", zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: invalidAuthTokenMsg,
+		})
+	}
+
+	if err = a.userFacade.ResubscribeUserEmail(ctx, userID); err != nil {
+		if errors.Is(err, facade.ErrResubscribeNoVerifiedEmail) {
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: "User has no verified email",
+			})
+		}
+		a.log.Error("resubscribe email", zap.String("userId", userID), zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	mocks "github.com/OutOfStack/game-library-auth/internal/handlers/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestResubscribeEmailHandler(t *testing.T) {
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   *web.ErrResp
+	}{
+		{
+			name:       "success",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().ResubscribeUserEmail(gomock.Any(), userID).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "missing auth header",
+			authHeader:     "",
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp:   &web.ErrResp{Error: invalidAuthToken},
+		},
+		{
+			name:       "no verified email",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().ResubscribeUserEmail(gomock.Any(), userID).Return(facade.ErrResubscribeNoVerifiedEmail)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   &web.ErrResp{Error: "User has no verified email"},
+		},
+		{
+			name:       "facade error",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().ResubscribeUserEmail(gomock.Any(), userID).Return(errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   &web.ErrResp{Error: internalErrorMsg},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.authHeader == "Bearer valid-token" {
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(auth.Claims{UserID: userID}, nil).
+					AnyTimes()
+			}
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/account/email/resubscribe", authAPI.ResubscribeEmailHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/account/email/resubscribe", nil)
+			req.Header.Set("Authorization", tt.authHeader)
+
+			resp, err := app.Test(req, 5000)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedResp != nil {
+				var errResp web.ErrResp
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+				assert.Equal(t, *tt.expectedResp, errResp)
+			}
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 
 	_ "github.com/OutOfStack/game-library-auth/docs" // swagger docs
 	"github.com/OutOfStack/game-library-auth/internal/appconf"
+	"github.com/OutOfStack/game-library-auth/internal/ratelimit"
 	"github.com/gofiber/adaptor/v2"
 	"github.com/gofiber/contrib/otelfiber"
 	"github.com/gofiber/fiber/v2"
@@ -24,8 +26,11 @@ import (
 
 var tracer = otel.Tracer("api")
 
-// Service creates and configures auth app
-func Service(authAPI *AuthAPI, checkAPI *CheckAPI, unsubscribeAPI *UnsubscribeAPI, cfg *appconf.Cfg) (*fiber.App, error) {
+// Service creates and configures auth app.
+// Rate limit store is used only when rate limiting is enabled in config.
+// Email webhook api is nil if email provider webhooks are not configured
+func Service(authAPI *AuthAPI, checkAPI *CheckAPI, unsubscribeAPI *UnsubscribeAPI, emailWebhookAPI *EmailWebhookAPI, rateLimitStore ratelimit.Store,
+	cfg *appconf.Cfg) (*fiber.App, error) {
 	err := initTracer(cfg.Zipkin.ReporterURL)
 	if err != nil {
 		return nil, fmt.Errorf("init exporter: %w", err)
@@ -35,10 +40,12 @@ func Service(authAPI *AuthAPI, checkAPI *CheckAPI, unsubscribeAPI *UnsubscribeAP
 	viewEngine := html.New("./internal/web/templates", ".html")
 
 	app := fiber.New(fiber.Config{
-		AppName:      appconf.ServiceName,
-		ReadTimeout:  cfg.Web.ReadTimeout,
-		WriteTimeout: cfg.Web.WriteTimeout,
-		Views:        viewEngine,
+		AppName:            appconf.ServiceName,
+		ReadTimeout:        cfg.Web.ReadTimeout,
+		WriteTimeout:       cfg.Web.WriteTimeout,
+		Views:              viewEngine,
+		ProxyHeader:        cfg.Web.ProxyHeader,
+		EnableIPValidation: cfg.Web.ProxyHeader != "",
 	})
 
 	// apply middleware
@@ -50,15 +57,22 @@ func Service(authAPI *AuthAPI, checkAPI *CheckAPI, unsubscribeAPI *UnsubscribeAP
 		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
 		AllowMethods:     "GET,POST,DELETE,PATCH,OPTIONS",
 		AllowCredentials: true,
+		ExposeHeaders:    "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After",
 	}))
 
-	registerRoutes(app, authAPI, checkAPI, unsubscribeAPI)
+	limits, err := newRouteLimits(authAPI, rateLimitStore, cfg.RateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("init rate limits: %w", err)
+	}
+
+	registerRoutes(app, authAPI, checkAPI, unsubscribeAPI, emailWebhookAPI, limits)
 
 	return app, nil
 }
 
-// DebugService creates and configures debug app
-func DebugService() *fiber.App {
+// DebugService creates and configures debug app.
+// Email history api is nil if support token is not configured
+func DebugService(emailPreviewAPI *EmailPreviewAPI, emailHistoryAPI *EmailHistoryAPI) *fiber.App {
 	app := fiber.New(fiber.Config{
 		AppName: "debug",
 	})
@@ -66,28 +80,72 @@ func DebugService() *fiber.App {
 	// apply middleware
 	app.Use(pprof.New())
 
+	// email templates preview
+	app.Get("/emails/:name", emailPreviewAPI.EmailPreviewHandler)
+
+	// send history of user emails for support
+	if emailHistoryAPI != nil {
+		app.Get("/admin/users/:id/emails", emailHistoryAPI.UserEmailHistoryHandler)
+	}
+
 	return app
 }
 
-func registerRoutes(app *fiber.App, authAPI *AuthAPI, checkAPI *CheckAPI, unsubscribeAPI *UnsubscribeAPI) {
+func registerRoutes(app *fiber.App, authAPI *AuthAPI, checkAPI *CheckAPI, unsubscribeAPI *UnsubscribeAPI, emailWebhookAPI *EmailWebhookAPI, limits routeLimits) {
 	// health
 	app.Get("/readiness", checkAPI.Readiness)
 	app.Get("/liveness", checkAPI.Liveness)
 
 	// user
-	app.Post("/signin", authAPI.SignInHandler)
-	app.Post("/signup", authAPI.SignUpHandler)
+	app.Post("/signin", limits.signIn, authAPI.SignInHandler)
+	app.Post("/signin/2fa", limits.signInTwoFactor, authAPI.SignInTwoFactorHandler)
+	app.Post("/signup", limits.signUp, authAPI.SignUpHandler)
+	app.Get("/account", authAPI.GetProfileHandler)
 	app.Patch("/account", authAPI.UpdateProfileHandler)
 	app.Delete("/account", authAPI.DeleteAccountHandler)
-	app.Post("/oauth/google", authAPI.GoogleOAuthHandler)
+	app.Post("/oauth/google", limits.googleOAuth, authAPI.GoogleOAuthHandler)
+
+	// passwordless sign in
+	app.Post("/signin/email", limits.signInEmail, authAPI.EmailSignInHandler)
+	app.Post("/signin/email/verify", limits.signInEmailVerify, authAPI.VerifyEmailSignInHandler)
+
+	// two-factor authentication
+	app.Post("/account/2fa/totp", authAPI.EnrollTOTPHandler)
+	app.Post("/account/2fa/totp/confirm", authAPI.ConfirmTOTPHandler)
+	app.Delete("/account/2fa/totp", authAPI.DisableTOTPHandler)
+	app.Post("/account/2fa/recovery-codes", authAPI.RegenerateRecoveryCodesHandler)
+
+	// passkeys
+	app.Post("/signin/passkey/begin", limits.signInPasskey, authAPI.BeginPasskeySignInHandler)
+	app.Post("/signin/passkey/finish", limits.signInPasskey, authAPI.FinishPasskeySignInHandler)
+	app.Get("/account/passkeys", authAPI.ListPasskeysHandler)
+	app.Delete("/account/passkeys/:id", authAPI.DeletePasskeyHandler)
+	app.Post("/account/passkeys/register/begin", authAPI.BeginPasskeyRegistrationHandler)
+	app.Post("/account/passkeys/register/finish", authAPI.FinishPasskeyRegistrationHandler)
 
 	// email verification
-	app.Post("/verify-email", authAPI.VerifyEmailHandler)
-	app.Post("/resend-verification", authAPI.ResendVerificationEmailHandler)
+	app.Post("/verify-email", limits.verifyEmail, authAPI.VerifyEmailHandler)
+	app.Post("/resend-verification", limits.resendVerification, authAPI.ResendVerificationEmailHandler)
+	app.Get("/verify-email/confirm", authAPI.VerifyEmailLinkHandler)
+	app.Post("/verify-email/confirm", limits.verifyEmailLink, authAPI.VerifyEmailLinkConfirmHandler)
+	app.Get("/account/email/status", authAPI.EmailStatusHandler)
+	app.Post("/account/email/resubscribe", authAPI.ResubscribeEmailHandler)
+	app.Post("/account/email", limits.resendVerification, authAPI.AddEmailHandler)
+
+	// email change
+	app.Post("/account/email/change", limits.resendVerification, authAPI.ChangeEmailHandler)
+	app.Post("/account/email/change/confirm", limits.verifyEmail, authAPI.ConfirmEmailChangeHandler)
 
 	// unsubscribe
 	app.Get("/unsubscribe", unsubscribeAPI.UnsubscribeHandler)
 	app.Post("/unsubscribe", unsubscribeAPI.UnsubscribeConfirmHandler)
+	app.Post("/email-preferences", unsubscribeAPI.EmailPreferencesHandler)
+	app.Post("/resubscribe", unsubscribeAPI.ResubscribeHandler)
+
+	// email provider webhooks
+	if emailWebhookAPI != nil {
+		app.Post("/webhooks/resend", emailWebhookAPI.ResendWebhookHandler)
+	}
 
 	// token
 	app.Post("/token/verify", authAPI.VerifyTokenHandler)
@@ -98,6 +156,88 @@ func registerRoutes(app *fiber.App, authAPI *AuthAPI, checkAPI *CheckAPI, unsubs
 	app.Get("/swagger/*", adaptor.HTTPHandler(swag.Handler()))
 }
 
+// routeLimits holds rate limit middleware for each limited route
+type routeLimits struct {
+	signUp             fiber.Handler
+	signIn             fiber.Handler
+	signInTwoFactor    fiber.Handler
+	signInPasskey      fiber.Handler
+	signInEmail        fiber.Handler
+	signInEmailVerify  fiber.Handler
+	googleOAuth        fiber.Handler
+	resendVerification fiber.Handler
+	verifyEmail        fiber.Handler
+	verifyEmailLink    fiber.Handler
+}
+
+// newRouteLimits creates rate limit middleware for limited routes.
+// Routes without configured limit or with rate limiting disabled get pass through middleware
+func newRouteLimits(authAPI *AuthAPI, store ratelimit.Store, cfg appconf.RateLimit) (routeLimits, error) {
+	var rateLimiter *RateLimiter
+	if cfg.Enabled {
+		if store == nil {
+			return routeLimits{}, errors.New("rate limit store is nil")
+		}
+		rateLimiter = NewRateLimiter(authAPI.log, store)
+	}
+
+	// routes with authenticated user are counted per user if enabled
+	userKeyFunc := rateLimitKeyByIP
+	if cfg.ByUser {
+		userKeyFunc = authAPI.rateLimitKeyByUser
+	}
+
+	newLimit := func(route, limitStr string, keyFunc RateLimitKeyFunc) (fiber.Handler, error) {
+		if rateLimiter == nil || limitStr == "" {
+			return func(c *fiber.Ctx) error { return c.Next() }, nil
+		}
+		limit, err := ratelimit.ParseLimit(limitStr)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s limit: %w", route, err)
+		}
+		return rateLimiter.Limit(route, limit, keyFunc), nil
+	}
+
+	var limits routeLimits
+	var err error
+	if limits.signUp, err = newLimit("signup", cfg.SignUp, rateLimitKeyByIP); err != nil {
+		return routeLimits{}, err
+	}
+	if limits.signIn, err = newLimit("signin", cfg.SignIn, rateLimitKeyByIP); err != nil {
+		return routeLimits{}, err
+	}
+	// second sign in step shares sign in limit, but is counted separately
+	if limits.signInTwoFactor, err = newLimit("signin_2fa", cfg.SignIn, rateLimitKeyByIP); err != nil {
+		return routeLimits{}, err
+	}
+	// passkey sign in shares sign in limit, but is counted separately
+	if limits.signInPasskey, err = newLimit("signin_passkey", cfg.SignIn, rateLimitKeyByIP); err != nil {
+		return routeLimits{}, err
+	}
+	if limits.signInEmail, err = newLimit("signin_email", cfg.SignInEmail, rateLimitKeyByIP); err != nil {
+		return routeLimits{}, err
+	}
+	// passwordless sign in code check shares sign in limit, but is counted separately
+	if limits.signInEmailVerify, err = newLimit("signin_email_verify", cfg.SignIn, rateLimitKeyByIP); err != nil {
+		return routeLimits{}, err
+	}
+	if limits.googleOAuth, err = newLimit("oauth_google", cfg.GoogleOAuth, rateLimitKeyByIP); err != nil {
+		return routeLimits{}, err
+	}
+	if limits.resendVerification, err = newLimit("resend_verification", cfg.ResendVerification, userKeyFunc); err != nil {
+		return routeLimits{}, err
+	}
+	if limits.verifyEmail, err = newLimit("verify_email", cfg.VerifyEmail, userKeyFunc); err != nil {
+		return routeLimits{}, err
+	}
+	// verification by link doesn't require signed in user, so it is counted by ip
+	if limits.verifyEmailLink, err = newLimit("verify_email_link", cfg.VerifyEmail, rateLimitKeyByIP); err != nil {
+		return routeLimits{}, err
+	}
+
+	return limits, nil
+}
+
 func initTracer(reporterURL string) error {
 	exporter, err := zipkin.New(reporterURL)
 	if err != nil {
@@ -49,5 +49,5 @@ func setupTest(t *testing.T, cfg *appconf.Cfg) (
 	authAPI, err := handlers.NewAuthAPI(logger, mockGoogleTokenValidator, mockUserFacade, authAPICfg)
 	require.NoError(t, err)
 
-	return mockGoogleTokenValidator, authAPI, mockUserFacade, fiber.New(), ctrl
+	return mockGoogleTokenValidator, authAPI, mockUserFacade, fiber.New(fiber.Config{Views: &mockTemplateEngine{}}), ctrl
 }
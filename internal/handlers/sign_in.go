@@ -18,8 +18,10 @@ import (
 // @Produce      json
 // @Param        signin body SignInReq true "User credentials"
 // @Success      200 {object} TokenResp
+// @Success      202 {object} TwoFactorChallengeResp "Two-factor authentication is required"
 // @Failure      400 {object} web.ErrResp
 // @Failure      401 {object} web.ErrResp
+// @Failure      429 {object} web.ErrResp "Too many failed sign in attempts"
 // @Failure      500 {object} web.ErrResp
 // @Router       /signin [post]
 func (a *AuthAPI) SignInHandler(c *fiber.Ctx) error {
@@ -45,14 +47,21 @@ func (a *AuthAPI) SignInHandler(c *fiber.Ctx) error {
 	}
 
 	// sign in
-	user, err := a.userFacade.SignIn(ctx, signIn.Username, signIn.Password)
+	user, err := a.userFacade.SignIn(ctx, signIn.Username, signIn.Password, c.IP())
 	if err != nil {
+		var tooManyRequestErr *facade.TooManyRequestsError
 		switch {
 		case errors.Is(err, facade.ErrSignInInvalidCredentials):
 			log.Info("invalid username or password", zap.Error(err))
 			return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
 				Error: authErrorMsg,
 			})
+		case errors.As(err, &tooManyRequestErr):
+			log.Info("sign in is locked", zap.String("ip", c.IP()), zap.Duration("retryAfter", tooManyRequestErr.RetryAfter))
+			setRetryAfterHeader(c, tooManyRequestErr.RetryAfter)
+			return c.Status(http.StatusTooManyRequests).JSON(web.ErrResp{
+				Error: tooManySignInAttemptsMsg,
+			})
 		default:
 			log.Error("sign in", zap.Error(err))
 			return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
@@ -61,8 +70,23 @@ func (a *AuthAPI) SignInHandler(c *fiber.Ctx) error {
 		}
 	}
 
+	// require second factor if user has two-factor authentication enabled
+	challengeToken, required, err := a.userFacade.StartTwoFactorChallenge(ctx, user.ID)
+	if err != nil {
+		log.Error("starting two-factor challenge", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+	if required {
+		return c.Status(http.StatusAccepted).JSON(TwoFactorChallengeResp{
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+		})
+	}
+
 	// create tokens
-	tokens, err := a.userFacade.CreateTokens(ctx, user)
+	tokens, err := a.userFacade.CreateSignInTokens(ctx, user, getClientInfo(c))
 	if err != nil {
 		log.Error("creating tokens", zap.Error(err))
 		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
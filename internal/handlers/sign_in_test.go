@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/OutOfStack/game-library-auth/internal/facade"
 	"github.com/OutOfStack/game-library-auth/internal/handlers"
@@ -37,11 +38,15 @@ func TestSignInHandler(t *testing.T) {
 				u := model.User{ID: "uid-1", Username: "testuser"}
 
 				mockUserFacade.EXPECT().
-					SignIn(gomock.Any(), "testuser", "password123").
+					SignIn(gomock.Any(), "testuser", "password123", gomock.Any()).
 					Return(u, nil)
 
 				mockUserFacade.EXPECT().
-					CreateTokens(gomock.Any(), u).
+					StartTwoFactorChallenge(gomock.Any(), "uid-1").
+					Return("", false, nil)
+
+				mockUserFacade.EXPECT().
+					CreateSignInTokens(gomock.Any(), u, model.ClientInfo{IP: "0.0.0.0", UserAgent: "test-agent"}).
 					Return(facade.TokenPair{
 						AccessToken:  "valid.jwt.token",
 						RefreshToken: facade.RefreshToken{Token: "valid.refresh.token"},
@@ -52,6 +57,51 @@ func TestSignInHandler(t *testing.T) {
 				AccessToken: "valid.jwt.token",
 			},
 		},
+		{
+			name: "two-factor authentication required",
+			request: handlers.SignInReq{
+				Username: "testuser",
+				Password: "password123",
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				u := model.User{ID: "uid-1", Username: "testuser"}
+
+				mockUserFacade.EXPECT().
+					SignIn(gomock.Any(), "testuser", "password123", gomock.Any()).
+					Return(u, nil)
+
+				mockUserFacade.EXPECT().
+					StartTwoFactorChallenge(gomock.Any(), "uid-1").
+					Return("challenge.jwt.token", true, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedResp: handlers.TwoFactorChallengeResp{
+				TwoFactorRequired: true,
+				ChallengeToken:    "challenge.jwt.token",
+			},
+		},
+		{
+			name: "two-factor challenge error",
+			request: handlers.SignInReq{
+				Username: "testuser",
+				Password: "password123",
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				u := model.User{ID: "uid-1", Username: "testuser"}
+
+				mockUserFacade.EXPECT().
+					SignIn(gomock.Any(), "testuser", "password123", gomock.Any()).
+					Return(u, nil)
+
+				mockUserFacade.EXPECT().
+					StartTwoFactorChallenge(gomock.Any(), "uid-1").
+					Return("", false, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp: web.ErrResp{
+				Error: internalErrorMsg,
+			},
+		},
 		{
 			name: "user not found",
 			request: handlers.SignInReq{
@@ -60,7 +110,7 @@ func TestSignInHandler(t *testing.T) {
 			},
 			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
 				mockUserFacade.EXPECT().
-					SignIn(gomock.Any(), "nonexistent", "password123").
+					SignIn(gomock.Any(), "nonexistent", "password123", gomock.Any()).
 					Return(model.User{}, facade.ErrSignInInvalidCredentials)
 			},
 			expectedStatus: http.StatusUnauthorized,
@@ -76,7 +126,7 @@ func TestSignInHandler(t *testing.T) {
 			},
 			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
 				mockUserFacade.EXPECT().
-					SignIn(gomock.Any(), "testuser", "wrongpassword").
+					SignIn(gomock.Any(), "testuser", "wrongpassword", gomock.Any()).
 					Return(model.User{}, facade.ErrSignInInvalidCredentials)
 			},
 			expectedStatus: http.StatusUnauthorized,
@@ -84,6 +134,22 @@ func TestSignInHandler(t *testing.T) {
 				Error: authErrorMsg,
 			},
 		},
+		{
+			name: "sign in locked",
+			request: handlers.SignInReq{
+				Username: "testuser",
+				Password: "password123",
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					SignIn(gomock.Any(), "testuser", "password123", gomock.Any()).
+					Return(model.User{}, facade.NewTooManyRequestsError(90*time.Second))
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedResp: web.ErrResp{
+				Error: "Too many failed sign in attempts. Please try again later",
+			},
+		},
 		{
 			name: "user repo error",
 			request: handlers.SignInReq{
@@ -92,7 +158,7 @@ func TestSignInHandler(t *testing.T) {
 			},
 			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
 				mockUserFacade.EXPECT().
-					SignIn(gomock.Any(), "testuser", "password123").
+					SignIn(gomock.Any(), "testuser", "password123", gomock.Any()).
 					Return(model.User{}, errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -110,11 +176,15 @@ func TestSignInHandler(t *testing.T) {
 				u := model.User{ID: "uid-1", Username: "testuser"}
 
 				mockUserFacade.EXPECT().
-					SignIn(gomock.Any(), "testuser", "password123").
+					SignIn(gomock.Any(), "testuser", "password123", gomock.Any()).
 					Return(u, nil)
 
 				mockUserFacade.EXPECT().
-					CreateTokens(gomock.Any(), u).
+					StartTwoFactorChallenge(gomock.Any(), "uid-1").
+					Return("", false, nil)
+
+				mockUserFacade.EXPECT().
+					CreateSignInTokens(gomock.Any(), u, gomock.Any()).
 					Return(facade.TokenPair{}, errors.New("token generation error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -138,12 +208,16 @@ func TestSignInHandler(t *testing.T) {
 			reqBody, _ := json.Marshal(tt.request)
 			req := httptest.NewRequest(http.MethodPost, "/signin", bytes.NewReader(reqBody))
 			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("User-Agent", "test-agent")
 
 			resp, err := app.Test(req)
 			require.NoError(t, err)
 			defer resp.Body.Close()
 
 			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedStatus == http.StatusTooManyRequests {
+				assert.Equal(t, "90", resp.Header.Get("Retry-After"))
+			}
 
 			body, err := io.ReadAll(resp.Body)
 			require.NoError(t, err)
@@ -154,6 +228,11 @@ func TestSignInHandler(t *testing.T) {
 				err = json.Unmarshal(body, &actual)
 				require.NoError(t, err)
 				assert.Equal(t, v, actual)
+			case handlers.TwoFactorChallengeResp:
+				var actual handlers.TwoFactorChallengeResp
+				err = json.Unmarshal(body, &actual)
+				require.NoError(t, err)
+				assert.Equal(t, v, actual)
 			case web.ErrResp:
 				var actual web.ErrResp
 				err = json.Unmarshal(body, &actual)
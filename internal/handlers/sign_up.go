@@ -12,12 +12,14 @@ import (
 
 // SignUpHandler godoc
 // @Summary		Register a new user
-// @Description Create a new user account with the provided information
+// @Description Create a new user account with the provided information.
+// @Description verificationEmailDelayed is a best-effort hint: email quota state is tracked per service instance,
+// @Description so verification email may be delayed even if it is not set
 // @Tags  		auth
 // @Accept 		json
 // @Produce 	json
 // @Param 		signup body SignUpReq true "User signup information"
-// @Success		200 {object} TokenResp 	 "User credentials"
+// @Success		200 {object} SignUpResp	 "User credentials"
 // @Failure 	400 {object} web.ErrResp "Invalid input data"
 // @Failure 	409 {object} web.ErrResp "Username or publisher name already exists"
 // @Failure 	500 {object} web.ErrResp "Internal server error"
@@ -46,7 +48,7 @@ func (a *AuthAPI) SignUpHandler(c *fiber.Ctx) error {
 	}
 
 	// sign up
-	user, err := a.userFacade.SignUp(ctx, signUp.Username, signUp.DisplayName, signUp.Email, signUp.Password, signUp.IsPublisher)
+	user, err := a.userFacade.SignUp(ctx, signUp.Username, signUp.DisplayName, signUp.Email, signUp.Password, getLocale(c), signUp.IsPublisher)
 	if err != nil {
 		switch {
 		case errors.Is(err, facade.ErrSignUpUsernameExists):
@@ -87,7 +89,12 @@ func (a *AuthAPI) SignUpHandler(c *fiber.Ctx) error {
 	// set refresh token as a cookie
 	a.setRefreshTokenCookie(c, tokens.RefreshToken)
 
-	return c.JSON(TokenResp{
-		AccessToken: tokens.AccessToken,
+	// verification email is sent once email quota is reset. Quota state is known only to this instance
+	// and is not shared with others, so the flag is a hint and is not set if another instance hit the quota
+	_, delayed := a.userFacade.EmailDeliveryDelay()
+
+	return c.JSON(SignUpResp{
+		AccessToken:              tokens.AccessToken,
+		VerificationEmailDelayed: delayed && signUp.Email != "",
 	})
 }
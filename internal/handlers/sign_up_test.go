@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/OutOfStack/game-library-auth/internal/appconf"
 	"github.com/OutOfStack/game-library-auth/internal/facade"
@@ -24,6 +25,7 @@ func TestSignUpHandler(t *testing.T) {
 	tests := []struct {
 		name                     string
 		request                  interface{}
+		acceptLanguage           string
 		emailVerificationEnabled bool
 		setupMocks               func(*mocks.MockUserFacade)
 		expectedStatus           int
@@ -41,7 +43,7 @@ func TestSignUpHandler(t *testing.T) {
 			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
 				u := model.User{ID: "uid-1", Username: "newuser", DisplayName: "New User", Role: "user"}
 				mockUserFacade.EXPECT().SignUp(
-					gomock.Any(), "newuser", "New User", "", "password123", false,
+					gomock.Any(), "newuser", "New User", "", "password123", "", false,
 				).Return(u, nil)
 				mockUserFacade.EXPECT().
 					CreateTokens(gomock.Any(), gomock.Any()).
@@ -49,9 +51,60 @@ func TestSignUpHandler(t *testing.T) {
 						AccessToken:  "test-token",
 						RefreshToken: facade.RefreshToken{Token: "refresh-token"},
 					}, nil)
+				mockUserFacade.EXPECT().EmailDeliveryDelay().Return(time.Duration(0), false)
 			},
 			expectedStatus: http.StatusOK,
-			expectedResp:   handlers.TokenResp{AccessToken: "test-token"},
+			expectedResp:   handlers.SignUpResp{AccessToken: "test-token"},
+		},
+		{
+			name: "signup with preferred locale",
+			request: handlers.SignUpReq{
+				Username:        "newuser",
+				DisplayName:     "New User",
+				Password:        "password123",
+				ConfirmPassword: "password123",
+			},
+			acceptLanguage: "en;q=0.5, es-MX, es;q=0.8",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				u := model.User{ID: "uid-1", Username: "newuser", DisplayName: "New User", Locale: "es-MX", Role: "user"}
+				mockUserFacade.EXPECT().SignUp(
+					gomock.Any(), "newuser", "New User", "", "password123", "es-MX", false,
+				).Return(u, nil)
+				mockUserFacade.EXPECT().
+					CreateTokens(gomock.Any(), u).
+					Return(facade.TokenPair{
+						AccessToken:  "test-token",
+						RefreshToken: facade.RefreshToken{Token: "refresh-token"},
+					}, nil)
+				mockUserFacade.EXPECT().EmailDeliveryDelay().Return(time.Duration(0), false)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   handlers.SignUpResp{AccessToken: "test-token"},
+		},
+		{
+			name: "signup with extended locale",
+			request: handlers.SignUpReq{
+				Username:        "newuser",
+				DisplayName:     "New User",
+				Password:        "password123",
+				ConfirmPassword: "password123",
+			},
+			acceptLanguage: "en-US-u-ca-gregory-co-phonebk-nu-latn-x-foo",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				u := model.User{ID: "uid-1", Username: "newuser", DisplayName: "New User", Locale: "en-US", Role: "user"}
+				mockUserFacade.EXPECT().SignUp(
+					gomock.Any(), "newuser", "New User", "", "password123", "en-US", false,
+				).Return(u, nil)
+				mockUserFacade.EXPECT().
+					CreateTokens(gomock.Any(), u).
+					Return(facade.TokenPair{
+						AccessToken:  "test-token",
+						RefreshToken: facade.RefreshToken{Token: "refresh-token"},
+					}, nil)
+				mockUserFacade.EXPECT().EmailDeliveryDelay().Return(time.Duration(0), false)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   handlers.SignUpResp{AccessToken: "test-token"},
 		},
 		{
 			name: "successful publisher signup",
@@ -65,7 +118,7 @@ func TestSignUpHandler(t *testing.T) {
 			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
 				u := model.User{ID: "uid-2", Username: "newpublisher", DisplayName: "Publisher Co", Role: "publisher"}
 				mockUserFacade.EXPECT().SignUp(
-					gomock.Any(), "newpublisher", "Publisher Co", "", "password123", true,
+					gomock.Any(), "newpublisher", "Publisher Co", "", "password123", "", true,
 				).Return(u, nil)
 				mockUserFacade.EXPECT().
 					CreateTokens(gomock.Any(), gomock.Any()).
@@ -73,9 +126,10 @@ func TestSignUpHandler(t *testing.T) {
 						AccessToken:  "test-token",
 						RefreshToken: facade.RefreshToken{Token: "refresh-token"},
 					}, nil)
+				mockUserFacade.EXPECT().EmailDeliveryDelay().Return(time.Duration(0), false)
 			},
 			expectedStatus: http.StatusOK,
-			expectedResp:   handlers.TokenResp{AccessToken: "test-token"},
+			expectedResp:   handlers.SignUpResp{AccessToken: "test-token"},
 		},
 		{
 			name: "successful user signup with email verification",
@@ -91,7 +145,7 @@ func TestSignUpHandler(t *testing.T) {
 			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
 				u := model.User{ID: "uid-3", Username: "newuser_verify", DisplayName: "New User Verify", Email: "verify@example.com", Role: "user"}
 				mockUserFacade.EXPECT().SignUp(
-					gomock.Any(), "newuser_verify", "New User Verify", "verify@example.com", "password123", false,
+					gomock.Any(), "newuser_verify", "New User Verify", "verify@example.com", "password123", "", false,
 				).Return(u, nil)
 				mockUserFacade.EXPECT().
 					CreateTokens(gomock.Any(), gomock.Any()).
@@ -99,9 +153,35 @@ func TestSignUpHandler(t *testing.T) {
 						AccessToken:  "test-token",
 						RefreshToken: facade.RefreshToken{Token: "refresh-token"},
 					}, nil)
+				mockUserFacade.EXPECT().EmailDeliveryDelay().Return(time.Duration(0), false)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   handlers.SignUpResp{AccessToken: "test-token"},
+		},
+		{
+			name: "signup with delayed verification email",
+			request: handlers.SignUpReq{
+				Username:        "newuser_verify",
+				DisplayName:     "New User Verify",
+				Email:           "verify@example.com",
+				Password:        "password123",
+				ConfirmPassword: "password123",
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				u := model.User{ID: "uid-3", Username: "newuser_verify", DisplayName: "New User Verify", Email: "verify@example.com", Role: "user"}
+				mockUserFacade.EXPECT().SignUp(
+					gomock.Any(), "newuser_verify", "New User Verify", "verify@example.com", "password123", "", false,
+				).Return(u, nil)
+				mockUserFacade.EXPECT().
+					CreateTokens(gomock.Any(), u).
+					Return(facade.TokenPair{
+						AccessToken:  "test-token",
+						RefreshToken: facade.RefreshToken{Token: "refresh-token"},
+					}, nil)
+				mockUserFacade.EXPECT().EmailDeliveryDelay().Return(time.Hour, true)
 			},
 			expectedStatus: http.StatusOK,
-			expectedResp:   handlers.TokenResp{AccessToken: "test-token"},
+			expectedResp:   handlers.SignUpResp{AccessToken: "test-token", VerificationEmailDelayed: true},
 		},
 		{
 			name: "username already exists",
@@ -114,7 +194,7 @@ func TestSignUpHandler(t *testing.T) {
 			},
 			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
 				mockUserFacade.EXPECT().SignUp(
-					gomock.Any(), "existinguser", "Existing User", "", "password123", false,
+					gomock.Any(), "existinguser", "Existing User", "", "password123", "", false,
 				).Return(model.User{}, facade.ErrSignUpUsernameExists)
 			},
 			expectedStatus: http.StatusConflict,
@@ -131,7 +211,7 @@ func TestSignUpHandler(t *testing.T) {
 			},
 			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
 				mockUserFacade.EXPECT().SignUp(
-					gomock.Any(), "newpublisher", "Existing Publisher", "", "password123", true,
+					gomock.Any(), "newpublisher", "Existing Publisher", "", "password123", "", true,
 				).Return(model.User{}, facade.ErrSignUpPublisherNameExists)
 			},
 			expectedStatus: http.StatusConflict,
@@ -148,7 +228,7 @@ func TestSignUpHandler(t *testing.T) {
 			},
 			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
 				mockUserFacade.EXPECT().SignUp(
-					gomock.Any(), "newuser", "New User", "", "password123", false,
+					gomock.Any(), "newuser", "New User", "", "password123", "", false,
 				).Return(model.User{}, errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -181,6 +261,9 @@ func TestSignUpHandler(t *testing.T) {
 			reqBody, _ := json.Marshal(tt.request)
 			req := httptest.NewRequest(http.MethodPost, "/signup", bytes.NewReader(reqBody))
 			req.Header.Set("Content-Type", "application/json")
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
 
 			resp, err := app.Test(req, 5000)
 			require.NoError(t, err)
@@ -192,13 +275,11 @@ func TestSignUpHandler(t *testing.T) {
 			require.NoError(t, err)
 
 			switch v := tt.expectedResp.(type) {
-			case handlers.TokenResp:
-				var actual handlers.TokenResp
+			case handlers.SignUpResp:
+				var actual handlers.SignUpResp
 				err = json.Unmarshal(body, &actual)
 				require.NoError(t, err)
-				if v.AccessToken != "" {
-					assert.Equal(t, v.AccessToken, actual.AccessToken)
-				}
+				assert.Equal(t, v, actual)
 			case web.ErrResp:
 				var actual web.ErrResp
 				err = json.Unmarshal(body, &actual)
@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// SignInTwoFactorHandler godoc
+// @Summary      Complete sign in with two-factor authentication
+// @Description  Exchanges challenge token returned by sign in and a code from authenticator app or a recovery code for an access token
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        signin body SignInTwoFactorReq true "Challenge token and code"
+// @Success      200 {object} TokenResp
+// @Failure      400 {object} web.ErrResp
+// @Failure      401 {object} web.ErrResp "Invalid or expired challenge token or invalid code"
+// @Failure      429 {object} web.ErrResp "Too many failed sign in attempts"
+// @Failure      500 {object} web.ErrResp
+// @Router       /signin/2fa [post]
+func (a *AuthAPI) SignInTwoFactorHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "signInTwoFactor")
+	defer span.End()
+
+	var req SignInTwoFactorReq
+	if err := c.BodyParser(&req); err != nil {
+		a.log.Error("parsing data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: "Error parsing data",
+		})
+	}
+
+	if fields, err := web.Validate(req); err != nil {
+		a.log.Info("validating two-factor sign in data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error:  validationErrorMsg,
+			Fields: fields,
+		})
+	}
+
+	user, err := a.userFacade.CompleteTwoFactorSignIn(ctx, req.ChallengeToken, req.Code, c.IP())
+	if err != nil {
+		var tooManyRequestErr *facade.TooManyRequestsError
+		switch {
+		case errors.Is(err, facade.ErrTwoFactorInvalidChallenge), errors.Is(err, facade.ErrTwoFactorNotEnabled):
+			a.log.Info("invalid two-factor challenge", zap.Error(err))
+			return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+				Error: "Invalid or expired challenge token",
+			})
+		case errors.Is(err, facade.ErrTwoFactorInvalidCode):
+			a.log.Info("invalid two-factor code", zap.Error(err))
+			return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+				Error: invalidTwoFactorCodeMsg,
+			})
+		case errors.As(err, &tooManyRequestErr):
+			a.log.Info("sign in is locked", zap.String("ip", c.IP()), zap.Duration("retryAfter", tooManyRequestErr.RetryAfter))
+			setRetryAfterHeader(c, tooManyRequestErr.RetryAfter)
+			return c.Status(http.StatusTooManyRequests).JSON(web.ErrResp{
+				Error: tooManySignInAttemptsMsg,
+			})
+		default:
+			a.log.Error("two-factor sign in", zap.Error(err))
+			return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+				Error: internalErrorMsg,
+			})
+		}
+	}
+
+	log := a.log.With(zap.String("userId", user.ID))
+
+	// create tokens
+	tokens, err := a.userFacade.CreateSignInTokens(ctx, user, getClientInfo(c))
+	if err != nil {
+		log.Error("creating tokens", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+			Error: internalErrorMsg,
+		})
+	}
+
+	// set refresh token as a cookie
+	a.setRefreshTokenCookie(c, tokens.RefreshToken)
+
+	return c.JSON(TokenResp{
+		AccessToken: tokens.AccessToken,
+	})
+}
+
+// EnrollTOTPHandler godoc
+// @Summary      Start TOTP two-factor authentication enrollment
+// @Description  Generates a new TOTP secret and provisioning uri for authenticator app. Enrollment has to be confirmed with a code
+// @Tags         auth
+// @Security     Bearer
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} TOTPEnrollmentResp
+// @Failure      401 {object} web.ErrResp "Invalid or missing authorization token"
+// @Failure      403 {object} web.ErrResp "Two-factor authentication is not available for account"
+// @Failure      404 {object} web.ErrResp "User not found"
+// @Failure      409 {object} web.ErrResp "Two-factor authentication is already enabled"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /account/2fa/totp [post]
+func (a *AuthAPI) EnrollTOTPHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "enrollTOTP")
+	defer span.End()
+
+	userID, err := a.getUserIDFromJWT(c)
+	if err != nil {
+		a.log.Error("extracting user ID from JWT", zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: invalidAuthTokenMsg,
+		})
+	}
+
+	log := a.log.With(zap.String("userId", userID))
+
+	enrollment, err := a.userFacade.EnrollTOTP(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, facade.ErrTwoFactorUserNotFound):
+			return c.Status(http.StatusNotFound).JSON(web.ErrResp{
+				Error: "User not found",
+			})
+		case errors.Is(err, facade.ErrTwoFactorNotAllowed):
+			return c.Status(http.StatusForbidden).JSON(web.ErrResp{
+				Error: "Two-factor authentication is not available for accounts signed in with external provider",
+			})
+		case errors.Is(err, facade.ErrTwoFactorAlreadyEnabled):
+			return c.Status(http.StatusConflict).JSON(web.ErrResp{
+				Error: "Two-factor authentication is already enabled",
+			})
+		default:
+			log.Error("enroll totp", zap.Error(err))
+			return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+				Error: internalErrorMsg,
+			})
+		}
+	}
+
+	return c.JSON(TOTPEnrollmentResp{
+		Secret: enrollment.Secret,
+		URI:    enrollment.URI,
+	})
+}
+
+// ConfirmTOTPHandler godoc
+// @Summary      Confirm TOTP two-factor authentication enrollment
+// @Description  Confirms TOTP enrollment with a code from authenticator app and enables two-factor authentication. Returns single-use recovery codes
+// @Tags         auth
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        confirmation body ConfirmTOTPReq true "Code from authenticator app"
+// @Success      200 {object} RecoveryCodesResp "Two-factor authentication enabled"
+// @Failure      400 {object} web.ErrResp "Invalid code or enrollment not started"
+// @Failure      401 {object} web.ErrResp "Invalid or missing authorization token"
+// @Failure      409 {object} web.ErrResp "Two-factor authentication is already enabled"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /account/2fa/totp/confirm [post]
+func (a *AuthAPI) ConfirmTOTPHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "confirmTOTP")
+	defer span.End()
+
+	userID, err := a.getUserIDFromJWT(c)
+	if err != nil {
+		a.log.Error("extracting user ID from JWT", zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: invalidAuthTokenMsg,
+		})
+	}
+
+	var req ConfirmTOTPReq
+	if err = c.BodyParser(&req); err != nil {
+		a.log.Error("parsing data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: "Error parsing data",
+		})
+	}
+
+	log := a.log.With(zap.String("userId", userID))
+
+	if fields, vErr := web.Validate(req); vErr != nil {
+		log.Info("validating confirm totp data", zap.Error(vErr))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error:  validationErrorMsg,
+			Fields: fields,
+		})
+	}
+
+	recoveryCodes, err := a.userFacade.ConfirmTOTP(ctx, userID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, facade.ErrTwoFactorNotEnrolled):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: "Two-factor authentication enrollment is not started",
+			})
+		case errors.Is(err, facade.ErrTwoFactorInvalidCode):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: invalidTwoFactorCodeMsg,
+			})
+		case errors.Is(err, facade.ErrTwoFactorAlreadyEnabled):
+			return c.Status(http.StatusConflict).JSON(web.ErrResp{
+				Error: "Two-factor authentication is already enabled",
+			})
+		default:
+			log.Error("confirm totp", zap.Error(err))
+			return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+				Error: internalErrorMsg,
+			})
+		}
+	}
+
+	return c.JSON(RecoveryCodesResp{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// DisableTOTPHandler godoc
+// @Summary      Disable TOTP two-factor authentication
+// @Description  Disables two-factor authentication. Requires current password and a code from authenticator app or a recovery code
+// @Tags         auth
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        params body DisableTOTPReq true "Current password and code from authenticator app or recovery code"
+// @Success      204 "Two-factor authentication disabled"
+// @Failure      400 {object} web.ErrResp "Invalid code or two-factor authentication is not enabled"
+// @Failure      401 {object} web.ErrResp "Invalid password or token"
+// @Failure      404 {object} web.ErrResp "User not found"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /account/2fa/totp [delete]
+func (a *AuthAPI) DisableTOTPHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "disableTOTP")
+	defer span.End()
+
+	userID, err := a.getUserIDFromJWT(c)
+	if err != nil {
+		a.log.Error("extracting user ID from JWT", zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: invalidAuthTokenMsg,
+		})
+	}
+
+	var req DisableTOTPReq
+	if err = c.BodyParser(&req); err != nil {
+		a.log.Error("parsing data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: "Error parsing data",
+		})
+	}
+
+	log := a.log.With(zap.String("userId", userID))
+
+	if fields, vErr := web.Validate(req); vErr != nil {
+		log.Info("validating disable totp data", zap.Error(vErr))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error:  validationErrorMsg,
+			Fields: fields,
+		})
+	}
+
+	if err = a.userFacade.DisableTOTP(ctx, userID, req.Password, req.Code); err != nil {
+		switch {
+		case errors.Is(err, facade.ErrTwoFactorUserNotFound):
+			return c.Status(http.StatusNotFound).JSON(web.ErrResp{
+				Error: "User not found",
+			})
+		case errors.Is(err, facade.ErrTwoFactorInvalidPassword):
+			return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+				Error: "Invalid password",
+			})
+		case errors.Is(err, facade.ErrTwoFactorNotEnabled):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: "Two-factor authentication is not enabled",
+			})
+		case errors.Is(err, facade.ErrTwoFactorInvalidCode):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: invalidTwoFactorCodeMsg,
+			})
+		default:
+			log.Error("disable totp", zap.Error(err))
+			return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+				Error: internalErrorMsg,
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodesHandler godoc
+// @Summary      Regenerate recovery codes
+// @Description  Generates a new batch of single-use recovery codes. Previous codes stop working
+// @Tags         auth
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        params body RegenerateRecoveryCodesReq true "Current password"
+// @Success      200 {object} RecoveryCodesResp
+// @Failure      400 {object} web.ErrResp "Two-factor authentication is not enabled"
+// @Failure      401 {object} web.ErrResp "Invalid password or token"
+// @Failure      404 {object} web.ErrResp "User not found"
+// @Failure      500 {object} web.ErrResp "Internal server error"
+// @Router       /account/2fa/recovery-codes [post]
+func (a *AuthAPI) RegenerateRecoveryCodesHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "regenerateRecoveryCodes")
+	defer span.End()
+
+	userID, err := a.getUserIDFromJWT(c)
+	if err != nil {
+		a.log.Error("extracting user ID from JWT", zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+			Error: invalidAuthTokenMsg,
+		})
+	}
+
+	var req RegenerateRecoveryCodesReq
+	if err = c.BodyParser(&req); err != nil {
+		a.log.Error("parsing data", zap.Error(err))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error: "Error parsing data",
+		})
+	}
+
+	log := a.log.With(zap.String("userId", userID))
+
+	if fields, vErr := web.Validate(req); vErr != nil {
+		log.Info("validating regenerate recovery codes data", zap.Error(vErr))
+		return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+			Error:  validationErrorMsg,
+			Fields: fields,
+		})
+	}
+
+	recoveryCodes, err := a.userFacade.RegenerateRecoveryCodes(ctx, userID, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, facade.ErrTwoFactorUserNotFound):
+			return c.Status(http.StatusNotFound).JSON(web.ErrResp{
+				Error: "User not found",
+			})
+		case errors.Is(err, facade.ErrTwoFactorInvalidPassword):
+			return c.Status(http.StatusUnauthorized).JSON(web.ErrResp{
+				Error: "Invalid password",
+			})
+		case errors.Is(err, facade.ErrTwoFactorNotEnabled):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: "Two-factor authentication is not enabled",
+			})
+		default:
+			log.Error("regenerate recovery codes", zap.Error(err))
+			return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
+				Error: internalErrorMsg,
+			})
+		}
+	}
+
+	return c.JSON(RecoveryCodesResp{
+		RecoveryCodes: recoveryCodes,
+	})
+}
@@ -0,0 +1,646 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	auth_ "github.com/OutOfStack/game-library-auth/internal/auth"
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/OutOfStack/game-library-auth/internal/handlers"
+	mocks "github.com/OutOfStack/game-library-auth/internal/handlers/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
+	"github.com/OutOfStack/game-library-auth/internal/web"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const invalidTwoFactorCodeMsg = "Invalid two-factor authentication code"
+
+func TestSignInTwoFactorHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		request        handlers.SignInTwoFactorReq
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name: "successful sign in",
+			request: handlers.SignInTwoFactorReq{
+				ChallengeToken: "challenge.jwt.token",
+				Code:           "123456",
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				u := model.User{ID: "uid-1", Username: "testuser"}
+
+				mockUserFacade.EXPECT().
+					CompleteTwoFactorSignIn(gomock.Any(), "challenge.jwt.token", "123456", gomock.Any()).
+					Return(u, nil)
+
+				mockUserFacade.EXPECT().
+					CreateSignInTokens(gomock.Any(), u, gomock.Any()).
+					Return(facade.TokenPair{
+						AccessToken:  "valid.jwt.token",
+						RefreshToken: facade.RefreshToken{Token: "valid.refresh.token"},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.TokenResp{
+				AccessToken: "valid.jwt.token",
+			},
+		},
+		{
+			name: "invalid code format",
+			request: handlers.SignInTwoFactorReq{
+				ChallengeToken: "challenge.jwt.token",
+				Code:           "123",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: "Validation error",
+			},
+		},
+		{
+			name: "invalid challenge token",
+			request: handlers.SignInTwoFactorReq{
+				ChallengeToken: "challenge.jwt.token",
+				Code:           "123456",
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					CompleteTwoFactorSignIn(gomock.Any(), "challenge.jwt.token", "123456", gomock.Any()).
+					Return(model.User{}, facade.ErrTwoFactorInvalidChallenge)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp: web.ErrResp{
+				Error: "Invalid or expired challenge token",
+			},
+		},
+		{
+			name: "invalid code",
+			request: handlers.SignInTwoFactorReq{
+				ChallengeToken: "challenge.jwt.token",
+				Code:           "123456",
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					CompleteTwoFactorSignIn(gomock.Any(), "challenge.jwt.token", "123456", gomock.Any()).
+					Return(model.User{}, facade.ErrTwoFactorInvalidCode)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp: web.ErrResp{
+				Error: invalidTwoFactorCodeMsg,
+			},
+		},
+		{
+			name: "sign in locked",
+			request: handlers.SignInTwoFactorReq{
+				ChallengeToken: "challenge.jwt.token",
+				Code:           "123456",
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					CompleteTwoFactorSignIn(gomock.Any(), "challenge.jwt.token", "123456", gomock.Any()).
+					Return(model.User{}, facade.NewTooManyRequestsError(90*time.Second))
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedResp: web.ErrResp{
+				Error: "Too many failed sign in attempts. Please try again later",
+			},
+		},
+		{
+			name: "facade error",
+			request: handlers.SignInTwoFactorReq{
+				ChallengeToken: "challenge.jwt.token",
+				Code:           "123456",
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					CompleteTwoFactorSignIn(gomock.Any(), "challenge.jwt.token", "123456", gomock.Any()).
+					Return(model.User{}, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp: web.ErrResp{
+				Error: internalErrorMsg,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/signin/2fa", authAPI.SignInTwoFactorHandler)
+
+			reqBody, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/signin/2fa", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedStatus == http.StatusTooManyRequests {
+				assert.Equal(t, "90", resp.Header.Get("Retry-After"))
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			switch v := tt.expectedResp.(type) {
+			case handlers.TokenResp:
+				var actual handlers.TokenResp
+				err = json.Unmarshal(body, &actual)
+				require.NoError(t, err)
+				assert.Equal(t, v, actual)
+			case web.ErrResp:
+				var actual web.ErrResp
+				err = json.Unmarshal(body, &actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.Error, actual.Error)
+			}
+		})
+	}
+}
+
+func TestEnrollTOTPHandler(t *testing.T) {
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:       "successful enrollment",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(auth_.Claims{UserID: userID}, nil)
+				mockUserFacade.EXPECT().
+					EnrollTOTP(gomock.Any(), userID).
+					Return(model.TOTPEnrollment{Secret: "SECRET", URI: "otpauth://totp/test"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.TOTPEnrollmentResp{
+				Secret: "SECRET",
+				URI:    "otpauth://totp/test",
+			},
+		},
+		{
+			name:           "missing token",
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp: web.ErrResp{
+				Error: "Invalid or missing authorization token",
+			},
+		},
+		{
+			name:       "already enabled",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(auth_.Claims{UserID: userID}, nil)
+				mockUserFacade.EXPECT().
+					EnrollTOTP(gomock.Any(), userID).
+					Return(model.TOTPEnrollment{}, facade.ErrTwoFactorAlreadyEnabled)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedResp: web.ErrResp{
+				Error: "Two-factor authentication is already enabled",
+			},
+		},
+		{
+			name:       "oauth user",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(auth_.Claims{UserID: userID}, nil)
+				mockUserFacade.EXPECT().
+					EnrollTOTP(gomock.Any(), userID).
+					Return(model.TOTPEnrollment{}, facade.ErrTwoFactorNotAllowed)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedResp: web.ErrResp{
+				Error: "Two-factor authentication is not available for accounts signed in with external provider",
+			},
+		},
+		{
+			name:       "facade error",
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(auth_.Claims{UserID: userID}, nil)
+				mockUserFacade.EXPECT().
+					EnrollTOTP(gomock.Any(), userID).
+					Return(model.TOTPEnrollment{}, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp: web.ErrResp{
+				Error: internalErrorMsg,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/account/2fa/totp", authAPI.EnrollTOTPHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/account/2fa/totp", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			switch v := tt.expectedResp.(type) {
+			case handlers.TOTPEnrollmentResp:
+				var actual handlers.TOTPEnrollmentResp
+				err = json.Unmarshal(body, &actual)
+				require.NoError(t, err)
+				assert.Equal(t, v, actual)
+			case web.ErrResp:
+				var actual web.ErrResp
+				err = json.Unmarshal(body, &actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.Error, actual.Error)
+			}
+		})
+	}
+}
+
+func TestConfirmTOTPHandler(t *testing.T) {
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		request        handlers.ConfirmTOTPReq
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:    "successful confirmation",
+			request: handlers.ConfirmTOTPReq{Code: "123456"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ConfirmTOTP(gomock.Any(), userID, "123456").
+					Return([]string{"abcde-fgh23", "jkmnp-qrs45"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.RecoveryCodesResp{
+				RecoveryCodes: []string{"abcde-fgh23", "jkmnp-qrs45"},
+			},
+		},
+		{
+			name:           "invalid code length",
+			request:        handlers.ConfirmTOTPReq{Code: "123"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: "Validation error",
+			},
+		},
+		{
+			name:    "invalid code",
+			request: handlers.ConfirmTOTPReq{Code: "123456"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ConfirmTOTP(gomock.Any(), userID, "123456").
+					Return(nil, facade.ErrTwoFactorInvalidCode)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: invalidTwoFactorCodeMsg,
+			},
+		},
+		{
+			name:    "not enrolled",
+			request: handlers.ConfirmTOTPReq{Code: "123456"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ConfirmTOTP(gomock.Any(), userID, "123456").
+					Return(nil, facade.ErrTwoFactorNotEnrolled)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: "Two-factor authentication enrollment is not started",
+			},
+		},
+		{
+			name:    "facade error",
+			request: handlers.ConfirmTOTPReq{Code: "123456"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ConfirmTOTP(gomock.Any(), userID, "123456").
+					Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp: web.ErrResp{
+				Error: internalErrorMsg,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			mockUserFacade.EXPECT().
+				ValidateAccessToken("valid-token").
+				Return(auth_.Claims{UserID: userID}, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/account/2fa/totp/confirm", authAPI.ConfirmTOTPHandler)
+
+			reqBody, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/account/2fa/totp/confirm", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			switch v := tt.expectedResp.(type) {
+			case handlers.RecoveryCodesResp:
+				var actual handlers.RecoveryCodesResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.RecoveryCodes, actual.RecoveryCodes)
+			case web.ErrResp:
+				var actual web.ErrResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.Error, actual.Error)
+			}
+		})
+	}
+}
+
+func TestDisableTOTPHandler(t *testing.T) {
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		request        handlers.DisableTOTPReq
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:    "successful disable",
+			request: handlers.DisableTOTPReq{Password: "password123", Code: "123456"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					DisableTOTP(gomock.Any(), userID, "password123", "123456").
+					Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "missing password",
+			request:        handlers.DisableTOTPReq{Code: "123456"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: "Validation error",
+			},
+		},
+		{
+			name:    "invalid password",
+			request: handlers.DisableTOTPReq{Password: "wrongpassword", Code: "123456"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					DisableTOTP(gomock.Any(), userID, "wrongpassword", "123456").
+					Return(facade.ErrTwoFactorInvalidPassword)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp: web.ErrResp{
+				Error: "Invalid password",
+			},
+		},
+		{
+			name:    "not enabled",
+			request: handlers.DisableTOTPReq{Password: "password123", Code: "123456"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					DisableTOTP(gomock.Any(), userID, "password123", "123456").
+					Return(facade.ErrTwoFactorNotEnabled)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: "Two-factor authentication is not enabled",
+			},
+		},
+		{
+			name:    "invalid code",
+			request: handlers.DisableTOTPReq{Password: "password123", Code: "123456"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					DisableTOTP(gomock.Any(), userID, "password123", "123456").
+					Return(facade.ErrTwoFactorInvalidCode)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: invalidTwoFactorCodeMsg,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			mockUserFacade.EXPECT().
+				ValidateAccessToken("valid-token").
+				Return(auth_.Claims{UserID: userID}, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Delete("/account/2fa/totp", authAPI.DisableTOTPHandler)
+
+			reqBody, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodDelete, "/account/2fa/totp", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if v, ok := tt.expectedResp.(web.ErrResp); ok {
+				var actual web.ErrResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.Error, actual.Error)
+			}
+		})
+	}
+}
+
+func TestRegenerateRecoveryCodesHandler(t *testing.T) {
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		request        handlers.RegenerateRecoveryCodesReq
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:    "successful regeneration",
+			request: handlers.RegenerateRecoveryCodesReq{Password: "password123"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					RegenerateRecoveryCodes(gomock.Any(), userID, "password123").
+					Return([]string{"abcde-fgh23"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.RecoveryCodesResp{
+				RecoveryCodes: []string{"abcde-fgh23"},
+			},
+		},
+		{
+			name:           "missing password",
+			request:        handlers.RegenerateRecoveryCodesReq{},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: "Validation error",
+			},
+		},
+		{
+			name:    "invalid password",
+			request: handlers.RegenerateRecoveryCodesReq{Password: "wrongpassword"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					RegenerateRecoveryCodes(gomock.Any(), userID, "wrongpassword").
+					Return(nil, facade.ErrTwoFactorInvalidPassword)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp: web.ErrResp{
+				Error: "Invalid password",
+			},
+		},
+		{
+			name:    "not enabled",
+			request: handlers.RegenerateRecoveryCodesReq{Password: "password123"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					RegenerateRecoveryCodes(gomock.Any(), userID, "password123").
+					Return(nil, facade.ErrTwoFactorNotEnabled)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp: web.ErrResp{
+				Error: "Two-factor authentication is not enabled",
+			},
+		},
+		{
+			name:    "user not found",
+			request: handlers.RegenerateRecoveryCodesReq{Password: "password123"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					RegenerateRecoveryCodes(gomock.Any(), userID, "password123").
+					Return(nil, facade.ErrTwoFactorUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp: web.ErrResp{
+				Error: "User not found",
+			},
+		},
+		{
+			name:    "facade error",
+			request: handlers.RegenerateRecoveryCodesReq{Password: "password123"},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					RegenerateRecoveryCodes(gomock.Any(), userID, "password123").
+					Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp: web.ErrResp{
+				Error: internalErrorMsg,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			mockUserFacade.EXPECT().
+				ValidateAccessToken("valid-token").
+				Return(auth_.Claims{UserID: userID}, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/account/2fa/recovery-codes", authAPI.RegenerateRecoveryCodesHandler)
+
+			reqBody, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/account/2fa/recovery-codes", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			switch v := tt.expectedResp.(type) {
+			case handlers.RecoveryCodesResp:
+				var actual handlers.RecoveryCodesResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.RecoveryCodes, actual.RecoveryCodes)
+			case web.ErrResp:
+				var actual web.ErrResp
+				err = json.NewDecoder(resp.Body).Decode(&actual)
+				require.NoError(t, err)
+				assert.Equal(t, v.Error, actual.Error)
+			}
+		})
+	}
+}
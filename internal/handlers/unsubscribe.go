@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/OutOfStack/game-library-auth/internal/auth"
 	"github.com/OutOfStack/game-library-auth/internal/database"
+	"github.com/OutOfStack/game-library-auth/internal/model"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
@@ -22,7 +24,35 @@ type UnsubscribeAPI struct {
 // UnsubscribeFacade provides methods for working with unsubscribe functionality
 type UnsubscribeFacade interface {
 	UnsubscribeEmail(ctx context.Context, token string) (string, error)
-	IsEmailUnsubscribed(ctx context.Context, email string) (bool, error)
+	GetEmailPreferences(ctx context.Context, email string) ([]model.EmailPreference, error)
+	UpdateEmailPreferences(ctx context.Context, token string, subscribed []string) (string, error)
+	ResubscribeEmail(ctx context.Context, token string) (string, error)
+}
+
+// emailCategoryInfo represents email category shown in preference center
+type emailCategoryInfo struct {
+	Category    string
+	Title       string
+	Description string
+	Subscribed  bool
+	// Locked - category can't be unsubscribed from
+	Locked bool
+}
+
+// titles and descriptions of email categories
+var emailCategoryTexts = map[string]struct{ title, description string }{
+	model.EmailCategorySecurity: {
+		title:       "Account & security",
+		description: "Verification codes, sign in links and alerts about changes to your account. These emails are always sent",
+	},
+	model.EmailCategoryProductNews: {
+		title:       "Product news",
+		description: "New games, features and updates of Game Library",
+	},
+	model.EmailCategoryPublisherReports: {
+		title:       "Publisher reports",
+		description: "Reports about your published games",
+	},
 }
 
 // NewUnsubscribeAPI creates a new unsubscribe API instance
@@ -35,7 +65,7 @@ func NewUnsubscribeAPI(log *zap.Logger, tokenGenerator *auth.UnsubscribeTokenGen
 	}
 }
 
-// UnsubscribeHandler handles GET /unsubscribe?token=xxx - shows confirmation page
+// UnsubscribeHandler handles GET /unsubscribe?token=xxx - shows email preference center
 func (a *UnsubscribeAPI) UnsubscribeHandler(c *fiber.Ctx) error {
 	ctx, span := tracer.Start(c.Context(), "unsubscribeHandler")
 	defer span.End()
@@ -48,43 +78,122 @@ func (a *UnsubscribeAPI) UnsubscribeHandler(c *fiber.Ctx) error {
 	// validate token and extract email
 	email, err := a.tokenGenerator.ValidateToken(token)
 	if err != nil {
-		a.log.Error("failed to validate unsubscribe token", zap.Error(err))
+		a.log.Info("invalid unsubscribe token", zap.Error(err))
 		return c.Status(http.StatusBadRequest).SendString("Invalid or expired unsubscribe link")
 	}
 
-	// check if email is already unsubscribed
-	isUnsubscribed, err := a.unsubscribeFacade.IsEmailUnsubscribed(ctx, email)
+	return a.renderEmailPreferences(ctx, c, email, token, false)
+}
+
+// EmailPreferencesHandler handles POST /email-preferences - saves subscriptions to email categories selected in preference center
+func (a *UnsubscribeAPI) EmailPreferencesHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "emailPreferencesHandler")
+	defer span.End()
+
+	token := c.FormValue("token")
+	if token == "" {
+		return c.Status(http.StatusBadRequest).SendString("Missing token")
+	}
+
+	if _, err := a.tokenGenerator.ValidateToken(token); err != nil {
+		a.log.Info("invalid unsubscribe token", zap.Error(err))
+		return c.Status(http.StatusBadRequest).SendString("Invalid or expired unsubscribe link")
+	}
+
+	var subscribed []string
+	for _, category := range c.Request().PostArgs().PeekMulti("category") {
+		subscribed = append(subscribed, string(category))
+	}
+
+	email, err := a.unsubscribeFacade.UpdateEmailPreferences(ctx, token, subscribed)
+	if err != nil {
+		a.log.Error("failed to update email preferences", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).SendString("Failed to save email preferences")
+	}
+
+	return a.renderEmailPreferences(ctx, c, email, token, true)
+}
+
+// ResubscribeHandler handles POST /resubscribe - resubscribes to all emails by signed link shown after unsubscribing
+func (a *UnsubscribeAPI) ResubscribeHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "resubscribeHandler")
+	defer span.End()
+
+	token := c.FormValue("token")
+	if token == "" {
+		return c.Status(http.StatusBadRequest).SendString("Missing token")
+	}
+
+	if _, err := a.tokenGenerator.ValidateResubscribeToken(token); err != nil {
+		a.log.Info("invalid resubscribe token", zap.Error(err))
+		return c.Status(http.StatusBadRequest).SendString("Invalid or expired resubscribe link")
+	}
+
+	email, err := a.unsubscribeFacade.ResubscribeEmail(ctx, token)
 	if err != nil {
-		a.log.Error("failed to check if email is unsubscribed", zap.Error(err))
-		return c.Status(http.StatusInternalServerError).SendString("Failed to check unsubscribe status")
+		a.log.Error("failed to resubscribe email", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).SendString("Failed to process resubscribe request")
 	}
 
-	if isUnsubscribed {
-		// render already unsubscribed page
-		return c.Render("unsubscribe_already", fiber.Map{
-			"Email":        email,
-			"ContactEmail": a.contactEmail,
+	// preferences link is valid as long as resubscribe link
+	return c.Render("resubscribe_success", fiber.Map{
+		"Email":        email,
+		"Token":        a.tokenGenerator.GenerateToken(email, time.Now().Add(model.ResubscribeTokenTTL)),
+		"ContactEmail": a.contactEmail,
+	})
+}
+
+// renders preference center page with subscriptions of email address to email categories
+func (a *UnsubscribeAPI) renderEmailPreferences(ctx context.Context, c *fiber.Ctx, email, token string, saved bool) error {
+	prefs, err := a.unsubscribeFacade.GetEmailPreferences(ctx, email)
+	if err != nil {
+		a.log.Error("failed to get email preferences", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).SendString("Failed to get email preferences")
+	}
+
+	categories := make([]emailCategoryInfo, 0, len(prefs))
+	for _, pref := range prefs {
+		texts := emailCategoryTexts[pref.Category]
+		categories = append(categories, emailCategoryInfo{
+			Category:    pref.Category,
+			Title:       texts.title,
+			Description: texts.description,
+			Subscribed:  pref.Subscribed,
+			Locked:      !model.IsEmailCategorySuppressible(pref.Category),
 		})
 	}
 
-	// render confirmation page
-	return c.Render("unsubscribe", fiber.Map{
+	return c.Render("email_preferences", fiber.Map{
 		"Email":        email,
 		"Token":        token,
+		"Categories":   categories,
+		"Saved":        saved,
 		"ContactEmail": a.contactEmail,
 	})
 }
 
-// UnsubscribeConfirmHandler handles POST /unsubscribe - processes the unsubscribe action
+// UnsubscribeConfirmHandler handles POST /unsubscribe - unsubscribes from all email categories except security.
+// Also handles one-click unsubscribe by mail clients (RFC 8058): request with "List-Unsubscribe=One-Click" body
+// to the List-Unsubscribe link, token is in link query. One-click unsubscribe returns no page
 func (a *UnsubscribeAPI) UnsubscribeConfirmHandler(c *fiber.Ctx) error {
 	ctx, span := tracer.Start(c.Context(), "unsubscribeConfirmHandler")
 	defer span.End()
 
+	oneClick := c.FormValue("List-Unsubscribe") == "One-Click"
+
 	token := c.FormValue("token")
+	if token == "" {
+		token = c.Query("token")
+	}
 	if token == "" {
 		return c.Status(http.StatusBadRequest).SendString("Missing token")
 	}
 
+	if _, err := a.tokenGenerator.ValidateToken(token); err != nil {
+		a.log.Error("failed to validate unsubscribe token", zap.Bool("oneClick", oneClick), zap.Error(err))
+		return c.Status(http.StatusBadRequest).SendString("Invalid or expired unsubscribe link")
+	}
+
 	// process unsubscribe through facade
 	email, err := a.unsubscribeFacade.UnsubscribeEmail(ctx, token)
 	if err != nil {
@@ -95,9 +204,15 @@ func (a *UnsubscribeAPI) UnsubscribeConfirmHandler(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).SendString("Failed to process unsubscribe request")
 	}
 
-	// render success page
+	if oneClick {
+		return c.SendString("Unsubscribed")
+	}
+
+	// render success page. Resubscribe link has its own token, so unsubscribe token can't be used to resubscribe
 	return c.Render("unsubscribe_success", fiber.Map{
-		"Email":        email,
-		"ContactEmail": a.contactEmail,
+		"Email":            email,
+		"Token":            token,
+		"ResubscribeToken": a.tokenGenerator.GenerateResubscribeToken(email, time.Now().Add(model.ResubscribeTokenTTL)),
+		"ContactEmail":     a.contactEmail,
 	})
 }
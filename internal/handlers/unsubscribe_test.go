@@ -1,8 +1,10 @@
 package handlers_test
 
 import (
+	"bytes"
 	"errors"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -14,6 +16,7 @@ import (
 	"github.com/OutOfStack/game-library-auth/internal/database"
 	"github.com/OutOfStack/game-library-auth/internal/handlers"
 	handlers_mocks "github.com/OutOfStack/game-library-auth/internal/handlers/mocks"
+	"github.com/OutOfStack/game-library-auth/internal/model"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
@@ -24,7 +27,11 @@ func setupUnsubscribeTest(t *testing.T) (*handlers.UnsubscribeAPI, *handlers_moc
 	ctrl := gomock.NewController(t)
 	mockFacade := handlers_mocks.NewMockUnsubscribeFacade(ctrl)
 	log := zap.NewNop()
-	tokenGen := auth.NewUnsubscribeTokenGenerator([]byte("test-secret-key"))
+	signedTokens, err := auth.NewSignedTokens(auth.SigningKey{ID: "test", Secret: []byte("test-secret-key")})
+	if err != nil {
+		t.Fatalf("create signed tokens: %v", err)
+	}
+	tokenGen := auth.NewUnsubscribeTokenGenerator(signedTokens, nil)
 	api := handlers.NewUnsubscribeAPI(log, tokenGen, mockFacade, "test@example.com")
 	return api, mockFacade, ctrl, tokenGen
 }
@@ -43,8 +50,11 @@ func TestUnsubscribeHandler_Success(t *testing.T) {
 	token := tokenGen.GenerateToken(email, expiresAt)
 
 	mockFacade.EXPECT().
-		IsEmailUnsubscribed(gomock.Any(), email).
-		Return(false, nil)
+		GetEmailPreferences(gomock.Any(), email).
+		Return([]model.EmailPreference{
+			{Category: model.EmailCategorySecurity, Subscribed: true},
+			{Category: model.EmailCategoryProductNews, Subscribed: true},
+		}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/unsubscribe?token="+token, nil)
 	resp, err := app.Test(req)
@@ -134,7 +144,7 @@ func TestUnsubscribeHandler_ExpiredToken(t *testing.T) {
 	}
 }
 
-func TestUnsubscribeHandler_AlreadyUnsubscribed(t *testing.T) {
+func TestUnsubscribeHandler_GetPreferencesError(t *testing.T) {
 	api, mockFacade, ctrl, tokenGen := setupUnsubscribeTest(t)
 	defer ctrl.Finish()
 
@@ -148,8 +158,8 @@ func TestUnsubscribeHandler_AlreadyUnsubscribed(t *testing.T) {
 	token := tokenGen.GenerateToken(email, expiresAt)
 
 	mockFacade.EXPECT().
-		IsEmailUnsubscribed(gomock.Any(), email).
-		Return(true, nil)
+		GetEmailPreferences(gomock.Any(), email).
+		Return(nil, errors.New("database error"))
 
 	req := httptest.NewRequest(http.MethodGet, "/unsubscribe?token="+token, nil)
 	resp, err := app.Test(req)
@@ -158,18 +168,47 @@ func TestUnsubscribeHandler_AlreadyUnsubscribed(t *testing.T) {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "Failed to get email preferences") {
+		t.Errorf("expected 'Failed to get email preferences' message, got %s", string(body))
+	}
+}
+
+func TestUnsubscribeConfirmHandler_MissingToken(t *testing.T) {
+	api, _, ctrl, _ := setupUnsubscribeTest(t)
+	defer ctrl.Finish()
+
+	app := fiber.New()
+	app.Post("/unsubscribe", api.UnsubscribeConfirmHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/unsubscribe", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "Missing token") {
+		t.Errorf("expected 'Missing token' message, got %s", string(body))
 	}
 }
 
-func TestUnsubscribeConfirmHandler_Success(t *testing.T) {
+func TestUnsubscribeConfirmHandler_NotFound(t *testing.T) {
 	api, mockFacade, ctrl, tokenGen := setupUnsubscribeTest(t)
 	defer ctrl.Finish()
 
-	app := fiber.New(fiber.Config{
-		Views: &mockTemplateEngine{},
-	})
+	app := fiber.New()
 	app.Post("/unsubscribe", api.UnsubscribeConfirmHandler)
 
 	email := "test@example.com"
@@ -178,7 +217,7 @@ func TestUnsubscribeConfirmHandler_Success(t *testing.T) {
 
 	mockFacade.EXPECT().
 		UnsubscribeEmail(gomock.Any(), token).
-		Return(email, nil)
+		Return("", database.ErrNotFound)
 
 	form := url.Values{}
 	form.Add("token", token)
@@ -192,19 +231,118 @@ func TestUnsubscribeConfirmHandler_Success(t *testing.T) {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "Unsubscribe link not found or expired") {
+		t.Errorf("expected 'Unsubscribe link not found or expired' message, got %s", string(body))
 	}
 }
 
-func TestUnsubscribeConfirmHandler_MissingToken(t *testing.T) {
+func TestUnsubscribeConfirmHandler_DatabaseError(t *testing.T) {
+	api, mockFacade, ctrl, tokenGen := setupUnsubscribeTest(t)
+	defer ctrl.Finish()
+
+	app := fiber.New()
+	app.Post("/unsubscribe", api.UnsubscribeConfirmHandler)
+
+	email := "test@example.com"
+	expiresAt := time.Now().Add(24 * time.Hour)
+	token := tokenGen.GenerateToken(email, expiresAt)
+
+	dbError := errors.New("database error")
+	mockFacade.EXPECT().
+		UnsubscribeEmail(gomock.Any(), token).
+		Return("", dbError)
+
+	form := url.Values{}
+	form.Add("token", token)
+
+	req := httptest.NewRequest(http.MethodPost, "/unsubscribe", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "Failed to process unsubscribe request") {
+		t.Errorf("expected 'Failed to process unsubscribe request' message, got %s", string(body))
+	}
+}
+
+func TestUnsubscribeConfirmHandler_OneClick(t *testing.T) {
+	api, mockFacade, ctrl, tokenGen := setupUnsubscribeTest(t)
+	defer ctrl.Finish()
+
+	// no views, one-click unsubscribe doesn't render page
+	app := fiber.New()
+	app.Post("/unsubscribe", api.UnsubscribeConfirmHandler)
+
+	email := "test@example.com"
+	token := tokenGen.GenerateToken(email, time.Now().Add(24*time.Hour))
+
+	tests := []struct {
+		name string
+		body func() (io.Reader, string)
+	}{
+		{
+			name: "form body",
+			body: func() (io.Reader, string) {
+				return strings.NewReader("List-Unsubscribe=One-Click"), "application/x-www-form-urlencoded"
+			},
+		},
+		{
+			name: "multipart body",
+			body: func() (io.Reader, string) {
+				var buf bytes.Buffer
+				mw := multipart.NewWriter(&buf)
+				_ = mw.WriteField("List-Unsubscribe", "One-Click")
+				_ = mw.Close()
+				return &buf, mw.FormDataContentType()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFacade.EXPECT().
+				UnsubscribeEmail(gomock.Any(), token).
+				Return(email, nil)
+
+			body, contentType := tt.body()
+			req := httptest.NewRequest(http.MethodPost, "/unsubscribe?token="+url.QueryEscape(token), body)
+			req.Header.Set("Content-Type", contentType)
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("expected status 200, got %d", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestUnsubscribeConfirmHandler_OneClickInvalidToken(t *testing.T) {
 	api, _, ctrl, _ := setupUnsubscribeTest(t)
 	defer ctrl.Finish()
 
 	app := fiber.New()
 	app.Post("/unsubscribe", api.UnsubscribeConfirmHandler)
 
-	req := httptest.NewRequest(http.MethodPost, "/unsubscribe", nil)
+	req := httptest.NewRequest(http.MethodPost, "/unsubscribe?token=invalid-token", strings.NewReader("List-Unsubscribe=One-Click"))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	resp, err := app.Test(req)
@@ -218,30 +356,42 @@ func TestUnsubscribeConfirmHandler_MissingToken(t *testing.T) {
 	}
 
 	body, _ := io.ReadAll(resp.Body)
-	if !strings.Contains(string(body), "Missing token") {
-		t.Errorf("expected 'Missing token' message, got %s", string(body))
+	if !strings.Contains(string(body), "Invalid or expired unsubscribe link") {
+		t.Errorf("expected 'Invalid or expired unsubscribe link' message, got %s", string(body))
 	}
 }
 
-func TestUnsubscribeConfirmHandler_NotFound(t *testing.T) {
+func TestEmailPreferencesHandler_Success(t *testing.T) {
 	api, mockFacade, ctrl, tokenGen := setupUnsubscribeTest(t)
 	defer ctrl.Finish()
 
-	app := fiber.New()
-	app.Post("/unsubscribe", api.UnsubscribeConfirmHandler)
+	app := fiber.New(fiber.Config{
+		Views: &mockTemplateEngine{},
+	})
+	app.Post("/email-preferences", api.EmailPreferencesHandler)
 
 	email := "test@example.com"
 	expiresAt := time.Now().Add(24 * time.Hour)
 	token := tokenGen.GenerateToken(email, expiresAt)
 
-	mockFacade.EXPECT().
-		UnsubscribeEmail(gomock.Any(), token).
-		Return("", database.ErrNotFound)
+	gomock.InOrder(
+		mockFacade.EXPECT().
+			UpdateEmailPreferences(gomock.Any(), token, []string{model.EmailCategoryPublisherReports}).
+			Return(email, nil),
+		mockFacade.EXPECT().
+			GetEmailPreferences(gomock.Any(), email).
+			Return([]model.EmailPreference{
+				{Category: model.EmailCategorySecurity, Subscribed: true},
+				{Category: model.EmailCategoryProductNews, Subscribed: false},
+				{Category: model.EmailCategoryPublisherReports, Subscribed: true},
+			}, nil),
+	)
 
 	form := url.Values{}
 	form.Add("token", token)
+	form.Add("category", model.EmailCategoryPublisherReports)
 
-	req := httptest.NewRequest(http.MethodPost, "/unsubscribe", strings.NewReader(form.Encode()))
+	req := httptest.NewRequest(http.MethodPost, "/email-preferences", strings.NewReader(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	resp, err := app.Test(req)
@@ -250,36 +400,222 @@ func TestUnsubscribeConfirmHandler_NotFound(t *testing.T) {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNotFound {
-		t.Errorf("expected status 404, got %d", resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestEmailPreferencesHandler_MissingToken(t *testing.T) {
+	api, _, ctrl, _ := setupUnsubscribeTest(t)
+	defer ctrl.Finish()
+
+	app := fiber.New()
+	app.Post("/email-preferences", api.EmailPreferencesHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/email-preferences", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestEmailPreferencesHandler_InvalidToken(t *testing.T) {
+	api, _, ctrl, _ := setupUnsubscribeTest(t)
+	defer ctrl.Finish()
+
+	app := fiber.New()
+	app.Post("/email-preferences", api.EmailPreferencesHandler)
+
+	form := url.Values{}
+	form.Add("token", "invalid-token")
+
+	req := httptest.NewRequest(http.MethodPost, "/email-preferences", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", resp.StatusCode)
 	}
 
 	body, _ := io.ReadAll(resp.Body)
-	if !strings.Contains(string(body), "Unsubscribe link not found or expired") {
-		t.Errorf("expected 'Unsubscribe link not found or expired' message, got %s", string(body))
+	if !strings.Contains(string(body), "Invalid or expired unsubscribe link") {
+		t.Errorf("expected 'Invalid or expired unsubscribe link' message, got %s", string(body))
 	}
 }
 
-func TestUnsubscribeConfirmHandler_DatabaseError(t *testing.T) {
+func TestEmailPreferencesHandler_UpdateError(t *testing.T) {
 	api, mockFacade, ctrl, tokenGen := setupUnsubscribeTest(t)
 	defer ctrl.Finish()
 
 	app := fiber.New()
-	app.Post("/unsubscribe", api.UnsubscribeConfirmHandler)
+	app.Post("/email-preferences", api.EmailPreferencesHandler)
+
+	token := tokenGen.GenerateToken("test@example.com", time.Now().Add(24*time.Hour))
+
+	mockFacade.EXPECT().
+		UpdateEmailPreferences(gomock.Any(), token, nil).
+		Return("", errors.New("database error"))
+
+	form := url.Values{}
+	form.Add("token", token)
+
+	req := httptest.NewRequest(http.MethodPost, "/email-preferences", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "Failed to save email preferences") {
+		t.Errorf("expected 'Failed to save email preferences' message, got %s", string(body))
+	}
+}
+
+func TestResubscribeHandler_Success(t *testing.T) {
+	api, mockFacade, ctrl, tokenGen := setupUnsubscribeTest(t)
+	defer ctrl.Finish()
+
+	app := fiber.New(fiber.Config{
+		Views: &mockTemplateEngine{},
+	})
+	app.Post("/resubscribe", api.ResubscribeHandler)
 
 	email := "test@example.com"
 	expiresAt := time.Now().Add(24 * time.Hour)
-	token := tokenGen.GenerateToken(email, expiresAt)
+	token := tokenGen.GenerateResubscribeToken(email, expiresAt)
 
-	dbError := errors.New("database error")
 	mockFacade.EXPECT().
-		UnsubscribeEmail(gomock.Any(), token).
-		Return("", dbError)
+		ResubscribeEmail(gomock.Any(), token).
+		Return(email, nil)
 
 	form := url.Values{}
 	form.Add("token", token)
 
-	req := httptest.NewRequest(http.MethodPost, "/unsubscribe", strings.NewReader(form.Encode()))
+	req := httptest.NewRequest(http.MethodPost, "/resubscribe", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestResubscribeHandler_MissingToken(t *testing.T) {
+	api, _, ctrl, _ := setupUnsubscribeTest(t)
+	defer ctrl.Finish()
+
+	app := fiber.New()
+	app.Post("/resubscribe", api.ResubscribeHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/resubscribe", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestResubscribeHandler_InvalidToken(t *testing.T) {
+	api, _, ctrl, _ := setupUnsubscribeTest(t)
+	defer ctrl.Finish()
+
+	app := fiber.New()
+	app.Post("/resubscribe", api.ResubscribeHandler)
+
+	form := url.Values{}
+	form.Add("token", "invalid-token")
+
+	req := httptest.NewRequest(http.MethodPost, "/resubscribe", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "Invalid or expired resubscribe link") {
+		t.Errorf("expected 'Invalid or expired resubscribe link' message, got %s", string(body))
+	}
+}
+
+func TestResubscribeHandler_UnsubscribeToken(t *testing.T) {
+	api, _, ctrl, tokenGen := setupUnsubscribeTest(t)
+	defer ctrl.Finish()
+
+	app := fiber.New()
+	app.Post("/resubscribe", api.ResubscribeHandler)
+
+	// unsubscribe token can't be used to resubscribe
+	form := url.Values{}
+	form.Add("token", tokenGen.GenerateToken("test@example.com", time.Now().Add(24*time.Hour)))
+
+	req := httptest.NewRequest(http.MethodPost, "/resubscribe", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestResubscribeHandler_FacadeError(t *testing.T) {
+	api, mockFacade, ctrl, tokenGen := setupUnsubscribeTest(t)
+	defer ctrl.Finish()
+
+	app := fiber.New()
+	app.Post("/resubscribe", api.ResubscribeHandler)
+
+	token := tokenGen.GenerateResubscribeToken("test@example.com", time.Now().Add(24*time.Hour))
+
+	mockFacade.EXPECT().
+		ResubscribeEmail(gomock.Any(), token).
+		Return("", errors.New("database error"))
+
+	form := url.Values{}
+	form.Add("token", token)
+
+	req := httptest.NewRequest(http.MethodPost, "/resubscribe", strings.NewReader(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	resp, err := app.Test(req)
@@ -293,8 +629,8 @@ func TestUnsubscribeConfirmHandler_DatabaseError(t *testing.T) {
 	}
 
 	body, _ := io.ReadAll(resp.Body)
-	if !strings.Contains(string(body), "Failed to process unsubscribe request") {
-		t.Errorf("expected 'Failed to process unsubscribe request' message, got %s", string(body))
+	if !strings.Contains(string(body), "Failed to process resubscribe request") {
+		t.Errorf("expected 'Failed to process resubscribe request' message, got %s", string(body))
 	}
 }
 
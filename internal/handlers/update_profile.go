@@ -9,6 +9,7 @@ import (
 	"github.com/OutOfStack/game-library-auth/internal/web"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
+	"golang.org/x/text/language"
 )
 
 // UpdateProfileHandler godoc
@@ -74,11 +75,18 @@ func (a *AuthAPI) UpdateProfileHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	// store locale in canonical form of language and region, e.g. "es-mx" as "es-MX"
+	if params.Locale != nil {
+		locale := normalizeLocale(language.Make(*params.Locale))
+		params.Locale = &locale
+	}
+
 	// update profile
 	updatedUser, err := a.userFacade.UpdateUserProfile(ctx, userID, model.UpdateProfileParams{
 		Name:        params.Name,
 		Password:    params.Password,
 		NewPassword: params.NewPassword,
+		Locale:      params.Locale,
 	})
 	if err != nil {
 		switch {
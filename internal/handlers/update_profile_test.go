@@ -2,6 +2,7 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -26,6 +27,9 @@ func TestUpdateProfileHandler(t *testing.T) {
 	oldPassword := "oldpassword"
 	newPassword := "newpassword"
 	newName := "Updated DisplayName"
+	newLocale := "es-mx"
+	extendedLocale := "es-mx-u-ca-gregory"
+	longLocale := "en-US-u-ca-gregory-co-phonebk-nu-latn-x-foo"
 
 	tests := []struct {
 		name           string
@@ -65,6 +69,88 @@ func TestUpdateProfileHandler(t *testing.T) {
 				AccessToken: "updated.jwt.token",
 			},
 		},
+		{
+			name:       "successful update locale",
+			authHeader: "Bearer valid-token",
+			request: handlers.UpdateProfileReq{
+				Locale: &newLocale,
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				claims := auth_.Claims{UserID: userID}
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(claims, nil).
+					AnyTimes()
+
+				updated := model.User{ID: userID, Username: "testuser", Locale: "es-MX"}
+				mockUserFacade.EXPECT().
+					UpdateUserProfile(gomock.Any(), userID, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, params model.UpdateProfileParams) (model.User, error) {
+						require.NotNil(t, params.Locale)
+						assert.Equal(t, "es-MX", *params.Locale)
+						return updated, nil
+					})
+
+				mockUserFacade.EXPECT().
+					CreateTokens(gomock.Any(), updated).
+					Return(facade.TokenPair{
+						AccessToken:  "updated.jwt.token",
+						RefreshToken: facade.RefreshToken{Token: "updated.refresh.token"},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.TokenResp{
+				AccessToken: "updated.jwt.token",
+			},
+		},
+		{
+			name:       "update locale with extensions",
+			authHeader: "Bearer valid-token",
+			request: handlers.UpdateProfileReq{
+				Locale: &extendedLocale,
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				claims := auth_.Claims{UserID: userID}
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(claims, nil).
+					AnyTimes()
+
+				updated := model.User{ID: userID, Username: "testuser", Locale: "es-MX"}
+				mockUserFacade.EXPECT().
+					UpdateUserProfile(gomock.Any(), userID, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, params model.UpdateProfileParams) (model.User, error) {
+						require.NotNil(t, params.Locale)
+						assert.Equal(t, "es-MX", *params.Locale)
+						return updated, nil
+					})
+
+				mockUserFacade.EXPECT().
+					CreateTokens(gomock.Any(), updated).
+					Return(facade.TokenPair{
+						AccessToken:  "updated.jwt.token",
+						RefreshToken: facade.RefreshToken{Token: "updated.refresh.token"},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: handlers.TokenResp{
+				AccessToken: "updated.jwt.token",
+			},
+		},
+		{
+			name:       "too long locale",
+			authHeader: "Bearer valid-token",
+			request: handlers.UpdateProfileReq{
+				Locale: &longLocale,
+			},
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().
+					ValidateAccessToken("valid-token").
+					Return(auth_.Claims{UserID: userID}, nil).
+					AnyTimes()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name:       "successful update password",
 			authHeader: "Bearer valid-token",
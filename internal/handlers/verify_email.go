@@ -19,7 +19,7 @@ import (
 // @Produce      json
 // @Param        verification body VerifyEmailReq true "Email verification code"
 // @Success      200 {object} TokenResp
-// @Failure      400 {object} web.ErrResp "Invalid or expired verification code"
+// @Failure      400 {object} web.ErrResp "Invalid or expired verification code or too many failed attempts"
 // @Failure      401 {object} web.ErrResp "Invalid or missing authorization token"
 // @Failure      404 {object} web.ErrResp "Verification code is not found"
 // @Failure      500 {object} web.ErrResp "Internal server error"
@@ -68,6 +68,10 @@ func (a *AuthAPI) VerifyEmailHandler(c *fiber.Ctx) error {
 			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
 				Error: invalidOrExpiredVrfCodeMsg,
 			})
+		case errors.Is(err, facade.ErrVerifyEmailTooManyAttempts):
+			return c.Status(http.StatusBadRequest).JSON(web.ErrResp{
+				Error: "Too many failed attempts. Please request a new verification code",
+			})
 		default:
 			a.log.Error("verify email", zap.Error(err))
 			return c.Status(http.StatusInternalServerError).JSON(web.ErrResp{
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// VerifyEmailLinkHandler handles GET /verify-email/confirm?token=xxx - shows email verification confirmation page.
+// Email is verified only after confirmation, so link prefetching by email clients doesn't use it
+func (a *AuthAPI) VerifyEmailLinkHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "verifyEmailLinkHandler")
+	defer span.End()
+
+	token := c.Query("token")
+	if token == "" {
+		return c.Status(http.StatusBadRequest).SendString("Missing token parameter")
+	}
+
+	email, err := a.userFacade.CheckEmailVerificationLink(ctx, token)
+	if err != nil {
+		switch {
+		case errors.Is(err, facade.ErrVerifyEmailAlreadyVerified):
+			return c.Render("verify_email_success", fiber.Map{
+				"ContactEmail": a.cfg.ContactEmail,
+			})
+		case errors.Is(err, facade.ErrVerifyEmailInvalidOrExpired), errors.Is(err, facade.ErrVerifyEmailUserNotFound):
+			return c.Status(http.StatusBadRequest).SendString(invalidOrExpiredVrfLinkMsg)
+		default:
+			a.log.Error("check email verification link", zap.Error(err))
+			return c.Status(http.StatusInternalServerError).SendString("Failed to check verification link")
+		}
+	}
+
+	// render confirmation page
+	return c.Render("verify_email", fiber.Map{
+		"Email":        email,
+		"Token":        token,
+		"ContactEmail": a.cfg.ContactEmail,
+	})
+}
+
+// VerifyEmailLinkConfirmHandler handles POST /verify-email/confirm - verifies email address by link token
+func (a *AuthAPI) VerifyEmailLinkConfirmHandler(c *fiber.Ctx) error {
+	ctx, span := tracer.Start(c.Context(), "verifyEmailLinkConfirmHandler")
+	defer span.End()
+
+	token := c.FormValue("token")
+	if token == "" {
+		return c.Status(http.StatusBadRequest).SendString("Missing token")
+	}
+
+	email, err := a.userFacade.VerifyEmailWithLink(ctx, token)
+	if err != nil {
+		switch {
+		case errors.Is(err, facade.ErrVerifyEmailAlreadyVerified):
+			// link could be opened on several devices
+		case errors.Is(err, facade.ErrVerifyEmailInvalidOrExpired), errors.Is(err, facade.ErrVerifyEmailUserNotFound):
+			return c.Status(http.StatusBadRequest).SendString(invalidOrExpiredVrfLinkMsg)
+		default:
+			a.log.Error("verify email with link", zap.Error(err))
+			return c.Status(http.StatusInternalServerError).SendString("Failed to verify email")
+		}
+	}
+
+	// render success page
+	return c.Render("verify_email_success", fiber.Map{
+		"Email":        email,
+		"ContactEmail": a.cfg.ContactEmail,
+	})
+}
@@ -0,0 +1,174 @@
+package handlers_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/OutOfStack/game-library-auth/internal/facade"
+	mocks "github.com/OutOfStack/game-library-auth/internal/handlers/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestVerifyEmailLinkHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "valid link",
+			token: "valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().CheckEmailVerificationLink(gomock.Any(), "valid-token").Return("test@example.com", nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "already verified",
+			token: "valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().CheckEmailVerificationLink(gomock.Any(), "valid-token").Return("", facade.ErrVerifyEmailAlreadyVerified)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing token",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Missing token parameter",
+		},
+		{
+			name:  "invalid or expired link",
+			token: "invalid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().CheckEmailVerificationLink(gomock.Any(), "invalid-token").Return("", facade.ErrVerifyEmailInvalidOrExpired)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid or expired verification link",
+		},
+		{
+			name:  "facade error",
+			token: "valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().CheckEmailVerificationLink(gomock.Any(), "valid-token").Return("", errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Get("/verify-email/confirm", authAPI.VerifyEmailLinkHandler)
+
+			target := "/verify-email/confirm"
+			if tt.token != "" {
+				target += "?token=" + url.QueryEscape(tt.token)
+			}
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedBody != "" {
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestVerifyEmailLinkConfirmHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		setupMocks     func(*mocks.MockUserFacade)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "successful verification",
+			token: "valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().VerifyEmailWithLink(gomock.Any(), "valid-token").Return("test@example.com", nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "already verified",
+			token: "valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().VerifyEmailWithLink(gomock.Any(), "valid-token").Return("", facade.ErrVerifyEmailAlreadyVerified)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing token",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Missing token",
+		},
+		{
+			name:  "link already used",
+			token: "used-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().VerifyEmailWithLink(gomock.Any(), "used-token").Return("", facade.ErrVerifyEmailInvalidOrExpired)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid or expired verification link",
+		},
+		{
+			name:  "facade error",
+			token: "valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().VerifyEmailWithLink(gomock.Any(), "valid-token").Return("", errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, authAPI, mockUserFacade, app, ctrl := setupTest(t, nil)
+			defer ctrl.Finish()
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserFacade)
+			}
+
+			app.Post("/verify-email/confirm", authAPI.VerifyEmailLinkConfirmHandler)
+
+			form := url.Values{}
+			if tt.token != "" {
+				form.Set("token", tt.token)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/verify-email/confirm", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedBody != "" {
+				body, _ := io.ReadAll(resp.Body)
+				assert.Contains(t, string(body), tt.expectedBody)
+			}
+		})
+	}
+}
@@ -70,6 +70,18 @@ func TestVerifyEmailHandler(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedResp:   web.ErrResp{Error: "Invalid or expired verification code"},
 		},
+		{
+			name: "too many failed attempts",
+			request: handlers.VerifyEmailReq{
+				Code: code,
+			},
+			authHeader: "Bearer valid-token",
+			setupMocks: func(mockUserFacade *mocks.MockUserFacade) {
+				mockUserFacade.EXPECT().VerifyEmail(gomock.Any(), userID, code).Return(model.User{}, facade.ErrVerifyEmailTooManyAttempts)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   web.ErrResp{Error: "Too many failed attempts. Please request a new verification code"},
+		},
 		{
 			name: "user email already verified",
 			request: handlers.VerifyEmailReq{
@@ -82,6 +94,15 @@ func TestVerifyEmailHandler(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedResp:   web.ErrResp{Error: "Email is already verified"},
 		},
+		{
+			name: "code with invalid characters",
+			request: handlers.VerifyEmailReq{
+				Code: "12-456",
+			},
+			authHeader:     "Bearer valid-token",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   web.ErrResp{Error: "Validation error"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	// EmailChangeCodeTTL is the time a code confirming new email address is valid for
+	EmailChangeCodeTTL = 30 * time.Minute
+
+	// ResendEmailChangeCodeCooldown is the cooldown period between email change code resends
+	ResendEmailChangeCodeCooldown = 60 * time.Second
+
+	// MaxEmailChangeCodeAttempts is the number of failed attempts after which email change code is invalidated
+	MaxEmailChangeCodeAttempts = 5
+)
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+)
+
+// Email delivery statuses reported by email provider
+const (
+	EmailDeliveryStatusDelivered  = "delivered"
+	EmailDeliveryStatusBounced    = "bounced"
+	EmailDeliveryStatusComplained = "complained"
+)
+
+// Email unsubscribe reasons. Unsubscribed addresses don't receive any emails.
+// Unsubscribing by link in email only opts out of email categories, see EmailPreference
+const (
+	// UnsubscribeReasonHardBounce - email address permanently rejected email
+	UnsubscribeReasonHardBounce = "hard_bounce"
+	// UnsubscribeReasonComplaint - recipient marked email as spam
+	UnsubscribeReasonComplaint = "complaint"
+)
+
+// EmailDeliveryEvent represents delivery event of sent email reported by email provider
+type EmailDeliveryEvent struct {
+	// EventID - id of event assigned by provider, repeated deliveries of the same event have the same id
+	EventID   string
+	MessageID string
+	Email     string
+	Status    string
+	// HardBounce - email address rejected email permanently
+	HardBounce bool
+	Detail     string
+	OccurredAt time.Time
+}
+
+// EmailDeliveryStatus represents delivery status of user email address
+type EmailDeliveryStatus struct {
+	Email string
+	// Status - status of the last delivery event, empty if there were none
+	Status            string
+	StatusAt          *time.Time
+	Unsubscribed      bool
+	UnsubscribeReason string
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+)
+
+// Outgoing email kinds
+const (
+	EmailKindVerification     = "email_verification"
+	EmailKindSignIn           = "email_sign_in"
+	EmailKindRecoveryCodeUsed = "recovery_code_used"
+	EmailKindEmailChange      = "email_change"
+	EmailKindEmailChanged     = "email_changed"
+	EmailKindSecurityNotice   = "security_notice"
+)
+
+// Email outbox message statuses
+const (
+	EmailOutboxStatusPending = "pending"
+	EmailOutboxStatusSent    = "sent"
+	EmailOutboxStatusFailed  = "failed"
+)
+
+// Email message statuses. Status of sent message is replaced by delivery status reported by email provider
+const (
+	EmailMessageStatusSent   = "sent"
+	EmailMessageStatusFailed = "failed"
+)
+
+const (
+	// MaxEmailOutboxAttempts is the number of send attempts after which outgoing email is marked as failed
+	MaxEmailOutboxAttempts = 6
+
+	// EmailOutboxRetryInitialDelay is the delay before the first retry of failed email send. Delay doubles with every attempt
+	EmailOutboxRetryInitialDelay = 10 * time.Second
+
+	// EmailOutboxRetryMaxDelay is the maximal delay between retries of failed email send
+	EmailOutboxRetryMaxDelay = 10 * time.Minute
+
+	// EmailMessageHistoryLimit is the maximal number of the latest email messages returned in send history of user
+	EmailMessageHistoryLimit = 100
+)
+
+// EmailMessage represents send attempt of outgoing email
+type EmailMessage struct {
+	ID        string
+	Template  string
+	Recipient string
+	// Provider - name of email provider that sent the message, empty if send failed
+	Provider string
+	// ProviderMessageID - id of message assigned by email provider, empty if send failed
+	ProviderMessageID string
+	Status            string
+	Error             string
+	DateCreated       time.Time
+	DateUpdated       time.Time
+}
+
+// IsUrgentEmailKind reports whether emails of kind are needed by user right away, e.g. codes completing user action.
+// Other emails are notices that can wait, they are deferred while sending quota of email provider is exhausted
+func IsUrgentEmailKind(kind string) bool {
+	switch kind {
+	case EmailKindVerification, EmailKindSignIn, EmailKindEmailChange:
+		return true
+	default:
+		return false
+	}
+}
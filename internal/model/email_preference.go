@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// ResubscribeTokenTTL - time resubscribe link shown after unsubscribing is valid for
+const ResubscribeTokenTTL = 24 * time.Hour
+
+// Email categories. Every outgoing email belongs to one category
+const (
+	// EmailCategorySecurity - verification codes, sign in links and account security notices. Can't be unsubscribed from
+	EmailCategorySecurity = "security"
+	// EmailCategoryProductNews - news about games and features of Game Library
+	EmailCategoryProductNews = "product_news"
+	// EmailCategoryPublisherReports - reports about games of publisher
+	EmailCategoryPublisherReports = "publisher_reports"
+)
+
+// Email resubscribe sources
+const (
+	// ResubscribeSourceAccount - account owner resubscribed their verified email address
+	ResubscribeSourceAccount = "account"
+	// ResubscribeSourceLink - recipient resubscribed by signed link after unsubscribing
+	ResubscribeSourceLink = "link"
+)
+
+// EmailCategories - all email categories in order they are shown in preference center
+var EmailCategories = []string{EmailCategorySecurity, EmailCategoryProductNews, EmailCategoryPublisherReports}
+
+// IsEmailCategorySuppressible checks if email address can be unsubscribed from emails of category
+func IsEmailCategorySuppressible(category string) bool {
+	return category != EmailCategorySecurity
+}
+
+// EmailKindCategory returns category of outgoing email kind. Kinds that are not account related are product news
+func EmailKindCategory(kind string) string {
+	switch kind {
+	case EmailKindVerification, EmailKindSignIn, EmailKindRecoveryCodeUsed, EmailKindEmailChange, EmailKindEmailChanged, EmailKindSecurityNotice:
+		return EmailCategorySecurity
+	default:
+		return EmailCategoryProductNews
+	}
+}
+
+// EmailPreference represents subscription of email address to emails of category.
+// Email address is subscribed to all categories until it unsubscribes
+type EmailPreference struct {
+	Category   string
+	Subscribed bool
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	// EmailSignInCodeTTL is the time a passwordless sign in code and link are valid for
+	EmailSignInCodeTTL = 15 * time.Minute
+
+	// ResendEmailSignInCodeCooldown is the cooldown period between passwordless sign in code resends
+	ResendEmailSignInCodeCooldown = 60 * time.Second
+
+	// MaxEmailSignInCodeAttempts is the number of failed attempts after which passwordless sign in code is invalidated
+	MaxEmailSignInCodeAttempts = 5
+)
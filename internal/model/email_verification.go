@@ -1,15 +1,14 @@
 package model
 
-import (
-	"time"
+const (
+	// MaxVerificationCodeAttempts is the number of failed attempts after which verification code is invalidated
+	MaxVerificationCodeAttempts = 5
 )
 
+// Verification code alphabets
 const (
-	// VerificationCodeTTL is the time a verification code is valid for
-	VerificationCodeTTL = 24 * time.Hour
-	// UnsubscribeTokenTTL is the time an unsubscribe token is valid for
-	UnsubscribeTokenTTL = 7 * 24 * time.Hour
-
-	// ResendVerificationCodeCooldown is the cooldown period between verification code resends
-	ResendVerificationCodeCooldown = 120 * time.Second
+	// VerificationCodeAlphabetNumeric - code consists of digits
+	VerificationCodeAlphabetNumeric = "numeric"
+	// VerificationCodeAlphabetAlphanumeric - code consists of uppercase letters and digits without easily confused characters
+	VerificationCodeAlphabetAlphanumeric = "alphanumeric"
 )
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// Login attempt kinds
+const (
+	// LoginAttemptKindUsername - failed sign in attempts tracked per username
+	LoginAttemptKindUsername = "username"
+	// LoginAttemptKindIP - failed sign in attempts tracked per client ip
+	LoginAttemptKindIP = "ip"
+)
+
+const (
+	// UsernameLoginFreeAttempts is the number of failed sign in attempts per username allowed before delays are applied
+	UsernameLoginFreeAttempts = 5
+	// IPLoginFreeAttempts is the number of failed sign in attempts per client ip allowed before delays are applied
+	IPLoginFreeAttempts = 20
+
+	// LoginLockoutBaseDelay is the delay applied after the first failed attempt exceeding free attempts. It doubles with every next failure
+	LoginLockoutBaseDelay = time.Second
+	// LoginLockoutMaxDelay is the maximum time sign in can be locked for
+	LoginLockoutMaxDelay = 15 * time.Minute
+	// LoginFailuresResetWindow is the period without failed attempts after which the failure counter starts over
+	LoginFailuresResetWindow = time.Hour
+)
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+// Passkey ceremony session kinds
+const (
+	WebAuthnSessionKindRegistration = "registration"
+	WebAuthnSessionKindSignIn       = "signin"
+)
+
+const (
+	// WebAuthnRPDisplayName is the relying party name displayed by authenticators
+	WebAuthnRPDisplayName = "Game Library"
+	// WebAuthnCeremonyTTL is the time passkey registration or sign in ceremony has to be completed within
+	WebAuthnCeremonyTTL = 5 * time.Minute
+)
+
+// Passkey represents passkey credential registered by user
+type Passkey struct {
+	ID          string
+	Name        string
+	DateCreated time.Time
+	LastUsedAt  *time.Time
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+)
+
+// Security event types. User is notified about security events by email, every event type has its own template
+const (
+	SecurityEventPasswordChanged          = "password_changed"
+	SecurityEventNewSignIn                = "new_sign_in"
+	SecurityEventEmailChanged             = "email_changed"
+	SecurityEventAccountDeletionRequested = "account_deletion_requested"
+	SecurityEventTwoFactorEnabled         = "two_factor_enabled"
+	SecurityEventTwoFactorDisabled        = "two_factor_disabled"
+	SecurityEventRecoveryCodesRegenerated = "recovery_codes_regenerated"
+)
+
+// MaxUserAgentLength is the maximal length of stored and displayed client user agent
+const MaxUserAgentLength = 255
+
+// SecurityEvent represents security relevant event of user account
+type SecurityEvent struct {
+	Type     string
+	ClientIP string
+	// UserAgent - user agent of client, set for sign in events
+	UserAgent string
+	// NewEmail - new email address, set for email change event
+	NewEmail   string
+	OccurredAt time.Time
+}
+
+// ClientInfo represents client the request is made from
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	// TOTPIssuer is the issuer name displayed in authenticator apps
+	TOTPIssuer = "Game Library"
+	// TwoFactorChallengeTTL is the time two-factor authentication challenge token is valid for
+	TwoFactorChallengeTTL = 5 * time.Minute
+)
+
+// TOTPEnrollment contains TOTP secret and provisioning uri for authenticator apps
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+const (
+	// RecoveryCodesCount is the number of recovery codes generated in a batch
+	RecoveryCodesCount = 10
+	// RecoveryCodeLength is the number of significant characters in a recovery code, not counting separator
+	RecoveryCodeLength = 10
+)
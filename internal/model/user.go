@@ -3,6 +3,9 @@ package model
 // Role - user role
 type Role string
 
+// DefaultLocale - locale of users who didn't provide one
+const DefaultLocale = "en"
+
 // User role names
 const (
 	UserRoleName      Role = "user"
@@ -16,6 +19,7 @@ type User struct {
 	DisplayName   string
 	Email         string
 	EmailVerified bool
+	Locale        string
 	Role          string
 	OAuthProvider string
 	OAuthID       string
@@ -31,4 +35,5 @@ type UpdateProfileParams struct {
 	Name        *string
 	Password    *string
 	NewPassword *string
+	Locale      *string
 }
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const memoryStoreCleanupInterval = time.Minute
+
+type counter struct {
+	count   int
+	resetAt time.Time
+}
+
+// MemoryStore is an in-memory rate limit store. Counters are not shared between service instances
+type MemoryStore struct {
+	mu          sync.Mutex
+	counters    map[string]counter
+	nextCleanup time.Time
+}
+
+// NewMemoryStore creates a new in-memory rate limit store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counters:    make(map[string]counter),
+		nextCleanup: time.Now().Add(memoryStoreCleanupInterval),
+	}
+}
+
+// IncrementRateLimit increments counter for key and returns its value and time when current window ends
+func (s *MemoryStore) IncrementRateLimit(_ context.Context, key string, window time.Duration) (int, time.Time, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanup(now)
+
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.resetAt) {
+		c = counter{resetAt: now.Add(window)}
+	}
+	c.count++
+	s.counters[key] = c
+
+	return c.count, c.resetAt, nil
+}
+
+// removes expired counters not more often than once per cleanup interval
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Before(s.nextCleanup) {
+		return
+	}
+	for key, c := range s.counters {
+		if !now.Before(c.resetAt) {
+			delete(s.counters, key)
+		}
+	}
+	s.nextCleanup = now.Add(memoryStoreCleanupInterval)
+}
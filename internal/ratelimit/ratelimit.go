@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Store keeps request counters for fixed time windows
+type Store interface {
+	// IncrementRateLimit increments counter for key and returns its value and time when current window ends.
+	// Counter starts over when window ends
+	IncrementRateLimit(ctx context.Context, key string, window time.Duration) (int, time.Time, error)
+}
+
+// Limit represents maximum number of requests allowed within a time window
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// ParseLimit parses limit in format "<requests>/<window>", e.g. "10/1m"
+func ParseLimit(s string) (Limit, error) {
+	requestsStr, windowStr, found := strings.Cut(strings.TrimSpace(s), "/")
+	if !found {
+		return Limit{}, fmt.Errorf("invalid limit %q: expected format <requests>/<window>", s)
+	}
+
+	requests, err := strconv.Atoi(requestsStr)
+	if err != nil {
+		return Limit{}, fmt.Errorf("invalid limit %q requests: %w", s, err)
+	}
+	if requests <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q: requests must be greater than 0", s)
+	}
+
+	window, err := time.ParseDuration(windowStr)
+	if err != nil {
+		return Limit{}, fmt.Errorf("invalid limit %q window: %w", s, err)
+	}
+	if window < time.Second {
+		return Limit{}, fmt.Errorf("invalid limit %q: window must be at least 1s", s)
+	}
+
+	return Limit{
+		Requests: requests,
+		Window:   window,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OutOfStack/game-library-auth/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected ratelimit.Limit
+		wantErr  bool
+	}{
+		{name: "minutes", input: "10/1m", expected: ratelimit.Limit{Requests: 10, Window: time.Minute}},
+		{name: "hours with spaces", input: " 5/1h ", expected: ratelimit.Limit{Requests: 5, Window: time.Hour}},
+		{name: "missing separator", input: "10", wantErr: true},
+		{name: "invalid requests", input: "ten/1m", wantErr: true},
+		{name: "zero requests", input: "0/1m", wantErr: true},
+		{name: "invalid window", input: "10/minute", wantErr: true},
+		{name: "window too short", input: "10/100ms", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, err := ratelimit.ParseLimit(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, limit)
+		})
+	}
+}
+
+func TestMemoryStore_IncrementRateLimit(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	ctx := t.Context()
+
+	count, resetAt, err := store.IncrementRateLimit(ctx, "key", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), resetAt, time.Second)
+
+	count, resetAt2, err := store.IncrementRateLimit(ctx, "key", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, resetAt, resetAt2)
+
+	// other keys are counted separately
+	count, _, err = store.IncrementRateLimit(ctx, "other", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestMemoryStore_WindowReset(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	ctx := t.Context()
+
+	_, _, err := store.IncrementRateLimit(ctx, "key", 50*time.Millisecond)
+	require.NoError(t, err)
+	_, _, err = store.IncrementRateLimit(ctx, "key", 50*time.Millisecond)
+	require.NoError(t, err)
+
+	time.Sleep(60 * time.Millisecond)
+
+	count, _, err := store.IncrementRateLimit(ctx, "key", 50*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,69 @@
+package crypto_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/OutOfStack/game-library-auth/pkg/crypto"
+)
+
+func TestCipher_EncryptDecrypt(t *testing.T) {
+	key, err := crypto.GenerateSecret(32)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	c, err := crypto.NewCipher(key)
+	if err != nil {
+		t.Fatalf("creating cipher: %v", err)
+	}
+
+	plaintext := []byte("JBSWY3DPEHPK3PXP")
+	encrypted, err := c.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("encrypting: %v", err)
+	}
+	if bytes.Contains(encrypted, plaintext) {
+		t.Error("expected encrypted data not to contain plaintext")
+	}
+
+	decrypted, err := c.Decrypt(encrypted)
+	if err != nil {
+		t.Fatalf("decrypting: %v", err)
+	}
+	if !bytes.Equal(plaintext, decrypted) {
+		t.Errorf("expected %q, got %q", plaintext, decrypted)
+	}
+}
+
+func TestCipher_DecryptTampered(t *testing.T) {
+	key, _ := crypto.GenerateSecret(32)
+	c, err := crypto.NewCipher(key)
+	if err != nil {
+		t.Fatalf("creating cipher: %v", err)
+	}
+
+	encrypted, err := c.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatalf("encrypting: %v", err)
+	}
+	encrypted[len(encrypted)-1] ^= 0xff
+
+	if _, err = c.Decrypt(encrypted); err == nil {
+		t.Error("expected error for tampered ciphertext")
+	}
+	if _, err = c.Decrypt([]byte("short")); err == nil {
+		t.Error("expected error for short ciphertext")
+	}
+}
+
+func TestNewCipher_InvalidKey(t *testing.T) {
+	if _, err := crypto.NewCipher("not base64!"); err == nil {
+		t.Error("expected error for invalid base64 key")
+	}
+
+	shortKey, _ := crypto.GenerateSecret(16)
+	if _, err := crypto.NewCipher(shortKey); err == nil {
+		t.Error("expected error for short key")
+	}
+}
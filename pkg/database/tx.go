@@ -26,6 +26,7 @@ func TxFromContext(ctx context.Context) (*sqlx.Tx, bool) {
 type Querier interface {
 	Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 	Get(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	Select(ctx context.Context, dest interface{}, query string, args ...interface{}) error
 }
 
 // Ex wraps db/tx with context
@@ -44,6 +45,7 @@ func NewQuerier(db Executor) Querier {
 type Executor interface {
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
 }
 
 // Exec executes a query with context
@@ -61,3 +63,11 @@ func (e *Ex) Get(ctx context.Context, dest interface{}, query string, args ...in
 	}
 	return e.db.GetContext(ctx, dest, query, args...)
 }
+
+// Select retrieves multiple rows with context
+func (e *Ex) Select(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.SelectContext(ctx, dest, query, args...)
+	}
+	return e.db.SelectContext(ctx, dest, query, args...)
+}
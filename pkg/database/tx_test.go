@@ -100,3 +100,21 @@ func TestEx_Get(t *testing.T) {
 
 	assert.NoError(t, err)
 }
+
+func TestEx_Select(t *testing.T) {
+	ctx := t.Context()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDB := mocks.NewMockExecutor(ctrl)
+
+	query := "SELECT name FROM users WHERE role = ?"
+	args := []interface{}{"user"}
+	dest := &[]struct{ Name string }{}
+
+	mockDB.EXPECT().SelectContext(ctx, dest, query, args[0]).Return(nil)
+
+	querier := database.NewQuerier(mockDB)
+	err := querier.Select(ctx, dest, query, args...)
+
+	assert.NoError(t, err)
+}
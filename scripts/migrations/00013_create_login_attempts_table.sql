@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE login_attempts (
+    kind            VARCHAR(16)     NOT NULL,
+    subject         VARCHAR(255)    NOT NULL,
+    failed_count    INT             NOT NULL    DEFAULT 0,
+    locked_until    TIMESTAMPTZ,
+    last_failed_at  TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+
+    PRIMARY KEY (kind, subject)
+);
+
+CREATE INDEX login_attempts_subject_idx ON login_attempts (subject);
+
+-- +migrate Down
+DROP TABLE IF EXISTS login_attempts;
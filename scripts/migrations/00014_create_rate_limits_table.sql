@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE rate_limits (
+    key             VARCHAR(255)    NOT NULL,
+    count           INT             NOT NULL    DEFAULT 0,
+    expires_at      TIMESTAMPTZ     NOT NULL,
+
+    PRIMARY KEY (key)
+);
+
+CREATE INDEX rate_limits_expires_at_idx ON rate_limits (expires_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS rate_limits;
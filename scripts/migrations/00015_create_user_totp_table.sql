@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE user_totp (
+    user_id         UUID            NOT NULL,
+    secret          BYTEA           NOT NULL,
+    confirmed_at    TIMESTAMPTZ,
+    last_used_step  BIGINT          NOT NULL    DEFAULT 0,
+    date_created    TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+
+    PRIMARY KEY (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS user_totp;
@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE recovery_codes (
+    id              UUID            DEFAULT gen_random_uuid(),
+    user_id         UUID            NOT NULL,
+    code_hash       VARCHAR(64)     NOT NULL,
+    used_at         TIMESTAMPTZ,
+    date_created    TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+
+    PRIMARY KEY (id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX recovery_codes_user_id_code_hash_idx ON recovery_codes (user_id, code_hash);
+
+-- +migrate Down
+DROP TABLE IF EXISTS recovery_codes;
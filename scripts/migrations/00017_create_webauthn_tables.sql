@@ -0,0 +1,34 @@
+-- +migrate Up
+CREATE TABLE webauthn_credentials (
+    id              UUID            DEFAULT gen_random_uuid(),
+    user_id         UUID            NOT NULL,
+    credential_id   BYTEA           NOT NULL,
+    name            VARCHAR(64)     NOT NULL,
+    data            JSONB           NOT NULL,
+    last_used_at    TIMESTAMPTZ,
+    date_created    TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+
+    PRIMARY KEY (id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX webauthn_credentials_credential_id_idx ON webauthn_credentials (credential_id);
+CREATE INDEX webauthn_credentials_user_id_idx ON webauthn_credentials (user_id);
+
+CREATE TABLE webauthn_sessions (
+    id              UUID            DEFAULT gen_random_uuid(),
+    user_id         UUID,
+    kind            VARCHAR(16)     NOT NULL,
+    data            JSONB           NOT NULL,
+    expires_at      TIMESTAMPTZ     NOT NULL,
+    date_created    TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+
+    PRIMARY KEY (id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX webauthn_sessions_expires_at_idx ON webauthn_sessions (expires_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS webauthn_sessions;
+DROP TABLE IF EXISTS webauthn_credentials;
@@ -0,0 +1,23 @@
+-- +migrate Up
+CREATE TABLE email_sign_ins (
+    id              UUID            DEFAULT gen_random_uuid(),
+    user_id         UUID            NOT NULL,
+    code_hash       VARCHAR(64),
+    token_hash      VARCHAR(64),
+    message_id      VARCHAR(64),
+    used_at         TIMESTAMPTZ,
+    date_created    TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+
+    PRIMARY KEY (id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX email_sign_ins_user_id_pending_idx
+    ON email_sign_ins (user_id, date_created DESC)
+    WHERE used_at IS NULL AND code_hash IS NOT NULL;
+
+CREATE UNIQUE INDEX email_sign_ins_token_hash_idx
+    ON email_sign_ins (token_hash) WHERE token_hash IS NOT NULL;
+
+-- +migrate Down
+DROP TABLE IF EXISTS email_sign_ins;
@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE email_verifications
+    ADD COLUMN failed_attempts INT NOT NULL DEFAULT 0;
+
+ALTER TABLE email_sign_ins
+    ADD COLUMN failed_attempts INT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE email_sign_ins
+    DROP COLUMN failed_attempts;
+
+ALTER TABLE email_verifications
+    DROP COLUMN failed_attempts;
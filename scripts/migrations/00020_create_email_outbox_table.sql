@@ -0,0 +1,24 @@
+-- +migrate Up
+CREATE TABLE email_outbox (
+    id              UUID            DEFAULT gen_random_uuid(),
+    kind            VARCHAR(32)     NOT NULL,
+    recipient       VARCHAR(255)    NOT NULL,
+    reference_id    UUID,
+    payload         BYTEA,
+    status          VARCHAR(16)     NOT NULL    DEFAULT 'pending',
+    attempts        INT             NOT NULL    DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+    last_error      TEXT,
+    message_id      VARCHAR(64),
+    sent_at         TIMESTAMPTZ,
+    date_created    TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX email_outbox_pending_idx
+    ON email_outbox (next_attempt_at)
+    WHERE status = 'pending';
+
+-- +migrate Down
+DROP TABLE IF EXISTS email_outbox;
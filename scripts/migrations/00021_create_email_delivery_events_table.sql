@@ -0,0 +1,25 @@
+-- +migrate Up
+ALTER TABLE email_unsubscribes
+    ADD COLUMN reason VARCHAR(32) NOT NULL DEFAULT 'user';
+
+CREATE TABLE email_delivery_events (
+    id              UUID            DEFAULT gen_random_uuid(),
+    event_id        VARCHAR(64)     NOT NULL,
+    message_id      VARCHAR(64)     NOT NULL,
+    email           VARCHAR(255)    NOT NULL,
+    status          VARCHAR(16)     NOT NULL,
+    detail          TEXT,
+    occurred_at     TIMESTAMPTZ     NOT NULL,
+    date_created    TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX email_delivery_events_event_id_idx ON email_delivery_events (event_id);
+CREATE INDEX email_delivery_events_email_idx ON email_delivery_events (email, occurred_at DESC);
+
+-- +migrate Down
+DROP TABLE IF EXISTS email_delivery_events;
+
+ALTER TABLE email_unsubscribes
+    DROP COLUMN reason;
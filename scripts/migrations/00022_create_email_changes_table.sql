@@ -0,0 +1,21 @@
+-- +migrate Up
+CREATE TABLE email_changes (
+    id              UUID            DEFAULT gen_random_uuid(),
+    user_id         UUID            NOT NULL,
+    new_email       VARCHAR(255)    NOT NULL,
+    code_hash       VARCHAR(64),
+    failed_attempts INT             NOT NULL    DEFAULT 0,
+    message_id      VARCHAR(64),
+    used_at         TIMESTAMPTZ,
+    date_created    TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+
+    PRIMARY KEY (id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX email_changes_user_id_pending_idx
+    ON email_changes (user_id, date_created DESC)
+    WHERE used_at IS NULL AND code_hash IS NOT NULL;
+
+-- +migrate Down
+DROP TABLE IF EXISTS email_changes;
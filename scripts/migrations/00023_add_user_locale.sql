@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE users
+    ADD COLUMN locale VARCHAR(35) NOT NULL DEFAULT 'en';
+
+-- +migrate Down
+ALTER TABLE users
+    DROP COLUMN locale;
@@ -0,0 +1,34 @@
+-- +migrate Up
+CREATE TABLE email_preferences (
+    email           VARCHAR(255)    NOT NULL,
+    category        VARCHAR(32)     NOT NULL,
+    subscribed      BOOLEAN         NOT NULL,
+    date_updated    TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+
+    PRIMARY KEY (email, category)
+);
+
+-- addresses unsubscribed by link are opted out of all categories except security
+INSERT INTO email_preferences (email, category, subscribed, date_updated)
+SELECT u.email, c.category, FALSE, u.date_created
+FROM email_unsubscribes u
+CROSS JOIN (VALUES ('product_news'), ('publisher_reports')) AS c (category)
+WHERE u.reason = 'user';
+
+DELETE FROM email_unsubscribes WHERE reason = 'user';
+
+ALTER TABLE email_unsubscribes
+    ALTER COLUMN reason DROP DEFAULT;
+
+-- +migrate Down
+ALTER TABLE email_unsubscribes
+    ALTER COLUMN reason SET DEFAULT 'user';
+
+INSERT INTO email_unsubscribes (email, reason, date_created)
+SELECT email, 'user', MIN(date_updated)
+FROM email_preferences
+WHERE NOT subscribed
+GROUP BY email
+ON CONFLICT (email) DO NOTHING;
+
+DROP TABLE IF EXISTS email_preferences;
@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE email_resubscribes (
+    id                  UUID            DEFAULT gen_random_uuid(),
+    email               VARCHAR(255)    NOT NULL,
+    source              VARCHAR(16)     NOT NULL,
+    user_id             UUID,
+    unsubscribe_reason  VARCHAR(32),
+    date_unsubscribed   TIMESTAMPTZ,
+    date_created        TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX email_resubscribes_email_idx ON email_resubscribes (email, date_created DESC);
+
+-- +migrate Down
+DROP TABLE IF EXISTS email_resubscribes;
@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE user_devices (
+    user_id         UUID            NOT NULL,
+    device_hash     VARCHAR(64)     NOT NULL,
+    user_agent      VARCHAR(255)    NOT NULL,
+    client_ip       VARCHAR(45),
+    date_created    TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+    date_last_seen  TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, device_hash),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS user_devices;
@@ -0,0 +1,27 @@
+-- +migrate Up
+ALTER TABLE email_outbox
+    ADD COLUMN user_id UUID;
+
+CREATE TABLE email_messages (
+    id                  UUID            DEFAULT gen_random_uuid(),
+    outbox_id           UUID            NOT NULL,
+    user_id             UUID,
+    template            VARCHAR(32)     NOT NULL,
+    recipient           VARCHAR(255)    NOT NULL,
+    provider_message_id VARCHAR(64),
+    status              VARCHAR(16)     NOT NULL,
+    error               TEXT,
+    date_created        TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+    date_updated        TIMESTAMPTZ     NOT NULL    DEFAULT NOW(),
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX email_messages_user_id_idx ON email_messages (user_id, date_created DESC);
+CREATE INDEX email_messages_provider_message_id_idx ON email_messages (provider_message_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS email_messages;
+
+ALTER TABLE email_outbox
+    DROP COLUMN user_id;
@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE email_verifications
+    ADD COLUMN provider VARCHAR(16);
+
+ALTER TABLE email_messages
+    ADD COLUMN provider VARCHAR(16);
+
+-- +migrate Down
+ALTER TABLE email_messages
+    DROP COLUMN provider;
+
+ALTER TABLE email_verifications
+    DROP COLUMN provider;